@@ -15,15 +15,20 @@ require (
 	github.com/go-redis/redis/v8 v8.4.0
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/gofrs/flock v0.8.0
+	github.com/gosnmp/gosnmp v1.34.0
 	github.com/ilyam8/hashstructure v1.1.0
 	github.com/jessevdk/go-flags v1.4.0
+	github.com/jmespath/go-jmespath v0.4.0
+	github.com/lib/pq v1.10.2
 	github.com/likexian/whois v1.12.0
 	github.com/likexian/whois-parser v1.20.3
 	github.com/mattn/go-isatty v0.0.12
 	github.com/mattn/go-xmlrpc v0.0.3
+	github.com/matttproud/golang_protobuf_extensions v1.0.1
 	github.com/miekg/dns v1.1.29
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
+	github.com/prometheus/client_model v0.2.0
 	github.com/prometheus/common v0.10.0 // indirect
 	github.com/prometheus/prometheus v2.5.0+incompatible
 	github.com/smartystreets/goconvey v1.6.4 // indirect
@@ -31,8 +36,11 @@ require (
 	github.com/tomasen/fcgi_client v0.0.0-20180423082037-2bb3d819fd19
 	github.com/valyala/fastjson v1.5.4
 	github.com/vmware/govmomi v0.22.2
+	go.mongodb.org/mongo-driver v1.8.6
+	golang.org/x/net v0.0.0-20210510120150-4163338589ed
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 	gopkg.in/ini.v1 v1.57.0
 	gopkg.in/yaml.v2 v2.3.0
 	layeh.com/radius v0.0.0-20190322222518-890bc1058917
+	modernc.org/sqlite v1.17.3
 )
@@ -2,9 +2,11 @@ package cockroachdb
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -45,8 +47,9 @@ func New() *CockroachDB {
 
 type (
 	Config struct {
-		web.HTTP    `yaml:",inline"`
-		UpdateEvery int `yaml:"update_every"`
+		web.HTTP        `yaml:",inline"`
+		relabel.Configs `yaml:",inline"`
+		UpdateEvery     int `yaml:"update_every"`
 	}
 
 	CockroachDB struct {
@@ -71,7 +74,12 @@ func (c *CockroachDB) initClient() error {
 		return err
 	}
 
-	c.prom = prometheus.New(client, c.Request)
+	rl, err := c.Configs.Parse()
+	if err != nil {
+		return fmt.Errorf("parsing relabel configs: %v", err)
+	}
+
+	c.prom = prometheus.NewWithRelabeling(client, c.Request, nil, rl)
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -45,9 +46,10 @@ func New() *CoreDNS {
 
 // Config is the CoreDNS module configuration.
 type Config struct {
-	web.HTTP       `yaml:",inline"`
-	PerServerStats matcher.SimpleExpr `yaml:"per_server_stats"`
-	PerZoneStats   matcher.SimpleExpr `yaml:"per_zone_stats"`
+	web.HTTP        `yaml:",inline"`
+	relabel.Configs `yaml:",inline"`
+	PerServerStats  matcher.SimpleExpr `yaml:"per_server_stats"`
+	PerZoneStats    matcher.SimpleExpr `yaml:"per_zone_stats"`
 }
 
 // CoreDNS CoreDNS module.
@@ -96,7 +98,13 @@ func (cd *CoreDNS) Init() bool {
 		return false
 	}
 
-	cd.prom = prometheus.New(client, cd.Request)
+	rl, err := cd.Configs.Parse()
+	if err != nil {
+		cd.Errorf("error on parsing relabel configs : %v", err)
+		return false
+	}
+
+	cd.prom = prometheus.NewWithRelabeling(client, cd.Request, nil, rl)
 
 	return true
 }
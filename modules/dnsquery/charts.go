@@ -1,10 +1,17 @@
 package dnsquery
 
-import "github.com/netdata/go.d.plugin/agent/module"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
 
 type (
 	// Charts is an alias for module.Charts
 	Charts = module.Charts
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
 	// Dim is an alias for module.Dim
 	Dim = module.Dim
 )
@@ -18,3 +25,40 @@ var charts = Charts{
 		Ctx:   "dns_query_time.query_time",
 	},
 }
+
+var queryCharts = Charts{
+	{
+		ID:    "%s_query_status",
+		Title: "DNS Query Status",
+		Units: "boolean",
+		Ctx:   "dns_query_time.query_status",
+	},
+	{
+		ID:    "%s_response_code",
+		Title: "DNS Response Code",
+		Units: "boolean",
+		Ctx:   "dns_query_time.response_code",
+	},
+}
+
+func newQueryCharts(q *query, statuses []string) *Charts {
+	cs := queryCharts.Copy()
+
+	status := cs.Get("%s_query_status")
+	for _, s := range statuses {
+		_ = status.AddDim(&Dim{ID: "%s_" + s, Name: strings.ReplaceAll(s, "_", " ")})
+	}
+	rcode := cs.Get("%s_response_code")
+	for _, code := range responseCodes {
+		_ = rcode.AddDim(&Dim{ID: "%s_rcode_" + code, Name: code})
+	}
+
+	for _, chart := range *cs {
+		chart.ID = fmt.Sprintf(chart.ID, q.id)
+		chart.Fam = q.name
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, q.id)
+		}
+	}
+	return cs
+}
@@ -0,0 +1,185 @@
+package dnsquery
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	statusSuccess          = "success"
+	statusTimeout          = "timeout"
+	statusNoConnection     = "no_connection"
+	statusBadRcode         = "bad_rcode"
+	statusNotAuthenticated = "not_authenticated"
+	statusBadAnswer        = "bad_answer"
+	statusBadTTL           = "bad_ttl"
+)
+
+var responseCodes = []string{"noerror", "formerr", "servfail", "nxdomain", "notimp", "refused", "other"}
+
+func (d *DNSQuery) collect() map[string]int64 {
+	domain := randomDomain(d.Domains)
+	d.Debugf("current domain : %s", domain)
+
+	for _, q := range d.queries {
+		d.task <- task{query: q, domain: domain, dnssec: d.DNSSEC, ad: d.DNSSEC || d.expect.authenticatedData}
+	}
+
+	for range d.queries {
+		<-d.taskDone
+	}
+
+	mx := make(map[string]int64)
+
+	for _, q := range d.queries {
+		d.collectQuery(mx, q, domain)
+	}
+
+	return mx
+}
+
+func (d *DNSQuery) collectQuery(mx map[string]int64, q *query, domain string) {
+	rtype := dns.TypeToString[q.rtype]
+
+	for _, s := range d.queryStatuses(q) {
+		mx[q.id+"_"+s] = 0
+	}
+	for _, code := range responseCodes {
+		mx[q.id+"_rcode_"+code] = 0
+	}
+
+	if q.err != nil {
+		d.Debugf("error on querying %s after %s query for %s : %s", q.server.name, rtype, domain, q.err)
+		if v, ok := q.err.(interface{ Timeout() bool }); ok && v.Timeout() {
+			mx[q.id+"_"+statusTimeout] = 1
+		} else {
+			mx[q.id+"_"+statusNoConnection] = 1
+		}
+		return
+	}
+
+	if q.resp == nil {
+		mx[q.id+"_"+statusNoConnection] = 1
+		return
+	}
+
+	mx[q.id+"_rcode_"+rcodeName(q.resp.Rcode)] = 1
+
+	if q.resp.Rcode != d.expect.rcode {
+		d.Errorf("invalid answer from %s after %s query for %s : %s", q.server.name, rtype, domain, dns.RcodeToString[q.resp.Rcode])
+		mx[q.id+"_"+statusBadRcode] = 1
+		return
+	}
+
+	mx[q.id] = q.rtt.Nanoseconds()
+
+	status := d.verifyResponse(q)
+	if status != statusSuccess {
+		d.Debugf("unexpected answer from %s after %s query for %s : %s", q.server.name, rtype, domain, status)
+	}
+	mx[q.id+"_"+status] = 1
+}
+
+func (d *DNSQuery) verifyResponse(q *query) string {
+	resp := q.resp
+
+	if d.expect.authenticatedData && !resp.AuthenticatedData {
+		return statusNotAuthenticated
+	}
+
+	if expected, ok := d.expect.answers[q.rtype]; ok && !hasAnswers(resp, q.rtype, expected) {
+		return statusBadAnswer
+	}
+
+	if d.expect.minTTL > 0 || d.expect.maxTTL > 0 {
+		for _, rr := range resp.Answer {
+			ttl := int64(rr.Header().Ttl)
+			if ttl < d.expect.minTTL || (d.expect.maxTTL > 0 && ttl > d.expect.maxTTL) {
+				return statusBadTTL
+			}
+		}
+	}
+
+	return statusSuccess
+}
+
+func (d *DNSQuery) queryStatuses(q *query) []string {
+	statuses := []string{statusSuccess, statusTimeout, statusNoConnection, statusBadRcode}
+	if d.expect == nil {
+		return statuses
+	}
+	if d.expect.authenticatedData {
+		statuses = append(statuses, statusNotAuthenticated)
+	}
+	if _, ok := d.expect.answers[q.rtype]; ok {
+		statuses = append(statuses, statusBadAnswer)
+	}
+	if d.expect.minTTL > 0 || d.expect.maxTTL > 0 {
+		statuses = append(statuses, statusBadTTL)
+	}
+	return statuses
+}
+
+func hasAnswers(resp *dns.Msg, rtype uint16, expected []string) bool {
+	values := make(map[string]bool)
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype != rtype {
+			continue
+		}
+		if v, ok := answerValue(rr); ok {
+			values[v] = true
+		}
+	}
+
+	for _, v := range expected {
+		if !values[v] {
+			return false
+		}
+	}
+	return true
+}
+
+func answerValue(rr dns.RR) (string, bool) {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A.String(), true
+	case *dns.AAAA:
+		return v.AAAA.String(), true
+	case *dns.CNAME:
+		return strings.ToLower(v.Target), true
+	case *dns.MX:
+		return strings.ToLower(v.Mx), true
+	case *dns.NS:
+		return strings.ToLower(v.Ns), true
+	case *dns.PTR:
+		return strings.ToLower(v.Ptr), true
+	case *dns.SOA:
+		return strings.ToLower(v.Ns), true
+	case *dns.SRV:
+		return strings.ToLower(v.Target), true
+	case *dns.TXT:
+		return strings.Join(v.Txt, ""), true
+	case *dns.SPF:
+		return strings.Join(v.Txt, ""), true
+	}
+	return "", false
+}
+
+func rcodeName(rcode int) string {
+	switch rcode {
+	case dns.RcodeSuccess:
+		return "noerror"
+	case dns.RcodeFormatError:
+		return "formerr"
+	case dns.RcodeServerFailure:
+		return "servfail"
+	case dns.RcodeNameError:
+		return "nxdomain"
+	case dns.RcodeNotImplemented:
+		return "notimp"
+	case dns.RcodeRefused:
+		return "refused"
+	}
+	return "other"
+}
@@ -1,12 +1,12 @@
 package dnsquery
 
 import (
-	"errors"
+	"crypto/tls"
 	"fmt"
 	"math/rand"
-	"strings"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/miekg/dns"
@@ -29,6 +29,8 @@ const (
 	defaultNetwork    = "udp"
 	defaultRecordType = "A"
 	defaultPort       = 53
+	defaultTLSPort    = 853
+	defaultHTTPSPort  = 443
 )
 
 // New creates DNSQuery with default values
@@ -37,7 +39,6 @@ func New() *DNSQuery {
 		Timeout:    web.Duration{Duration: defaultTimeout},
 		Network:    defaultNetwork,
 		RecordType: defaultRecordType,
-		Port:       defaultPort,
 
 		task:             make(chan task),
 		taskDone:         make(chan struct{}),
@@ -47,21 +48,35 @@ func New() *DNSQuery {
 	}
 }
 
-type exchanger interface {
-	Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error)
+// ExpectConfig is the expected response configuration.
+type ExpectConfig struct {
+	Rcode             string              `yaml:"rcode"`
+	Answers           map[string][]string `yaml:"answers"`
+	MinTTL            int64               `yaml:"min_ttl"`
+	MaxTTL            int64               `yaml:"max_ttl"`
+	AuthenticatedData bool                `yaml:"authenticated_data"`
 }
 
-func newExchanger(network string, timeout time.Duration) exchanger {
-	return &dns.Client{
-		Net:         network,
-		ReadTimeout: timeout,
-	}
+type expectation struct {
+	rcode             int
+	answers           map[uint16][]string
+	minTTL            int64
+	maxTTL            int64
+	authenticatedData bool
 }
 
 type server struct {
-	id   string
-	name string
-	port int
+	id      string
+	name    string
+	port    int
+	address string
+}
+
+type query struct {
+	id     string
+	name   string
+	server *server
+	rtype  uint16
 
 	resp *dns.Msg
 	rtt  time.Duration
@@ -72,20 +87,26 @@ type server struct {
 type DNSQuery struct {
 	module.Base
 
-	Domains    []string
-	Servers    []string
-	Network    string
-	RecordType string `yaml:"record_type"`
-	Port       int
-	Timeout    web.Duration
+	Domains          []string
+	Servers          []string
+	Network          string
+	RecordType       string   `yaml:"record_type"`
+	RecordTypes      []string `yaml:"record_types"`
+	Port             int
+	Timeout          web.Duration
+	DNSSEC           bool         `yaml:"dnssec"`
+	Expect           ExpectConfig `yaml:"expect"`
+	tlscfg.TLSConfig `yaml:",inline"`
 
 	task     chan task
 	taskDone chan struct{}
 
-	exchangerFactory func(network string, duration time.Duration) exchanger
+	exchangerFactory func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger
 
-	rtype   uint16
+	rtypes  []uint16
+	expect  *expectation
 	servers []*server
+	queries []*query
 	workers []*worker
 }
 
@@ -98,29 +119,6 @@ func (d *DNSQuery) Cleanup() {
 	d.workers = make([]*worker, 0)
 }
 
-func (d *DNSQuery) setup() error {
-	if len(d.Domains) == 0 {
-		return errors.New("no domains specified")
-	}
-
-	if len(d.Servers) == 0 {
-		return errors.New("no servers specified")
-	}
-
-	if !(d.Network == "" || d.Network == "udp" || d.Network == "tcp" || d.Network == "tcp-tls") {
-		return fmt.Errorf("wrong network transport : %s", d.Network)
-	}
-
-	rtype, err := parseRecordType(d.RecordType)
-
-	if err != nil {
-		return fmt.Errorf("error on parsing record type : %s", err)
-	}
-	d.rtype = rtype
-
-	return nil
-}
-
 // Init makes initialization
 func (d *DNSQuery) Init() bool {
 	if err := d.setup(); err != nil {
@@ -128,10 +126,16 @@ func (d *DNSQuery) Init() bool {
 		return false
 	}
 
-	exch := d.exchangerFactory(d.Network, d.Timeout.Duration)
+	tlsConfig, err := tlscfg.NewTLSConfig(d.TLSConfig)
+	if err != nil {
+		d.Errorf("error on creating TLS config : %v", err)
+		return false
+	}
 
-	for _, srv := range d.Servers {
-		d.servers = append(d.servers, &server{id: serverNameReplacer.Replace(srv), name: srv, port: d.Port})
+	exch := d.exchangerFactory(d.Network, d.Timeout.Duration, tlsConfig)
+
+	d.initServers()
+	for range d.queries {
 		// newWorker spawns worker goroutine
 		d.workers = append(d.workers, newWorker(exch, d.task, d.taskDone))
 	}
@@ -148,14 +152,19 @@ func (DNSQuery) Check() bool {
 func (d DNSQuery) Charts() *Charts {
 	charts := charts.Copy()
 
-	for _, srv := range d.servers {
+	for _, q := range d.queries {
 		chart := charts.Get("query_time")
-		dim := &Dim{ID: srv.id, Name: srv.name, Div: 1000000}
+		dim := &Dim{ID: q.id, Name: q.name, Div: 1000000}
 
 		if err := chart.AddDim(dim); err != nil {
 			d.Errorf("error on creating charts : %s", err)
 			return nil
 		}
+
+		if err := charts.Add(*newQueryCharts(q, d.queryStatuses(q))...); err != nil {
+			d.Errorf("error on creating charts : %s", err)
+			return nil
+		}
 	}
 
 	return charts
@@ -163,38 +172,13 @@ func (d DNSQuery) Charts() *Charts {
 
 // Collect collects metrics
 func (d *DNSQuery) Collect() map[string]int64 {
-	domain := randomDomain(d.Domains)
-	d.Debugf("current domain : %s", domain)
-
-	for _, srv := range d.servers {
-		d.task <- task{server: srv, domain: domain, rtype: d.rtype}
-	}
-
-	for range d.servers {
-		<-d.taskDone
-	}
+	mx := d.collect()
 
-	metrics := make(map[string]int64)
-
-	for _, srv := range d.servers {
-		if srv.resp != nil && srv.resp.Rcode != dns.RcodeSuccess {
-			d.Errorf("invalid answer from %s after %s query for %s", srv.name, d.RecordType, domain)
-			continue
-		}
-
-		if srv.err != nil {
-			d.Debugf("error on querying %s after %s query for %s : %s", srv.name, d.RecordType, domain, srv.err)
-			continue
-		}
-
-		metrics[srv.id] = srv.rtt.Nanoseconds()
-	}
-
-	if len(metrics) == 0 {
+	if len(mx) == 0 {
 		return nil
 	}
 
-	return metrics
+	return mx
 }
 
 func parseRecordType(recordType string) (uint16, error) {
@@ -234,5 +218,3 @@ func randomDomain(domains []string) string {
 	rand.Seed(time.Now().UnixNano())
 	return domains[rand.Intn(len(domains))]
 }
-
-var serverNameReplacer = strings.NewReplacer(".", "_")
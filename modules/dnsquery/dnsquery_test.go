@@ -1,7 +1,12 @@
 package dnsquery
 
 import (
+	"crypto/tls"
 	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -76,7 +81,7 @@ func TestDNSQuery_Collect(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
-	mod.exchangerFactory = func(network string, duration time.Duration) exchanger {
+	mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
 		return okMockExchanger{}
 	}
 
@@ -85,7 +90,20 @@ func TestDNSQuery_Collect(t *testing.T) {
 
 	assert.Equal(
 		t,
-		map[string]int64{"8_8_8_8": 1000000000},
+		map[string]int64{
+			"8_8_8_8":                1000000000,
+			"8_8_8_8_bad_rcode":      0,
+			"8_8_8_8_no_connection":  0,
+			"8_8_8_8_rcode_formerr":  0,
+			"8_8_8_8_rcode_noerror":  1,
+			"8_8_8_8_rcode_notimp":   0,
+			"8_8_8_8_rcode_nxdomain": 0,
+			"8_8_8_8_rcode_other":    0,
+			"8_8_8_8_rcode_refused":  0,
+			"8_8_8_8_rcode_servfail": 0,
+			"8_8_8_8_success":        1,
+			"8_8_8_8_timeout":        0,
+		},
 		mod.Collect(),
 	)
 }
@@ -96,24 +114,371 @@ func TestDNSQuery_Collect_Error(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
-	mod.exchangerFactory = func(network string, duration time.Duration) exchanger {
+	mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
 		return errMockExchanger{}
 	}
 
 	require.True(t, mod.Init())
 	require.True(t, mod.Check())
 
-	assert.Len(
-		t,
-		mod.Collect(),
-		0,
-	)
+	mx := mod.Collect()
+
+	assert.NotContains(t, mx, "8_8_8_8")
+	assert.Equal(t, int64(1), mx["8_8_8_8_no_connection"])
+	assert.Equal(t, int64(0), mx["8_8_8_8_success"])
+}
+
+func TestDNSQuery_Init_Config(t *testing.T) {
+	tests := map[string]struct {
+		config   func(d *DNSQuery)
+		wantFail bool
+	}{
+		"multiple record types": {
+			config: func(d *DNSQuery) { d.RecordTypes = []string{"A", "AAAA", "TXT"} },
+		},
+		"DNS over HTTPS": {
+			config: func(d *DNSQuery) { d.Network = "https" },
+		},
+		"full expect": {
+			config: func(d *DNSQuery) {
+				d.RecordTypes = []string{"A", "CNAME"}
+				d.Expect = ExpectConfig{
+					Rcode:             "NOERROR",
+					Answers:           map[string][]string{"A": {"192.0.2.1"}, "CNAME": {"example.com"}},
+					MinTTL:            60,
+					MaxTTL:            3600,
+					AuthenticatedData: true,
+				}
+			},
+		},
+		"unknown network": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.Network = "quic" },
+		},
+		"unknown record type": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.RecordTypes = []string{"A", "BAD"} },
+		},
+		"duplicate record type": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.RecordTypes = []string{"A", "A"} },
+		},
+		"unknown expected rcode": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.Expect.Rcode = "BAD" },
+		},
+		"expected answers for not queried record type": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.Expect.Answers = map[string][]string{"AAAA": {"2001:db8::1"}} },
+		},
+		"expected A answer is not an IP": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.Expect.Answers = map[string][]string{"A": {"example.com"}} },
+		},
+		"min TTL greater than max TTL": {
+			wantFail: true,
+			config:   func(d *DNSQuery) { d.Expect.MinTTL, d.Expect.MaxTTL = 100, 10 },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			defer mod.Cleanup()
+			mod.Domains = []string{"example.com"}
+			mod.Servers = []string{"127.0.0.1"}
+			test.config(mod)
+
+			if test.wantFail {
+				assert.False(t, mod.Init())
+			} else {
+				assert.True(t, mod.Init())
+			}
+		})
+	}
+}
+
+func TestDNSQuery_Charts_MultipleRecordTypes(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Domains = []string{"example.com"}
+	mod.Servers = []string{"127.0.0.1"}
+	mod.RecordTypes = []string{"A", "TXT"}
+	mod.Expect.Answers = map[string][]string{"TXT": {"v=spf1 -all"}}
+	require.True(t, mod.Init())
+
+	charts := mod.Charts()
+	require.NotNil(t, charts)
+	assert.Len(t, mod.workers, 2)
+	assert.True(t, charts.Get("query_time").HasDim("127_0_0_1_a"))
+	assert.True(t, charts.Get("query_time").HasDim("127_0_0_1_txt"))
+	assert.False(t, charts.Get("127_0_0_1_a_query_status").HasDim("127_0_0_1_a_bad_answer"))
+	assert.True(t, charts.Get("127_0_0_1_txt_query_status").HasDim("127_0_0_1_txt_bad_answer"))
+	assert.True(t, charts.Has("127_0_0_1_txt_response_code"))
+}
+
+func TestDNSQuery_Collect_Server(t *testing.T) {
+	srv := newTestDNSServers(t)
+	defer srv.Close()
+
+	tests := map[string]struct {
+		config        func(d *DNSQuery)
+		wantStatus    map[string]string
+		wantRcode     map[string]string
+		wantQueryTime bool
+	}{
+		"udp A success": {
+			config:        func(d *DNSQuery) {},
+			wantStatus:    map[string]string{"127_0_0_1": "success"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"tcp multiple record types with expected answers": {
+			config: func(d *DNSQuery) {
+				d.Network = "tcp"
+				d.Port = srv.tcpPort
+				d.RecordTypes = []string{"A", "AAAA", "TXT"}
+				d.Expect.Answers = map[string][]string{
+					"A":    {"192.0.2.1"},
+					"AAAA": {"2001:db8:0:0::1"},
+					"TXT":  {"v=spf1 -all"},
+				}
+			},
+			wantStatus:    map[string]string{"127_0_0_1_a": "success", "127_0_0_1_aaaa": "success", "127_0_0_1_txt": "success"},
+			wantRcode:     map[string]string{"127_0_0_1_a": "noerror", "127_0_0_1_aaaa": "noerror", "127_0_0_1_txt": "noerror"},
+			wantQueryTime: true,
+		},
+		"expected CNAME": {
+			config: func(d *DNSQuery) {
+				d.Domains = []string{"www.example.com"}
+				d.RecordTypes = []string{"CNAME"}
+				d.Expect.Answers = map[string][]string{"CNAME": {"Example.com"}}
+			},
+			wantStatus:    map[string]string{"127_0_0_1": "success"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"bad answer": {
+			config:        func(d *DNSQuery) { d.Expect.Answers = map[string][]string{"A": {"192.0.2.2"}} },
+			wantStatus:    map[string]string{"127_0_0_1": "bad_answer"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"bad TTL": {
+			config:        func(d *DNSQuery) { d.Expect.MinTTL, d.Expect.MaxTTL = 60, 120 },
+			wantStatus:    map[string]string{"127_0_0_1": "bad_ttl"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"NXDOMAIN": {
+			config:     func(d *DNSQuery) { d.Domains = []string{"nxdomain.example.com"} },
+			wantStatus: map[string]string{"127_0_0_1": "bad_rcode"},
+			wantRcode:  map[string]string{"127_0_0_1": "nxdomain"},
+		},
+		"expected NXDOMAIN": {
+			config: func(d *DNSQuery) {
+				d.Domains = []string{"nxdomain.example.com"}
+				d.Expect.Rcode = "NXDOMAIN"
+			},
+			wantStatus:    map[string]string{"127_0_0_1": "success"},
+			wantRcode:     map[string]string{"127_0_0_1": "nxdomain"},
+			wantQueryTime: true,
+		},
+		"SERVFAIL": {
+			config:     func(d *DNSQuery) { d.Domains = []string{"servfail.example.com"} },
+			wantStatus: map[string]string{"127_0_0_1": "bad_rcode"},
+			wantRcode:  map[string]string{"127_0_0_1": "servfail"},
+		},
+		"authenticated data": {
+			config: func(d *DNSQuery) {
+				d.Domains = []string{"secure.example.com"}
+				d.DNSSEC = true
+				d.Expect.AuthenticatedData = true
+			},
+			wantStatus:    map[string]string{"127_0_0_1": "success"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"not authenticated data": {
+			config:        func(d *DNSQuery) { d.Expect.AuthenticatedData = true },
+			wantStatus:    map[string]string{"127_0_0_1": "not_authenticated"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"DNS over TLS": {
+			config: func(d *DNSQuery) {
+				d.Network = "tcp-tls"
+				d.Port = srv.tlsPort
+				d.InsecureSkipVerify = true
+				d.Expect.Answers = map[string][]string{"A": {"192.0.2.1"}}
+			},
+			wantStatus:    map[string]string{"127_0_0_1": "success"},
+			wantRcode:     map[string]string{"127_0_0_1": "noerror"},
+			wantQueryTime: true,
+		},
+		"DNS over TLS fails certificate verification": {
+			config: func(d *DNSQuery) {
+				d.Network = "tcp-tls"
+				d.Port = srv.tlsPort
+			},
+			wantStatus: map[string]string{"127_0_0_1": "no_connection"},
+		},
+		"DNS over HTTPS": {
+			config: func(d *DNSQuery) {
+				d.Network = "https"
+				d.Servers = []string{srv.dohURL}
+				d.InsecureSkipVerify = true
+				d.Expect.Answers = map[string][]string{"A": {"192.0.2.1"}}
+			},
+			wantStatus:    map[string]string{srv.dohID: "success"},
+			wantRcode:     map[string]string{srv.dohID: "noerror"},
+			wantQueryTime: true,
+		},
+		"connection refused": {
+			config: func(d *DNSQuery) {
+				d.Network = "tcp"
+				d.Port = srv.closedPort
+			},
+			wantStatus: map[string]string{"127_0_0_1": "no_connection"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			defer mod.Cleanup()
+			mod.Domains = []string{"example.com"}
+			mod.Servers = []string{"127.0.0.1"}
+			mod.Port = srv.udpPort
+			test.config(mod)
+			require.True(t, mod.Init())
+
+			mx := mod.Collect()
+			require.NotNil(t, mx)
+
+			for id, status := range test.wantStatus {
+				assert.Equalf(t, int64(1), mx[id+"_"+status], "query '%s' status", id)
+				_, ok := mx[id]
+				assert.Equalf(t, test.wantQueryTime, ok, "query '%s' query time", id)
+			}
+			for id, rcode := range test.wantRcode {
+				assert.Equalf(t, int64(1), mx[id+"_rcode_"+rcode], "query '%s' rcode", id)
+			}
+			ensureCollectedHasAllChartsDims(t, mod, mx)
+		})
+	}
+}
+
+func ensureCollectedHasAllChartsDims(t *testing.T, d *DNSQuery, mx map[string]int64) {
+	for _, chart := range *d.Charts() {
+		if chart.ID == "query_time" {
+			continue
+		}
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+		}
+	}
+}
+
+type testDNSServers struct {
+	udp, tcp, tls *dns.Server
+	doh           *httptest.Server
+
+	udpPort, tcpPort, tlsPort, closedPort int
+	dohURL, dohID                         string
+}
+
+func newTestDNSServers(t *testing.T) *testDNSServers {
+	srv := &testDNSServers{}
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) { _ = w.WriteMsg(testDNSResponse(r)) })
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv.udp = &dns.Server{PacketConn: pc, Handler: handler}
+	srv.udpPort = pc.LocalAddr().(*net.UDPAddr).Port
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv.tcp = &dns.Server{Listener: ln, Handler: handler}
+	srv.tcpPort = ln.Addr().(*net.TCPAddr).Port
+
+	srv.doh = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		req := new(dns.Msg)
+		if r.Header.Get("Content-Type") != "application/dns-message" || req.Unpack(bs) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp, _ := testDNSResponse(req).Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(resp)
+	}))
+	srv.dohURL = srv.doh.URL + "/dns-query"
+	srv.dohID = serverID(srv.dohURL)
+
+	tlsLn, err := tls.Listen("tcp", "127.0.0.1:0", srv.doh.TLS)
+	require.NoError(t, err)
+	srv.tls = &dns.Server{Listener: tlsLn, Net: "tcp-tls", Handler: handler}
+	srv.tlsPort = tlsLn.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv.closedPort = closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	for _, s := range []*dns.Server{srv.udp, srv.tcp, srv.tls} {
+		started := make(chan struct{})
+		s.NotifyStartedFunc = func() { close(started) }
+		go func(s *dns.Server) { _ = s.ActivateAndServe() }(s)
+		<-started
+	}
+
+	return srv
+}
+
+func (s *testDNSServers) Close() {
+	_ = s.udp.Shutdown()
+	_ = s.tcp.Shutdown()
+	_ = s.tls.Shutdown()
+	s.doh.Close()
+}
+
+func testDNSResponse(r *dns.Msg) *dns.Msg {
+	m := new(dns.Msg).SetReply(r)
+	q := r.Question[0]
+	hdr := func(rtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: q.Name, Rrtype: rtype, Class: dns.ClassINET, Ttl: 300}
+	}
+
+	switch q.Name {
+	case "example.com.":
+		switch q.Qtype {
+		case dns.TypeA:
+			m.Answer = append(m.Answer, &dns.A{Hdr: hdr(dns.TypeA), A: net.ParseIP("192.0.2.1")})
+		case dns.TypeAAAA:
+			m.Answer = append(m.Answer, &dns.AAAA{Hdr: hdr(dns.TypeAAAA), AAAA: net.ParseIP("2001:db8::1")})
+		case dns.TypeTXT:
+			m.Answer = append(m.Answer, &dns.TXT{Hdr: hdr(dns.TypeTXT), Txt: []string{"v=spf1 ", "-all"}})
+		}
+	case "www.example.com.":
+		m.Answer = append(m.Answer, &dns.CNAME{Hdr: hdr(dns.TypeCNAME), Target: "example.com."})
+	case "secure.example.com.":
+		m.Answer = append(m.Answer, &dns.A{Hdr: hdr(dns.TypeA), A: net.ParseIP("192.0.2.3")})
+		m.AuthenticatedData = r.IsEdns0() != nil && r.IsEdns0().Do()
+	case "servfail.example.com.":
+		m.Rcode = dns.RcodeServerFailure
+	default:
+		m.Rcode = dns.RcodeNameError
+	}
+	return m
 }
 
 type okMockExchanger struct{}
 
 func (m okMockExchanger) Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error) {
-	return nil, time.Second, nil
+	return new(dns.Msg).SetReply(msg), time.Second, nil
 }
 
 type errMockExchanger struct{}
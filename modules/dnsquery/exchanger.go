@@ -0,0 +1,90 @@
+package dnsquery
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+type exchanger interface {
+	Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error)
+}
+
+func newExchanger(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
+	if network == networkHTTPS {
+		return &dohExchanger{
+			client: &http.Client{
+				Timeout:   timeout,
+				Transport: &http.Transport{TLSClientConfig: tlsConfig, TLSHandshakeTimeout: timeout},
+			},
+		}
+	}
+	return &dns.Client{
+		Net:         network,
+		ReadTimeout: timeout,
+		TLSConfig:   tlsConfig,
+	}
+}
+
+const dnsMessageContentType = "application/dns-message"
+
+// dohExchanger sends DNS queries over HTTPS (RFC 8484) using the POST method.
+type dohExchanger struct {
+	client *http.Client
+}
+
+func (e *dohExchanger) Exchange(msg *dns.Msg, address string) (*dns.Msg, time.Duration, error) {
+	// RFC 8484 4.1: the DNS ID SHOULD be 0 for HTTP cache friendliness.
+	m := msg.Copy()
+	m.Id = 0
+
+	bs, err := m.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(bs))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", dnsMessageContentType)
+	req.Header.Set("Accept", dnsMessageContentType)
+
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer closeBody(resp)
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	rtt := time.Since(start)
+	if err != nil {
+		return nil, rtt, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, rtt, fmt.Errorf("'%s' returned HTTP status code %d", address, resp.StatusCode)
+	}
+
+	r := new(dns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, rtt, fmt.Errorf("error on unpacking response from '%s' : %v", address, err)
+	}
+	r.Id = msg.Id
+
+	return r, rtt, nil
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,168 @@
+package dnsquery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	networkUDP   = "udp"
+	networkTCP   = "tcp"
+	networkTLS   = "tcp-tls"
+	networkHTTPS = "https"
+)
+
+func (d *DNSQuery) setup() error {
+	if len(d.Domains) == 0 {
+		return errors.New("no domains specified")
+	}
+
+	if len(d.Servers) == 0 {
+		return errors.New("no servers specified")
+	}
+
+	switch d.Network {
+	case "", networkUDP, networkTCP, networkTLS, networkHTTPS:
+	default:
+		return fmt.Errorf("wrong network transport : %s", d.Network)
+	}
+
+	recordTypes := d.RecordTypes
+	if len(recordTypes) == 0 {
+		recordTypes = []string{d.RecordType}
+	}
+
+	d.rtypes = d.rtypes[:0]
+	seen := make(map[string]bool)
+	for _, recordType := range recordTypes {
+		rtype, err := parseRecordType(recordType)
+		if err != nil {
+			return fmt.Errorf("error on parsing record type : %s", err)
+		}
+		if seen[recordType] {
+			return fmt.Errorf("duplicate record type : %s", recordType)
+		}
+		seen[recordType] = true
+		d.rtypes = append(d.rtypes, rtype)
+	}
+
+	expect, err := parseExpectConfig(d.Expect, seen)
+	if err != nil {
+		return fmt.Errorf("error on parsing 'expect' : %v", err)
+	}
+	d.expect = expect
+
+	return nil
+}
+
+func parseExpectConfig(cfg ExpectConfig, recordTypes map[string]bool) (*expectation, error) {
+	exp := &expectation{
+		rcode:             dns.RcodeSuccess,
+		answers:           make(map[uint16][]string),
+		minTTL:            cfg.MinTTL,
+		maxTTL:            cfg.MaxTTL,
+		authenticatedData: cfg.AuthenticatedData,
+	}
+
+	if cfg.Rcode != "" {
+		rcode, ok := dns.StringToRcode[strings.ToUpper(cfg.Rcode)]
+		if !ok {
+			return nil, fmt.Errorf("unknown response code : %s", cfg.Rcode)
+		}
+		exp.rcode = rcode
+	}
+
+	if cfg.MinTTL < 0 || cfg.MaxTTL < 0 || (cfg.MaxTTL > 0 && cfg.MinTTL > cfg.MaxTTL) {
+		return nil, fmt.Errorf("wrong TTL bounds : min_ttl %d, max_ttl %d", cfg.MinTTL, cfg.MaxTTL)
+	}
+
+	for recordType, values := range cfg.Answers {
+		if !recordTypes[recordType] {
+			return nil, fmt.Errorf("expected answers for not queried record type : %s", recordType)
+		}
+		rtype, _ := parseRecordType(recordType)
+		if rtype == dns.TypeANY {
+			return nil, errors.New("expected answers are not supported for ANY record type")
+		}
+		for _, value := range values {
+			v, err := normalizeAnswer(rtype, value)
+			if err != nil {
+				return nil, err
+			}
+			exp.answers[rtype] = append(exp.answers[rtype], v)
+		}
+	}
+
+	return exp, nil
+}
+
+func (d *DNSQuery) initServers() {
+	port := d.Port
+	if port == 0 {
+		port = defaultPorts[d.Network]
+	}
+
+	multiType := len(d.rtypes) > 1
+
+	for _, name := range d.Servers {
+		srv := &server{
+			id:      serverID(name),
+			name:    name,
+			port:    port,
+			address: serverAddress(d.Network, name, port),
+		}
+		d.servers = append(d.servers, srv)
+
+		for _, rtype := range d.rtypes {
+			q := &query{server: srv, rtype: rtype, id: srv.id, name: srv.name}
+			if multiType {
+				q.id = srv.id + "_" + strings.ToLower(dns.TypeToString[rtype])
+				q.name = srv.name + " " + dns.TypeToString[rtype]
+			}
+			d.queries = append(d.queries, q)
+		}
+	}
+}
+
+var defaultPorts = map[string]int{
+	"":           defaultPort,
+	networkUDP:   defaultPort,
+	networkTCP:   defaultPort,
+	networkTLS:   defaultTLSPort,
+	networkHTTPS: defaultHTTPSPort,
+}
+
+func serverAddress(network, name string, port int) string {
+	if network != networkHTTPS {
+		return net.JoinHostPort(name, strconv.Itoa(port))
+	}
+	if strings.HasPrefix(name, "https://") {
+		return name
+	}
+	return "https://" + net.JoinHostPort(name, strconv.Itoa(port)) + "/dns-query"
+}
+
+func serverID(name string) string {
+	return serverNameReplacer.Replace(strings.TrimPrefix(name, "https://"))
+}
+
+var serverNameReplacer = strings.NewReplacer(".", "_", "/", "_")
+
+func normalizeAnswer(rtype uint16, value string) (string, error) {
+	switch rtype {
+	case dns.TypeA, dns.TypeAAAA:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("expected answer '%s' is not an IP address", value)
+		}
+		return ip.String(), nil
+	case dns.TypeCNAME, dns.TypeMX, dns.TypeNS, dns.TypePTR, dns.TypeSOA, dns.TypeSRV:
+		return dns.Fqdn(strings.ToLower(value)), nil
+	}
+	return value, nil
+}
@@ -1,16 +1,14 @@
 package dnsquery
 
 import (
-	"net"
-	"strconv"
-
 	"github.com/miekg/dns"
 )
 
 type task struct {
-	server *server
+	query  *query
 	domain string
-	rtype  uint16
+	dnssec bool
+	ad     bool
 }
 
 func newWorker(exchanger exchanger, task chan task, taskDone chan struct{}) *worker {
@@ -40,14 +38,18 @@ func (w *worker) workLoop() {
 
 func (w *worker) doWork(t task) {
 	msg := new(dns.Msg)
-	msg.SetQuestion(dns.Fqdn(t.domain), t.rtype)
-	address := net.JoinHostPort(t.server.name, strconv.Itoa(t.server.port))
+	msg.SetQuestion(dns.Fqdn(t.domain), t.query.rtype)
+	if t.dnssec {
+		msg.SetEdns0(4096, true)
+	}
+	// RFC 6840 5.7: the AD bit in a query requests the AD bit in the response
+	msg.AuthenticatedData = t.ad
 
-	resp, rtt, err := w.exchanger.Exchange(msg, address)
+	resp, rtt, err := w.exchanger.Exchange(msg, t.query.server.address)
 
-	t.server.resp = resp
-	t.server.rtt = rtt
-	t.server.err = err
+	t.query.resp = resp
+	t.query.rtt = rtt
+	t.query.err = err
 
 	w.taskDone <- struct{}{}
 }
@@ -0,0 +1,62 @@
+package exec
+
+import (
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+)
+
+var baseCharts = module.Charts{
+	{
+		ID:    "exit_code",
+		Title: "Command Exit Code",
+		Units: "code",
+		Fam:   "command",
+		Ctx:   "exec.exit_code",
+		Dims: module.Dims{
+			{ID: "exit_code", Name: "exit_code"},
+		},
+	},
+	{
+		ID:    "execution_time",
+		Title: "Command Execution Time",
+		Units: "ms",
+		Fam:   "command",
+		Ctx:   "exec.execution_time",
+		Dims: module.Dims{
+			{ID: "execution_time", Name: "time"},
+		},
+	},
+}
+
+type chartDef struct {
+	chart *module.Chart
+	dims  []*dimDef
+}
+
+type dimDef struct {
+	cfg     DimConfig
+	chartID string
+	// match is set if the key is a pattern, there is a dimension per matched key
+	match matcher.Matcher
+	seen  map[string]bool
+}
+
+func (d *dimDef) newDim(name, key string) *module.Dim {
+	return d.cfg.New(d.dimID(key), name)
+}
+
+// dimName returns the dimension name for the matched key, prefixed with the configured name (if set).
+func (d *dimDef) dimName(key string) string {
+	if d.cfg.Name == "" {
+		return key
+	}
+	return d.cfg.Name + "_" + key
+}
+
+var idReplacer = strings.NewReplacer(" ", "_", ".", "_", ",", "_", "{", "_", "}", "", "\"", "", "=", "_")
+
+func (d *dimDef) dimID(key string) string {
+	return d.chartID + "_" + idReplacer.Replace(key)
+}
@@ -0,0 +1,60 @@
+package exec
+
+import "github.com/netdata/go.d.plugin/pkg/chartdef"
+
+func (e *Exec) collect() (map[string]int64, error) {
+	res, err := e.cmd.run()
+	if err != nil {
+		return nil, err
+	}
+
+	mx := map[string]int64{
+		"exit_code":      int64(res.exitCode),
+		"execution_time": res.duration.Milliseconds(),
+	}
+
+	// a non-zero exit code is a failure, except for Nagios plugins, it is the service state there
+	if res.exitCode != 0 {
+		if e.Format != formatNagios {
+			e.Errorf("'%s' exited with code %d: %s", e.cmd, res.exitCode, res.stderr)
+			return mx, nil
+		}
+		e.Debugf("'%s' exited with code %d", e.cmd, res.exitCode)
+	}
+
+	values, err := e.parse(res.output)
+	if err != nil {
+		e.Errorf("error on parsing '%s' output (%s format): %v", e.cmd, e.Format, err)
+		return mx, nil
+	}
+
+	for _, def := range e.defs {
+		e.collectChart(mx, def, values)
+	}
+	return mx, nil
+}
+
+func (e *Exec) collectChart(mx map[string]int64, def *chartDef, values map[string]float64) {
+	for _, dim := range def.dims {
+		if dim.match == nil {
+			if v, ok := values[dim.cfg.Key]; ok {
+				mx[dim.dimID(dim.cfg.Key)] = int64(v * chartdef.Precision)
+			}
+			continue
+		}
+
+		for key, v := range values {
+			if !dim.match.MatchString(key) {
+				continue
+			}
+			if !dim.seen[key] {
+				dim.seen[key] = true
+				if err := def.chart.AddDim(dim.newDim(dim.dimName(key), key)); err != nil {
+					e.Warning(err)
+				}
+				def.chart.MarkNotCreated()
+			}
+			mx[dim.dimID(key)] = int64(v * chartdef.Precision)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package exec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// command is a command ready to run. The shell is used only if it is explicitly enabled.
+type command struct {
+	path    string
+	args    []string
+	shell   bool
+	env     []string
+	dir     string
+	timeout time.Duration
+	maxSize int
+}
+
+func (c command) String() string {
+	return strings.Join(append([]string{c.path}, c.args...), " ")
+}
+
+type commandResult struct {
+	output   []byte
+	stderr   []byte
+	exitCode int
+	duration time.Duration
+}
+
+var errOutputTooLarge = errors.New("output size limit exceeded")
+
+// run runs the command and returns its output.
+// A non-zero exit code is not an error, it is reported in the result.
+func (c command) run() (*commandResult, error) {
+	cmd := osexec.Command(c.path, c.args...)
+	cmd.Dir = c.dir
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	setProcessGroup(cmd)
+	if c.shell {
+		setShellCmdLine(cmd)
+	}
+
+	stdout := &limitedBuffer{limit: c.maxSize}
+	stderr := &limitedBuffer{limit: 4096, truncate: true}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error on running '%s': %v", c, err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-timer.C:
+		killProcessGroup(cmd)
+		<-done
+		return nil, fmt.Errorf("'%s' timed out after %s", c, c.timeout)
+	}
+
+	if stdout.exceeded {
+		return nil, fmt.Errorf("'%s': %v (%d bytes)", c, errOutputTooLarge, c.maxSize)
+	}
+
+	res := &commandResult{
+		output:   stdout.Bytes(),
+		stderr:   bytes.TrimSpace(stderr.Bytes()),
+		duration: time.Since(start),
+	}
+	if err == nil {
+		return res, nil
+	}
+
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		res.exitCode = exitErr.ExitCode()
+		return res, nil
+	}
+	return nil, fmt.Errorf("error on running '%s': %v", c, err)
+}
+
+// limitedBuffer is a buffer that accepts up to limit bytes.
+// It fails writes once the limit is exceeded, or drops the excess if truncate is set.
+// The buffer is not embedded to not expose its ReadFrom, io.Copy would use it and bypass the limit.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		b.exceeded = true
+		if !b.truncate {
+			return 0, errOutputTooLarge
+		}
+		_, _ = b.buf.Write(p[:b.limit-b.buf.Len()])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// splitCommand splits the command line into the arguments, no shell is involved.
+// Arguments can be quoted with single or double quotes, a backslash escapes the next character outside single quotes.
+func splitCommand(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var inArg, escaped bool
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+func envList(env map[string]string) []string {
+	list := make([]string, 0, len(env))
+	for k, v := range env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,107 @@
+package exec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := map[string]struct {
+		line     string
+		expected []string
+		wantErr  bool
+	}{
+		"simple":             {line: "/usr/bin/storcli show all", expected: []string{"/usr/bin/storcli", "show", "all"}},
+		"extra spaces":       {line: "  cmd   a\tb ", expected: []string{"cmd", "a", "b"}},
+		"double quotes":      {line: `cmd "a b" c`, expected: []string{"cmd", "a b", "c"}},
+		"single quotes":      {line: `cmd 'a "b"' c`, expected: []string{"cmd", `a "b"`, "c"}},
+		"empty quotes":       {line: `cmd ""`, expected: []string{"cmd", ""}},
+		"escaped space":      {line: `cmd a\ b`, expected: []string{"cmd", "a b"}},
+		"no shell expansion": {line: "cmd $HOME | grep x", expected: []string{"cmd", "$HOME", "|", "grep", "x"}},
+		"empty":              {line: ""},
+		"unterminated quote": {line: `cmd "a`, wantErr: true},
+		"trailing backslash": {line: `cmd a\`, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			args, err := splitCommand(test.line)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, args)
+			}
+		})
+	}
+}
+
+func TestCommand_run(t *testing.T) {
+	newCmd := func(script string) command {
+		return command{
+			path:    "/bin/sh",
+			args:    []string{"-c", script},
+			timeout: time.Second,
+			maxSize: 16,
+		}
+	}
+
+	tests := map[string]struct {
+		cmd          command
+		wantOutput   string
+		wantExitCode int
+		wantErr      bool
+	}{
+		"success": {
+			cmd:        newCmd("echo ok"),
+			wantOutput: "ok\n",
+		},
+		"non-zero exit code": {
+			cmd:          newCmd("echo failed >&2; exit 2"),
+			wantExitCode: 2,
+		},
+		"env and working dir": {
+			cmd: func() command {
+				cmd := newCmd("echo $FOO; pwd")
+				cmd.env = []string{"FOO=bar"}
+				cmd.dir = "/"
+				return cmd
+			}(),
+			wantOutput: "bar\n/\n",
+		},
+		"output size limit": {
+			cmd:     newCmd("echo 0123456789abcdefghij"),
+			wantErr: true,
+		},
+		"timeout": {
+			cmd: func() command {
+				cmd := newCmd("sleep 5")
+				cmd.timeout = time.Millisecond * 100
+				return cmd
+			}(),
+			wantErr: true,
+		},
+		"not found": {
+			cmd:     command{path: "/not/exists", timeout: time.Second, maxSize: 16},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := test.cmd.run()
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantOutput, string(res.output))
+				assert.Equal(t, test.wantExitCode, res.exitCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// +build !windows
+
+package exec
+
+import (
+	osexec "os/exec"
+	"syscall"
+)
+
+// shellCommand returns the command that runs the line with the system shell.
+func shellCommand(line string) (string, []string) {
+	return "/bin/sh", []string{"-c", line}
+}
+
+// setShellCmdLine is a no-op, the arguments are passed to the shell as is.
+func setShellCmdLine(_ *osexec.Cmd) {}
+
+// setProcessGroup makes the command run in its own process group to be able to kill its children on timeout,
+// they would keep the output pipes open otherwise.
+func setProcessGroup(cmd *osexec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *osexec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
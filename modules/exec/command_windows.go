@@ -0,0 +1,26 @@
+package exec
+
+import (
+	osexec "os/exec"
+	"strings"
+	"syscall"
+)
+
+// shellCommand returns the command that runs the line with the system shell.
+func shellCommand(line string) (string, []string) {
+	return "cmd.exe", []string{"/C", line}
+}
+
+// setShellCmdLine passes the command line to cmd.exe as is. It doesn't follow the escaping rules
+// os/exec uses to build the command line from the arguments, they would break the quotes in the line.
+func setShellCmdLine(cmd *osexec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: strings.Join(cmd.Args, " ")}
+}
+
+// setProcessGroup is a no-op, there are no process groups on Windows.
+func setProcessGroup(_ *osexec.Cmd) {}
+
+// killProcessGroup kills only the command, its children are not killed.
+func killProcessGroup(cmd *osexec.Cmd) {
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,113 @@
+package exec
+
+import (
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	module.Register("exec", module.Creator{
+		Create: func() module.Module { return New() },
+	})
+}
+
+const (
+	defaultTimeout       = time.Second * 5
+	defaultMaxOutputSize = 1 << 20
+)
+
+func New() *Exec {
+	return &Exec{
+		Config: Config{
+			Timeout:       web.Duration{Duration: defaultTimeout},
+			MaxOutputSize: defaultMaxOutputSize,
+			Format:        formatKeyValue,
+		},
+		charts: baseCharts.Copy(),
+	}
+}
+
+type (
+	Config struct {
+		Command       string            `yaml:"command"`
+		Shell         bool              `yaml:"shell"`
+		Env           map[string]string `yaml:"env"`
+		WorkingDir    string            `yaml:"working_dir"`
+		Timeout       web.Duration      `yaml:"timeout"`
+		MaxOutputSize int               `yaml:"max_output_size"`
+		Format        string            `yaml:"format"`
+		Charts        []ChartConfig     `yaml:"charts"`
+	}
+	ChartConfig struct {
+		chartdef.Chart `yaml:",inline"`
+		Dimensions     []DimConfig `yaml:"dimensions"`
+	}
+	DimConfig struct {
+		chartdef.Dim `yaml:",inline"`
+		Key          string `yaml:"key"`
+	}
+	Exec struct {
+		module.Base
+		Config `yaml:",inline"`
+
+		cmd    *command
+		parse  parseFunc
+		defs   []*chartDef
+		charts *module.Charts
+	}
+)
+
+func (e *Exec) Cleanup() {}
+
+func (e *Exec) Init() bool {
+	if err := e.validateConfig(); err != nil {
+		e.Errorf("config validation: %v", err)
+		return false
+	}
+
+	cmd, err := e.initCommand()
+	if err != nil {
+		e.Errorf("error on initializing command: %v", err)
+		return false
+	}
+	e.cmd = cmd
+
+	parse, err := e.initParser()
+	if err != nil {
+		e.Errorf("error on initializing parser: %v", err)
+		return false
+	}
+	e.parse = parse
+
+	if err := e.initCharts(); err != nil {
+		e.Errorf("error on creating charts: %v", err)
+		return false
+	}
+
+	e.Debugf("using command: %s", e.cmd)
+	e.Debugf("using format: %s", e.Format)
+	return true
+}
+
+func (e *Exec) Check() bool {
+	return len(e.Collect()) > 0
+}
+
+func (e *Exec) Charts() *module.Charts {
+	return e.charts
+}
+
+func (e *Exec) Collect() map[string]int64 {
+	mx, err := e.collect()
+	if err != nil {
+		e.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
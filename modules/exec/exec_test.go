@@ -0,0 +1,231 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestExec_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   func(cfg *Config)
+		wantFail bool
+	}{
+		"success on default test config": {
+			config: func(cfg *Config) {},
+		},
+		"success on shell command": {
+			config: func(cfg *Config) { cfg.Command, cfg.Shell = "cat testdata/keyvalue.txt | grep -v state", true },
+		},
+		"fails on unset command": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Command = "" },
+		},
+		"fails on bad command": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Command = `cat "testdata` },
+		},
+		"fails on zero timeout": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Timeout.Duration = 0 },
+		},
+		"fails on zero max_output_size": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.MaxOutputSize = 0 },
+		},
+		"fails on unknown format": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Format = "xml" },
+		},
+		"fails on unset charts": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts = nil },
+		},
+		"fails on chart id collision with base charts": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].ID = "exit_code" },
+		},
+		"fails on unknown chart type": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Type = "pie" },
+		},
+		"fails on dimension without key": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Dimensions[0].Key = "" },
+		},
+		"fails on bad key pattern": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Dimensions[0].Key = "[" },
+		},
+		"fails on unknown algorithm": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Dimensions[0].Algorithm = "delta" },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config = prepareConfig(formatKeyValue, "cat testdata/keyvalue.txt")
+			test.config(&job.Config)
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestExec_Check(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig(formatKeyValue, "cat testdata/keyvalue.txt")
+	require.True(t, job.Init())
+
+	assert.True(t, job.Check())
+}
+
+func TestExec_Check_ReturnsFalseOnCommandNotFound(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig(formatKeyValue, "/not/exists")
+	require.True(t, job.Init())
+
+	assert.False(t, job.Check())
+}
+
+func TestExec_Charts(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig(formatKeyValue, "cat testdata/keyvalue.txt")
+	require.True(t, job.Init())
+
+	charts := job.Charts()
+	require.Len(t, *charts, len(baseCharts)+1)
+
+	chart := charts.Get("io")
+	require.NotNil(t, chart)
+	assert.Equal(t, "exec.io", chart.Ctx)
+	require.Len(t, chart.Dims, 2)
+	assert.Equal(t, "reads", chart.Dims[0].Name)
+	assert.Equal(t, module.Incremental, chart.Dims[0].Algo)
+}
+
+func TestExec_Cleanup(t *testing.T) {
+	assert.NotPanics(t, New().Cleanup)
+}
+
+func TestExec_Collect(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		command  string
+		shell    bool
+		charts   []ChartConfig
+		expected map[string]int64
+	}{
+		"keyvalue": {
+			format:  formatKeyValue,
+			command: "cat testdata/keyvalue.txt",
+			charts: []ChartConfig{
+				{Chart: chartdef.Chart{ID: "io"}, Dimensions: []DimConfig{{Key: "reads"}, {Key: "writes"}, {Key: "missing"}}},
+				{Chart: chartdef.Chart{ID: "latency"}, Dimensions: []DimConfig{{Dim: chartdef.Dim{Name: "avg"}, Key: "latency"}}},
+			},
+			expected: map[string]int64{
+				"exit_code":       0,
+				"io_reads":        1024000,
+				"io_writes":       2048000,
+				"latency_latency": 250,
+			},
+		},
+		"nagios with warning state": {
+			format:  formatNagios,
+			command: "cat testdata/nagios.txt; exit 1",
+			shell:   true,
+			charts: []ChartConfig{
+				{Chart: chartdef.Chart{ID: "disk"}, Dimensions: []DimConfig{{Key: "/*"}}},
+			},
+			expected: map[string]int64{
+				"exit_code":  1,
+				"disk_/":     2643000,
+				"disk_/boot": 68000,
+				"disk_/home": 69357000,
+			},
+		},
+		"json": {
+			format:  formatJSON,
+			command: "cat testdata/status.json",
+			charts: []ChartConfig{
+				{Chart: chartdef.Chart{ID: "disk_errors"}, Dimensions: []DimConfig{{Dim: chartdef.Dim{Name: "disk"}, Key: "disks.*.errors"}}},
+				{Chart: chartdef.Chart{ID: "temperature"}, Dimensions: []DimConfig{{Key: "controller.temperature"}}},
+			},
+			expected: map[string]int64{
+				"exit_code":                          0,
+				"disk_errors_disks_0_errors":         0,
+				"disk_errors_disks_1_errors":         3000,
+				"temperature_controller_temperature": 41500,
+			},
+		},
+		"prometheus": {
+			format:  formatPrometheus,
+			command: "cat testdata/metrics.txt",
+			charts: []ChartConfig{
+				{Chart: chartdef.Chart{ID: "jobs"}, Dimensions: []DimConfig{{Dim: chartdef.Dim{Algorithm: "incremental"}, Key: "jobs_processed_total*"}}},
+			},
+			expected: map[string]int64{
+				"exit_code": 0,
+				"jobs_jobs_processed_total_queue_email_status_ok": 10000,
+				"jobs_jobs_processed_total_queue_sms_status_ok":   5000,
+			},
+		},
+		"non-zero exit code": {
+			format:  formatKeyValue,
+			command: "echo reads=1; exit 3",
+			shell:   true,
+			charts: []ChartConfig{
+				{Chart: chartdef.Chart{ID: "io"}, Dimensions: []DimConfig{{Key: "reads"}}},
+			},
+			expected: map[string]int64{
+				"exit_code": 3,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config = prepareConfig(test.format, test.command)
+			job.Shell = test.shell
+			job.Config.Charts = test.charts
+			require.True(t, job.Init())
+
+			mx := job.Collect()
+
+			require.Contains(t, mx, "execution_time")
+			delete(mx, "execution_time")
+			assert.Equal(t, test.expected, mx)
+		})
+	}
+}
+
+func prepareConfig(format, command string) Config {
+	cfg := New().Config
+	cfg.Format = format
+	cfg.Command = command
+	cfg.Charts = []ChartConfig{
+		{
+			Chart: chartdef.Chart{ID: "io"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Algorithm: "incremental"}, Key: "reads"},
+				{Dim: chartdef.Dim{Algorithm: "incremental"}, Key: "writes"},
+			},
+		},
+	}
+	return cfg
+}
@@ -0,0 +1,117 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+)
+
+func (e Exec) validateConfig() error {
+	if e.Command == "" {
+		return errors.New("'command' not set")
+	}
+	if e.Timeout.Duration <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+	if e.MaxOutputSize <= 0 {
+		return errors.New("'max_output_size' must be positive")
+	}
+	if len(e.Config.Charts) == 0 {
+		return errors.New("'charts' not set")
+	}
+	return nil
+}
+
+func (e Exec) initCommand() (*command, error) {
+	cmd := &command{
+		env:     envList(e.Env),
+		dir:     e.WorkingDir,
+		timeout: e.Timeout.Duration,
+		maxSize: e.MaxOutputSize,
+	}
+
+	if e.Shell {
+		cmd.path, cmd.args = shellCommand(e.Command)
+		cmd.shell = true
+		return cmd, nil
+	}
+
+	args, err := splitCommand(e.Command)
+	if err != nil {
+		return nil, fmt.Errorf("bad command '%s': %v", e.Command, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("bad command '%s': empty", e.Command)
+	}
+	cmd.path, cmd.args = args[0], args[1:]
+	return cmd, nil
+}
+
+func (e Exec) initParser() (parseFunc, error) {
+	parse, ok := parsers[e.Format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s'", e.Format)
+	}
+	return parse, nil
+}
+
+func (e *Exec) initCharts() error {
+	for i, cfg := range e.Config.Charts {
+		def, err := newChartDef(cfg)
+		if err != nil {
+			return fmt.Errorf("chart[%d]: %v", i+1, err)
+		}
+		if err := e.charts.Add(def.chart); err != nil {
+			return fmt.Errorf("chart[%d]: %v", i+1, err)
+		}
+		e.defs = append(e.defs, def)
+	}
+	return nil
+}
+
+func newChartDef(cfg ChartConfig) (*chartDef, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if len(cfg.Dimensions) == 0 {
+		return nil, fmt.Errorf("chart '%s': 'dimensions' not set", cfg.ID)
+	}
+
+	def := &chartDef{chart: cfg.New("exec.")}
+	for i, dimCfg := range cfg.Dimensions {
+		dim, err := newDimDef(cfg.ID, dimCfg)
+		if err != nil {
+			return nil, fmt.Errorf("chart '%s': dimension[%d]: %v", cfg.ID, i+1, err)
+		}
+		if dim.match == nil {
+			if err := def.chart.AddDim(dim.newDim(dim.cfg.Name, dim.cfg.Key)); err != nil {
+				return nil, fmt.Errorf("chart '%s': %v", cfg.ID, err)
+			}
+		}
+		def.dims = append(def.dims, dim)
+	}
+	return def, nil
+}
+
+func newDimDef(chartID string, cfg DimConfig) (*dimDef, error) {
+	if cfg.Key == "" {
+		return nil, errors.New("'key' not set")
+	}
+	if err := cfg.Init(); err != nil {
+		return nil, err
+	}
+
+	dim := &dimDef{cfg: cfg, chartID: chartID, seen: make(map[string]bool)}
+	if strings.ContainsAny(cfg.Key, "*?[") {
+		m, err := matcher.NewGlobMatcher(cfg.Key)
+		if err != nil {
+			return nil, fmt.Errorf("bad key pattern '%s': %v", cfg.Key, err)
+		}
+		dim.match = m
+	} else if cfg.Name == "" {
+		dim.cfg.Name = cfg.Key
+	}
+	return dim, nil
+}
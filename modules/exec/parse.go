@@ -0,0 +1,239 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+)
+
+const (
+	formatNagios     = "nagios"
+	formatJSON       = "json"
+	formatKeyValue   = "keyvalue"
+	formatPrometheus = "prometheus"
+)
+
+// parseFunc parses the command output to a key => value map.
+type parseFunc func(output []byte) (map[string]float64, error)
+
+var parsers = map[string]parseFunc{
+	formatNagios:     parseNagios,
+	formatJSON:       parseJSON,
+	formatKeyValue:   parseKeyValue,
+	formatPrometheus: parsePrometheus,
+}
+
+// parseNagios parses Nagios plugin performance data.
+// https://nagios-plugins.org/doc/guidelines.html#AEN200
+//
+//	TEXT OUTPUT | 'label'=value[UOM];[warn];[crit];[min];[max] ...
+//	LONG TEXT LINE 1
+//	LONG TEXT LINE 2 | PERFDATA LINE 2
+//	PERFDATA LINE 3
+//
+// The unit of measurement is dropped, unknown ('U') values are skipped.
+func parseNagios(output []byte) (map[string]float64, error) {
+	values := make(map[string]float64)
+	var inPerfData bool
+
+	sc := bufio.NewScanner(bytes.NewReader(output))
+	for i := 0; sc.Scan(); i++ {
+		line := sc.Text()
+		if !inPerfData {
+			idx := strings.IndexByte(line, '|')
+			if idx == -1 {
+				continue
+			}
+			line = line[idx+1:]
+			// the perfdata in the long text continues till the end of the output
+			inPerfData = i > 0
+		}
+		if err := parsePerfData(line, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, sc.Err()
+}
+
+func parsePerfData(line string, values map[string]float64) error {
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		var label string
+		if line[0] == '\'' {
+			end := strings.Index(line[1:], "'=")
+			if end == -1 {
+				return fmt.Errorf("bad perfdata '%s': unterminated label", line)
+			}
+			label, line = line[1:end+1], line[end+3:]
+		} else {
+			end := strings.IndexByte(line, '=')
+			if end == -1 {
+				return fmt.Errorf("bad perfdata '%s': missing '='", line)
+			}
+			label, line = line[:end], line[end+1:]
+		}
+
+		var data string
+		if end := strings.IndexAny(line, " \t"); end != -1 {
+			data, line = line[:end], line[end:]
+		} else {
+			data, line = line, ""
+		}
+
+		value := data
+		if end := strings.IndexByte(value, ';'); end != -1 {
+			value = value[:end]
+		}
+		if value == "U" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimRightFunc(value, isUOM), 64)
+		if err != nil {
+			return fmt.Errorf("bad perfdata value '%s': %v", data, err)
+		}
+		values[label] = v
+	}
+	return nil
+}
+
+func isUOM(r rune) bool {
+	return r == '%' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// parseJSON flattens the JSON document, the keys are joined with '.', array elements are keyed by their index.
+// Numbers, booleans (1/0) and strings holding a number are collected.
+func parseJSON(output []byte) (map[string]float64, error) {
+	dec := json.NewDecoder(bytes.NewReader(output))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64)
+	flattenJSON("", doc, values)
+	return values, nil
+}
+
+func flattenJSON(key string, v interface{}, values map[string]float64) {
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + "." + k
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			flattenJSON(join(k), child, values)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSON(join(strconv.Itoa(i)), child, values)
+		}
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			values[key] = f
+		}
+	case string:
+		if f, ok := parseValue(v); ok {
+			values[key] = f
+		}
+	case bool:
+		values[key] = boolToFloat(v)
+	}
+}
+
+// parseKeyValue parses 'key=value' or 'key: value' lines. Empty lines and lines starting with '#' are skipped.
+func parseKeyValue(output []byte) (map[string]float64, error) {
+	values := make(map[string]float64)
+
+	sc := bufio.NewScanner(bytes.NewReader(output))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx == -1 {
+			return nil, fmt.Errorf("bad line '%s': missing separator", line)
+		}
+		key, value := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+		if key == "" {
+			return nil, fmt.Errorf("bad line '%s': empty key", line)
+		}
+		if v, ok := parseValue(value); ok {
+			values[key] = v
+		}
+	}
+	return values, sc.Err()
+}
+
+// parsePrometheus parses the Prometheus text format.
+// Series without labels are keyed by the metric name, the others by 'name{label="value",...}'.
+func parsePrometheus(output []byte) (map[string]float64, error) {
+	values := make(map[string]float64)
+	parser := textparse.NewPromParser(output)
+
+	for {
+		entry, err := parser.Next()
+		if err != nil {
+			if err == io.EOF {
+				return values, nil
+			}
+			return nil, err
+		}
+		if entry != textparse.EntrySeries {
+			continue
+		}
+
+		var lbs labels.Labels
+		_, _, v := parser.Series()
+		parser.Metric(&lbs)
+		values[seriesKey(lbs)] = v
+	}
+}
+
+func seriesKey(lbs labels.Labels) string {
+	var name string
+	var pairs []string
+	for _, lb := range lbs {
+		if lb.Name == labels.MetricName {
+			name = lb.Value
+			continue
+		}
+		pairs = append(pairs, lb.Name+"="+strconv.Quote(lb.Value))
+	}
+	if len(pairs) == 0 {
+		return name
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+func parseValue(s string) (float64, bool) {
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, true
+	}
+	if v, err := strconv.ParseBool(s); err == nil {
+		return boolToFloat(v), true
+	}
+	return 0, false
+}
+
+func boolToFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package exec
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testDataNagios, _     = ioutil.ReadFile("testdata/nagios.txt")
+	testDataJSON, _       = ioutil.ReadFile("testdata/status.json")
+	testDataKeyValue, _   = ioutil.ReadFile("testdata/keyvalue.txt")
+	testDataPrometheus, _ = ioutil.ReadFile("testdata/metrics.txt")
+)
+
+func Test_testDataIsCorrectlyReadAndValid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"testDataNagios":     testDataNagios,
+		"testDataJSON":       testDataJSON,
+		"testDataKeyValue":   testDataKeyValue,
+		"testDataPrometheus": testDataPrometheus,
+	} {
+		require.NotNilf(t, data, name)
+	}
+}
+
+func TestParsers(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		input    []byte
+		expected map[string]float64
+		wantErr  bool
+	}{
+		"nagios": {
+			format: formatNagios,
+			input:  testDataNagios,
+			expected: map[string]float64{
+				"/":          2643,
+				"/boot":      68,
+				"/home":      69357,
+				"used space": 42,
+			},
+		},
+		"nagios without perfdata": {
+			format:   formatNagios,
+			input:    []byte("PING OK - Packet loss = 0%\n"),
+			expected: map[string]float64{},
+		},
+		"nagios bad value": {
+			format:  formatNagios,
+			input:   []byte("OK | time=fast"),
+			wantErr: true,
+		},
+		"nagios unterminated label": {
+			format:  formatNagios,
+			input:   []byte("OK | 'time=1"),
+			wantErr: true,
+		},
+		"json": {
+			format: formatJSON,
+			input:  testDataJSON,
+			expected: map[string]float64{
+				"controller.temperature": 41.5,
+				"controller.healthy":     1,
+				"controller.firmware":    2.1,
+				"disks.0.errors":         0,
+				"disks.1.errors":         3,
+			},
+		},
+		"json invalid": {
+			format:  formatJSON,
+			input:   []byte("{"),
+			wantErr: true,
+		},
+		"keyvalue": {
+			format: formatKeyValue,
+			input:  testDataKeyValue,
+			expected: map[string]float64{
+				"reads":   1024,
+				"writes":  2048,
+				"latency": 0.25,
+				"enabled": 1,
+			},
+		},
+		"keyvalue missing separator": {
+			format:  formatKeyValue,
+			input:   []byte("reads 1024"),
+			wantErr: true,
+		},
+		"prometheus": {
+			format: formatPrometheus,
+			input:  testDataPrometheus,
+			expected: map[string]float64{
+				`jobs_processed_total{queue="email",status="ok"}`: 10,
+				`jobs_processed_total{queue="sms",status="ok"}`:   5,
+				"jobs_running": 2,
+			},
+		},
+		"prometheus invalid": {
+			format:  formatPrometheus,
+			input:   []byte("jobs_running{"),
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, err := parsers[test.format](test.input)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, values)
+			}
+		})
+	}
+}
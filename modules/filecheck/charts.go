@@ -33,6 +33,41 @@ var (
 		Fam:   "files",
 		Ctx:   "filecheck.file_size",
 	}
+	fileContentChangedChart = module.Chart{
+		ID:    "file_content_changed",
+		Title: "File Content Changed Since the Last Collection (0: not changed, 1: changed)",
+		Units: "boolean",
+		Fam:   "files",
+		Ctx:   "filecheck.file_content_changed",
+	}
+	fileLineCountChart = module.Chart{
+		ID:    "file_line_count",
+		Title: "File Line Count",
+		Units: "lines",
+		Fam:   "files",
+		Ctx:   "filecheck.file_line_count",
+	}
+	fileGrowthRateChart = module.Chart{
+		ID:    "file_growth_rate",
+		Title: "File Growth Rate",
+		Units: "bytes/s",
+		Fam:   "files",
+		Ctx:   "filecheck.file_growth_rate",
+	}
+	fileModeDriftChart = module.Chart{
+		ID:    "file_mode_drift",
+		Title: "File Permissions Drift (0: as expected, 1: differs)",
+		Units: "boolean",
+		Fam:   "files",
+		Ctx:   "filecheck.file_mode_drift",
+	}
+	fileOwnerDriftChart = module.Chart{
+		ID:    "file_owner_drift",
+		Title: "File Owner Drift (0: as expected, 1: differs)",
+		Units: "boolean",
+		Fam:   "files",
+		Ctx:   "filecheck.file_owner_drift",
+	}
 )
 
 var (
@@ -74,4 +109,12 @@ var (
 		Fam:   "dirs",
 		Ctx:   "filecheck.dir_size",
 	}
+	dirFilesByExtChartTmpl = module.Chart{
+		ID:    "dir_%s_files_by_extension",
+		Title: "Dir Number of Files By Extension",
+		Units: "files",
+		Fam:   "dirs",
+		Ctx:   "filecheck.dir_files_by_extension",
+		Type:  module.Stacked,
+	}
 )
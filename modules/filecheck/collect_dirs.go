@@ -42,6 +42,12 @@ func (fc *Filecheck) collectDir(ms map[string]int64, path string, curTime time.T
 
 	ms[dirDimID(path, "exists")] = 1
 	ms[dirDimID(path, "mtime_ago")] = int64(curTime.Sub(info.ModTime()).Seconds())
+
+	if fc.Dirs.Recursive || fc.Dirs.CountByExtension {
+		fc.collectDirWalk(ms, path)
+		return
+	}
+
 	if num, err := calcDirNumOfFiles(path); err == nil {
 		ms[dirDimID(path, "num_of_files")] = int64(num)
 	}
@@ -52,6 +58,57 @@ func (fc *Filecheck) collectDir(ms map[string]int64, path string, curTime time.T
 	}
 }
 
+func (fc *Filecheck) collectDirWalk(ms map[string]int64, path string) {
+	maxDepth := 1
+	if fc.Dirs.Recursive {
+		maxDepth = fc.Dirs.MaxDepth
+	}
+
+	stats, err := walkDir(path, maxDepth)
+	if err != nil {
+		fc.Debug(err)
+		return
+	}
+
+	if fc.Dirs.Recursive {
+		ms[dirDimID(path, "num_of_files")] = stats.numOfFiles
+		if fc.Dirs.CollectDirSize {
+			ms[dirDimID(path, "size_bytes")] = stats.size
+		}
+	} else {
+		if num, err := calcDirNumOfFiles(path); err == nil {
+			ms[dirDimID(path, "num_of_files")] = int64(num)
+		}
+		if fc.Dirs.CollectDirSize {
+			if size, err := calcDirSize(path); err == nil {
+				ms[dirDimID(path, "size_bytes")] = size
+			}
+		}
+	}
+
+	if fc.Dirs.CountByExtension {
+		fc.collectDirExtensions(ms, path, stats.byExt)
+	}
+}
+
+func (fc *Filecheck) collectDirExtensions(ms map[string]int64, path string, byExt map[string]int64) {
+	exts := fc.dirExtensions[path]
+	if exts == nil {
+		exts = make(map[string]bool)
+		fc.dirExtensions[path] = exts
+	}
+
+	for ext := range byExt {
+		if !exts[ext] {
+			exts[ext] = true
+			fc.addDirExtensionToCharts(path, ext)
+		}
+	}
+	for ext := range exts {
+		ms[dirExtDimID(path, ext)] = byExt[ext]
+	}
+}
+
 func (fc Filecheck) discoveryDirs() (dirs []string) {
 	for _, path := range fc.Dirs.Include {
 		if hasMeta(path) {
@@ -87,6 +144,7 @@ func (fc *Filecheck) updateDirsCharts(dirs []string) {
 	for path := range fc.collectedDirs {
 		if !set[path] {
 			delete(fc.collectedDirs, path)
+			delete(fc.dirExtensions, path)
 			fc.removeDirFromCharts(path)
 		}
 	}
@@ -94,7 +152,7 @@ func (fc *Filecheck) updateDirsCharts(dirs []string) {
 
 func (fc *Filecheck) addDirToCharts(path string) {
 	for _, chart := range *fc.Charts() {
-		if !strings.HasPrefix(chart.ID, "dir_") {
+		if !strings.HasPrefix(chart.ID, "dir_") || chart.Ctx == dirFilesByExtChartTmpl.Ctx {
 			continue
 		}
 
@@ -121,11 +179,37 @@ func (fc *Filecheck) addDirToCharts(path string) {
 		}
 		chart.MarkNotCreated()
 	}
+
+	if fc.Dirs.CountByExtension {
+		chart := dirFilesByExtChartTmpl.Copy()
+		chart.ID = fmt.Sprintf(chart.ID, path)
+		chart.Title = fmt.Sprintf("%s (%s)", chart.Title, path)
+		if err := fc.Charts().Add(chart); err != nil {
+			fc.Warning(err)
+		}
+	}
+}
+
+func (fc *Filecheck) addDirExtensionToCharts(path, ext string) {
+	chart := fc.Charts().Get(fmt.Sprintf(dirFilesByExtChartTmpl.ID, path))
+	if chart == nil {
+		return
+	}
+
+	name := ext
+	if name == "" {
+		name = "no extension"
+	}
+	if err := chart.AddDim(&module.Dim{ID: dirExtDimID(path, ext), Name: name}); err != nil {
+		fc.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
 }
 
 func (fc *Filecheck) removeDirFromCharts(path string) {
 	for _, chart := range *fc.Charts() {
-		if !strings.HasPrefix(chart.ID, "dir_") {
+		if !strings.HasPrefix(chart.ID, "dir_") || chart.Ctx == dirFilesByExtChartTmpl.Ctx {
 			continue
 		}
 
@@ -150,12 +234,64 @@ func (fc *Filecheck) removeDirFromCharts(path string) {
 		}
 		chart.MarkNotCreated()
 	}
+
+	if chart := fc.Charts().Get(fmt.Sprintf(dirFilesByExtChartTmpl.ID, path)); chart != nil {
+		chart.MarkRemove()
+		chart.MarkNotCreated()
+	}
 }
 
 func dirDimID(path, metric string) string {
 	return fmt.Sprintf("dir_%s_%s", path, metric)
 }
 
+func dirExtDimID(path, ext string) string {
+	return fmt.Sprintf("dir_%s_ext_%s_num_of_files", path, ext)
+}
+
+type dirStats struct {
+	numOfFiles int64
+	size       int64
+	byExt      map[string]int64
+}
+
+// walkDir counts regular files up to maxDepth levels below the root (1: the root entries only, 0: no limit).
+func walkDir(root string, maxDepth int) (*dirStats, error) {
+	stats := &dirStats{byExt: make(map[string]int64)}
+	root = filepath.Clean(root)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && maxDepth > 0 && pathDepth(root, path) >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		stats.numOfFiles++
+		stats.size += info.Size()
+		stats.byExt[strings.ToLower(strings.TrimPrefix(filepath.Ext(info.Name()), "."))]++
+		return nil
+	})
+	return stats, err
+}
+
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(rel, string(os.PathSeparator)) + 1
+}
+
 func calcDirNumOfFiles(dirpath string) (int, error) {
 	f, err := os.Open(dirpath)
 	if err != nil {
@@ -0,0 +1,102 @@
+package filecheck
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+)
+
+func (fc *Filecheck) collectFileContentChanged(ms map[string]int64, path string) {
+	sum, err := hashFile(path)
+	if err != nil {
+		fc.Debug(err)
+		return
+	}
+
+	prev, ok := fc.fileHashes[path]
+	fc.fileHashes[path] = sum
+	ms[fileDimID(path, "content_changed")] = boolToInt(ok && prev != sum)
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// lineCounter counts lines of an append-only file incrementally, only the appended data is read.
+type lineCounter struct {
+	info   os.FileInfo
+	offset int64
+	lines  int64
+}
+
+func (fc *Filecheck) collectFileLines(ms map[string]int64, path string, info os.FileInfo) {
+	lc, ok := fc.fileLines[path]
+	if !ok {
+		lc = &lineCounter{}
+		fc.fileLines[path] = lc
+	}
+
+	// the file was truncated or replaced (rotated), count from the beginning
+	if lc.info != nil && (!os.SameFile(lc.info, info) || info.Size() < lc.offset) {
+		lc.offset, lc.lines = 0, 0
+	}
+	lc.info = info
+
+	if info.Size() > lc.offset {
+		n, read, err := countLines(path, lc.offset)
+		if err != nil {
+			fc.Debug(err)
+			return
+		}
+		lc.lines += n
+		lc.offset += read
+	}
+
+	ms[fileDimID(path, "lines")] = lc.lines
+}
+
+func countLines(path string, offset int64) (lines, read int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+		read += int64(n)
+		if err == io.EOF {
+			return lines, read, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
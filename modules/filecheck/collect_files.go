@@ -43,6 +43,22 @@ func (fc *Filecheck) collectFile(ms map[string]int64, path string, curTime time.
 	ms[fileDimID(path, "exists")] = 1
 	ms[fileDimID(path, "size_bytes")] = info.Size()
 	ms[fileDimID(path, "mtime_ago")] = int64(curTime.Sub(info.ModTime()).Seconds())
+
+	if fc.Files.HashContent {
+		fc.collectFileContentChanged(ms, path)
+	}
+	if fc.Files.AppendOnly {
+		fc.collectFileLines(ms, path, info)
+	}
+	if fc.expectedMode != nil {
+		ms[fileDimID(path, "mode_drift")] = boolToInt(info.Mode().Perm() != *fc.expectedMode)
+	}
+	if fc.expectedUID != nil || fc.expectedGID != nil {
+		if uid, gid, ok := fileOwner(info); ok {
+			drift := (fc.expectedUID != nil && uid != *fc.expectedUID) || (fc.expectedGID != nil && gid != *fc.expectedGID)
+			ms[fileDimID(path, "owner_drift")] = boolToInt(drift)
+		}
+	}
 }
 
 func (fc Filecheck) discoveryFiles() (files []string) {
@@ -80,6 +96,8 @@ func (fc *Filecheck) updateFilesCharts(files []string) {
 	for path := range fc.collectedFiles {
 		if !set[path] {
 			delete(fc.collectedFiles, path)
+			delete(fc.fileHashes, path)
+			delete(fc.fileLines, path)
 			fc.removeFileFromCharts(path)
 		}
 	}
@@ -91,20 +109,16 @@ func (fc *Filecheck) addFileToCharts(path string) {
 			continue
 		}
 
-		var id string
-		switch chart.ID {
-		case fileExistenceChart.ID:
-			id = fileDimID(path, "exists")
-		case fileModTimeAgoChart.ID:
-			id = fileDimID(path, "mtime_ago")
-		case fileSizeChart.ID:
-			id = fileDimID(path, "size_bytes")
-		default:
+		id := fileChartDimID(chart.ID, path)
+		if id == "" {
 			fc.Warningf("add dimension: couldn't dim id for '%s' chart (file '%s')", chart.ID, path)
 			continue
 		}
 
 		dim := &module.Dim{ID: id, Name: path}
+		if chart.ID == fileGrowthRateChart.ID {
+			dim.Algo = module.Incremental
+		}
 
 		if err := chart.AddDim(dim); err != nil {
 			fc.Warning(err)
@@ -120,15 +134,8 @@ func (fc *Filecheck) removeFileFromCharts(path string) {
 			continue
 		}
 
-		var id string
-		switch chart.ID {
-		case fileExistenceChart.ID:
-			id = fileDimID(path, "exists")
-		case fileModTimeAgoChart.ID:
-			id = fileDimID(path, "mtime_ago")
-		case fileSizeChart.ID:
-			id = fileDimID(path, "size_bytes")
-		default:
+		id := fileChartDimID(chart.ID, path)
+		if id == "" {
 			fc.Warningf("remove dimension: couldn't dim id for '%s' chart (file '%s')", chart.ID, path)
 			continue
 		}
@@ -141,6 +148,26 @@ func (fc *Filecheck) removeFileFromCharts(path string) {
 	}
 }
 
+func fileChartDimID(chartID, path string) string {
+	switch chartID {
+	case fileExistenceChart.ID:
+		return fileDimID(path, "exists")
+	case fileModTimeAgoChart.ID:
+		return fileDimID(path, "mtime_ago")
+	case fileSizeChart.ID, fileGrowthRateChart.ID:
+		return fileDimID(path, "size_bytes")
+	case fileContentChangedChart.ID:
+		return fileDimID(path, "content_changed")
+	case fileLineCountChart.ID:
+		return fileDimID(path, "lines")
+	case fileModeDriftChart.ID:
+		return fileDimID(path, "mode_drift")
+	case fileOwnerDriftChart.ID:
+		return fileDimID(path, "owner_drift")
+	}
+	return ""
+}
+
 func fileDimID(path, metric string) string {
 	return fmt.Sprintf("file_%s_%s", path, metric)
 }
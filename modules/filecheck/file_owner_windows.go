@@ -0,0 +1,7 @@
+package filecheck
+
+import "os"
+
+func fileOwner(_ os.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
@@ -1,6 +1,8 @@
 package filecheck
 
 import (
+	"crypto/sha256"
+	"os"
 	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -26,7 +28,10 @@ func New() *Filecheck {
 			},
 		},
 		collectedFiles: make(map[string]bool),
+		fileHashes:     make(map[string][sha256.Size]byte),
+		fileLines:      make(map[string]*lineCounter),
 		collectedDirs:  make(map[string]bool),
+		dirExtensions:  make(map[string]map[string]bool),
 	}
 }
 
@@ -37,13 +42,21 @@ type (
 		Dirs           dirsConfig   `yaml:"dirs"`
 	}
 	filesConfig struct {
-		Include []string `yaml:"include"`
-		Exclude []string `yaml:"exclude"`
+		Include       []string `yaml:"include"`
+		Exclude       []string `yaml:"exclude"`
+		HashContent   bool     `yaml:"hash_content"`
+		AppendOnly    bool     `yaml:"append_only"`
+		ExpectedMode  string   `yaml:"expected_mode"`
+		ExpectedOwner string   `yaml:"expected_owner"`
+		ExpectedGroup string   `yaml:"expected_group"`
 	}
 	dirsConfig struct {
-		Include        []string `yaml:"include"`
-		Exclude        []string `yaml:"exclude"`
-		CollectDirSize bool     `yaml:"collect_dir_size"`
+		Include          []string `yaml:"include"`
+		Exclude          []string `yaml:"exclude"`
+		CollectDirSize   bool     `yaml:"collect_dir_size"`
+		Recursive        bool     `yaml:"recursive"`
+		MaxDepth         int      `yaml:"max_depth"`
+		CountByExtension bool     `yaml:"count_by_extension"`
 	}
 )
 
@@ -54,10 +67,16 @@ type Filecheck struct {
 	lastDiscoveryFiles time.Time
 	curFiles           []string
 	collectedFiles     map[string]bool
+	fileHashes         map[string][sha256.Size]byte
+	fileLines          map[string]*lineCounter
+	expectedMode       *os.FileMode
+	expectedUID        *uint32
+	expectedGID        *uint32
 
 	lastDiscoveryDirs time.Time
 	curDirs           []string
 	collectedDirs     map[string]bool
+	dirExtensions     map[string]map[string]bool
 
 	charts *module.Charts
 }
@@ -72,6 +91,11 @@ func (fc *Filecheck) Init() bool {
 		return false
 	}
 
+	if err := fc.initExpectations(); err != nil {
+		fc.Errorf("error on expected file attributes initialization: %v", err)
+		return false
+	}
+
 	charts, err := fc.initCharts()
 	if err != nil {
 		fc.Errorf("error on charts initialization: %v", err)
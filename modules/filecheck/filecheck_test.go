@@ -1,6 +1,10 @@
 package filecheck
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -76,6 +80,56 @@ func TestFilecheck_Init(t *testing.T) {
 			},
 			wantNumOfCharts: len(dirCharts),
 		},
+		"files->include with content, line count and attributes drift": {
+			config: Config{
+				Files: filesConfig{
+					Include:       []string{"/path/to/file1"},
+					HashContent:   true,
+					AppendOnly:    true,
+					ExpectedMode:  "0644",
+					ExpectedOwner: "0",
+					ExpectedGroup: "0",
+				},
+			},
+			wantNumOfCharts: len(fileCharts) + 5,
+		},
+		"files->expected_mode not octal": {
+			config: Config{
+				Files: filesConfig{
+					Include:      []string{"/path/to/file1"},
+					ExpectedMode: "0948",
+				},
+			},
+			wantFail: true,
+		},
+		"files->expected_mode not permission bits": {
+			config: Config{
+				Files: filesConfig{
+					Include:      []string{"/path/to/file1"},
+					ExpectedMode: "1777",
+				},
+			},
+			wantFail: true,
+		},
+		"files->expected_owner unknown user": {
+			config: Config{
+				Files: filesConfig{
+					Include:       []string{"/path/to/file1"},
+					ExpectedOwner: "no-such-user-filecheck",
+				},
+			},
+			wantFail: true,
+		},
+		"dirs->max_depth negative": {
+			config: Config{
+				Dirs: dirsConfig{
+					Include:   []string{"/path/to/dir1"},
+					Recursive: true,
+					MaxDepth:  -1,
+				},
+			},
+			wantFail: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -235,6 +289,223 @@ func TestFilecheck_Collect(t *testing.T) {
 	}
 }
 
+func TestFilecheck_Collect_ContentChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.conf")
+	writeFile(t, path, "key=value\n")
+
+	fc := New()
+	fc.Files.Include = []string{path}
+	fc.Files.HashContent = true
+	require.True(t, fc.Init())
+
+	id := fileDimID(path, "content_changed")
+
+	assert.Equal(t, int64(0), fc.Collect()[id], "first collection")
+	assert.Equal(t, int64(0), fc.Collect()[id], "not changed")
+
+	writeFile(t, path, "key=other\n")
+	assert.Equal(t, int64(1), fc.Collect()[id], "changed")
+	assert.Equal(t, int64(0), fc.Collect()[id], "not changed after change")
+	assert.True(t, fc.Charts().Get(fileContentChangedChart.ID).HasDim(id))
+}
+
+func TestFilecheck_Collect_LineCount(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	writeFile(t, path, "line1\nline2\n")
+
+	fc := New()
+	fc.Files.Include = []string{path}
+	fc.Files.AppendOnly = true
+	require.True(t, fc.Init())
+
+	id := fileDimID(path, "lines")
+
+	assert.Equal(t, int64(2), fc.Collect()[id], "initial")
+
+	appendFile(t, path, "line3\nline4\nline5")
+	assert.Equal(t, int64(4), fc.Collect()[id], "appended, last line is incomplete")
+
+	appendFile(t, path, "\n")
+	assert.Equal(t, int64(5), fc.Collect()[id], "last line completed")
+
+	writeFile(t, path, "line1\n")
+	assert.Equal(t, int64(1), fc.Collect()[id], "truncated")
+
+	require.NoError(t, os.Remove(path))
+	writeFile(t, path, "line1\nline2\nline3\nline4\nline5\nline6\nline7\n")
+	assert.Equal(t, int64(7), fc.Collect()[id], "replaced")
+
+	growth := fc.Charts().Get(fileGrowthRateChart.ID)
+	require.NotNil(t, growth)
+	require.True(t, growth.HasDim(fileDimID(path, "size_bytes")))
+	assert.Equal(t, module.Incremental, growth.GetDim(fileDimID(path, "size_bytes")).Algo)
+}
+
+func TestFilecheck_Collect_AttributesDrift(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.conf")
+	writeFile(t, path, "key=value\n")
+	require.NoError(t, os.Chmod(path, 0644))
+
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+
+	tests := map[string]struct {
+		mode, owner, group string
+		wantCollected      map[string]int64
+	}{
+		"as expected": {
+			mode: "0644", owner: uid, group: gid,
+			wantCollected: map[string]int64{
+				fileDimID(path, "mode_drift"):  0,
+				fileDimID(path, "owner_drift"): 0,
+			},
+		},
+		"mode differs": {
+			mode: "0600",
+			wantCollected: map[string]int64{
+				fileDimID(path, "mode_drift"): 1,
+			},
+		},
+		"owner differs": {
+			owner: strconv.Itoa(os.Getuid() + 1),
+			wantCollected: map[string]int64{
+				fileDimID(path, "owner_drift"): 1,
+			},
+		},
+		"group differs": {
+			owner: uid, group: strconv.Itoa(os.Getgid() + 1),
+			wantCollected: map[string]int64{
+				fileDimID(path, "owner_drift"): 1,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fc := New()
+			fc.Files.Include = []string{path}
+			fc.Files.ExpectedMode = test.mode
+			fc.Files.ExpectedOwner = test.owner
+			fc.Files.ExpectedGroup = test.group
+			require.True(t, fc.Init())
+
+			collected := fc.Collect()
+
+			for id, want := range test.wantCollected {
+				assert.Equalf(t, want, collected[id], "metric '%s'", id)
+			}
+			_, ok := collected[fileDimID(path, "mode_drift")]
+			assert.Equal(t, test.mode != "", ok)
+		})
+	}
+}
+
+func TestFilecheck_Collect_DirRecursive(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.log"), "12345")
+	writeFile(t, filepath.Join(root, "b.LOG"), "1")
+	writeFile(t, filepath.Join(root, "README"), "12")
+	writeFile(t, filepath.Join(root, "sub", "c.json"), "123")
+	writeFile(t, filepath.Join(root, "sub", "deep", "d.log"), "1234")
+
+	tests := map[string]struct {
+		recursive     bool
+		maxDepth      int
+		dirSize       bool
+		wantCollected map[string]int64
+	}{
+		"not recursive": {
+			wantCollected: map[string]int64{
+				dirDimID(root, "num_of_files"): 4,
+				dirExtDimID(root, "log"):       2,
+				dirExtDimID(root, ""):          1,
+			},
+		},
+		"not recursive with dir size": {
+			dirSize: true,
+			wantCollected: map[string]int64{
+				dirDimID(root, "num_of_files"): 4,
+				dirDimID(root, "size_bytes"):   15,
+				dirExtDimID(root, "log"):       2,
+				dirExtDimID(root, ""):          1,
+			},
+		},
+		"recursive without depth limit": {
+			recursive: true,
+			dirSize:   true,
+			wantCollected: map[string]int64{
+				dirDimID(root, "num_of_files"): 5,
+				dirDimID(root, "size_bytes"):   15,
+				dirExtDimID(root, "log"):       3,
+				dirExtDimID(root, "json"):      1,
+				dirExtDimID(root, ""):          1,
+			},
+		},
+		"recursive max_depth 2": {
+			recursive: true,
+			maxDepth:  2,
+			dirSize:   true,
+			wantCollected: map[string]int64{
+				dirDimID(root, "num_of_files"): 4,
+				dirDimID(root, "size_bytes"):   11,
+				dirExtDimID(root, "log"):       2,
+				dirExtDimID(root, "json"):      1,
+				dirExtDimID(root, ""):          1,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fc := New()
+			fc.Dirs.Include = []string{root}
+			fc.Dirs.CollectDirSize = test.dirSize
+			fc.Dirs.Recursive = test.recursive
+			fc.Dirs.MaxDepth = test.maxDepth
+			fc.Dirs.CountByExtension = true
+			require.True(t, fc.Init())
+
+			collected := fc.Collect()
+
+			for id, want := range test.wantCollected {
+				assert.Equalf(t, want, collected[id], "metric '%s'", id)
+			}
+			chart := fc.Charts().Get("dir_" + root + "_files_by_extension")
+			require.NotNil(t, chart)
+			for id := range test.wantCollected {
+				if strings.Contains(id, "_ext_") {
+					assert.Truef(t, chart.HasDim(id), "chart has no dim '%s'", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFilecheck_Collect_DirExtensionsRemoved(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.log"), "")
+
+	fc := New()
+	fc.Dirs.Include = []string{root}
+	fc.Dirs.CountByExtension = true
+	require.True(t, fc.Init())
+
+	assert.Equal(t, int64(1), fc.Collect()[dirExtDimID(root, "log")])
+
+	require.NoError(t, os.Remove(filepath.Join(root, "a.log")))
+	collected := fc.Collect()
+	v, ok := collected[dirExtDimID(root, "log")]
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), v)
+
+	fc.Dirs.Include = []string{filepath.Join(root, "non_existent")}
+	fc.lastDiscoveryDirs = fc.lastDiscoveryDirs.Add(-fc.DiscoveryEvery.Duration)
+	_ = fc.Collect()
+	assert.True(t, fc.Charts().Get("dir_"+root+"_files_by_extension").Obsolete)
+}
+
 func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, fc *Filecheck, collected map[string]int64) {
 	// TODO: check other charts
 	for _, chart := range *fc.Charts() {
@@ -341,3 +612,16 @@ func copyModTime(dst, src map[string]int64) {
 		}
 	}
 }
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func appendFile(t *testing.T, path, content string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+}
@@ -2,6 +2,10 @@ package filecheck
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 )
@@ -10,9 +14,63 @@ func (fc Filecheck) validateConfig() error {
 	if len(fc.Files.Include) == 0 && len(fc.Dirs.Include) == 0 {
 		return errors.New("both 'files->include' and 'dirs->include' are empty")
 	}
+	if fc.Dirs.MaxDepth < 0 {
+		return fmt.Errorf("'dirs->max_depth' must not be negative (%d)", fc.Dirs.MaxDepth)
+	}
+	return nil
+}
+
+func (fc *Filecheck) initExpectations() error {
+	if v := fc.Files.ExpectedMode; v != "" {
+		mode, err := strconv.ParseUint(v, 8, 32)
+		if err != nil || mode > 0777 {
+			return fmt.Errorf("invalid 'files->expected_mode' '%s', must be octal permission bits (e.g. 0644)", v)
+		}
+		m := os.FileMode(mode)
+		fc.expectedMode = &m
+	}
+	if v := fc.Files.ExpectedOwner; v != "" {
+		uid, err := lookupID(v, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid 'files->expected_owner' '%s': %v", v, err)
+		}
+		fc.expectedUID = &uid
+	}
+	if v := fc.Files.ExpectedGroup; v != "" {
+		gid, err := lookupID(v, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid 'files->expected_group' '%s': %v", v, err)
+		}
+		fc.expectedGID = &gid
+	}
 	return nil
 }
 
+// lookupID returns numeric user/group id, v is either a numeric id or a name.
+func lookupID(v string, lookup func(name string) (string, error)) (uint32, error) {
+	if id, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	s, err := lookup(v)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseUint(s, 10, 32)
+	return uint32(id), err
+}
+
 func (fc Filecheck) initCharts() (*module.Charts, error) {
 	charts := &module.Charts{}
 
@@ -20,6 +78,24 @@ func (fc Filecheck) initCharts() (*module.Charts, error) {
 		if err := charts.Add(*fileCharts.Copy()...); err != nil {
 			return nil, err
 		}
+		var optional []module.Chart
+		if fc.Files.HashContent {
+			optional = append(optional, fileContentChangedChart)
+		}
+		if fc.Files.AppendOnly {
+			optional = append(optional, fileLineCountChart, fileGrowthRateChart)
+		}
+		if fc.Files.ExpectedMode != "" {
+			optional = append(optional, fileModeDriftChart)
+		}
+		if fc.Files.ExpectedOwner != "" || fc.Files.ExpectedGroup != "" {
+			optional = append(optional, fileOwnerDriftChart)
+		}
+		for _, chart := range optional {
+			if err := charts.Add(chart.Copy()); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if len(fc.Dirs.Include) > 0 {
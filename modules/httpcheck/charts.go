@@ -1,6 +1,8 @@
 package httpcheck
 
 import (
+	"fmt"
+
 	"github.com/netdata/go.d.plugin/agent/module"
 )
 
@@ -24,6 +26,21 @@ var charts = Charts{
 			{ID: "time"},
 		},
 	},
+	{
+		ID:    "response_time_phases",
+		Title: "HTTP Response Time Phases",
+		Units: "ms",
+		Fam:   "response",
+		Ctx:   "httpcheck.response_time_phases",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "dns_lookup_time", Name: "dns lookup", Div: 1000},
+			{ID: "connect_time", Name: "connect", Div: 1000},
+			{ID: "tls_handshake_time", Name: "tls handshake", Div: 1000},
+			{ID: "server_processing_time", Name: "server processing", Div: 1000},
+			{ID: "content_transfer_time", Name: "content transfer", Div: 1000},
+		},
+	},
 	{
 		ID:    "response_length",
 		Title: "HTTP Response Body Length",
@@ -46,10 +63,10 @@ var charts = Charts{
 			{ID: "timeout"},
 			{ID: "bad_content", Name: "bad content"},
 			{ID: "bad_status", Name: "bad status"},
-			//{ID: "dns_lookup_error", Name: "dns lookup error"},
-			//{ID: "address_parse_error", Name: "address parse error"},
-			//{ID: "redirect_error", Name: "redirect error"},
-			//{ID: "body_read_error", Name: "body read error"},
+			{ID: "dns_lookup_error", Name: "dns lookup error"},
+			{ID: "address_parse_error", Name: "address parse error"},
+			{ID: "redirect_error", Name: "redirect error"},
+			{ID: "body_read_error", Name: "body read error"},
 		},
 	},
 	{
@@ -63,3 +80,93 @@ var charts = Charts{
 		},
 	},
 }
+
+var certExpiryChart = Chart{
+	ID:    "cert_expiry",
+	Title: "HTTP Certificate Time Until Expiration",
+	Units: "seconds",
+	Fam:   "certificate",
+	Ctx:   "httpcheck.cert_expiry",
+	Dims: Dims{
+		{ID: "cert_expiry", Name: "expiry"},
+	},
+}
+
+var transactionStatusDims = Dims{
+	{ID: "bad_header", Name: "bad header"},
+	{ID: "slow_response", Name: "slow response"},
+	{ID: "extract_error", Name: "extract error"},
+}
+
+var stepChartsTmpl = Charts{
+	{
+		ID:    "step_%s_response_time",
+		Title: "HTTP Transaction Step Response Time",
+		Units: "ms",
+		Fam:   "step %s",
+		Ctx:   "httpcheck.step_response_time",
+		Dims: Dims{
+			{ID: "step_%s_time", Name: "time"},
+		},
+	},
+	{
+		ID:    "step_%s_request_status",
+		Title: "HTTP Transaction Step Status",
+		Units: "boolean",
+		Fam:   "step %s",
+		Ctx:   "httpcheck.step_status",
+		Dims: Dims{
+			{ID: "step_%s_success", Name: "success"},
+			{ID: "step_%s_no_connection", Name: "no connection"},
+			{ID: "step_%s_timeout", Name: "timeout"},
+			{ID: "step_%s_bad_content", Name: "bad content"},
+			{ID: "step_%s_bad_status", Name: "bad status"},
+			{ID: "step_%s_dns_lookup_error", Name: "dns lookup error"},
+			{ID: "step_%s_address_parse_error", Name: "address parse error"},
+			{ID: "step_%s_redirect_error", Name: "redirect error"},
+			{ID: "step_%s_body_read_error", Name: "body read error"},
+			{ID: "step_%s_bad_header", Name: "bad header"},
+			{ID: "step_%s_slow_response", Name: "slow response"},
+			{ID: "step_%s_extract_error", Name: "extract error"},
+			{ID: "step_%s_skipped", Name: "skipped"},
+		},
+	},
+}
+
+func (hc *HTTPCheck) addCertExpiryChart() {
+	if err := hc.charts.Add(certExpiryChart.Copy()); err != nil {
+		hc.Warning(err)
+	}
+}
+
+func (hc *HTTPCheck) addTransactionCharts() {
+	if chart := hc.charts.Get("request_status"); chart != nil {
+		for _, dim := range transactionStatusDims {
+			dim := *dim
+			if err := chart.AddDim(&dim); err != nil {
+				hc.Warning(err)
+			}
+		}
+	}
+	if chart := hc.charts.Get("response_time"); chart != nil {
+		chart.Title = "HTTP Transaction Response Time"
+	}
+	// request phases are measured for a single request check only
+	if err := hc.charts.Remove("response_time_phases"); err != nil {
+		hc.Warning(err)
+	}
+
+	for _, s := range hc.steps {
+		charts := stepChartsTmpl.Copy()
+		for _, chart := range *charts {
+			chart.ID = fmt.Sprintf(chart.ID, s.name)
+			chart.Fam = fmt.Sprintf(chart.Fam, s.name)
+			for _, dim := range chart.Dims {
+				dim.ID = fmt.Sprintf(dim.ID, s.name)
+			}
+		}
+		if err := hc.charts.Add(*charts...); err != nil {
+			hc.Warning(err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package httpcheck
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,79 +17,92 @@ type reqErrCode int
 
 const (
 	codeTimeout reqErrCode = iota
-	//codeDNSLookup
-	//codeParseAddress
-	//codeRedirect
+	codeDNSLookup
+	codeParseAddress
+	codeRedirect
 	codeNoConnection
 )
 
 func (hc *HTTPCheck) collect() (map[string]int64, error) {
+	if len(hc.steps) > 0 {
+		return hc.collectTransaction()
+	}
+
 	req, err := web.NewHTTPRequest(hc.Request)
 	if err != nil {
 		return nil, fmt.Errorf("error on creating HTTP requests to %s : %v", hc.Request.URL, err)
 	}
+	// every check makes a new connection, otherwise DNS lookup, connect and TLS handshake phases are not measured
+	req.Close = true
 
 	var mx metrics
+	var trace requestTrace
 
 	start := time.Now()
-	resp, err := hc.client.Do(req)
+	resp, err := hc.client.Do(trace.withTrace(req))
 	dur := time.Since(start)
 	defer closeBody(resp)
 
 	if err != nil {
 		hc.Warning(err)
-		hc.collectErrResponse(&mx, err)
+		collectErrResponse(&mx.Status, err)
+		if mx.Status.RedirectError {
+			mx.ResponseTime = durationToMs(dur)
+		}
 	} else {
 		mx.ResponseTime = durationToMs(dur)
-		hc.collectOKResponse(&mx, resp)
+		hc.collectOKResponse(&mx, resp, &trace)
+		hc.collectCertExpiry(&mx, resp)
 	}
 
+	hc.updateInState(&mx)
+
+	return stm.ToMap(mx), nil
+}
+
+func (hc *HTTPCheck) updateInState(mx *metrics) {
 	changed := hc.metrics.Status != mx.Status
 	if changed {
 		mx.InState = hc.UpdateEvery
 	} else {
 		mx.InState = hc.metrics.InState + hc.UpdateEvery
 	}
-	hc.metrics = mx
-
-	//if err == nil || mx.Status.RedirectError {
-	//	mx.ResponseTime = durationToMs(end)
-	//}
-
-	return stm.ToMap(mx), nil
+	hc.metrics = *mx
 }
 
-func (hc HTTPCheck) collectErrResponse(mx *metrics, err error) {
+func collectErrResponse(st *status, err error) {
 	switch code := decodeReqError(err); code {
 	default:
 		panic(fmt.Sprintf("unknown request error code : %d", code))
 	case codeNoConnection:
-		mx.Status.NoConnection = true
-	//case codeDNSLookup:
-	//	mx.Status.DNSLookupError = true
-	//case codeParseAddress:
-	//	mx.Status.ParseAddressError = true
-	//case codeRedirect:
-	//	mx.Status.RedirectError = true
+		st.NoConnection = true
+	case codeDNSLookup:
+		st.DNSLookupError = true
+	case codeParseAddress:
+		st.ParseAddressError = true
+	case codeRedirect:
+		st.RedirectError = true
 	case codeTimeout:
-		mx.Status.Timeout = true
+		st.Timeout = true
 	}
 }
 
-func (hc HTTPCheck) collectOKResponse(mx *metrics, resp *http.Response) {
+func (hc HTTPCheck) collectOKResponse(mx *metrics, resp *http.Response, trace *requestTrace) {
 	if !hc.acceptedStatuses[resp.StatusCode] {
 		mx.Status.BadStatusCode = true
 		return
 	}
 
 	bs, err := ioutil.ReadAll(resp.Body)
+	trace.markBodyRead()
 	if err != nil && err != io.EOF {
 		hc.Warningf("error on reading body : %v", err)
-		mx.Status.BadContent = true
+		mx.Status.BodyReadError = true
 		return
 	}
 
 	mx.ResponseLength = len(bs)
+	trace.collect(&mx.Phases)
 
 	if hc.reResponse != nil && !hc.reResponse.Match(bs) {
 		mx.Status.BadContent = true
@@ -98,42 +112,43 @@ func (hc HTTPCheck) collectOKResponse(mx *metrics, resp *http.Response) {
 	mx.Status.Success = true
 }
 
+func (hc *HTTPCheck) collectCertExpiry(mx *metrics, resp *http.Response) {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return
+	}
+
+	expiry := int(time.Until(resp.TLS.PeerCertificates[0].NotAfter).Seconds())
+	mx.CertExpiry = &expiry
+
+	if !hc.hasCertChart {
+		hc.hasCertChart = true
+		hc.addCertExpiryChart()
+	}
+}
+
 func decodeReqError(err error) reqErrCode {
 	if err == nil {
 		panic("nil error")
 	}
+
 	if v, ok := err.(net.Error); ok && v.Timeout() {
 		return codeTimeout
 	}
+	if errors.Is(err, web.ErrRedirectAttempted) {
+		return codeRedirect
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return codeDNSLookup
+	}
+	var parseErr *net.ParseError
+	var addrErr *net.AddrError
+	if errors.As(err, &parseErr) || errors.As(err, &addrErr) {
+		return codeParseAddress
+	}
+
 	return codeNoConnection
-	//
-	//netErr, isNetErr := err.(net.Error)
-	//if isNetErr && netErr.Timeout() {
-	//	return codeTimeout
-	//}
-	//
-	//urlErr, isURLErr := err.(*url.Error)
-	//if !isURLErr {
-	//	return codeNoConnection
-	//}
-	//
-	//if urlErr.Err == web.ErrRedirectAttempted {
-	//	return codeRedirect
-	//}
-	//
-	//opErr, isOpErr := (urlErr.Err).(*net.OpError)
-	//if !isOpErr {
-	//	return codeNoConnection
-	//}
-	//
-	//switch (opErr.Err).(type) {
-	//case *net.DNSError:
-	//	return codeDNSLookup
-	//case *net.ParseError:
-	//	return codeParseAddress
-	//}
-	//
-	//return codeNoConnection
 }
 
 func closeBody(resp *http.Response) {
@@ -0,0 +1,128 @@
+package httpcheck
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/stm"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func (hc *HTTPCheck) collectTransaction() (map[string]int64, error) {
+	// cookies set by a step are sent by the next steps (login sessions)
+	jar, _ := cookiejar.New(nil)
+	vars := make(map[string]string)
+
+	var mx metrics
+	stepsMx := make(map[string]stepMetrics, len(hc.steps))
+	failed := false
+
+	for _, s := range hc.steps {
+		if failed {
+			stepsMx[s.name] = stepMetrics{Skipped: true}
+			continue
+		}
+
+		smx := hc.runStep(s, vars, jar)
+		stepsMx[s.name] = smx
+
+		mx.ResponseTime += smx.ResponseTime
+		mx.ResponseLength += smx.ResponseLength
+		if !smx.Status.Success {
+			failed = true
+			mx.Status = smx.Status
+		}
+	}
+	if !failed {
+		mx.Status.Success = true
+	}
+
+	hc.updateInState(&mx)
+
+	collected := stm.ToMap(mx)
+	for name, smx := range stepsMx {
+		for k, v := range stm.ToMap(smx) {
+			collected["step_"+name+"_"+k] = v
+		}
+	}
+	return collected, nil
+}
+
+func (hc *HTTPCheck) runStep(s *step, vars map[string]string, jar http.CookieJar) (mx stepMetrics) {
+	reqCfg := s.newRequest(vars)
+	req, err := web.NewHTTPRequest(reqCfg)
+	if err != nil {
+		hc.Warningf("step '%s': error on creating HTTP request to %s : %v", s.name, reqCfg.URL, err)
+		mx.Status.NoConnection = true
+		return mx
+	}
+	for _, c := range jar.Cookies(req.URL) {
+		req.AddCookie(c)
+	}
+
+	start := time.Now()
+	resp, err := hc.client.Do(req)
+	dur := time.Since(start)
+	defer closeBody(resp)
+
+	if err != nil {
+		hc.Warningf("step '%s': %v", s.name, err)
+		collectErrResponse(&mx.Status, err)
+		return mx
+	}
+	mx.ResponseTime = durationToMs(dur)
+	jar.SetCookies(req.URL, resp.Cookies())
+
+	if !s.acceptedStatuses[resp.StatusCode] {
+		hc.Debugf("step '%s': bad status code %d", s.name, resp.StatusCode)
+		mx.Status.BadStatusCode = true
+		return mx
+	}
+
+	var bs []byte
+	if resp.Body != nil {
+		bs, err = ioutil.ReadAll(resp.Body)
+		if err != nil && err != io.EOF {
+			hc.Warningf("step '%s': error on reading body : %v", s.name, err)
+			mx.Status.BodyReadError = true
+			return mx
+		}
+	}
+	mx.ResponseLength = len(bs)
+
+	if s.reResponse != nil && !s.reResponse.Match(bs) {
+		hc.Debugf("step '%s': body doesn't match %s", s.name, s.reResponse)
+		mx.Status.BadContent = true
+		return mx
+	}
+
+	for name, re := range s.reHeaders {
+		if v := resp.Header.Get(name); v == "" || !re.MatchString(v) {
+			hc.Debugf("step '%s': header '%s' value '%s' doesn't match %s", s.name, name, v, re)
+			mx.Status.BadHeader = true
+			return mx
+		}
+	}
+
+	if s.maxResponseTime.Duration > 0 && dur > s.maxResponseTime.Duration {
+		hc.Debugf("step '%s': response time %s exceeds %s", s.name, dur, s.maxResponseTime.Duration)
+		mx.Status.SlowResponse = true
+		return mx
+	}
+
+	for _, e := range s.extractors {
+		v, err := e.extract(resp.Header, bs)
+		if err != nil {
+			hc.Warningf("step '%s': %v", s.name, err)
+			mx.Status.ExtractError = true
+			return mx
+		}
+		vars[e.name] = v
+	}
+
+	mx.Status.Success = true
+	return mx
+}
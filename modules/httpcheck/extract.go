@@ -0,0 +1,115 @@
+package httpcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/netdata/go.d.plugin/pkg/jsonpath"
+)
+
+type extractor struct {
+	name     string
+	header   string
+	jsonPath jsonpath.Path
+	re       *regexp.Regexp
+}
+
+func newExtractor(cfg ExtractConfig) (*extractor, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("extract: 'name' not set")
+	}
+	if cfg.Header == "" && cfg.JSONPath == "" && cfg.Regexp == "" {
+		return nil, fmt.Errorf("extract '%s': one of 'header', 'json_path' or 'regexp' must be set", cfg.Name)
+	}
+	if cfg.Header != "" && cfg.JSONPath != "" {
+		return nil, fmt.Errorf("extract '%s': 'header' and 'json_path' are mutually exclusive", cfg.Name)
+	}
+
+	e := &extractor{name: cfg.Name, header: cfg.Header}
+	if cfg.JSONPath != "" {
+		path, err := jsonpath.Parse(cfg.JSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("extract '%s': %v", cfg.Name, err)
+		}
+		if path.HasWildcard() {
+			return nil, fmt.Errorf("extract '%s': wildcards are not supported in 'json_path'", cfg.Name)
+		}
+		e.jsonPath = path
+	}
+	if cfg.Regexp != "" {
+		re, err := regexp.Compile(cfg.Regexp)
+		if err != nil {
+			return nil, fmt.Errorf("extract '%s': error on creating regexp %s : %v", cfg.Name, cfg.Regexp, err)
+		}
+		e.re = re
+	}
+	return e, nil
+}
+
+// extract returns the value from the response header or body.
+// The regexp (if set) is applied to the header value, the JSONPath result or the body,
+// the value is the first capturing group or the whole match if there is no groups.
+func (e *extractor) extract(header http.Header, body []byte) (string, error) {
+	var value string
+	switch {
+	case e.header != "":
+		if value = header.Get(e.header); value == "" {
+			return "", fmt.Errorf("extract '%s': header '%s' not found", e.name, e.header)
+		}
+	case e.jsonPath != nil:
+		v, err := evalJSONPath(body, e.jsonPath)
+		if err != nil {
+			return "", fmt.Errorf("extract '%s': %v", e.name, err)
+		}
+		value = v
+	default:
+		value = string(body)
+	}
+
+	if e.re == nil {
+		return value, nil
+	}
+	match := e.re.FindStringSubmatch(value)
+	switch len(match) {
+	case 0:
+		return "", fmt.Errorf("extract '%s': regexp %s doesn't match", e.name, e.re)
+	case 1:
+		return match[0], nil
+	default:
+		return match[1], nil
+	}
+}
+
+func evalJSONPath(data []byte, path jsonpath.Path) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("error on decoding JSON : %v", err)
+	}
+
+	matches := path.Find(doc)
+	if len(matches) == 0 {
+		return "", errors.New("JSONPath: no value found")
+	}
+
+	switch val := matches[0].Value.(type) {
+	case nil:
+		return "", errors.New("JSONPath: value is null")
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		bs, err := json.Marshal(val)
+		return string(bs), err
+	}
+}
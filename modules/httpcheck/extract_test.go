@@ -0,0 +1,108 @@
+package httpcheck
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExtractor(t *testing.T) {
+	tests := map[string]struct {
+		config   ExtractConfig
+		wantFail bool
+	}{
+		"regexp":                {config: ExtractConfig{Name: "a", Regexp: `id=(\d+)`}},
+		"header":                {config: ExtractConfig{Name: "a", Header: "X-Id"}},
+		"header and regexp":     {config: ExtractConfig{Name: "a", Header: "X-Id", Regexp: `\d+`}},
+		"json_path":             {config: ExtractConfig{Name: "a", JSONPath: "$.a.b[0]"}},
+		"json_path and regexp":  {config: ExtractConfig{Name: "a", JSONPath: "a.b", Regexp: `\d+`}},
+		"no name":               {config: ExtractConfig{Regexp: `\d+`}, wantFail: true},
+		"no source":             {config: ExtractConfig{Name: "a"}, wantFail: true},
+		"header and json_path":  {config: ExtractConfig{Name: "a", Header: "X-Id", JSONPath: "a"}, wantFail: true},
+		"bad regexp":            {config: ExtractConfig{Name: "a", Regexp: "(?:qwe))"}, wantFail: true},
+		"bad json_path":         {config: ExtractConfig{Name: "a", JSONPath: "$"}, wantFail: true},
+		"bad json_path index":   {config: ExtractConfig{Name: "a", JSONPath: "a[x]"}, wantFail: true},
+		"bad json_path bracket": {config: ExtractConfig{Name: "a", JSONPath: "a[0"}, wantFail: true},
+		"json_path wildcard":    {config: ExtractConfig{Name: "a", JSONPath: "$.a[*]"}, wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := newExtractor(test.config)
+
+			if test.wantFail {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, e)
+			}
+		})
+	}
+}
+
+func TestExtractor_extract(t *testing.T) {
+	body := []byte(`{"data": {"id": 42, "ok": true, "items": [{"name": "a"}, {"name": "b", "tags": ["x", "y"]}], "nil": null}}`)
+	header := http.Header{}
+	header.Set("X-Request-Id", "req-123")
+
+	tests := map[string]struct {
+		config    ExtractConfig
+		wantValue string
+		wantFail  bool
+	}{
+		"header":                    {config: ExtractConfig{Header: "X-Request-Id"}, wantValue: "req-123"},
+		"header with regexp group":  {config: ExtractConfig{Header: "X-Request-Id", Regexp: `req-(\d+)`}, wantValue: "123"},
+		"header not found":          {config: ExtractConfig{Header: "X-Missing"}, wantFail: true},
+		"body regexp whole match":   {config: ExtractConfig{Regexp: `"id": \d+`}, wantValue: `"id": 42`},
+		"body regexp no match":      {config: ExtractConfig{Regexp: `not match`}, wantFail: true},
+		"json_path number":          {config: ExtractConfig{JSONPath: "$.data.id"}, wantValue: "42"},
+		"json_path bool":            {config: ExtractConfig{JSONPath: "data.ok"}, wantValue: "true"},
+		"json_path array index":     {config: ExtractConfig{JSONPath: "$.data.items[1].name"}, wantValue: "b"},
+		"json_path nested index":    {config: ExtractConfig{JSONPath: "$.data.items[1].tags[0]"}, wantValue: "x"},
+		"json_path object":          {config: ExtractConfig{JSONPath: "$.data.items[0]"}, wantValue: `{"name":"a"}`},
+		"json_path with regexp":     {config: ExtractConfig{JSONPath: "$.data.items[1].name", Regexp: "."}, wantValue: "b"},
+		"json_path key not found":   {config: ExtractConfig{JSONPath: "$.data.missing"}, wantFail: true},
+		"json_path index on object": {config: ExtractConfig{JSONPath: "$.data[0]"}, wantFail: true},
+		"json_path key on array":    {config: ExtractConfig{JSONPath: "$.data.items.name"}, wantFail: true},
+		"json_path index overflow":  {config: ExtractConfig{JSONPath: "$.data.items[5]"}, wantFail: true},
+		"json_path through scalar":  {config: ExtractConfig{JSONPath: "$.data.id.value"}, wantFail: true},
+		"json_path null":            {config: ExtractConfig{JSONPath: "$.data.nil"}, wantFail: true},
+		"json_path bracket key":     {config: ExtractConfig{JSONPath: "$['data']['items'][0]['name']"}, wantValue: "a"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.config.Name = "test"
+			e, err := newExtractor(test.config)
+			require.NoError(t, err)
+
+			v, err := e.extract(header, body)
+
+			if test.wantFail {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantValue, v)
+			}
+		})
+	}
+}
+
+func TestExtractor_extractInvalidJSON(t *testing.T) {
+	e, err := newExtractor(ExtractConfig{Name: "test", JSONPath: "$.a"})
+	require.NoError(t, err)
+
+	_, err = e.extract(http.Header{}, []byte("not json"))
+
+	assert.Error(t, err)
+}
+
+func Test_expandVariables(t *testing.T) {
+	vars := map[string]string{"token": "abc", "id": "42"}
+
+	assert.Equal(t, "/api/42?t=abc", expandVariables("/api/${id}?t=${token}", vars))
+	assert.Equal(t, "/api/${unknown}", expandVariables("/api/${unknown}", vars))
+	assert.Equal(t, "$id ${id", expandVariables("$id ${id", vars))
+}
@@ -39,14 +39,16 @@ func New() *HTTPCheck {
 	return &HTTPCheck{
 		Config:           config,
 		acceptedStatuses: make(map[int]bool),
+		charts:           charts.Copy(),
 	}
 }
 
 // Config is the HTTPCheck module configuration.
 type Config struct {
 	web.HTTP         `yaml:",inline"`
-	AcceptedStatuses []int  `yaml:"status_accepted"`
-	ResponseMatch    string `yaml:"response_match"`
+	AcceptedStatuses []int        `yaml:"status_accepted"`
+	ResponseMatch    string       `yaml:"response_match"`
+	Steps            []StepConfig `yaml:"steps"`
 }
 
 type client interface {
@@ -61,8 +63,11 @@ type HTTPCheck struct {
 
 	acceptedStatuses map[int]bool
 	reResponse       *regexp.Regexp
+	steps            []*step
 	client           client
 	metrics          metrics
+	charts           *Charts
+	hasCertChart     bool
 }
 
 // Cleanup makes cleanup.
@@ -70,10 +75,14 @@ func (HTTPCheck) Cleanup() {}
 
 // Init makes initialization
 func (hc *HTTPCheck) Init() bool {
-	if hc.URL == "" {
+	if hc.URL == "" && len(hc.Steps) == 0 {
 		hc.Error("URL not set")
 		return false
 	}
+	if hc.URL != "" && len(hc.Steps) > 0 {
+		hc.Error("'url' and 'steps' are mutually exclusive")
+		return false
+	}
 
 	client, err := web.NewHTTPClient(hc.Client)
 	if err != nil {
@@ -95,6 +104,19 @@ func (hc *HTTPCheck) Init() bool {
 		hc.acceptedStatuses[v] = true
 	}
 
+	if len(hc.Steps) > 0 {
+		steps, err := newSteps(hc.Steps)
+		if err != nil {
+			hc.Errorf("error on creating transaction steps : %v", err)
+			return false
+		}
+		hc.steps = steps
+		hc.addTransactionCharts()
+		hc.Debugf("using transaction of %d steps", len(hc.steps))
+		hc.Debugf("using HTTP timeout %s", hc.Timeout.Duration)
+		return true
+	}
+
 	hc.Debugf("using URL %s", hc.URL)
 	hc.Debugf("using HTTP timeout %s", hc.Timeout.Duration)
 	hc.Debugf("using accepted HTTP statuses %v", hc.AcceptedStatuses)
@@ -108,8 +130,8 @@ func (hc *HTTPCheck) Init() bool {
 // Check makes check.
 func (hc *HTTPCheck) Check() bool { return len(hc.Collect()) > 0 }
 
-// Charts creates Charts
-func (hc HTTPCheck) Charts() *Charts { return charts.Copy() }
+// Charts returns Charts.
+func (hc HTTPCheck) Charts() *Charts { return hc.charts }
 
 // Collect collects metrics
 func (hc *HTTPCheck) Collect() map[string]int64 {
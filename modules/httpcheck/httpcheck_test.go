@@ -2,11 +2,17 @@ package httpcheck
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/stm"
+	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/stretchr/testify/assert"
@@ -97,51 +103,114 @@ func TestHTTPCheck_Collect_TimeoutError(t *testing.T) {
 
 }
 
-//func TestHTTPCheck_Collect_DNSLookupError(t *testing.T) {
-//	job := New()
-//
-//	job.UserURL = testURL
-//	require.True(t, job.Init())
-//
-//	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.DNSError{}}})
-//	job.client = newClientFunc(nil, err)
-//	assert.Equal(
-//		t,
-//		stm.ToMap(metrics{Status: status{DNSLookupError: true}}),
-//		job.Collect(),
-//	)
-//}
-
-//func TestHTTPCheck_Collect_AddressParseError(t *testing.T) {
-//	job := New()
-//
-//	job.UserURL = testURL
-//	require.True(t, job.Init())
-//
-//	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.ParseError{}}})
-//	job.client = newClientFunc(nil, err)
-//	assert.Equal(
-//		t,
-//		stm.ToMap(metrics{Status: status{ParseAddressError: true}}),
-//		job.Collect(),
-//	)
-//
-//}
-
-//func TestHTTPCheck_Collect_RedirectError(t *testing.T) {
-//	job := New()
-//
-//	job.UserURL = testURL
-//	require.True(t, job.Init())
-//
-//	err := net.Error(&url.Error{Err: web.ErrRedirectAttempted})
-//	job.client = newClientFunc(nil, err)
-//	assert.Equal(
-//		t,
-//		stm.ToMap(metrics{Status: status{RedirectError: true}}),
-//		job.Collect(),
-//	)
-//}
+func TestHTTPCheck_Collect_DNSLookupError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.DNSError{}}})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{DNSLookupError: true}}),
+		job.Collect(),
+	)
+}
+
+func TestHTTPCheck_Collect_AddressParseError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: &net.OpError{Err: &net.ParseError{}}})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{ParseAddressError: true}}),
+		job.Collect(),
+	)
+
+}
+
+func TestHTTPCheck_Collect_RedirectError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: web.ErrRedirectAttempted})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{RedirectError: true}}),
+		job.Collect(),
+	)
+}
+
+func TestHTTPCheck_Collect_NoConnectionError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	err := net.Error(&url.Error{Err: &net.OpError{Err: errors.New("connection refused")}})
+	job.client = newClientFunc(nil, err)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{NoConnection: true}}),
+		job.Collect(),
+	)
+}
+
+func TestHTTPCheck_Collect_BodyReadError(t *testing.T) {
+	job := New()
+
+	job.URL = testURL
+	require.True(t, job.Init())
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       nopCloser{errReader{}},
+	}
+	job.client = newClientFunc(resp, nil)
+	assert.Equal(
+		t,
+		stm.ToMap(metrics{Status: status{BodyReadError: true}}),
+		job.Collect(),
+	)
+}
+
+func TestHTTPCheck_Collect_PhasesAndCertExpiry(t *testing.T) {
+	body := "hello"
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	job.InsecureSkipVerify = true
+	require.True(t, job.Init())
+	require.False(t, job.Charts().Has(certExpiryChart.ID))
+
+	for i := 0; i < 2; i++ {
+		mx := job.Collect()
+		require.NotNil(t, mx)
+
+		assert.Equal(t, int64(1), mx["success"])
+		assert.Equal(t, int64(len(body)), mx["length"])
+		assert.Greater(t, mx["connect_time"], int64(0))
+		assert.Greater(t, mx["tls_handshake_time"], int64(0))
+		assert.Greater(t, mx["server_processing_time"], int64(0))
+		assert.Equal(t, int64(0), mx["dns_lookup_time"]) // IP address, no lookup
+
+		notAfter := ts.Certificate().NotAfter
+		assert.InDelta(t, time.Until(notAfter).Seconds(), mx["cert_expiry"], 5)
+		assert.True(t, job.Charts().Has(certExpiryChart.ID))
+	}
+}
 
 func TestHTTPCheck_Collect_BadContentError(t *testing.T) {
 	job := New()
@@ -258,6 +327,10 @@ type nopCloser struct {
 
 func (nopCloser) Close() error { return nil }
 
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read error") }
+
 type timeoutError struct{}
 
 func (r timeoutError) Timeout() bool { return true }
@@ -265,3 +338,252 @@ func (r timeoutError) Timeout() bool { return true }
 func (r timeoutError) Error() string { return "" }
 
 func (r timeoutError) Temporary() bool { return true }
+
+func TestHTTPCheck_InitTransaction(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"valid steps": {
+			config: Config{Steps: []StepConfig{
+				{Name: "login", Request: web.Request{URL: testURL}},
+				{Name: "api", Request: web.Request{URL: testURL}},
+			}},
+		},
+		"url and steps": {
+			wantFail: true,
+			config: Config{
+				HTTP:  web.HTTP{Request: web.Request{URL: testURL}},
+				Steps: []StepConfig{{Name: "login", Request: web.Request{URL: testURL}}},
+			},
+		},
+		"step without name": {
+			wantFail: true,
+			config:   Config{Steps: []StepConfig{{Request: web.Request{URL: testURL}}}},
+		},
+		"step with bad name": {
+			wantFail: true,
+			config:   Config{Steps: []StepConfig{{Name: "log in", Request: web.Request{URL: testURL}}}},
+		},
+		"duplicate step names": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Name: "login", Request: web.Request{URL: testURL}},
+				{Name: "login", Request: web.Request{URL: testURL}},
+			}},
+		},
+		"step without url": {
+			wantFail: true,
+			config:   Config{Steps: []StepConfig{{Name: "login"}}},
+		},
+		"step bad header_match regexp": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Name: "login", Request: web.Request{URL: testURL}, HeaderMatch: map[string]string{"X-A": "(?:qwe))"}},
+			}},
+		},
+		"step bad extract": {
+			wantFail: true,
+			config: Config{Steps: []StepConfig{
+				{Name: "login", Request: web.Request{URL: testURL}, Extract: []ExtractConfig{{Name: "token"}}},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			test.config.Timeout = job.Timeout
+			test.config.AcceptedStatuses = job.AcceptedStatuses
+			job.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+				// no response time phases chart for transactions
+				assert.Len(t, *job.Charts(), len(charts)-1+len(stepChartsTmpl)*len(test.config.Steps))
+			}
+		})
+	}
+}
+
+func TestHTTPCheck_CollectTransaction(t *testing.T) {
+	ts := newTransactionServer()
+	defer ts.Close()
+
+	tests := map[string]struct {
+		prepareSteps func(url string) []StepConfig
+		wantStatus   map[string]string
+	}{
+		"all steps succeed": {
+			prepareSteps: prepareTransactionSteps,
+			wantStatus:   map[string]string{"": "success", "login": "success", "token": "success", "api": "success"},
+		},
+		"bad status": {
+			prepareSteps: func(url string) []StepConfig {
+				steps := prepareTransactionSteps(url)
+				steps[0].Body = "user=admin&password=wrong"
+				return steps
+			},
+			wantStatus: map[string]string{"": "bad_status", "login": "bad_status", "token": "skipped", "api": "skipped"},
+		},
+		"bad content": {
+			prepareSteps: func(url string) []StepConfig {
+				steps := prepareTransactionSteps(url)
+				steps[2].ResponseMatch = "not match"
+				return steps
+			},
+			wantStatus: map[string]string{"": "bad_content", "login": "success", "token": "success", "api": "bad_content"},
+		},
+		"bad header": {
+			prepareSteps: func(url string) []StepConfig {
+				steps := prepareTransactionSteps(url)
+				steps[2].HeaderMatch = map[string]string{"Content-Type": "^text/html"}
+				return steps
+			},
+			wantStatus: map[string]string{"": "bad_header", "login": "success", "token": "success", "api": "bad_header"},
+		},
+		"slow response": {
+			prepareSteps: func(url string) []StepConfig {
+				steps := prepareTransactionSteps(url)
+				steps[2].URL += "?delay=1"
+				steps[2].MaxResponseTime = web.Duration{Duration: time.Millisecond}
+				return steps
+			},
+			wantStatus: map[string]string{"": "slow_response", "login": "success", "token": "success", "api": "slow_response"},
+		},
+		"extract error": {
+			prepareSteps: func(url string) []StepConfig {
+				steps := prepareTransactionSteps(url)
+				steps[1].Extract[0].JSONPath = "$.data.missing"
+				return steps
+			},
+			wantStatus: map[string]string{"": "extract_error", "login": "success", "token": "extract_error", "api": "skipped"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Steps = test.prepareSteps(ts.URL)
+			job.UpdateEvery = 5
+			require.True(t, job.Init())
+
+			mx := job.Collect()
+			require.NotNil(t, mx)
+
+			for step, want := range test.wantStatus {
+				px := ""
+				if step != "" {
+					px = "step_" + step + "_"
+				}
+				for _, st := range []string{
+					"success", "no_connection", "timeout", "bad_content", "bad_status",
+					"bad_header", "slow_response", "extract_error", "skipped",
+				} {
+					if step == "" && st == "skipped" {
+						continue
+					}
+					var v int64
+					if st == want {
+						v = 1
+					}
+					assert.Equalf(t, v, mx[px+st], "step '%s' status '%s'", step, st)
+				}
+			}
+			assert.Equal(t, int64(job.UpdateEvery), mx["in_state"])
+			ensureCollectedHasAllChartsDimsIDs(t, job, mx)
+		})
+	}
+}
+
+func TestHTTPCheck_CollectTransactionNoConnection(t *testing.T) {
+	job := New()
+	job.Steps = prepareTransactionSteps(testURL)
+	require.True(t, job.Init())
+	job.client = newClientFunc(nil, errors.New("no connection"))
+
+	mx := job.Collect()
+
+	assert.Equal(t, int64(1), mx["no_connection"])
+	assert.Equal(t, int64(1), mx["step_login_no_connection"])
+	assert.Equal(t, int64(1), mx["step_token_skipped"])
+	assert.Equal(t, int64(1), mx["step_api_skipped"])
+}
+
+func ensureCollectedHasAllChartsDimsIDs(t *testing.T, hc *HTTPCheck, collected map[string]int64) {
+	for _, chart := range *hc.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := collected[dim.ID]
+			assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+		}
+	}
+}
+
+func prepareTransactionSteps(url string) []StepConfig {
+	return []StepConfig{
+		{
+			Name: "login",
+			Request: web.Request{
+				URL:     url + "/login",
+				Method:  http.MethodPost,
+				Body:    "user=admin&password=secret",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+			Extract: []ExtractConfig{
+				{Name: "request_id", Header: "X-Request-Id", Regexp: `^req-(\d+)$`},
+			},
+		},
+		{
+			Name:    "token",
+			Request: web.Request{URL: url + "/token?request_id=${request_id}"},
+			Extract: []ExtractConfig{
+				{Name: "token", JSONPath: "$.data.tokens[1].value"},
+			},
+		},
+		{
+			Name: "api",
+			Request: web.Request{
+				URL:     url + "/api",
+				Headers: map[string]string{"Authorization": "Bearer ${token}"},
+			},
+			AcceptedStatuses: []int{200, 204},
+			ResponseMatch:    `"status":\s*"ok"`,
+			HeaderMatch:      map[string]string{"Content-Type": "^application/json"},
+			MaxResponseTime:  web.Duration{Duration: time.Second},
+		},
+	}
+}
+
+func newTransactionServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Method != http.MethodPost || r.Form.Get("password") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+		w.Header().Set("X-Request-Id", "req-42")
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "s3cr3t" || r.URL.Query().Get("request_id") != "42" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"tokens": [{"value": "old"}, {"value": "abc123"}]}}`))
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc123" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("delay") != "" {
+			time.Sleep(time.Millisecond * 10)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	})
+	return httptest.NewServer(mux)
+}
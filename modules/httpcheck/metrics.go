@@ -5,16 +5,37 @@ type metrics struct {
 	InState        int    `stm:"in_state"`
 	ResponseTime   int    `stm:"time"`
 	ResponseLength int    `stm:"length"`
+	Phases         phases `stm:""`
+	CertExpiry     *int   `stm:"cert_expiry"` // Only for HTTPS
+}
+
+type stepMetrics struct {
+	Status         status `stm:""`
+	Skipped        bool   `stm:"skipped"` // A previous step of the transaction failed
+	ResponseTime   int    `stm:"time"`
+	ResponseLength int    `stm:"length"`
+}
+
+// phases are in microseconds.
+type phases struct {
+	DNSLookup        int `stm:"dns_lookup_time"`
+	Connect          int `stm:"connect_time"`
+	TLSHandshake     int `stm:"tls_handshake_time"`
+	ServerProcessing int `stm:"server_processing_time"`
+	ContentTransfer  int `stm:"content_transfer_time"`
 }
 
 type status struct {
-	Success bool `stm:"success"` // No error on request, body reading and checking its content
-	Timeout bool `stm:"timeout"`
-	//DNSLookupError    bool `stm:"dns_lookup_error"`
-	//ParseAddressError bool `stm:"address_parse_error"`
-	//RedirectError     bool `stm:"redirect_error"`
-	//BodyReadError     bool `stm:"body_read_error"`
-	BadContent    bool `stm:"bad_content"`
-	BadStatusCode bool `stm:"bad_status"`
-	NoConnection  bool `stm:"no_connection"` // All other errors basically
+	Success           bool `stm:"success"` // No error on request, body reading and checking its content
+	Timeout           bool `stm:"timeout"`
+	DNSLookupError    bool `stm:"dns_lookup_error"`
+	ParseAddressError bool `stm:"address_parse_error"`
+	RedirectError     bool `stm:"redirect_error"`
+	BodyReadError     bool `stm:"body_read_error"`
+	BadContent        bool `stm:"bad_content"`
+	BadStatusCode     bool `stm:"bad_status"`
+	BadHeader         bool `stm:"bad_header"`    // Transaction step only
+	SlowResponse      bool `stm:"slow_response"` // Transaction step only
+	ExtractError      bool `stm:"extract_error"` // Transaction step only
+	NoConnection      bool `stm:"no_connection"` // All other errors basically
 }
@@ -0,0 +1,71 @@
+package httpcheck
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// requestTrace records the time of the HTTP request phases.
+// In case of redirects the phases of the last request are recorded.
+type requestTrace struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	bodyRead     time.Time
+}
+
+func (t *requestTrace) withTrace(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// called for every address when dialing in parallel ('happy eyeballs'), the first one is the start
+			if t.connectStart.IsZero() || !t.connectDone.IsZero() {
+				t.connectStart, t.connectDone = time.Now(), time.Time{}
+			}
+		},
+		ConnectDone:          func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+func (t *requestTrace) mark(v *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*v = time.Now()
+}
+
+func (t *requestTrace) markBodyRead() { t.mark(&t.bodyRead) }
+
+func (t *requestTrace) collect(mx *phases) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	mx.DNSLookup = durationToUs(t.dnsStart, t.dnsDone)
+	mx.Connect = durationToUs(t.connectStart, t.connectDone)
+	mx.TLSHandshake = durationToUs(t.tlsStart, t.tlsDone)
+	mx.ServerProcessing = durationToUs(t.wroteRequest, t.firstByte)
+	mx.ContentTransfer = durationToUs(t.firstByte, t.bodyRead)
+}
+
+func durationToUs(start, end time.Time) int {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start).Microseconds())
+}
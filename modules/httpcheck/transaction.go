@@ -0,0 +1,140 @@
+package httpcheck
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+// StepConfig is the configuration of a single step of an HTTP transaction.
+type StepConfig struct {
+	Name             string `yaml:"name"`
+	web.Request      `yaml:",inline"`
+	AcceptedStatuses []int             `yaml:"status_accepted"`
+	ResponseMatch    string            `yaml:"response_match"`
+	HeaderMatch      map[string]string `yaml:"header_match"`
+	MaxResponseTime  web.Duration      `yaml:"max_response_time"`
+	Extract          []ExtractConfig   `yaml:"extract"`
+}
+
+// ExtractConfig is the configuration of a variable extracted from a step response.
+type ExtractConfig struct {
+	Name     string `yaml:"name"`
+	Header   string `yaml:"header"`
+	JSONPath string `yaml:"json_path"`
+	Regexp   string `yaml:"regexp"`
+}
+
+type step struct {
+	name             string
+	request          web.Request
+	acceptedStatuses map[int]bool
+	reResponse       *regexp.Regexp
+	reHeaders        map[string]*regexp.Regexp
+	maxResponseTime  web.Duration
+	extractors       []*extractor
+}
+
+var reStepName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func newSteps(configs []StepConfig) ([]*step, error) {
+	var steps []*step
+	seen := make(map[string]bool)
+
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("step %d: 'name' not set", i+1)
+		}
+		if !reStepName.MatchString(cfg.Name) {
+			return nil, fmt.Errorf("step '%s': 'name' contains not allowed characters", cfg.Name)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("step '%s': duplicate name", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		s, err := newStep(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("step '%s': %v", cfg.Name, err)
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+func newStep(cfg StepConfig) (*step, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("'url' not set")
+	}
+
+	s := &step{
+		name:             cfg.Name,
+		request:          cfg.Request.Copy(),
+		acceptedStatuses: make(map[int]bool),
+		reHeaders:        make(map[string]*regexp.Regexp),
+		maxResponseTime:  cfg.MaxResponseTime,
+	}
+
+	statuses := cfg.AcceptedStatuses
+	if len(statuses) == 0 {
+		statuses = defaultAcceptedStatuses
+	}
+	for _, v := range statuses {
+		s.acceptedStatuses[v] = true
+	}
+
+	if cfg.ResponseMatch != "" {
+		re, err := regexp.Compile(cfg.ResponseMatch)
+		if err != nil {
+			return nil, fmt.Errorf("error on creating regexp %s : %v", cfg.ResponseMatch, err)
+		}
+		s.reResponse = re
+	}
+
+	for name, expr := range cfg.HeaderMatch {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("error on creating header '%s' regexp %s : %v", name, expr, err)
+		}
+		s.reHeaders[name] = re
+	}
+
+	for _, ec := range cfg.Extract {
+		e, err := newExtractor(ec)
+		if err != nil {
+			return nil, err
+		}
+		s.extractors = append(s.extractors, e)
+	}
+	return s, nil
+}
+
+var reVariable = regexp.MustCompile(`\$\{([a-zA-Z0-9_]+)}`)
+
+// expandVariables replaces ${name} with values extracted by the previous steps.
+// Unknown variables are left as is.
+func expandVariables(s string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return s
+	}
+	return reVariable.ReplaceAllStringFunc(s, func(match string) string {
+		if v, ok := vars[match[2:len(match)-1]]; ok {
+			return v
+		}
+		return match
+	})
+}
+
+func (s *step) newRequest(vars map[string]string) web.Request {
+	req := s.request.Copy()
+	req.URL = expandVariables(req.URL, vars)
+	req.Body = expandVariables(req.Body, vars)
+	req.Username = expandVariables(req.Username, vars)
+	req.Password = expandVariables(req.Password, vars)
+	for k, v := range req.Headers {
+		req.Headers[k] = expandVariables(v, vars)
+	}
+	return req
+}
@@ -18,17 +18,21 @@ import (
 	_ "github.com/netdata/go.d.plugin/modules/elasticsearch"
 	_ "github.com/netdata/go.d.plugin/modules/energid"
 	_ "github.com/netdata/go.d.plugin/modules/example"
+	_ "github.com/netdata/go.d.plugin/modules/exec"
 	_ "github.com/netdata/go.d.plugin/modules/filecheck"
 	_ "github.com/netdata/go.d.plugin/modules/fluentd"
 	_ "github.com/netdata/go.d.plugin/modules/freeradius"
 	_ "github.com/netdata/go.d.plugin/modules/hdfs"
 	_ "github.com/netdata/go.d.plugin/modules/httpcheck"
 	_ "github.com/netdata/go.d.plugin/modules/isc_dhcpd"
+	_ "github.com/netdata/go.d.plugin/modules/jsonapi"
 	_ "github.com/netdata/go.d.plugin/modules/k8s_kubelet"
 	_ "github.com/netdata/go.d.plugin/modules/k8s_kubeproxy"
 	_ "github.com/netdata/go.d.plugin/modules/lighttpd"
 	_ "github.com/netdata/go.d.plugin/modules/lighttpd2"
 	_ "github.com/netdata/go.d.plugin/modules/logstash"
+	_ "github.com/netdata/go.d.plugin/modules/memcached"
+	_ "github.com/netdata/go.d.plugin/modules/mongodb"
 	_ "github.com/netdata/go.d.plugin/modules/mysql"
 	_ "github.com/netdata/go.d.plugin/modules/nginx"
 	_ "github.com/netdata/go.d.plugin/modules/nginxvts"
@@ -37,7 +41,9 @@ import (
 	_ "github.com/netdata/go.d.plugin/modules/phpfpm"
 	_ "github.com/netdata/go.d.plugin/modules/pihole"
 	_ "github.com/netdata/go.d.plugin/modules/pika"
+	_ "github.com/netdata/go.d.plugin/modules/ping"
 	_ "github.com/netdata/go.d.plugin/modules/portcheck"
+	_ "github.com/netdata/go.d.plugin/modules/postgres"
 	_ "github.com/netdata/go.d.plugin/modules/powerdns"
 	_ "github.com/netdata/go.d.plugin/modules/powerdns_recursor"
 	_ "github.com/netdata/go.d.plugin/modules/prometheus"
@@ -45,8 +51,10 @@ import (
 	_ "github.com/netdata/go.d.plugin/modules/rabbitmq"
 	_ "github.com/netdata/go.d.plugin/modules/redis"
 	_ "github.com/netdata/go.d.plugin/modules/scaleio"
+	_ "github.com/netdata/go.d.plugin/modules/snmp"
 	_ "github.com/netdata/go.d.plugin/modules/solr"
 	_ "github.com/netdata/go.d.plugin/modules/springboot2"
+	_ "github.com/netdata/go.d.plugin/modules/sqlquery"
 	_ "github.com/netdata/go.d.plugin/modules/squidlog"
 	_ "github.com/netdata/go.d.plugin/modules/supervisord"
 	_ "github.com/netdata/go.d.plugin/modules/systemdunits"
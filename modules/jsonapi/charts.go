@@ -0,0 +1,88 @@
+package jsonapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/jsonpath"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+type chartDef struct {
+	chart *module.Chart
+	dims  []*dimDef
+}
+
+type dimDef struct {
+	cfg     DimConfig
+	chartID string
+	path    jsonpath.Path
+	jmes    *jmespath.JMESPath
+	dynamic bool
+	seen    map[string]bool
+}
+
+// find returns the values the dimension path matches in the document.
+// JMESPath expressions are evaluated against jmesDoc, see toJMESPathDoc.
+func (d *dimDef) find(doc, jmesDoc interface{}) ([]jsonpath.Match, error) {
+	if d.jmes == nil {
+		return d.path.Find(doc), nil
+	}
+	v, err := d.jmes.Search(jmesDoc)
+	if err != nil {
+		return nil, err
+	}
+	return jmesPathMatches(v), nil
+}
+
+// jmesPathMatches converts a JMESPath result to matches: a match per key (index) for objects (arrays),
+// a single match without keys for everything else.
+func jmesPathMatches(v interface{}) []jsonpath.Match {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		matches := make([]jsonpath.Match, 0, len(keys))
+		for _, k := range keys {
+			matches = append(matches, jsonpath.Match{Keys: []string{k}, Value: v[k]})
+		}
+		return matches
+	case []interface{}:
+		matches := make([]jsonpath.Match, 0, len(v))
+		for i, e := range v {
+			matches = append(matches, jsonpath.Match{Keys: []string{strconv.Itoa(i)}, Value: e})
+		}
+		return matches
+	default:
+		return []jsonpath.Match{{Value: v}}
+	}
+}
+
+// dimName returns the dimension name for the matched value.
+// Dynamic dimensions are named after the matched keys, prefixed with the configured name.
+func (d *dimDef) dimName(m jsonpath.Match) string {
+	name := strings.Join(m.Keys, "_")
+	if name == "" {
+		return d.cfg.Name
+	}
+	if d.cfg.Name != "" {
+		name = d.cfg.Name + "_" + name
+	}
+	return name
+}
+
+func (d *dimDef) dimID(name string) string {
+	return d.chartID + "_" + name
+}
+
+func (d *dimDef) newDim(name string) *module.Dim {
+	return d.cfg.New(d.dimID(name), name)
+}
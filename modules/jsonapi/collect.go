@@ -0,0 +1,139 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func (j *JSONAPI) collect() (map[string]int64, error) {
+	doc, err := j.fetchDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	var jmesDoc interface{}
+	if j.doJMESPath {
+		jmesDoc = toJMESPathDoc(doc)
+	}
+
+	mx := make(map[string]int64)
+	for _, def := range j.defs {
+		j.collectChart(mx, def, doc, jmesDoc)
+	}
+	return mx, nil
+}
+
+func (j *JSONAPI) collectChart(mx map[string]int64, def *chartDef, doc, jmesDoc interface{}) {
+	for _, dim := range def.dims {
+		matches, err := dim.find(doc, jmesDoc)
+		if err != nil {
+			j.Warningf("chart '%s': jmespath '%s': %v", def.chart.ID, dim.cfg.JMESPath, err)
+			continue
+		}
+		for _, m := range matches {
+			v, ok := toFloat(m.Value)
+			if !ok {
+				continue
+			}
+
+			name := dim.dimName(m)
+			if name == "" {
+				j.Debugf("chart '%s': jmespath '%s' returned a single value, but the dimension 'name' is not set",
+					def.chart.ID, dim.cfg.JMESPath)
+				continue
+			}
+			if !dim.seen[name] {
+				dim.seen[name] = true
+				if err := def.chart.AddDim(dim.newDim(name)); err != nil {
+					j.Warning(err)
+				}
+				def.chart.MarkNotCreated()
+			}
+			mx[dim.dimID(name)] = int64(v * chartdef.Precision)
+		}
+	}
+}
+
+func (j *JSONAPI) fetchDocument() (interface{}, error) {
+	req, err := web.NewHTTPRequest(j.Request)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request : %v", err)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on HTTP request '%s': %v", req.URL, err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("'%s' returned HTTP status code: %d", req.URL, resp.StatusCode)
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error on decoding response from '%s': %v", req.URL, err)
+	}
+	return doc, nil
+}
+
+// toJMESPathDoc returns a copy of the document with the numbers converted to float64,
+// JMESPath functions and comparisons don't work with json.Number.
+func toJMESPathDoc(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = toJMESPathDoc(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, e := range v {
+			s[i] = toJMESPathDoc(e)
+		}
+		return s
+	}
+	return v
+}
+
+// toFloat converts a JSON value to a number.
+// Booleans are converted to 1/0, strings are accepted if they hold a number.
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
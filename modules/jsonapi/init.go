@@ -0,0 +1,100 @@
+package jsonapi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/pkg/jsonpath"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+func (j JSONAPI) validateConfig() error {
+	if j.URL == "" {
+		return errors.New("URL not set")
+	}
+	if _, err := web.NewHTTPRequest(j.Request); err != nil {
+		return err
+	}
+	if len(j.Config.Charts) == 0 {
+		return errors.New("'charts' not set")
+	}
+	return nil
+}
+
+func (j *JSONAPI) initCharts() error {
+	for i, cfg := range j.Config.Charts {
+		def, err := newChartDef(cfg)
+		if err != nil {
+			return fmt.Errorf("chart[%d]: %v", i+1, err)
+		}
+		if err := j.charts.Add(def.chart); err != nil {
+			return fmt.Errorf("chart[%d]: %v", i+1, err)
+		}
+		for _, dim := range def.dims {
+			j.doJMESPath = j.doJMESPath || dim.jmes != nil
+		}
+		j.defs = append(j.defs, def)
+	}
+	return nil
+}
+
+func newChartDef(cfg ChartConfig) (*chartDef, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if len(cfg.Dimensions) == 0 {
+		return nil, fmt.Errorf("chart '%s': 'dimensions' not set", cfg.ID)
+	}
+
+	def := &chartDef{chart: cfg.New("jsonapi.")}
+	for i, dimCfg := range cfg.Dimensions {
+		dim, err := newDimDef(cfg.ID, dimCfg)
+		if err != nil {
+			return nil, fmt.Errorf("chart '%s': dimension[%d]: %v", cfg.ID, i+1, err)
+		}
+		if !dim.dynamic {
+			if err := def.chart.AddDim(dim.newDim(dim.cfg.Name)); err != nil {
+				return nil, fmt.Errorf("chart '%s': %v", cfg.ID, err)
+			}
+			dim.seen[dim.cfg.Name] = true
+		}
+		def.dims = append(def.dims, dim)
+	}
+	return def, nil
+}
+
+func newDimDef(chartID string, cfg DimConfig) (*dimDef, error) {
+	if (cfg.Path == "") == (cfg.JMESPath == "") {
+		return nil, errors.New("one of 'path' or 'jmespath' must be set")
+	}
+	if err := cfg.Init(); err != nil {
+		return nil, err
+	}
+	dim := &dimDef{
+		cfg:     cfg,
+		chartID: chartID,
+		seen:    make(map[string]bool),
+	}
+
+	if cfg.JMESPath != "" {
+		jmes, err := jmespath.Compile(cfg.JMESPath)
+		if err != nil {
+			return nil, fmt.Errorf("bad jmespath '%s': %v", cfg.JMESPath, err)
+		}
+		// the result type is known only after the search, the dimensions are added as they appear
+		dim.jmes, dim.dynamic = jmes, true
+		return dim, nil
+	}
+
+	path, err := jsonpath.Parse(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	dim.path, dim.dynamic = path, path.HasWildcard()
+	if !dim.dynamic && cfg.Name == "" {
+		return nil, errors.New("'name' not set")
+	}
+	return dim, nil
+}
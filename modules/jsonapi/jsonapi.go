@@ -0,0 +1,115 @@
+package jsonapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	creator := module.Creator{
+		Create: func() module.Module { return New() },
+	}
+
+	module.Register("jsonapi", creator)
+}
+
+const defaultHTTPTimeout = time.Second * 2
+
+// New creates JSONAPI with default values.
+func New() *JSONAPI {
+	config := Config{
+		HTTP: web.HTTP{
+			Client: web.Client{
+				Timeout: web.Duration{Duration: defaultHTTPTimeout},
+			},
+		},
+	}
+
+	return &JSONAPI{
+		Config: config,
+		charts: &module.Charts{},
+	}
+}
+
+type (
+	Config struct {
+		web.HTTP `yaml:",inline"`
+		Charts   []ChartConfig `yaml:"charts"`
+	}
+	ChartConfig struct {
+		chartdef.Chart `yaml:",inline"`
+		Dimensions     []DimConfig `yaml:"dimensions"`
+	}
+	DimConfig struct {
+		chartdef.Dim `yaml:",inline"`
+		Path         string `yaml:"path"`
+		JMESPath     string `yaml:"jmespath"`
+	}
+)
+
+// JSONAPI JSONAPI module.
+type JSONAPI struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	httpClient *http.Client
+	charts     *module.Charts
+	defs       []*chartDef
+	doJMESPath bool
+}
+
+// Cleanup makes cleanup.
+func (j *JSONAPI) Cleanup() {
+	if j.httpClient == nil {
+		return
+	}
+	j.httpClient.CloseIdleConnections()
+}
+
+// Init makes initialization.
+func (j *JSONAPI) Init() bool {
+	if err := j.validateConfig(); err != nil {
+		j.Errorf("config validation: %v", err)
+		return false
+	}
+
+	client, err := web.NewHTTPClient(j.Client)
+	if err != nil {
+		j.Errorf("error on creating http client : %v", err)
+		return false
+	}
+	j.httpClient = client
+
+	if err := j.initCharts(); err != nil {
+		j.Errorf("error on creating charts : %v", err)
+		return false
+	}
+
+	j.Debugf("using URL %s", j.URL)
+	j.Debugf("using timeout: %s", j.Timeout.Duration)
+
+	return true
+}
+
+// Check makes check.
+func (j *JSONAPI) Check() bool { return len(j.Collect()) > 0 }
+
+// Charts returns Charts.
+func (j *JSONAPI) Charts() *module.Charts { return j.charts }
+
+// Collect collects metrics.
+func (j *JSONAPI) Collect() map[string]int64 {
+	mx, err := j.collect()
+	if err != nil {
+		j.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
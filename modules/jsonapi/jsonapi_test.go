@@ -0,0 +1,338 @@
+package jsonapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+	"github.com/netdata/go.d.plugin/pkg/jsonpath"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDataStatus, _ = ioutil.ReadFile("testdata/status.json")
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestJSONAPI_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"success on default test config": {
+			config: prepareConfig("http://127.0.0.1:38001"),
+		},
+		"fails on unset URL": {
+			wantFail: true,
+			config:   prepareConfig(""),
+		},
+		"fails on unset charts": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts = nil
+				return cfg
+			}(),
+		},
+		"fails on chart without id": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].ID = ""
+				return cfg
+			}(),
+		},
+		"fails on duplicate chart id": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[1].ID = cfg.Charts[0].ID
+				return cfg
+			}(),
+		},
+		"fails on unknown chart type": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Type = "pie"
+				return cfg
+			}(),
+		},
+		"fails on chart without dimensions": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions = nil
+				return cfg
+			}(),
+		},
+		"fails on bad path": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].Path = "$.a["
+				return cfg
+			}(),
+		},
+		"fails on both path and jmespath": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].JMESPath = "uptime"
+				return cfg
+			}(),
+		},
+		"fails on neither path nor jmespath": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].Path = ""
+				return cfg
+			}(),
+		},
+		"fails on bad jmespath": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].Path = ""
+				cfg.Charts[0].Dimensions[0].JMESPath = "pools[?"
+				return cfg
+			}(),
+		},
+		"fails on static dimension without name": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].Name = ""
+				return cfg
+			}(),
+		},
+		"fails on unknown algorithm": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].Algorithm = "delta"
+				return cfg
+			}(),
+		},
+		"fails on negative divisor": {
+			wantFail: true,
+			config: func() Config {
+				cfg := prepareConfig("http://127.0.0.1:38001")
+				cfg.Charts[0].Dimensions[0].Divisor = -1
+				return cfg
+			}(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestJSONAPI_Check(t *testing.T) {
+	job, cleanup := prepareJSONAPIWithStatus(t)
+	defer cleanup()
+
+	assert.True(t, job.Check())
+}
+
+func TestJSONAPI_Check_ReturnsFalseOnConnectionRefused(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig("http://127.0.0.1:38001")
+	require.True(t, job.Init())
+
+	assert.False(t, job.Check())
+}
+
+func TestJSONAPI_Check_ReturnsFalseOnInvalidData(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello and\n goodbye"))
+		}))
+	defer ts.Close()
+
+	job := New()
+	job.Config = prepareConfig(ts.URL)
+	require.True(t, job.Init())
+
+	assert.False(t, job.Check())
+}
+
+func TestJSONAPI_Check_ReturnsFalseOnNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+	defer ts.Close()
+
+	job := New()
+	job.Config = prepareConfig(ts.URL)
+	require.True(t, job.Init())
+
+	assert.False(t, job.Check())
+}
+
+func TestJSONAPI_Charts(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig("http://127.0.0.1:38001")
+	require.True(t, job.Init())
+
+	charts := job.Charts()
+	require.Len(t, *charts, 3)
+
+	chart := charts.Get("requests")
+	require.NotNil(t, chart)
+	assert.Equal(t, "jsonapi.requests", chart.Ctx)
+	assert.Equal(t, "requests", chart.Fam)
+	assert.Equal(t, module.Stacked, chart.Type)
+	require.Len(t, chart.Dims, 3)
+	assert.Equal(t, module.Incremental, chart.Dims[0].Algo)
+	assert.Equal(t, 1000, chart.Dims[0].Div)
+
+	chart = charts.Get("queues")
+	require.NotNil(t, chart)
+	assert.Equal(t, "app.queues", chart.Ctx)
+	assert.Len(t, chart.Dims, 0)
+}
+
+func TestJSONAPI_Cleanup(t *testing.T) {
+	assert.NotPanics(t, New().Cleanup)
+}
+
+func TestJSONAPI_Collect(t *testing.T) {
+	job, cleanup := prepareJSONAPIWithStatus(t)
+	defer cleanup()
+
+	expected := map[string]int64{
+		"health_healthy":       1000,
+		"health_load":          750,
+		"health_uptime":        3600000,
+		"queues_email_size":    10000,
+		"queues_email_workers": 2000,
+		"queues_sms_size":      3500,
+		"queues_sms_workers":   1000,
+		"requests_failed":      12000,
+		"requests_pool_0":      4000,
+		"requests_pool_1":      1000,
+		"requests_rss":         1048576000,
+		"requests_total":       1500000,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+
+	queues := job.Charts().Get("queues")
+	require.NotNil(t, queues)
+	assert.Len(t, queues.Dims, 4)
+	assert.True(t, queues.HasDim("queues_email_size"))
+	assert.Equal(t, "email_size", queues.GetDim("queues_email_size").Name)
+	assert.Len(t, job.Charts().Get("requests").Dims, 5)
+}
+
+func TestJSONAPI_Collect_JMESPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(testDataStatus)
+		}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	job.Config.Charts = []ChartConfig{
+		{
+			Chart: chartdef.Chart{ID: "pools"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Name: "active"}, JMESPath: "sum(pools[].active)"},
+				{Dim: chartdef.Dim{Name: "main"}, JMESPath: "pools[?name=='main'] | [0].active"},
+				{Dim: chartdef.Dim{Name: "size"}, JMESPath: "{email: queues.email.size, sms: queues.sms.size}"},
+				{JMESPath: "uptime"},
+				{Dim: chartdef.Dim{Name: "missing"}, JMESPath: "no.such.key"},
+			},
+		},
+	}
+	require.True(t, job.Init())
+
+	expected := map[string]int64{
+		"pools_active":     5000,
+		"pools_main":       4000,
+		"pools_size_email": 10000,
+		"pools_size_sms":   3500,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+	chart := job.Charts().Get("pools")
+	require.NotNil(t, chart)
+	assert.Len(t, chart.Dims, 4)
+	assert.Equal(t, "size_email", chart.GetDim("pools_size_email").Name)
+}
+
+func TestJSONAPI_Collect_SkipsNonNumericValues(t *testing.T) {
+	job, cleanup := prepareJSONAPIWithStatus(t)
+	defer cleanup()
+	job.defs[0].dims[0].path, _ = jsonpath.Parse("$.version")
+
+	mx := job.Collect()
+
+	_, ok := mx["health_uptime"]
+	assert.False(t, ok)
+}
+
+func prepareJSONAPIWithStatus(t *testing.T) (*JSONAPI, func()) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(testDataStatus)
+		}))
+
+	job := New()
+	job.Config = prepareConfig(ts.URL)
+	require.True(t, job.Init())
+
+	return job, ts.Close
+}
+
+func prepareConfig(url string) Config {
+	cfg := New().Config
+	cfg.URL = url
+	cfg.Charts = []ChartConfig{
+		{
+			Chart: chartdef.Chart{ID: "health"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Name: "uptime"}, Path: "$.uptime"},
+				{Dim: chartdef.Dim{Name: "healthy"}, Path: "$.healthy"},
+				{Dim: chartdef.Dim{Name: "load"}, Path: "$.load"},
+			},
+		},
+		{
+			Chart: chartdef.Chart{ID: "requests", Title: "Requests", Units: "requests/s", Type: "stacked"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Name: "total", Algorithm: "incremental"}, Path: "$.requests.total"},
+				{Dim: chartdef.Dim{Name: "failed", Algorithm: "incremental"}, Path: "$.requests.failed"},
+				{Dim: chartdef.Dim{Name: "rss"}, Path: "$['memory.stats'].rss"},
+				{Dim: chartdef.Dim{Name: "pool"}, Path: "$.pools[*].active"},
+			},
+		},
+		{
+			Chart: chartdef.Chart{ID: "queues", Context: "app.queues"},
+			Dimensions: []DimConfig{
+				{Path: "$.queues.*.*"},
+			},
+		},
+	}
+	return cfg
+}
@@ -0,0 +1,243 @@
+package memcached
+
+import (
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+type (
+	Charts = module.Charts
+	Dims   = module.Dims
+)
+
+var charts = Charts{
+	{
+		ID:    "cache",
+		Title: "Cache Size",
+		Units: "bytes",
+		Fam:   "cache",
+		Ctx:   "memcached.cache",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "avail", Name: "available"},
+			{ID: "bytes", Name: "used"},
+		},
+	},
+	{
+		ID:    "net",
+		Title: "Network",
+		Units: "bytes/s",
+		Fam:   "network",
+		Ctx:   "memcached.net",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "bytes_read", Name: "in", Algo: module.Incremental},
+			{ID: "bytes_written", Name: "out", Algo: module.Incremental, Mul: -1},
+		},
+	},
+	{
+		ID:    "connections",
+		Title: "Connections",
+		Units: "connections",
+		Fam:   "connections",
+		Ctx:   "memcached.connections",
+		Dims: Dims{
+			{ID: "curr_connections", Name: "current"},
+		},
+	},
+	{
+		ID:    "connections_rate",
+		Title: "Connections Rate",
+		Units: "connections/s",
+		Fam:   "connections",
+		Ctx:   "memcached.connections_rate",
+		Dims: Dims{
+			{ID: "total_connections", Name: "opened", Algo: module.Incremental},
+			{ID: "rejected_connections", Name: "rejected", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "items",
+		Title: "Items",
+		Units: "items",
+		Fam:   "items",
+		Ctx:   "memcached.items",
+		Dims: Dims{
+			{ID: "curr_items", Name: "current"},
+		},
+	},
+	{
+		ID:    "items_rate",
+		Title: "Stored Items",
+		Units: "items/s",
+		Fam:   "items",
+		Ctx:   "memcached.items_rate",
+		Dims: Dims{
+			{ID: "total_items", Name: "stored", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "evictions",
+		Title: "Evicted And Reclaimed Items",
+		Units: "items/s",
+		Fam:   "items",
+		Ctx:   "memcached.evictions",
+		Dims: Dims{
+			{ID: "evictions", Name: "evicted", Algo: module.Incremental},
+			{ID: "reclaimed", Name: "reclaimed", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "commands",
+		Title: "Commands",
+		Units: "commands/s",
+		Fam:   "commands",
+		Ctx:   "memcached.commands",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "cmd_get", Name: "get", Algo: module.Incremental},
+			{ID: "cmd_set", Name: "set", Algo: module.Incremental},
+			{ID: "cmd_flush", Name: "flush", Algo: module.Incremental},
+			{ID: "cmd_touch", Name: "touch", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "get_ratio",
+		Title: "Get Hits Ratio",
+		Units: "percentage",
+		Fam:   "hits",
+		Ctx:   "memcached.get_ratio",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "get_hits", Name: "hits", Algo: module.PercentOfIncremental},
+			{ID: "get_misses", Name: "misses", Algo: module.PercentOfIncremental},
+		},
+	},
+	{
+		ID:    "hits",
+		Title: "Hits",
+		Units: "requests/s",
+		Fam:   "hits",
+		Ctx:   "memcached.hits",
+		Dims: Dims{
+			{ID: "get_hits", Name: "get", Algo: module.Incremental},
+			{ID: "delete_hits", Name: "delete", Algo: module.Incremental},
+			{ID: "incr_hits", Name: "incr", Algo: module.Incremental},
+			{ID: "decr_hits", Name: "decr", Algo: module.Incremental},
+			{ID: "cas_hits", Name: "cas", Algo: module.Incremental},
+			{ID: "touch_hits", Name: "touch", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "misses",
+		Title: "Misses",
+		Units: "requests/s",
+		Fam:   "hits",
+		Ctx:   "memcached.misses",
+		Dims: Dims{
+			{ID: "get_misses", Name: "get", Algo: module.Incremental},
+			{ID: "delete_misses", Name: "delete", Algo: module.Incremental},
+			{ID: "incr_misses", Name: "incr", Algo: module.Incremental},
+			{ID: "decr_misses", Name: "decr", Algo: module.Incremental},
+			{ID: "cas_misses", Name: "cas", Algo: module.Incremental},
+			{ID: "touch_misses", Name: "touch", Algo: module.Incremental},
+		},
+	},
+}
+
+var slabChartsTmpl = Charts{
+	{
+		ID:    "slab_%s_chunks",
+		Title: "Slab Chunks",
+		Units: "chunks",
+		Fam:   "slab %s",
+		Ctx:   "memcached.slab_chunks",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "slab_%s_used_chunks", Name: "used"},
+			{ID: "slab_%s_free_chunks", Name: "free"},
+		},
+	},
+	{
+		ID:    "slab_%s_memory",
+		Title: "Slab Requested Memory",
+		Units: "bytes",
+		Fam:   "slab %s",
+		Ctx:   "memcached.slab_memory",
+		Dims: Dims{
+			{ID: "slab_%s_mem_requested", Name: "requested"},
+		},
+	},
+	{
+		ID:    "slab_%s_hits",
+		Title: "Slab Hits",
+		Units: "requests/s",
+		Fam:   "slab %s",
+		Ctx:   "memcached.slab_hits",
+		Dims: Dims{
+			{ID: "slab_%s_get_hits", Name: "get", Algo: module.Incremental},
+			{ID: "slab_%s_cmd_set", Name: "set", Algo: module.Incremental},
+			{ID: "slab_%s_delete_hits", Name: "delete", Algo: module.Incremental},
+			{ID: "slab_%s_incr_hits", Name: "incr", Algo: module.Incremental},
+			{ID: "slab_%s_decr_hits", Name: "decr", Algo: module.Incremental},
+			{ID: "slab_%s_cas_hits", Name: "cas", Algo: module.Incremental},
+			{ID: "slab_%s_touch_hits", Name: "touch", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "slab_%s_items",
+		Title: "Slab Items",
+		Units: "items",
+		Fam:   "slab %s",
+		Ctx:   "memcached.slab_items",
+		Dims: Dims{
+			{ID: "slab_%s_items_number", Name: "items"},
+		},
+	},
+	{
+		ID:    "slab_%s_evictions",
+		Title: "Slab Evicted And Reclaimed Items",
+		Units: "items/s",
+		Fam:   "slab %s",
+		Ctx:   "memcached.slab_evictions",
+		Dims: Dims{
+			{ID: "slab_%s_items_evicted", Name: "evicted", Algo: module.Incremental},
+			{ID: "slab_%s_items_reclaimed", Name: "reclaimed", Algo: module.Incremental},
+			{ID: "slab_%s_items_outofmemory", Name: "outofmemory", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "slab_%s_items_age",
+		Title: "Slab Oldest Item Age",
+		Units: "seconds",
+		Fam:   "slab %s",
+		Ctx:   "memcached.slab_items_age",
+		Dims: Dims{
+			{ID: "slab_%s_items_age", Name: "age"},
+		},
+	},
+}
+
+func (m *Memcached) addSlabCharts(id string) {
+	charts := slabChartsTmpl.Copy()
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Fam = fmt.Sprintf(chart.Fam, id)
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+	if err := m.Charts().Add(*charts...); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *Memcached) removeSlabCharts(id string) {
+	for _, chart := range slabChartsTmpl {
+		if chart := m.Charts().Get(fmt.Sprintf(chart.ID, id)); chart != nil {
+			chart.MarkRemove()
+			chart.MarkNotCreated()
+		}
+	}
+}
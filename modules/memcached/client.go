@@ -0,0 +1,103 @@
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+type clientConfig struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func newClient(config clientConfig) *client {
+	return &client{
+		network: config.network,
+		address: config.address,
+		timeout: config.timeout,
+	}
+}
+
+type client struct {
+	network string
+	address string
+	timeout time.Duration
+	conn    net.Conn
+}
+
+func (c *client) connect() (err error) {
+	c.conn, err = net.DialTimeout(c.network, c.address, c.timeout)
+	return err
+}
+
+func (c *client) disconnect() error {
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *client) send(command string) error {
+	err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write([]byte(command + "\r\n"))
+	return err
+}
+
+func (c *client) read() ([]string, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	return read(c.conn)
+}
+
+// fetch sends the command and returns the response lines without the trailing 'END' line.
+func (c *client) fetch(command string) ([]string, error) {
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	defer func() { _ = c.disconnect() }()
+
+	if err := c.send(command); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+const limitReadLines = 4000
+
+var errNoEndLine = errors.New("unexpected end of response (no 'END' line)")
+
+// https://github.com/memcached/memcached/blob/master/doc/protocol.txt
+func read(reader io.Reader) ([]string, error) {
+	var lines []string
+	s := bufio.NewScanner(reader)
+
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "END":
+			return lines, nil
+		case line == "ERROR",
+			strings.HasPrefix(line, "CLIENT_ERROR"),
+			strings.HasPrefix(line, "SERVER_ERROR"):
+			return nil, fmt.Errorf("server returned an error: '%s'", line)
+		}
+
+		lines = append(lines, line)
+		if len(lines) >= limitReadLines {
+			return nil, fmt.Errorf("read line limit exceeded (%d)", limitReadLines)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errNoEndLine
+}
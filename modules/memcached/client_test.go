@@ -0,0 +1,166 @@
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testServerAddress = "127.0.0.1:38002"
+)
+
+func Test_clientFetch(t *testing.T) {
+	tests := map[string]struct {
+		network string
+		address string
+	}{
+		"tcp":  {network: "tcp", address: testServerAddress},
+		"unix": {network: "unix", address: filepath.Join(t.TempDir(), "memcached.sock")},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := &tcpServer{network: test.network, addr: test.address, responses: testResponses()}
+			require.NoError(t, srv.Listen())
+			go func() { _ = srv.Serve() }()
+			defer srv.Close()
+
+			c := newClient(clientConfig{
+				network: test.network,
+				address: test.address,
+				timeout: time.Second,
+			})
+
+			rows, err := c.fetch("stats")
+			assert.NoError(t, err)
+			assert.Len(t, rows, 43)
+
+			rows, err = c.fetch("stats slabs")
+			assert.NoError(t, err)
+			assert.Len(t, rows, 34)
+		})
+	}
+}
+
+func Test_clientFetchErrorResponse(t *testing.T) {
+	srv := &tcpServer{network: "tcp", addr: testServerAddress, responses: testResponses()}
+	require.NoError(t, srv.Listen())
+	go func() { _ = srv.Serve() }()
+	defer srv.Close()
+
+	c := newClient(clientConfig{
+		network: "tcp",
+		address: testServerAddress,
+		timeout: time.Second,
+	})
+
+	rows, err := c.fetch("whatever")
+	assert.Error(t, err)
+	assert.Len(t, rows, 0)
+}
+
+func Test_clientFetchReadLineLimitExceeded(t *testing.T) {
+	responses := map[string]string{
+		"stats": strings.Repeat("STAT pid 1\r\n", limitReadLines+1) + "END\r\n",
+	}
+	srv := &tcpServer{network: "tcp", addr: testServerAddress, responses: responses}
+	require.NoError(t, srv.Listen())
+	go func() { _ = srv.Serve() }()
+	defer srv.Close()
+
+	c := newClient(clientConfig{
+		network: "tcp",
+		address: testServerAddress,
+		timeout: time.Second,
+	})
+
+	rows, err := c.fetch("stats")
+	assert.Error(t, err)
+	assert.Len(t, rows, 0)
+}
+
+func Test_clientFetchNoEndLine(t *testing.T) {
+	responses := map[string]string{
+		"stats": "STAT pid 1\r\n",
+	}
+	srv := &tcpServer{network: "tcp", addr: testServerAddress, responses: responses}
+	require.NoError(t, srv.Listen())
+	go func() { _ = srv.Serve() }()
+	defer srv.Close()
+
+	c := newClient(clientConfig{
+		network: "tcp",
+		address: testServerAddress,
+		timeout: time.Second,
+	})
+
+	rows, err := c.fetch("stats")
+	assert.Error(t, err)
+	assert.Len(t, rows, 0)
+}
+
+func testResponses() map[string]string {
+	return map[string]string{
+		"stats":       string(testStatsData),
+		"stats slabs": string(testStatsSlabsData),
+		"stats items": string(testStatsItemsData),
+	}
+}
+
+type tcpServer struct {
+	network   string
+	addr      string
+	server    net.Listener
+	responses map[string]string
+}
+
+func (t *tcpServer) Listen() (err error) {
+	t.server, err = net.Listen(t.network, t.addr)
+	return err
+}
+
+func (t *tcpServer) Serve() error {
+	return t.handleConnections()
+}
+
+func (t *tcpServer) Close() (err error) {
+	return t.server.Close()
+}
+
+func (t *tcpServer) handleConnections() (err error) {
+	for {
+		conn, err := t.server.Accept()
+		if err != nil || conn == nil {
+			return errors.New("could not accept connection")
+		}
+		go t.handleConnection(conn)
+	}
+}
+
+func (t *tcpServer) handleConnection(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Second * 2))
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	req, err := rw.ReadString('\n')
+	if err != nil {
+		_, _ = rw.WriteString("SERVER_ERROR failed to read input\r\n")
+		_ = rw.Flush()
+		return
+	}
+
+	resp, ok := t.responses[strings.TrimSpace(req)]
+	if !ok {
+		resp = "ERROR\r\n"
+	}
+	_, _ = rw.WriteString(resp)
+	_ = rw.Flush()
+}
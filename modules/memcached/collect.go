@@ -0,0 +1,119 @@
+package memcached
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (m *Memcached) collect() (map[string]int64, error) {
+	mx := make(map[string]int64)
+
+	if err := m.collectStats(mx); err != nil {
+		return nil, err
+	}
+	if err := m.collectSlabs(mx); err != nil {
+		m.Warning(err)
+	}
+	return mx, nil
+}
+
+func (m *Memcached) collectStats(mx map[string]int64) error {
+	const command = "stats"
+	stats, err := m.fetchStats(command)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range stats {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		mx[key] = v
+	}
+
+	if _, ok := mx["curr_connections"]; !ok {
+		return fmt.Errorf("'%s' command: failed to parse response", command)
+	}
+	if v, ok := mx["limit_maxbytes"]; ok {
+		mx["avail"] = v - mx["bytes"]
+	}
+	return nil
+}
+
+func (m *Memcached) collectSlabs(mx map[string]int64) error {
+	slabs, err := m.fetchStats("stats slabs")
+	if err != nil {
+		return err
+	}
+	// 'stats items' adds per slab class item counters (evictions, age, etc.) to the 'stats slabs' metrics.
+	items, err := m.fetchStats("stats items")
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for key, value := range slabs {
+		// STAT <slabclass>:<stat> <value>
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 || !isSlabID(parts[0]) {
+			continue
+		}
+		id, metric := parts[0], parts[1]
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		seen[id] = true
+		mx["slab_"+id+"_"+metric] = v
+	}
+	for key, value := range items {
+		// STAT items:<slabclass>:<stat> <value>
+		parts := strings.SplitN(key, ":", 3)
+		if len(parts) != 3 || parts[0] != "items" || !seen[parts[1]] {
+			continue
+		}
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		mx["slab_"+parts[1]+"_items_"+parts[2]] = v
+	}
+
+	for id := range seen {
+		if !m.collectedSlabs[id] {
+			m.collectedSlabs[id] = true
+			m.addSlabCharts(id)
+		}
+	}
+	for id := range m.collectedSlabs {
+		if !seen[id] {
+			delete(m.collectedSlabs, id)
+			m.removeSlabCharts(id)
+		}
+	}
+	return nil
+}
+
+func (m *Memcached) fetchStats(command string) (map[string]string, error) {
+	lines, err := m.fetch(command)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' command: %v", command, err)
+	}
+
+	stats := make(map[string]string)
+	for _, line := range lines {
+		parts := strings.Fields(line)
+		if len(parts) != 3 || parts[0] != "STAT" {
+			continue
+		}
+		stats[parts[1]] = parts[2]
+	}
+	return stats, nil
+}
+
+func isSlabID(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
@@ -0,0 +1,109 @@
+package memcached
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	creator := module.Creator{
+		Create: func() module.Module { return New() },
+	}
+
+	module.Register("memcached", creator)
+}
+
+// Config is the Memcached module configuration.
+type Config struct {
+	Address string       `yaml:"address"`
+	Timeout web.Duration `yaml:"timeout"`
+}
+
+// New creates Memcached with default values.
+func New() *Memcached {
+	config := Config{
+		Address: "127.0.0.1:11211",
+		Timeout: web.Duration{Duration: time.Second},
+	}
+	return &Memcached{
+		Config:         config,
+		charts:         charts.Copy(),
+		collectedSlabs: make(map[string]bool),
+	}
+}
+
+type memcachedFetcher interface {
+	fetch(command string) ([]string, error)
+}
+
+// Memcached Memcached module.
+type Memcached struct {
+	module.Base
+	memcachedFetcher
+	Config `yaml:",inline"`
+
+	charts         *Charts
+	collectedSlabs map[string]bool
+}
+
+// Cleanup makes cleanup.
+func (Memcached) Cleanup() {}
+
+func (m *Memcached) createMemcachedFetcher() error {
+	if m.Address == "" {
+		return errors.New("'address' not set")
+	}
+
+	network, address := "tcp", m.Address
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	} else if strings.HasPrefix(address, "unix://") {
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	}
+
+	conf := clientConfig{
+		network: network,
+		address: address,
+		timeout: m.Timeout.Duration,
+	}
+	m.memcachedFetcher = newClient(conf)
+	return nil
+}
+
+// Init makes initialization.
+func (m *Memcached) Init() bool {
+	err := m.createMemcachedFetcher()
+	if err != nil {
+		m.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// Check makes check.
+func (m *Memcached) Check() bool {
+	return len(m.Collect()) > 0
+}
+
+// Charts returns Charts.
+func (m *Memcached) Charts() *Charts {
+	return m.charts
+}
+
+// Collect collects metrics.
+func (m *Memcached) Collect() map[string]int64 {
+	mx, err := m.collect()
+	if err != nil {
+		m.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
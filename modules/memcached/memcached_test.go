@@ -0,0 +1,311 @@
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testStatsData, _      = ioutil.ReadFile("testdata/stats.txt")
+	testStatsSlabsData, _ = ioutil.ReadFile("testdata/stats_slabs.txt")
+	testStatsItemsData, _ = ioutil.ReadFile("testdata/stats_items.txt")
+)
+
+func Test_testDataLoad(t *testing.T) {
+	assert.NotNil(t, testStatsData)
+	assert.NotNil(t, testStatsSlabsData)
+	assert.NotNil(t, testStatsItemsData)
+}
+
+func TestNew(t *testing.T) {
+	job := New()
+
+	assert.IsType(t, (*Memcached)(nil), job)
+}
+
+func TestMemcached_Init(t *testing.T) {
+	tests := map[string]struct {
+		address     string
+		wantNetwork string
+		wantAddress string
+		wantFail    bool
+	}{
+		"tcp":             {address: "127.0.0.1:11211", wantNetwork: "tcp", wantAddress: "127.0.0.1:11211"},
+		"unix path":       {address: "/var/run/memcached.sock", wantNetwork: "unix", wantAddress: "/var/run/memcached.sock"},
+		"unix scheme":     {address: "unix:///var/run/memcached.sock", wantNetwork: "unix", wantAddress: "/var/run/memcached.sock"},
+		"address not set": {address: "", wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Address = test.address
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+				return
+			}
+
+			require.True(t, job.Init())
+			c, ok := job.memcachedFetcher.(*client)
+			require.True(t, ok)
+			assert.Equal(t, test.wantNetwork, c.network)
+			assert.Equal(t, test.wantAddress, c.address)
+		})
+	}
+}
+
+func TestMemcached_Check(t *testing.T) {
+	tests := map[string]struct {
+		fetcher  memcachedFetcher
+		wantFail bool
+	}{
+		"success":             {fetcher: newMockMemcachedFetcher()},
+		"error on fetch":      {fetcher: &mockMemcachedFetcher{err: true}, wantFail: true},
+		"empty response":      {fetcher: &mockMemcachedFetcher{}, wantFail: true},
+		"slabs not available": {fetcher: &mockMemcachedFetcher{data: map[string][]byte{"stats": testStatsData}}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			require.True(t, job.Init())
+			job.memcachedFetcher = test.fetcher
+
+			if test.wantFail {
+				assert.False(t, job.Check())
+			} else {
+				assert.True(t, job.Check())
+			}
+		})
+	}
+}
+
+func TestMemcached_Charts(t *testing.T) {
+	assert.NotNil(t, New().Charts())
+}
+
+func TestMemcached_Cleanup(t *testing.T) {
+	New().Cleanup()
+}
+
+func TestMemcached_Collect(t *testing.T) {
+	job := New()
+	require.True(t, job.Init())
+	job.memcachedFetcher = newMockMemcachedFetcher()
+
+	expected := map[string]int64{
+		"avail":                          65011712,
+		"bytes":                          2097152,
+		"bytes_read":                     102400,
+		"bytes_written":                  204800,
+		"cas_badval":                     1,
+		"cas_hits":                       7,
+		"cas_misses":                     3,
+		"cmd_flush":                      2,
+		"cmd_get":                        140,
+		"cmd_set":                        60,
+		"cmd_touch":                      5,
+		"connection_structures":          4,
+		"curr_connections":               3,
+		"curr_items":                     50,
+		"decr_hits":                      8,
+		"decr_misses":                    2,
+		"delete_hits":                    6,
+		"delete_misses":                  4,
+		"evicted_unfetched":              1,
+		"evictions":                      5,
+		"expired_unfetched":              2,
+		"get_expired":                    3,
+		"get_flushed":                    0,
+		"get_hits":                       100,
+		"get_misses":                     40,
+		"incr_hits":                      9,
+		"incr_misses":                    1,
+		"limit_maxbytes":                 67108864,
+		"max_connections":                1024,
+		"pid":                            1,
+		"pointer_size":                   64,
+		"reclaimed":                      3,
+		"rejected_connections":           1,
+		"threads":                        4,
+		"time":                           1650000000,
+		"total_connections":              25,
+		"total_items":                    60,
+		"touch_hits":                     4,
+		"touch_misses":                   1,
+		"uptime":                         3600,
+		"slab_1_cas_badval":              1,
+		"slab_1_cas_hits":                7,
+		"slab_1_chunk_size":              96,
+		"slab_1_chunks_per_page":         10922,
+		"slab_1_cmd_set":                 40,
+		"slab_1_decr_hits":               8,
+		"slab_1_delete_hits":             4,
+		"slab_1_free_chunks":             10892,
+		"slab_1_free_chunks_end":         0,
+		"slab_1_get_hits":                70,
+		"slab_1_incr_hits":               9,
+		"slab_1_mem_requested":           2400,
+		"slab_1_total_chunks":            10922,
+		"slab_1_total_pages":             1,
+		"slab_1_touch_hits":              3,
+		"slab_1_used_chunks":             30,
+		"slab_1_items_age":               1200,
+		"slab_1_items_age_hot":           10,
+		"slab_1_items_age_warm":          0,
+		"slab_1_items_evicted":           3,
+		"slab_1_items_evicted_nonzero":   0,
+		"slab_1_items_evicted_time":      0,
+		"slab_1_items_evicted_unfetched": 1,
+		"slab_1_items_expired_unfetched": 1,
+		"slab_1_items_mem_requested":     2400,
+		"slab_1_items_number":            30,
+		"slab_1_items_number_cold":       25,
+		"slab_1_items_number_hot":        5,
+		"slab_1_items_number_warm":       0,
+		"slab_1_items_outofmemory":       1,
+		"slab_1_items_reclaimed":         2,
+		"slab_1_items_tailrepairs":       0,
+		"slab_5_cas_badval":              0,
+		"slab_5_cas_hits":                0,
+		"slab_5_chunk_size":              240,
+		"slab_5_chunks_per_page":         4369,
+		"slab_5_cmd_set":                 20,
+		"slab_5_decr_hits":               0,
+		"slab_5_delete_hits":             2,
+		"slab_5_free_chunks":             4349,
+		"slab_5_free_chunks_end":         0,
+		"slab_5_get_hits":                30,
+		"slab_5_incr_hits":               0,
+		"slab_5_mem_requested":           4000,
+		"slab_5_total_chunks":            4369,
+		"slab_5_total_pages":             1,
+		"slab_5_touch_hits":              1,
+		"slab_5_used_chunks":             20,
+		"slab_5_items_age":               600,
+		"slab_5_items_age_hot":           5,
+		"slab_5_items_age_warm":          0,
+		"slab_5_items_evicted":           2,
+		"slab_5_items_evicted_nonzero":   0,
+		"slab_5_items_evicted_time":      0,
+		"slab_5_items_evicted_unfetched": 0,
+		"slab_5_items_expired_unfetched": 1,
+		"slab_5_items_mem_requested":     4000,
+		"slab_5_items_number":            20,
+		"slab_5_items_number_cold":       18,
+		"slab_5_items_number_hot":        2,
+		"slab_5_items_number_warm":       0,
+		"slab_5_items_outofmemory":       0,
+		"slab_5_items_reclaimed":         1,
+		"slab_5_items_tailrepairs":       0,
+	}
+
+	collected := job.Collect()
+
+	assert.Equal(t, expected, collected)
+	assert.Len(t, *job.Charts(), len(charts)+len(slabChartsTmpl)*2)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, job, collected)
+}
+
+func TestMemcached_CollectRemovesGoneSlabCharts(t *testing.T) {
+	job := New()
+	require.True(t, job.Init())
+	job.memcachedFetcher = newMockMemcachedFetcher()
+	require.NotNil(t, job.Collect())
+
+	fetcher := newMockMemcachedFetcher()
+	fetcher.data["stats slabs"] = removeLinesWithPrefix(testStatsSlabsData, "STAT 5:")
+	job.memcachedFetcher = fetcher
+	require.NotNil(t, job.Collect())
+
+	for _, chart := range *job.Charts() {
+		if strings.HasPrefix(chart.ID, "slab_5_") {
+			assert.Truef(t, chart.Obsolete, "chart '%s' is not removed", chart.ID)
+		} else {
+			assert.Falsef(t, chart.Obsolete, "chart '%s' is removed", chart.ID)
+		}
+	}
+}
+
+func TestMemcached_CollectErrorResponse(t *testing.T) {
+	tests := map[string]struct {
+		fetcher memcachedFetcher
+	}{
+		"error on fetch": {fetcher: &mockMemcachedFetcher{err: true}},
+		"empty response": {fetcher: &mockMemcachedFetcher{}},
+		"invalid data": {fetcher: &mockMemcachedFetcher{
+			data: map[string][]byte{"stats": []byte("hello \nand good buy\n")},
+		}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			require.True(t, job.Init())
+			job.memcachedFetcher = test.fetcher
+
+			assert.Nil(t, job.Collect())
+		})
+	}
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, m *Memcached, collected map[string]int64) {
+	for _, chart := range *m.Charts() {
+		if chart.Obsolete {
+			continue
+		}
+		for _, dim := range chart.Dims {
+			_, ok := collected[dim.ID]
+			assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+		}
+		for _, v := range chart.Vars {
+			_, ok := collected[v.ID]
+			assert.Truef(t, ok, "collected metrics has no data for var '%s' chart '%s'", v.ID, chart.ID)
+		}
+	}
+}
+
+func removeLinesWithPrefix(data []byte, prefix string) []byte {
+	var buf bytes.Buffer
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		if !strings.HasPrefix(s.Text(), prefix) {
+			buf.WriteString(s.Text() + "\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+func newMockMemcachedFetcher() *mockMemcachedFetcher {
+	return &mockMemcachedFetcher{
+		data: map[string][]byte{
+			"stats":       testStatsData,
+			"stats slabs": testStatsSlabsData,
+			"stats items": testStatsItemsData,
+		},
+	}
+}
+
+type mockMemcachedFetcher struct {
+	data map[string][]byte
+	err  bool
+}
+
+func (m mockMemcachedFetcher) fetch(command string) ([]string, error) {
+	if m.err {
+		return nil, errors.New("mock fetch error")
+	}
+	data, ok := m.data[command]
+	if !ok {
+		return nil, errors.New("mock fetch: unknown command")
+	}
+	return read(bytes.NewReader(data))
+}
@@ -0,0 +1,519 @@
+package mongodb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+const (
+	prioOperations = module.Priority + iota
+	prioDocumentOperations
+	prioOperationsRepl
+
+	prioConnections
+	prioConnectionsRate
+
+	prioNetworkIO
+	prioNetworkRequests
+
+	prioMemory
+	prioAsserts
+
+	prioGlobalLockQueue
+	prioGlobalLockActiveClients
+	prioLocksGlobal
+	prioLocksDatabase
+	prioLocksCollection
+
+	prioWiredTigerCacheUsage
+	prioWiredTigerCachePages
+	prioWiredTigerCacheEvictions
+	prioWiredTigerConcurrentTxnsRead
+	prioWiredTigerConcurrentTxnsWrite
+
+	prioReplSetMemberReplicationLag
+	prioReplSetMemberHealth
+	prioReplSetMemberState
+
+	prioDatabaseCollections
+	prioDatabaseObjects
+	prioDatabaseSize
+
+	prioCollectionDocuments
+	prioCollectionIndexes
+	prioCollectionSize
+)
+
+var charts = module.Charts{
+	chartOperations.Copy(),
+	chartDocumentOperations.Copy(),
+
+	chartConnections.Copy(),
+	chartConnectionsRate.Copy(),
+
+	chartNetworkIO.Copy(),
+	chartNetworkRequests.Copy(),
+
+	chartMemory.Copy(),
+	chartAsserts.Copy(),
+
+	chartGlobalLockQueue.Copy(),
+	chartGlobalLockActiveClients.Copy(),
+}
+
+var (
+	chartOperations = module.Chart{
+		ID:       "operations",
+		Title:    "Operations",
+		Units:    "operations/s",
+		Fam:      "operations",
+		Ctx:      "mongodb.operations",
+		Type:     module.Stacked,
+		Priority: prioOperations,
+		Dims: module.Dims{
+			{ID: "opcounters_insert", Name: "insert", Algo: module.Incremental},
+			{ID: "opcounters_query", Name: "query", Algo: module.Incremental},
+			{ID: "opcounters_update", Name: "update", Algo: module.Incremental},
+			{ID: "opcounters_delete", Name: "delete", Algo: module.Incremental},
+			{ID: "opcounters_getmore", Name: "getmore", Algo: module.Incremental},
+			{ID: "opcounters_command", Name: "command", Algo: module.Incremental},
+		},
+	}
+	chartDocumentOperations = module.Chart{
+		ID:       "document_operations",
+		Title:    "Document Operations",
+		Units:    "documents/s",
+		Fam:      "operations",
+		Ctx:      "mongodb.document_operations",
+		Type:     module.Stacked,
+		Priority: prioDocumentOperations,
+		Dims: module.Dims{
+			{ID: "metrics_document_inserted", Name: "inserted", Algo: module.Incremental},
+			{ID: "metrics_document_deleted", Name: "deleted", Algo: module.Incremental},
+			{ID: "metrics_document_returned", Name: "returned", Algo: module.Incremental},
+			{ID: "metrics_document_updated", Name: "updated", Algo: module.Incremental},
+		},
+	}
+	chartOperationsRepl = module.Chart{
+		ID:       "operations_repl",
+		Title:    "Replicated Operations",
+		Units:    "operations/s",
+		Fam:      "operations",
+		Ctx:      "mongodb.operations_repl",
+		Type:     module.Stacked,
+		Priority: prioOperationsRepl,
+		Dims: module.Dims{
+			{ID: "opcounters_repl_insert", Name: "insert", Algo: module.Incremental},
+			{ID: "opcounters_repl_query", Name: "query", Algo: module.Incremental},
+			{ID: "opcounters_repl_update", Name: "update", Algo: module.Incremental},
+			{ID: "opcounters_repl_delete", Name: "delete", Algo: module.Incremental},
+			{ID: "opcounters_repl_getmore", Name: "getmore", Algo: module.Incremental},
+			{ID: "opcounters_repl_command", Name: "command", Algo: module.Incremental},
+		},
+	}
+
+	chartConnections = module.Chart{
+		ID:       "connections",
+		Title:    "Connections",
+		Units:    "connections",
+		Fam:      "connections",
+		Ctx:      "mongodb.connections",
+		Priority: prioConnections,
+		Dims: module.Dims{
+			{ID: "connections_current", Name: "current"},
+			{ID: "connections_available", Name: "available"},
+			{ID: "connections_active", Name: "active"},
+		},
+	}
+	chartConnectionsRate = module.Chart{
+		ID:       "connections_rate",
+		Title:    "Created Connections",
+		Units:    "connections/s",
+		Fam:      "connections",
+		Ctx:      "mongodb.connections_rate",
+		Priority: prioConnectionsRate,
+		Dims: module.Dims{
+			{ID: "connections_total_created", Name: "created", Algo: module.Incremental},
+		},
+	}
+
+	chartNetworkIO = module.Chart{
+		ID:       "network_io",
+		Title:    "Network IO",
+		Units:    "B/s",
+		Fam:      "network",
+		Ctx:      "mongodb.network_io",
+		Type:     module.Area,
+		Priority: prioNetworkIO,
+		Dims: module.Dims{
+			{ID: "network_bytes_in", Name: "in", Algo: module.Incremental},
+			{ID: "network_bytes_out", Name: "out", Algo: module.Incremental, Mul: -1},
+		},
+	}
+	chartNetworkRequests = module.Chart{
+		ID:       "network_requests",
+		Title:    "Network Requests",
+		Units:    "requests/s",
+		Fam:      "network",
+		Ctx:      "mongodb.network_requests",
+		Priority: prioNetworkRequests,
+		Dims: module.Dims{
+			{ID: "network_requests", Name: "requests", Algo: module.Incremental},
+		},
+	}
+
+	chartMemory = module.Chart{
+		ID:       "memory",
+		Title:    "Memory",
+		Units:    "MiB",
+		Fam:      "memory",
+		Ctx:      "mongodb.memory",
+		Priority: prioMemory,
+		Dims: module.Dims{
+			{ID: "memory_resident", Name: "resident"},
+			{ID: "memory_virtual", Name: "virtual"},
+		},
+	}
+	chartAsserts = module.Chart{
+		ID:       "asserts",
+		Title:    "Raised Assertions",
+		Units:    "asserts/s",
+		Fam:      "asserts",
+		Ctx:      "mongodb.asserts",
+		Type:     module.Stacked,
+		Priority: prioAsserts,
+		Dims: module.Dims{
+			{ID: "asserts_regular", Name: "regular", Algo: module.Incremental},
+			{ID: "asserts_warning", Name: "warning", Algo: module.Incremental},
+			{ID: "asserts_msg", Name: "msg", Algo: module.Incremental},
+			{ID: "asserts_user", Name: "user", Algo: module.Incremental},
+			{ID: "asserts_rollovers", Name: "rollovers", Algo: module.Incremental},
+		},
+	}
+
+	chartGlobalLockQueue = module.Chart{
+		ID:       "global_lock_current_queue",
+		Title:    "Operations Queued Waiting For The Lock",
+		Units:    "operations",
+		Fam:      "locks",
+		Ctx:      "mongodb.global_lock_current_queue",
+		Type:     module.Stacked,
+		Priority: prioGlobalLockQueue,
+		Dims: module.Dims{
+			{ID: "global_lock_current_queue_readers", Name: "readers"},
+			{ID: "global_lock_current_queue_writers", Name: "writers"},
+		},
+	}
+	chartGlobalLockActiveClients = module.Chart{
+		ID:       "global_lock_active_clients",
+		Title:    "Active Clients",
+		Units:    "clients",
+		Fam:      "locks",
+		Ctx:      "mongodb.global_lock_active_clients",
+		Type:     module.Stacked,
+		Priority: prioGlobalLockActiveClients,
+		Dims: module.Dims{
+			{ID: "global_lock_active_clients_readers", Name: "readers"},
+			{ID: "global_lock_active_clients_writers", Name: "writers"},
+		},
+	}
+	chartLocksAcquiredTmpl = module.Chart{
+		ID:    "locks_%s_acquired",
+		Title: "%s Lock Acquisitions",
+		Units: "acquisitions/s",
+		Fam:   "locks",
+		Ctx:   "mongodb.locks_%s_acquired",
+		Type:  module.Stacked,
+		Dims: module.Dims{
+			{ID: "locks_%s_acquire_shared", Name: "shared", Algo: module.Incremental},
+			{ID: "locks_%s_acquire_exclusive", Name: "exclusive", Algo: module.Incremental},
+			{ID: "locks_%s_acquire_intent_shared", Name: "intent_shared", Algo: module.Incremental},
+			{ID: "locks_%s_acquire_intent_exclusive", Name: "intent_exclusive", Algo: module.Incremental},
+		},
+	}
+)
+
+var wiredTigerCharts = module.Charts{
+	{
+		ID:       "wiredtiger_cache_usage",
+		Title:    "WiredTiger Cache Usage",
+		Units:    "B",
+		Fam:      "wiredtiger",
+		Ctx:      "mongodb.wiredtiger_cache_usage",
+		Priority: prioWiredTigerCacheUsage,
+		Dims: module.Dims{
+			{ID: "wiredtiger_cache_bytes", Name: "used"},
+			{ID: "wiredtiger_cache_dirty_bytes", Name: "dirty"},
+			{ID: "wiredtiger_cache_max_bytes", Name: "max"},
+		},
+	},
+	{
+		ID:       "wiredtiger_cache_pages",
+		Title:    "WiredTiger Cache Pages IO",
+		Units:    "pages/s",
+		Fam:      "wiredtiger",
+		Ctx:      "mongodb.wiredtiger_cache_pages",
+		Priority: prioWiredTigerCachePages,
+		Dims: module.Dims{
+			{ID: "wiredtiger_cache_pages_read", Name: "read", Algo: module.Incremental},
+			{ID: "wiredtiger_cache_pages_written", Name: "written", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:       "wiredtiger_cache_evictions",
+		Title:    "WiredTiger Cache Evictions",
+		Units:    "pages/s",
+		Fam:      "wiredtiger",
+		Ctx:      "mongodb.wiredtiger_cache_evictions",
+		Type:     module.Stacked,
+		Priority: prioWiredTigerCacheEvictions,
+		Dims: module.Dims{
+			{ID: "wiredtiger_cache_modified_evicted", Name: "modified", Algo: module.Incremental},
+			{ID: "wiredtiger_cache_unmodified_evicted", Name: "unmodified", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:       "wiredtiger_concurrent_read_txns",
+		Title:    "WiredTiger Concurrent Read Transactions",
+		Units:    "tickets",
+		Fam:      "wiredtiger",
+		Ctx:      "mongodb.wiredtiger_concurrent_read_txns",
+		Type:     module.Stacked,
+		Priority: prioWiredTigerConcurrentTxnsRead,
+		Dims: module.Dims{
+			{ID: "wiredtiger_concurrent_txns_read_out", Name: "used"},
+			{ID: "wiredtiger_concurrent_txns_read_available", Name: "available"},
+		},
+	},
+	{
+		ID:       "wiredtiger_concurrent_write_txns",
+		Title:    "WiredTiger Concurrent Write Transactions",
+		Units:    "tickets",
+		Fam:      "wiredtiger",
+		Ctx:      "mongodb.wiredtiger_concurrent_write_txns",
+		Type:     module.Stacked,
+		Priority: prioWiredTigerConcurrentTxnsWrite,
+		Dims: module.Dims{
+			{ID: "wiredtiger_concurrent_txns_write_out", Name: "used"},
+			{ID: "wiredtiger_concurrent_txns_write_available", Name: "available"},
+		},
+	},
+}
+
+var replSetMemberChartsTmpl = module.Charts{
+	{
+		ID:       "repl_set_member_%s_replication_lag",
+		Title:    "Replica Set Member Replication Lag",
+		Units:    "milliseconds",
+		Fam:      "replica set %s",
+		Ctx:      "mongodb.repl_set_member_replication_lag",
+		Priority: prioReplSetMemberReplicationLag,
+		Dims: module.Dims{
+			{ID: "repl_set_member_%s_replication_lag", Name: "lag"},
+		},
+	},
+	{
+		ID:       "repl_set_member_%s_health",
+		Title:    "Replica Set Member Health",
+		Units:    "status",
+		Fam:      "replica set %s",
+		Ctx:      "mongodb.repl_set_member_health",
+		Priority: prioReplSetMemberHealth,
+		Dims: module.Dims{
+			{ID: "repl_set_member_%s_health", Name: "up"},
+		},
+	},
+	{
+		ID:       "repl_set_member_%s_state",
+		Title:    "Replica Set Member State",
+		Units:    "state",
+		Fam:      "replica set %s",
+		Ctx:      "mongodb.repl_set_member_state",
+		Priority: prioReplSetMemberState,
+		Dims:     newReplSetMemberStateDims(),
+	},
+}
+
+var databaseChartsTmpl = module.Charts{
+	{
+		ID:       "database_%s_collections",
+		Title:    "Database Collections, Views And Indexes",
+		Units:    "number",
+		Fam:      "database %s",
+		Ctx:      "mongodb.database_collections",
+		Priority: prioDatabaseCollections,
+		Dims: module.Dims{
+			{ID: "database_%s_collections", Name: "collections"},
+			{ID: "database_%s_views", Name: "views"},
+			{ID: "database_%s_indexes", Name: "indexes"},
+		},
+	},
+	{
+		ID:       "database_%s_objects",
+		Title:    "Database Documents",
+		Units:    "documents",
+		Fam:      "database %s",
+		Ctx:      "mongodb.database_objects",
+		Priority: prioDatabaseObjects,
+		Dims: module.Dims{
+			{ID: "database_%s_objects", Name: "documents"},
+		},
+	},
+	{
+		ID:       "database_%s_size",
+		Title:    "Database Size",
+		Units:    "B",
+		Fam:      "database %s",
+		Ctx:      "mongodb.database_size",
+		Priority: prioDatabaseSize,
+		Dims: module.Dims{
+			{ID: "database_%s_data_size", Name: "data"},
+			{ID: "database_%s_storage_size", Name: "storage"},
+			{ID: "database_%s_index_size", Name: "index"},
+		},
+	},
+}
+
+var collectionChartsTmpl = module.Charts{
+	{
+		ID:       "collection_%s_documents",
+		Title:    "Collection Documents",
+		Units:    "documents",
+		Fam:      "collection %s",
+		Ctx:      "mongodb.collection_documents",
+		Priority: prioCollectionDocuments,
+		Dims: module.Dims{
+			{ID: "collection_%s_documents", Name: "documents"},
+		},
+	},
+	{
+		ID:       "collection_%s_indexes",
+		Title:    "Collection Indexes",
+		Units:    "indexes",
+		Fam:      "collection %s",
+		Ctx:      "mongodb.collection_indexes",
+		Priority: prioCollectionIndexes,
+		Dims: module.Dims{
+			{ID: "collection_%s_indexes", Name: "indexes"},
+		},
+	},
+	{
+		ID:       "collection_%s_size",
+		Title:    "Collection Size",
+		Units:    "B",
+		Fam:      "collection %s",
+		Ctx:      "mongodb.collection_size",
+		Priority: prioCollectionSize,
+		Dims: module.Dims{
+			{ID: "collection_%s_size", Name: "data"},
+			{ID: "collection_%s_storage_size", Name: "storage"},
+			{ID: "collection_%s_index_size", Name: "index"},
+		},
+	},
+}
+
+func newReplSetMemberStateDims() module.Dims {
+	var dims module.Dims
+	for _, state := range replSetMemberStates {
+		dims = append(dims, &module.Dim{
+			ID:   "repl_set_member_%s_state_" + state.name,
+			Name: state.name,
+		})
+	}
+	return dims
+}
+
+func (m *MongoDB) addWiredTigerCharts() {
+	if err := m.Charts().Add(*wiredTigerCharts.Copy()...); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *MongoDB) addLocksCharts(locks *documentLocks) {
+	for _, v := range []struct {
+		name     string
+		title    string
+		priority int
+		lock     *documentLockType
+	}{
+		{"global", "Global", prioLocksGlobal, locks.Global},
+		{"database", "Database", prioLocksDatabase, locks.Database},
+		{"collection", "Collection", prioLocksCollection, locks.Collection},
+	} {
+		if v.lock == nil {
+			continue
+		}
+		chart := chartLocksAcquiredTmpl.Copy()
+		chart.ID = fmt.Sprintf(chart.ID, v.name)
+		chart.Title = fmt.Sprintf(chart.Title, v.title)
+		chart.Ctx = fmt.Sprintf(chart.Ctx, v.name)
+		chart.Priority = v.priority
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, v.name)
+		}
+		if err := m.Charts().Add(chart); err != nil {
+			m.Warning(err)
+		}
+	}
+}
+
+func (m *MongoDB) addReplSetCharts() {
+	if err := m.Charts().Add(chartOperationsRepl.Copy()); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *MongoDB) addReplSetMemberCharts(name string) {
+	m.addChartsFromTemplate(replSetMemberChartsTmpl, name)
+}
+
+func (m *MongoDB) removeReplSetMemberCharts(name string) {
+	m.removeChartsFromTemplate(replSetMemberChartsTmpl, name)
+}
+
+func (m *MongoDB) addDatabaseCharts(name string) {
+	m.addChartsFromTemplate(databaseChartsTmpl, name)
+}
+
+func (m *MongoDB) removeDatabaseCharts(name string) {
+	m.removeChartsFromTemplate(databaseChartsTmpl, name)
+}
+
+func (m *MongoDB) addCollectionCharts(name string) {
+	m.addChartsFromTemplate(collectionChartsTmpl, name)
+}
+
+func (m *MongoDB) removeCollectionCharts(name string) {
+	m.removeChartsFromTemplate(collectionChartsTmpl, name)
+}
+
+func (m *MongoDB) addChartsFromTemplate(tmpl module.Charts, name string) {
+	charts := tmpl.Copy()
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, cleanChartID(name))
+		chart.Fam = fmt.Sprintf(chart.Fam, name)
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, name)
+		}
+	}
+	if err := m.Charts().Add(*charts...); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *MongoDB) removeChartsFromTemplate(tmpl module.Charts, name string) {
+	for _, chart := range tmpl {
+		id := fmt.Sprintf(chart.ID, cleanChartID(name))
+		if chart := m.Charts().Get(id); chart != nil {
+			chart.MarkRemove()
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func cleanChartID(name string) string {
+	r := strings.NewReplacer(" ", "_", ".", "_", ":", "_", ",", "_")
+	return strings.ToLower(r.Replace(name))
+}
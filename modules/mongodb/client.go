@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoClient struct {
+	client  *mongo.Client
+	timeout time.Duration
+}
+
+func newMongoClient(uri string, timeout time.Duration) (*mongoClient, error) {
+	opts := options.Client().
+		ApplyURI(uri).
+		SetConnectTimeout(timeout).
+		SetServerSelectionTimeout(timeout).
+		SetMaxPoolSize(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &mongoClient{client: client, timeout: timeout}, nil
+}
+
+func (c *mongoClient) serverStatus() (*documentServerStatus, error) {
+	cmd := bson.D{
+		{Key: "serverStatus", Value: 1},
+		{Key: "repl", Value: 1},
+		{Key: "metrics", Value: 1},
+	}
+	var doc documentServerStatus
+	if err := c.runCommand("admin", cmd, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (c *mongoClient) replSetGetStatus() (*documentReplSetStatus, error) {
+	cmd := bson.D{{Key: "replSetGetStatus", Value: 1}}
+	var doc documentReplSetStatus
+	if err := c.runCommand("admin", cmd, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (c *mongoClient) listDatabaseNames() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	return c.client.ListDatabaseNames(ctx, bson.D{})
+}
+
+func (c *mongoClient) dbStats(database string) (*documentDBStats, error) {
+	cmd := bson.D{{Key: "dbStats", Value: 1}}
+	var doc documentDBStats
+	if err := c.runCommand(database, cmd, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (c *mongoClient) listCollectionNames(database string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "type", Value: "collection"}}
+	return c.client.Database(database).ListCollectionNames(ctx, filter)
+}
+
+func (c *mongoClient) collStats(database, collection string) (*documentCollStats, error) {
+	cmd := bson.D{{Key: "collStats", Value: collection}}
+	var doc documentCollStats
+	if err := c.runCommand(database, cmd, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (c *mongoClient) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	return c.client.Disconnect(ctx)
+}
+
+func (c *mongoClient) runCommand(database string, cmd bson.D, doc interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	return c.client.Database(database).RunCommand(ctx, cmd).Decode(doc)
+}
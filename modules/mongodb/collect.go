@@ -0,0 +1,34 @@
+package mongodb
+
+import (
+	"fmt"
+)
+
+func (m *MongoDB) collect() (map[string]int64, error) {
+	if m.conn == nil {
+		conn, err := m.initMongoConn()
+		if err != nil {
+			return nil, fmt.Errorf("init mongodb client: %v", err)
+		}
+		m.conn = conn
+	}
+
+	mx := make(map[string]int64)
+
+	status, err := m.collectServerStatus(mx)
+	if err != nil {
+		return nil, fmt.Errorf("error on collecting server status: %v", err)
+	}
+
+	if status.Repl != nil && status.Repl.SetName != "" {
+		if err := m.collectReplSetStatus(mx); err != nil {
+			m.Errorf("error on collecting replica set status: %v", err)
+		}
+	}
+
+	if err := m.collectDatabases(mx); err != nil {
+		m.Errorf("error on collecting databases statistics: %v", err)
+	}
+
+	return mx, nil
+}
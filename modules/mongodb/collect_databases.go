@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+func (m *MongoDB) collectDatabases(mx map[string]int64) error {
+	names, err := m.conn.listDatabaseNames()
+	if err != nil {
+		return fmt.Errorf("list databases: %v", err)
+	}
+
+	seenDBs := make(map[string]bool)
+	seenColls := make(map[string]bool)
+	for _, name := range names {
+		if !m.databasesFilter.MatchString(name) {
+			continue
+		}
+
+		stats, err := m.conn.dbStats(name)
+		if err != nil {
+			m.Warningf("error on dbStats on '%s': %v", name, err)
+			continue
+		}
+		seenDBs[name] = true
+
+		px := "database_" + name + "_"
+		for k, v := range stm.ToMap(stats) {
+			mx[px+k] = v
+		}
+
+		if m.Collections == "" {
+			continue
+		}
+		if err := m.collectCollections(mx, name, seenColls); err != nil {
+			m.Warning(err)
+		}
+	}
+
+	for name := range seenDBs {
+		if !m.collectedDatabases[name] {
+			m.collectedDatabases[name] = true
+			m.addDatabaseCharts(name)
+		}
+	}
+	for name := range m.collectedDatabases {
+		if !seenDBs[name] {
+			delete(m.collectedDatabases, name)
+			m.removeDatabaseCharts(name)
+		}
+	}
+
+	for name := range seenColls {
+		if !m.collectedCollections[name] {
+			m.collectedCollections[name] = true
+			m.addCollectionCharts(name)
+		}
+	}
+	for name := range m.collectedCollections {
+		if !seenColls[name] {
+			delete(m.collectedCollections, name)
+			m.removeCollectionCharts(name)
+		}
+	}
+	return nil
+}
+
+func (m *MongoDB) collectCollections(mx map[string]int64, database string, seen map[string]bool) error {
+	names, err := m.conn.listCollectionNames(database)
+	if err != nil {
+		return fmt.Errorf("list collections of '%s': %v", database, err)
+	}
+
+	for _, name := range names {
+		fullName := database + "." + name
+		if !m.collectionsFilter.MatchString(fullName) {
+			continue
+		}
+
+		stats, err := m.conn.collStats(database, name)
+		if err != nil {
+			m.Warningf("error on collStats on '%s': %v", fullName, err)
+			continue
+		}
+		seen[fullName] = true
+
+		px := "collection_" + fullName + "_"
+		for k, v := range stm.ToMap(stats) {
+			mx[px+k] = v
+		}
+	}
+	return nil
+}
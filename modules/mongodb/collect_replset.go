@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"time"
+)
+
+// https://docs.mongodb.com/manual/reference/replica-states/
+var replSetMemberStates = []struct {
+	id   int
+	name string
+}{
+	{0, "startup"},
+	{1, "primary"},
+	{2, "secondary"},
+	{3, "recovering"},
+	{5, "startup2"},
+	{6, "unknown"},
+	{7, "arbiter"},
+	{8, "down"},
+	{9, "rollback"},
+	{10, "removed"},
+}
+
+const replSetStatePrimary = 1
+
+func (m *MongoDB) collectReplSetStatus(mx map[string]int64) error {
+	status, err := m.conn.replSetGetStatus()
+	if err != nil {
+		return err
+	}
+
+	m.addReplSetChartsOnce.Do(m.addReplSetCharts)
+
+	var primaryOptime time.Time
+	for _, member := range status.Members {
+		if member.State == replSetStatePrimary {
+			primaryOptime = member.OptimeDate
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, member := range status.Members {
+		seen[member.Name] = true
+		px := "repl_set_member_" + member.Name + "_"
+
+		mx[px+"health"] = member.Health
+		for _, state := range replSetMemberStates {
+			mx[px+"state_"+state.name] = boolToInt(member.State == state.id)
+		}
+
+		// arbiters and unreachable members have no optime, lag is unknown while there is no primary
+		var lag int64
+		if isValidOptime(primaryOptime) && isValidOptime(member.OptimeDate) && primaryOptime.After(member.OptimeDate) {
+			lag = primaryOptime.Sub(member.OptimeDate).Milliseconds()
+		}
+		mx[px+"replication_lag"] = lag
+	}
+
+	for name := range seen {
+		if !m.collectedMembers[name] {
+			m.collectedMembers[name] = true
+			m.addReplSetMemberCharts(name)
+		}
+	}
+	for name := range m.collectedMembers {
+		if !seen[name] {
+			delete(m.collectedMembers, name)
+			m.removeReplSetMemberCharts(name)
+		}
+	}
+	return nil
+}
+
+func isValidOptime(t time.Time) bool {
+	return t.Unix() > 0
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
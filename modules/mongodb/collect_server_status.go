@@ -0,0 +1,24 @@
+package mongodb
+
+import (
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+func (m *MongoDB) collectServerStatus(mx map[string]int64) (*documentServerStatus, error) {
+	status, err := m.conn.serverStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range stm.ToMap(status) {
+		mx[k] = v
+	}
+
+	if status.WiredTiger != nil {
+		m.addWiredTigerChartsOnce.Do(m.addWiredTigerCharts)
+	}
+	if status.Locks != nil {
+		m.addLocksChartsOnce.Do(func() { m.addLocksCharts(status.Locks) })
+	}
+	return status, nil
+}
@@ -0,0 +1,156 @@
+package mongodb
+
+import "time"
+
+// https://docs.mongodb.com/manual/reference/command/serverStatus/
+type documentServerStatus struct {
+	OpCounters     documentOpCounters       `bson:"opcounters" stm:"opcounters"`
+	OpCountersRepl *documentOpCounters      `bson:"opcountersRepl" stm:"opcounters_repl"`
+	Connections    documentConnections      `bson:"connections" stm:"connections"`
+	Network        documentNetwork          `bson:"network" stm:"network"`
+	Memory         documentMemory           `bson:"mem" stm:"memory"`
+	Asserts        documentAsserts          `bson:"asserts" stm:"asserts"`
+	GlobalLock     *documentGlobalLock      `bson:"globalLock" stm:"global_lock"`
+	Locks          *documentLocks           `bson:"locks" stm:"locks"`
+	Metrics        documentMetrics          `bson:"metrics" stm:"metrics"`
+	WiredTiger     *documentWiredTiger      `bson:"wiredTiger" stm:"wiredtiger"`
+	Repl           *documentReplicationInfo `bson:"repl"`
+}
+
+type documentOpCounters struct {
+	Insert  int64 `bson:"insert" stm:"insert"`
+	Query   int64 `bson:"query" stm:"query"`
+	Update  int64 `bson:"update" stm:"update"`
+	Delete  int64 `bson:"delete" stm:"delete"`
+	GetMore int64 `bson:"getmore" stm:"getmore"`
+	Command int64 `bson:"command" stm:"command"`
+}
+
+type documentConnections struct {
+	Current      int64  `bson:"current" stm:"current"`
+	Available    int64  `bson:"available" stm:"available"`
+	TotalCreated int64  `bson:"totalCreated" stm:"total_created"`
+	Active       *int64 `bson:"active" stm:"active"`
+}
+
+type documentNetwork struct {
+	BytesIn     int64 `bson:"bytesIn" stm:"bytes_in"`
+	BytesOut    int64 `bson:"bytesOut" stm:"bytes_out"`
+	NumRequests int64 `bson:"numRequests" stm:"requests"`
+}
+
+// resident and virtual memory are reported in mebibytes
+type documentMemory struct {
+	Resident int64 `bson:"resident" stm:"resident"`
+	Virtual  int64 `bson:"virtual" stm:"virtual"`
+}
+
+type documentAsserts struct {
+	Regular   int64 `bson:"regular" stm:"regular"`
+	Warning   int64 `bson:"warning" stm:"warning"`
+	Msg       int64 `bson:"msg" stm:"msg"`
+	User      int64 `bson:"user" stm:"user"`
+	Rollovers int64 `bson:"rollovers" stm:"rollovers"`
+}
+
+type documentGlobalLock struct {
+	CurrentQueue  documentReadWriteClients `bson:"currentQueue" stm:"current_queue"`
+	ActiveClients documentReadWriteClients `bson:"activeClients" stm:"active_clients"`
+}
+
+type documentReadWriteClients struct {
+	Readers int64 `bson:"readers" stm:"readers"`
+	Writers int64 `bson:"writers" stm:"writers"`
+}
+
+// https://docs.mongodb.com/manual/reference/command/serverStatus/#locks
+type documentLocks struct {
+	Global     *documentLockType `bson:"Global" stm:"global"`
+	Database   *documentLockType `bson:"Database" stm:"database"`
+	Collection *documentLockType `bson:"Collection" stm:"collection"`
+}
+
+type documentLockType struct {
+	AcquireCount documentLockModes `bson:"acquireCount" stm:"acquire"`
+}
+
+type documentLockModes struct {
+	Shared          int64 `bson:"R" stm:"shared"`
+	Exclusive       int64 `bson:"W" stm:"exclusive"`
+	IntentShared    int64 `bson:"r" stm:"intent_shared"`
+	IntentExclusive int64 `bson:"w" stm:"intent_exclusive"`
+}
+
+type documentMetrics struct {
+	Document documentDocumentMetrics `bson:"document" stm:"document"`
+}
+
+type documentDocumentMetrics struct {
+	Deleted  int64 `bson:"deleted" stm:"deleted"`
+	Inserted int64 `bson:"inserted" stm:"inserted"`
+	Returned int64 `bson:"returned" stm:"returned"`
+	Updated  int64 `bson:"updated" stm:"updated"`
+}
+
+// https://docs.mongodb.com/manual/reference/command/serverStatus/#wiredtiger
+type documentWiredTiger struct {
+	Cache                  documentWiredTigerCache        `bson:"cache" stm:"cache"`
+	ConcurrentTransactions documentConcurrentTransactions `bson:"concurrentTransactions" stm:"concurrent_txns"`
+}
+
+type documentWiredTigerCache struct {
+	BytesInCache           int64 `bson:"bytes currently in the cache" stm:"bytes"`
+	MaxBytesConfigured     int64 `bson:"maximum bytes configured" stm:"max_bytes"`
+	TrackedDirtyBytes      int64 `bson:"tracked dirty bytes in the cache" stm:"dirty_bytes"`
+	PagesReadIntoCache     int64 `bson:"pages read into cache" stm:"pages_read"`
+	PagesWrittenFromCache  int64 `bson:"pages written from cache" stm:"pages_written"`
+	ModifiedPagesEvicted   int64 `bson:"modified pages evicted" stm:"modified_evicted"`
+	UnmodifiedPagesEvicted int64 `bson:"unmodified pages evicted" stm:"unmodified_evicted"`
+}
+
+type documentConcurrentTransactions struct {
+	Read  documentTicketsInfo `bson:"read" stm:"read"`
+	Write documentTicketsInfo `bson:"write" stm:"write"`
+}
+
+type documentTicketsInfo struct {
+	Out       int64 `bson:"out" stm:"out"`
+	Available int64 `bson:"available" stm:"available"`
+}
+
+type documentReplicationInfo struct {
+	SetName string `bson:"setName"`
+}
+
+// https://docs.mongodb.com/manual/reference/command/dbStats/
+type documentDBStats struct {
+	Collections int64 `bson:"collections" stm:"collections"`
+	Views       int64 `bson:"views" stm:"views"`
+	Indexes     int64 `bson:"indexes" stm:"indexes"`
+	Objects     int64 `bson:"objects" stm:"objects"`
+	DataSize    int64 `bson:"dataSize,truncate" stm:"data_size"`
+	StorageSize int64 `bson:"storageSize,truncate" stm:"storage_size"`
+	IndexSize   int64 `bson:"indexSize,truncate" stm:"index_size"`
+}
+
+// https://docs.mongodb.com/manual/reference/command/collStats/
+type documentCollStats struct {
+	Count          int64 `bson:"count" stm:"documents"`
+	Size           int64 `bson:"size,truncate" stm:"size"`
+	StorageSize    int64 `bson:"storageSize,truncate" stm:"storage_size"`
+	TotalIndexSize int64 `bson:"totalIndexSize,truncate" stm:"index_size"`
+	NIndexes       int64 `bson:"nindexes" stm:"indexes"`
+}
+
+// https://docs.mongodb.com/manual/reference/command/replSetGetStatus/
+type documentReplSetStatus struct {
+	Set     string                  `bson:"set"`
+	Members []documentReplSetMember `bson:"members"`
+}
+
+type documentReplSetMember struct {
+	Name       string    `bson:"name"`
+	Health     int64     `bson:"health,truncate"`
+	State      int       `bson:"state"`
+	OptimeDate time.Time `bson:"optimeDate"`
+}
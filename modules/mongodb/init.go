@@ -0,0 +1,51 @@
+package mongodb
+
+import (
+	"errors"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+)
+
+func (m MongoDB) validateConfig() error {
+	if m.URI == "" {
+		return errors.New("'uri' not set")
+	}
+	if m.Timeout.Duration <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+	return nil
+}
+
+// initDatabasesFilter returns a matcher for database names, an empty expression matches all databases.
+func (m MongoDB) initDatabasesFilter() (matcher.Matcher, error) {
+	if m.Databases == "" {
+		return matcher.TRUE(), nil
+	}
+	mr, err := matcher.NewSimplePatternsMatcher(m.Databases)
+	if err != nil {
+		return nil, err
+	}
+	return matcher.WithCache(mr), nil
+}
+
+// initCollectionsFilter returns a matcher for '<database>.<collection>' names,
+// an empty expression disables per-collection statistics.
+func (m MongoDB) initCollectionsFilter() (matcher.Matcher, error) {
+	if m.Collections == "" {
+		return matcher.FALSE(), nil
+	}
+	mr, err := matcher.NewSimplePatternsMatcher(m.Collections)
+	if err != nil {
+		return nil, err
+	}
+	return matcher.WithCache(mr), nil
+}
+
+func (m MongoDB) initCharts() (*module.Charts, error) {
+	return charts.Copy(), nil
+}
+
+func (m *MongoDB) initMongoConn() (mongoConn, error) {
+	return newMongoClient(m.URI, m.Timeout.Duration)
+}
@@ -0,0 +1,132 @@
+package mongodb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	module.Register("mongodb", module.Creator{
+		Create: func() module.Module { return New() },
+	})
+}
+
+func New() *MongoDB {
+	return &MongoDB{
+		Config: Config{
+			URI:       "mongodb://localhost:27017",
+			Timeout:   web.Duration{Duration: time.Second * 2},
+			Databases: "!admin !config !local *",
+		},
+
+		addWiredTigerChartsOnce: &sync.Once{},
+		addLocksChartsOnce:      &sync.Once{},
+		addReplSetChartsOnce:    &sync.Once{},
+		collectedDatabases:      make(map[string]bool),
+		collectedCollections:    make(map[string]bool),
+		collectedMembers:        make(map[string]bool),
+	}
+}
+
+type Config struct {
+	URI         string       `yaml:"uri"`
+	Timeout     web.Duration `yaml:"timeout"`
+	Databases   string       `yaml:"databases"`
+	Collections string       `yaml:"collections"`
+}
+
+type (
+	MongoDB struct {
+		module.Base
+		Config `yaml:",inline"`
+
+		conn mongoConn
+
+		databasesFilter   matcher.Matcher
+		collectionsFilter matcher.Matcher
+
+		collectedDatabases   map[string]bool
+		collectedCollections map[string]bool
+		collectedMembers     map[string]bool
+
+		addWiredTigerChartsOnce *sync.Once
+		addLocksChartsOnce      *sync.Once
+		addReplSetChartsOnce    *sync.Once
+
+		charts *module.Charts
+	}
+	mongoConn interface {
+		serverStatus() (*documentServerStatus, error)
+		replSetGetStatus() (*documentReplSetStatus, error)
+		listDatabaseNames() ([]string, error)
+		dbStats(database string) (*documentDBStats, error)
+		listCollectionNames(database string) ([]string, error)
+		collStats(database, collection string) (*documentCollStats, error)
+		close() error
+	}
+)
+
+func (m *MongoDB) Init() bool {
+	err := m.validateConfig()
+	if err != nil {
+		m.Errorf("config validation: %v", err)
+		return false
+	}
+
+	databasesFilter, err := m.initDatabasesFilter()
+	if err != nil {
+		m.Errorf("init databases filter: %v", err)
+		return false
+	}
+	m.databasesFilter = databasesFilter
+
+	collectionsFilter, err := m.initCollectionsFilter()
+	if err != nil {
+		m.Errorf("init collections filter: %v", err)
+		return false
+	}
+	m.collectionsFilter = collectionsFilter
+
+	charts, err := m.initCharts()
+	if err != nil {
+		m.Errorf("init charts: %v", err)
+		return false
+	}
+	m.charts = charts
+
+	return true
+}
+
+func (m *MongoDB) Check() bool {
+	return len(m.Collect()) > 0
+}
+
+func (m *MongoDB) Charts() *module.Charts {
+	return m.charts
+}
+
+func (m *MongoDB) Collect() map[string]int64 {
+	mx, err := m.collect()
+	if err != nil {
+		m.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
+
+func (m *MongoDB) Cleanup() {
+	if m.conn == nil {
+		return
+	}
+	if err := m.conn.close(); err != nil {
+		m.Warningf("cleanup: error on closing mongodb client [%s]: %v", m.URI, err)
+	}
+	m.conn = nil
+}
@@ -0,0 +1,477 @@
+package mongodb
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	v505ServerStatus, _      = ioutil.ReadFile("testdata/v5.0.5/serverStatus.json")
+	v505ReplSetGetStatus, _  = ioutil.ReadFile("testdata/v5.0.5/replSetGetStatus.json")
+	v505DBStatsAdmin, _      = ioutil.ReadFile("testdata/v5.0.5/dbStats_admin.json")
+	v505DBStatsApp, _        = ioutil.ReadFile("testdata/v5.0.5/dbStats_app.json")
+	v505CollStatsAppUsers, _ = ioutil.ReadFile("testdata/v5.0.5/collStats_app_users.json")
+)
+
+func Test_testDataIsValid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"v505ServerStatus":      v505ServerStatus,
+		"v505ReplSetGetStatus":  v505ReplSetGetStatus,
+		"v505DBStatsAdmin":      v505DBStatsAdmin,
+		"v505DBStatsApp":        v505DBStatsApp,
+		"v505CollStatsAppUsers": v505CollStatsAppUsers,
+	} {
+		require.NotNilf(t, data, name)
+		var doc bson.M
+		require.NoErrorf(t, bson.UnmarshalExtJSON(data, false, &doc), name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestMongoDB_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"success on default config": {
+			config: New().Config,
+		},
+		"success on empty filters": {
+			config: Config{URI: "mongodb://localhost:27017", Timeout: New().Timeout},
+		},
+		"fails on unset 'uri'": {
+			wantFail: true,
+			config:   Config{URI: "", Timeout: New().Timeout},
+		},
+		"fails on zero 'timeout'": {
+			wantFail: true,
+			config:   Config{URI: "mongodb://localhost:27017"},
+		},
+		"fails on invalid 'databases'": {
+			wantFail: true,
+			config:   Config{URI: "mongodb://localhost:27017", Timeout: New().Timeout, Databases: `ab\`},
+		},
+		"fails on invalid 'collections'": {
+			wantFail: true,
+			config:   Config{URI: "mongodb://localhost:27017", Timeout: New().Timeout, Collections: `ab\`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mongo := New()
+			mongo.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, mongo.Init())
+			} else {
+				assert.True(t, mongo.Init())
+			}
+		})
+	}
+}
+
+func TestMongoDB_Charts(t *testing.T) {
+	mongo := New()
+	require.True(t, mongo.Init())
+
+	assert.NotNil(t, mongo.Charts())
+}
+
+func TestMongoDB_Cleanup(t *testing.T) {
+	mongo := New()
+	assert.NotPanics(t, mongo.Cleanup)
+
+	require.True(t, mongo.Init())
+	m := &mockMongoClient{}
+	mongo.conn = m
+
+	mongo.Cleanup()
+
+	assert.True(t, m.calledClose)
+	assert.Nil(t, mongo.conn)
+}
+
+func TestMongoDB_Check(t *testing.T) {
+	tests := map[string]struct {
+		prepare  func() *mockMongoClient
+		wantFail bool
+	}{
+		"success on valid response": {
+			prepare: prepareMockReplicaSetPrimary,
+		},
+		"success on error on dbStats": {
+			prepare: func() *mockMongoClient {
+				m := prepareMockReplicaSetPrimary()
+				m.errOnDBStats = true
+				return m
+			},
+		},
+		"fails on error on serverStatus": {
+			wantFail: true,
+			prepare: func() *mockMongoClient {
+				m := prepareMockReplicaSetPrimary()
+				m.errOnServerStatus = true
+				return m
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mongo := New()
+			require.True(t, mongo.Init())
+			mongo.conn = test.prepare()
+
+			if test.wantFail {
+				assert.False(t, mongo.Check())
+			} else {
+				assert.True(t, mongo.Check())
+			}
+		})
+	}
+}
+
+func TestMongoDB_Collect(t *testing.T) {
+	tests := map[string]struct {
+		config        func() Config
+		prepare       func() *mockMongoClient
+		wantCollected map[string]int64
+		wantCharts    []string
+		wantNoCharts  []string
+	}{
+		"replica set primary, default config": {
+			config:  func() Config { return New().Config },
+			prepare: prepareMockReplicaSetPrimary,
+			wantCollected: merge(
+				serverStatusMetrics(),
+				opCountersReplMetrics(),
+				replSetMemberMetrics("mongo-rs0-1:27017", 1, "primary", 0),
+				replSetMemberMetrics("mongo-rs0-2:27017", 1, "secondary", 1500),
+				replSetMemberMetrics("mongo-rs0-3:27017", 0, "down", 0),
+				databaseAppMetrics(),
+			),
+			wantCharts: []string{
+				"operations_repl",
+				"wiredtiger_cache_usage",
+				"locks_global_acquired",
+				"locks_collection_acquired",
+				"repl_set_member_mongo-rs0-2_27017_replication_lag",
+				"database_app_size",
+			},
+			wantNoCharts: []string{
+				"database_admin_size",
+				"collection_app_users_size",
+			},
+		},
+		"standalone, collections filter": {
+			config: func() Config {
+				cfg := New().Config
+				cfg.Databases = "admin app"
+				cfg.Collections = "app.*"
+				return cfg
+			},
+			prepare: func() *mockMongoClient {
+				m := prepareMockReplicaSetPrimary()
+				m.standalone = true
+				return m
+			},
+			wantCollected: merge(
+				serverStatusMetrics(),
+				databaseAppMetrics(),
+				map[string]int64{
+					"database_admin_collections":  1,
+					"database_admin_views":        0,
+					"database_admin_indexes":      1,
+					"database_admin_objects":      1,
+					"database_admin_data_size":    59,
+					"database_admin_storage_size": 20480,
+					"database_admin_index_size":   20480,
+
+					"collection_app.users_documents":    1681,
+					"collection_app.users_size":         576064,
+					"collection_app.users_storage_size": 245760,
+					"collection_app.users_index_size":   94208,
+					"collection_app.users_indexes":      3,
+				},
+			),
+			wantCharts: []string{
+				"database_admin_size",
+				"database_app_size",
+				"collection_app_users_size",
+			},
+			wantNoCharts: []string{
+				"operations_repl",
+				"repl_set_member_mongo-rs0-1_27017_replication_lag",
+			},
+		},
+		"error on serverStatus": {
+			config: func() Config { return New().Config },
+			prepare: func() *mockMongoClient {
+				m := prepareMockReplicaSetPrimary()
+				m.errOnServerStatus = true
+				return m
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mongo := New()
+			mongo.Config = test.config()
+			require.True(t, mongo.Init())
+			mongo.conn = test.prepare()
+
+			mx := mongo.Collect()
+
+			assert.Equal(t, test.wantCollected, mx)
+			for _, id := range test.wantCharts {
+				assert.Truef(t, mongo.Charts().Has(id), "chart '%s' is not in charts", id)
+			}
+			for _, id := range test.wantNoCharts {
+				assert.Falsef(t, mongo.Charts().Has(id), "chart '%s' is in charts", id)
+			}
+			if len(test.wantCollected) > 0 {
+				ensureCollectedHasAllChartsDimsVarsIDs(t, mongo, mx)
+			}
+		})
+	}
+}
+
+func TestMongoDB_Collect_RemovesObsoleteCharts(t *testing.T) {
+	mongo := New()
+	require.True(t, mongo.Init())
+	m := prepareMockReplicaSetPrimary()
+	mongo.conn = m
+
+	require.NotNil(t, mongo.Collect())
+	require.True(t, mongo.Charts().Has("database_app_size"))
+
+	m.databases = []string{"admin"}
+	require.NotNil(t, mongo.Collect())
+
+	chart := mongo.Charts().Get("database_app_size")
+	require.NotNil(t, chart)
+	assert.True(t, chart.Obsolete)
+}
+
+func prepareMockReplicaSetPrimary() *mockMongoClient {
+	return &mockMongoClient{
+		serverStatusData:  v505ServerStatus,
+		replSetStatusData: v505ReplSetGetStatus,
+		databases:         []string{"admin", "app", "config", "local"},
+		dbStatsData: map[string][]byte{
+			"admin": v505DBStatsAdmin,
+			"app":   v505DBStatsApp,
+		},
+		collections: map[string][]string{
+			"admin": {"system.version"},
+			"app":   {"users"},
+		},
+		collStatsData: map[string][]byte{
+			"app.users": v505CollStatsAppUsers,
+		},
+	}
+}
+
+func serverStatusMetrics() map[string]int64 {
+	return map[string]int64{
+		"asserts_msg":                                0,
+		"asserts_regular":                            0,
+		"asserts_rollovers":                          0,
+		"asserts_user":                               246,
+		"asserts_warning":                            0,
+		"connections_active":                         8,
+		"connections_available":                      838839,
+		"connections_current":                        21,
+		"connections_total_created":                  1473,
+		"global_lock_active_clients_readers":         4,
+		"global_lock_active_clients_writers":         5,
+		"global_lock_current_queue_readers":          1,
+		"global_lock_current_queue_writers":          2,
+		"locks_collection_acquire_exclusive":         41,
+		"locks_collection_acquire_intent_exclusive":  2826,
+		"locks_collection_acquire_intent_shared":     17413,
+		"locks_collection_acquire_shared":            1,
+		"locks_database_acquire_exclusive":           39,
+		"locks_database_acquire_intent_exclusive":    2864,
+		"locks_database_acquire_intent_shared":       21012,
+		"locks_database_acquire_shared":              0,
+		"locks_global_acquire_exclusive":             4,
+		"locks_global_acquire_intent_exclusive":      3829,
+		"locks_global_acquire_intent_shared":         163728,
+		"locks_global_acquire_shared":                2,
+		"memory_resident":                            191,
+		"memory_virtual":                             3104,
+		"metrics_document_deleted":                   51,
+		"metrics_document_inserted":                  1732,
+		"metrics_document_returned":                  62847,
+		"metrics_document_updated":                   302,
+		"network_bytes_in":                           3459223,
+		"network_bytes_out":                          44918347,
+		"network_requests":                           27491,
+		"opcounters_command":                         21876,
+		"opcounters_delete":                          51,
+		"opcounters_getmore":                         7219,
+		"opcounters_insert":                          1732,
+		"opcounters_query":                           2043,
+		"opcounters_update":                          302,
+		"wiredtiger_cache_bytes":                     2201840,
+		"wiredtiger_cache_dirty_bytes":               1421,
+		"wiredtiger_cache_max_bytes":                 8053063680,
+		"wiredtiger_cache_modified_evicted":          11,
+		"wiredtiger_cache_pages_read":                218,
+		"wiredtiger_cache_pages_written":             2941,
+		"wiredtiger_cache_unmodified_evicted":        0,
+		"wiredtiger_concurrent_txns_read_available":  126,
+		"wiredtiger_concurrent_txns_read_out":        2,
+		"wiredtiger_concurrent_txns_write_available": 127,
+		"wiredtiger_concurrent_txns_write_out":       1,
+	}
+}
+
+func opCountersReplMetrics() map[string]int64 {
+	return map[string]int64{
+		"opcounters_repl_command": 0,
+		"opcounters_repl_delete":  0,
+		"opcounters_repl_getmore": 0,
+		"opcounters_repl_insert":  0,
+		"opcounters_repl_query":   0,
+		"opcounters_repl_update":  0,
+	}
+}
+
+func databaseAppMetrics() map[string]int64 {
+	return map[string]int64{
+		"database_app_collections":  2,
+		"database_app_views":        1,
+		"database_app_indexes":      4,
+		"database_app_objects":      1681,
+		"database_app_data_size":    576064,
+		"database_app_storage_size": 270336,
+		"database_app_index_size":   114688,
+	}
+}
+
+func replSetMemberMetrics(name string, health int64, state string, lag int64) map[string]int64 {
+	px := "repl_set_member_" + name + "_"
+	mx := map[string]int64{
+		px + "health":          health,
+		px + "replication_lag": lag,
+	}
+	for _, v := range replSetMemberStates {
+		mx[px+"state_"+v.name] = 0
+	}
+	mx[px+"state_"+state] = 1
+	return mx
+}
+
+func merge(maps ...map[string]int64) map[string]int64 {
+	mx := make(map[string]int64)
+	for _, m := range maps {
+		for k, v := range m {
+			mx[k] = v
+		}
+	}
+	return mx
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, mongo *MongoDB, mx map[string]int64) {
+	for _, chart := range *mongo.Charts() {
+		if chart.Obsolete {
+			continue
+		}
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "chart '%s' dim '%s': no dim in collected", chart.ID, dim.ID)
+		}
+		for _, v := range chart.Vars {
+			_, ok := mx[v.ID]
+			assert.Truef(t, ok, "chart '%s' var '%s': no var in collected", chart.ID, v.ID)
+		}
+	}
+}
+
+type mockMongoClient struct {
+	serverStatusData  []byte
+	replSetStatusData []byte
+	databases         []string
+	dbStatsData       map[string][]byte
+	collections       map[string][]string
+	collStatsData     map[string][]byte
+
+	standalone        bool
+	errOnServerStatus bool
+	errOnDBStats      bool
+	calledClose       bool
+}
+
+func (m *mockMongoClient) serverStatus() (*documentServerStatus, error) {
+	if m.errOnServerStatus {
+		return nil, errors.New("mock.serverStatus() error")
+	}
+	var doc documentServerStatus
+	if err := bson.UnmarshalExtJSON(m.serverStatusData, false, &doc); err != nil {
+		return nil, err
+	}
+	if m.standalone {
+		doc.Repl = nil
+		doc.OpCountersRepl = nil
+	}
+	return &doc, nil
+}
+
+func (m *mockMongoClient) replSetGetStatus() (*documentReplSetStatus, error) {
+	var doc documentReplSetStatus
+	if err := bson.UnmarshalExtJSON(m.replSetStatusData, false, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (m *mockMongoClient) listDatabaseNames() ([]string, error) {
+	return m.databases, nil
+}
+
+func (m *mockMongoClient) dbStats(database string) (*documentDBStats, error) {
+	data, ok := m.dbStatsData[database]
+	if m.errOnDBStats || !ok {
+		return nil, errors.New("mock.dbStats() error")
+	}
+	var doc documentDBStats
+	if err := bson.UnmarshalExtJSON(data, false, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (m *mockMongoClient) listCollectionNames(database string) ([]string, error) {
+	return m.collections[database], nil
+}
+
+func (m *mockMongoClient) collStats(database, collection string) (*documentCollStats, error) {
+	data, ok := m.collStatsData[database+"."+collection]
+	if !ok {
+		return nil, errors.New("mock.collStats() error")
+	}
+	var doc documentCollStats
+	if err := bson.UnmarshalExtJSON(data, false, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (m *mockMongoClient) close() error {
+	m.calledClose = true
+	return nil
+}
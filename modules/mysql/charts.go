@@ -747,6 +747,202 @@ func newUserStatisticsCharts(user string) module.Charts {
 	}
 }
 
+var groupReplicationMembersChart = module.Chart{
+	ID:    "group_replication_members",
+	Title: "Group Replication Members By State",
+	Units: "members",
+	Fam:   "group replication",
+	Ctx:   "mysql.group_replication_members",
+	Type:  module.Stacked,
+	Dims: Dims{
+		{ID: "group_replication_members_online", Name: "online"},
+		{ID: "group_replication_members_recovering", Name: "recovering"},
+		{ID: "group_replication_members_offline", Name: "offline"},
+		{ID: "group_replication_members_error", Name: "error"},
+		{ID: "group_replication_members_unreachable", Name: "unreachable"},
+	},
+}
+
+func newGroupReplicationMemberCharts(member string, hasRole bool) module.Charts {
+	key := groupReplicationMemberKey(member)
+	prefix := "group_replication_" + key + "_"
+	cs := module.Charts{
+		{
+			ID:    "group_replication_member_state_" + key,
+			Title: "Group Replication Member State",
+			Units: "state",
+			Fam:   "group replication " + member,
+			Ctx:   "mysql.group_replication_member_state",
+			Dims: Dims{
+				{ID: prefix + "member_state_online", Name: "online"},
+				{ID: prefix + "member_state_recovering", Name: "recovering"},
+				{ID: prefix + "member_state_offline", Name: "offline"},
+				{ID: prefix + "member_state_error", Name: "error"},
+				{ID: prefix + "member_state_unreachable", Name: "unreachable"},
+			},
+		},
+		{
+			ID:    "group_replication_transactions_queue_" + key,
+			Title: "Group Replication Transactions Queue",
+			Units: "transactions",
+			Fam:   "group replication " + member,
+			Ctx:   "mysql.group_replication_transactions_queue",
+			Dims: Dims{
+				{ID: prefix + "transactions_in_queue", Name: "certification"},
+			},
+		},
+		{
+			ID:    "group_replication_transactions_" + key,
+			Title: "Group Replication Transactions",
+			Units: "transactions/s",
+			Fam:   "group replication " + member,
+			Ctx:   "mysql.group_replication_transactions",
+			Dims: Dims{
+				{ID: prefix + "transactions_checked", Name: "checked", Algo: module.Incremental},
+			},
+		},
+		{
+			ID:    "group_replication_conflicts_" + key,
+			Title: "Group Replication Conflicts Detected",
+			Units: "conflicts/s",
+			Fam:   "group replication " + member,
+			Ctx:   "mysql.group_replication_conflicts",
+			Dims: Dims{
+				{ID: prefix + "conflicts_detected", Name: "conflicts", Algo: module.Incremental},
+			},
+		},
+		{
+			ID:    "group_replication_rows_validating_" + key,
+			Title: "Group Replication Certification Database Rows",
+			Units: "rows",
+			Fam:   "group replication " + member,
+			Ctx:   "mysql.group_replication_rows_validating",
+			Dims: Dims{
+				{ID: prefix + "transactions_rows_validating", Name: "rows"},
+			},
+		},
+	}
+
+	// MySQL 8.0.2+
+	if hasRole {
+		_ = cs.Add(&module.Chart{
+			ID:    "group_replication_member_role_" + key,
+			Title: "Group Replication Member Role",
+			Units: "role",
+			Fam:   "group replication " + member,
+			Ctx:   "mysql.group_replication_member_role",
+			Dims: Dims{
+				{ID: prefix + "member_role_primary", Name: "primary"},
+				{ID: prefix + "member_role_secondary", Name: "secondary"},
+			},
+		})
+		_ = cs.Get("group_replication_transactions_queue_" + key).AddDim(
+			&module.Dim{ID: prefix + "transactions_remote_in_applier_queue", Name: "applier"},
+		)
+		chart := cs.Get("group_replication_transactions_" + key)
+		for _, dim := range []*module.Dim{
+			{ID: prefix + "transactions_remote_applied", Name: "remote applied", Algo: module.Incremental},
+			{ID: prefix + "transactions_local_proposed", Name: "local proposed", Algo: module.Incremental},
+			{ID: prefix + "transactions_local_rollback", Name: "local rollback", Algo: module.Incremental},
+		} {
+			_ = chart.AddDim(dim)
+		}
+	}
+
+	for _, chart := range cs {
+		chart.Title += " Member " + member
+	}
+	return cs
+}
+
+func newTableCharts(tbl table) module.Charts {
+	key, prefix := tableKey(tbl), tableMetricPrefix(tbl)
+	cs := module.Charts{
+		{
+			ID:    "table_io_operations_" + key,
+			Title: "Table I/O Operations",
+			Units: "operations/s",
+			Fam:   "table " + tbl.String(),
+			Ctx:   "mysql.table_io_operations",
+			Type:  module.Stacked,
+			Dims: Dims{
+				{ID: prefix + "io_fetch", Name: "fetch", Algo: module.Incremental},
+				{ID: prefix + "io_insert", Name: "insert", Algo: module.Incremental},
+				{ID: prefix + "io_update", Name: "update", Algo: module.Incremental},
+				{ID: prefix + "io_delete", Name: "delete", Algo: module.Incremental},
+			},
+		},
+		{
+			ID:    "table_io_latency_" + key,
+			Title: "Table I/O Latency",
+			Units: "milliseconds/s",
+			Fam:   "table " + tbl.String(),
+			Ctx:   "mysql.table_io_latency",
+			Type:  module.Stacked,
+			Dims: Dims{
+				{ID: prefix + "io_read_latency", Name: "read", Algo: module.Incremental, Div: 1000},
+				{ID: prefix + "io_write_latency", Name: "write", Algo: module.Incremental, Div: 1000},
+			},
+		},
+		{
+			ID:    "table_lock_wait_time_" + key,
+			Title: "Table Lock Wait Time",
+			Units: "milliseconds/s",
+			Fam:   "table " + tbl.String(),
+			Ctx:   "mysql.table_lock_wait_time",
+			Type:  module.Stacked,
+			Dims: Dims{
+				{ID: prefix + "lock_read_wait", Name: "read", Algo: module.Incremental, Div: 1000},
+				{ID: prefix + "lock_write_wait", Name: "write", Algo: module.Incremental, Div: 1000},
+			},
+		},
+		{
+			ID:    "table_size_" + key,
+			Title: "Table Size",
+			Units: "MiB",
+			Fam:   "table " + tbl.String(),
+			Ctx:   "mysql.table_size",
+			Type:  module.Stacked,
+			Dims: Dims{
+				{ID: prefix + "data_length", Name: "data", Div: 1024 * 1024},
+				{ID: prefix + "index_length", Name: "index", Div: 1024 * 1024},
+				{ID: prefix + "data_free", Name: "free", Div: 1024 * 1024},
+			},
+		},
+	}
+	for _, chart := range cs {
+		chart.Title += " " + tbl.String()
+	}
+	return cs
+}
+
+var (
+	digestsLatencyChart = module.Chart{
+		ID:    "statement_digests_latency",
+		Title: "Top Statements By Total Latency",
+		Units: "milliseconds/s",
+		Fam:   "statement digests",
+		Ctx:   "mysql.statement_digests_latency",
+		Type:  module.Stacked,
+	}
+	digestsRowsExaminedChart = module.Chart{
+		ID:    "statement_digests_rows_examined",
+		Title: "Top Statements By Rows Examined",
+		Units: "rows/s",
+		Fam:   "statement digests",
+		Ctx:   "mysql.statement_digests_rows_examined",
+		Type:  module.Stacked,
+	}
+	digestsErrorsChart = module.Chart{
+		ID:    "statement_digests_errors",
+		Title: "Top Statements By Errors",
+		Units: "errors/s",
+		Fam:   "statement digests",
+		Ctx:   "mysql.statement_digests_errors",
+		Type:  module.Stacked,
+	}
+)
+
 func (m *MySQL) addSlaveReplicationConnCharts(conn string) {
 	var cs module.Charts
 	if conn == "" {
@@ -799,3 +995,65 @@ func (m *MySQL) addGaleraCharts() {
 		m.Warning(err)
 	}
 }
+
+func (m *MySQL) addGroupReplicationCharts() {
+	if err := m.Charts().Add(groupReplicationMembersChart.Copy()); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *MySQL) addGroupReplicationMemberCharts(member string, hasRole bool) {
+	if err := m.Charts().Add(newGroupReplicationMemberCharts(member, hasRole)...); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *MySQL) removeGroupReplicationMemberCharts(member string) {
+	m.removeCharts(newGroupReplicationMemberCharts(member, true))
+}
+
+func (m *MySQL) addTableCharts(tbl table) {
+	if err := m.Charts().Add(newTableCharts(tbl)...); err != nil {
+		m.Warning(err)
+	}
+}
+
+func (m *MySQL) removeTableCharts(tbl table) {
+	m.removeCharts(newTableCharts(tbl))
+}
+
+func (m *MySQL) removeCharts(cs module.Charts) {
+	for _, v := range cs {
+		if chart := m.Charts().Get(v.ID); chart != nil {
+			chart.MarkRemove()
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func (m *MySQL) addDigestDim(top *digestTop, d *statementDigest) {
+	if !m.Charts().Has(top.chart.ID) {
+		if err := m.Charts().Add(top.chart); err != nil {
+			m.Warning(err)
+			return
+		}
+	}
+
+	dim := &module.Dim{ID: digestDimID(d.key, top.metric), Name: digestDimName(d), Algo: module.Incremental}
+	if top.metric == "latency" {
+		dim.Div = 1000
+	}
+	if err := top.chart.AddDim(dim); err != nil {
+		m.Warning(err)
+		return
+	}
+	top.chart.MarkNotCreated()
+}
+
+func (m *MySQL) removeDigestDim(top *digestTop, key string) {
+	if err := top.chart.MarkDimRemove(digestDimID(key, top.metric), true); err != nil {
+		m.Warning(err)
+		return
+	}
+	top.chart.MarkNotCreated()
+}
@@ -25,6 +25,9 @@ func (m *MySQL) collect() (map[string]int64, error) {
 		// https://mariadb.com/kb/en/user-statistics/
 		minVer := semver.Version{Major: 10, Minor: 1, Patch: 1}
 		m.doUserStatistics = m.isMariaDB && m.version.GTE(minVer)
+		// https://dev.mysql.com/doc/refman/5.7/en/group-replication.html
+		minVer = semver.Version{Major: 5, Minor: 7, Patch: 17}
+		m.doGroupReplication = !m.isMariaDB && m.version.GTE(minVer)
 	}
 
 	collected := make(map[string]int64)
@@ -64,6 +67,27 @@ func (m *MySQL) collect() (map[string]int64, error) {
 		}
 	}
 
+	if m.doGroupReplication {
+		if err := m.collectGroupReplication(collected); err != nil {
+			m.Errorf("error on collecting group replication: %v", err)
+			m.doGroupReplication = false
+		}
+	}
+
+	if m.doTableMetrics {
+		if err := m.collectTableStats(collected); err != nil {
+			m.Errorf("error on collecting table metrics: %v", err)
+			m.doTableMetrics = false
+		}
+	}
+
+	if m.doStatementDigests {
+		if err := m.collectStatementDigests(collected); err != nil {
+			m.Errorf("error on collecting statement digests: %v", err)
+			m.doStatementDigests = false
+		}
+	}
+
 	calcThreadCacheMisses(collected)
 	return collected, nil
 }
@@ -0,0 +1,140 @@
+package mysql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// https://dev.mysql.com/doc/refman/8.0/en/group-replication-monitoring.html
+const (
+	queryGroupReplicationSelect = "SELECT m.MEMBER_ID, m.MEMBER_HOST, m.MEMBER_PORT, m.MEMBER_STATE, " +
+		"s.COUNT_TRANSACTIONS_IN_QUEUE, s.COUNT_TRANSACTIONS_CHECKED, s.COUNT_CONFLICTS_DETECTED, s.COUNT_TRANSACTIONS_ROWS_VALIDATING"
+	// MySQL 8.0.2+
+	queryGroupReplicationSelectV8 = ", m.MEMBER_ROLE, " +
+		"s.COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE, s.COUNT_TRANSACTIONS_REMOTE_APPLIED, " +
+		"s.COUNT_TRANSACTIONS_LOCAL_PROPOSED, s.COUNT_TRANSACTIONS_LOCAL_ROLLBACK"
+	queryGroupReplicationFrom = " FROM performance_schema.replication_group_members m " +
+		"LEFT JOIN performance_schema.replication_group_member_stats s ON m.MEMBER_ID = s.MEMBER_ID"
+)
+
+var groupReplicationMemberStates = []string{
+	"online",
+	"recovering",
+	"offline",
+	"error",
+	"unreachable",
+}
+
+var groupReplicationMemberStatsMetrics = []string{
+	"COUNT_TRANSACTIONS_IN_QUEUE",
+	"COUNT_TRANSACTIONS_CHECKED",
+	"COUNT_CONFLICTS_DETECTED",
+	"COUNT_TRANSACTIONS_ROWS_VALIDATING",
+	"COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE",
+	"COUNT_TRANSACTIONS_REMOTE_APPLIED",
+	"COUNT_TRANSACTIONS_LOCAL_PROPOSED",
+	"COUNT_TRANSACTIONS_LOCAL_ROLLBACK",
+}
+
+func queryGroupReplication(version *semver.Version) string {
+	if version != nil && version.GTE(semver.Version{Major: 8, Minor: 0, Patch: 2}) {
+		return queryGroupReplicationSelect + queryGroupReplicationSelectV8 + queryGroupReplicationFrom
+	}
+	return queryGroupReplicationSelect + queryGroupReplicationFrom
+}
+
+func (m *MySQL) collectGroupReplication(collected map[string]int64) error {
+	query := queryGroupReplication(m.version)
+	m.Debugf("executing query: '%s'", query)
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := nullStringsFromColumns(columns)
+	seen := make(map[string]bool)
+	states := make(map[string]int64)
+
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		set := rowAsMap(columns, values)
+
+		// the table has a row with an empty member id if the group replication is not running
+		if set["MEMBER_ID"] == "" {
+			continue
+		}
+
+		member := set["MEMBER_HOST"] + ":" + set["MEMBER_PORT"]
+		prefix := "group_replication_" + groupReplicationMemberKey(member) + "_"
+		seen[member] = true
+
+		if !m.collectedGRMembers[member] {
+			m.collectedGRMembers[member] = true
+			m.addGroupReplicationOnce.Do(m.addGroupReplicationCharts)
+			m.addGroupReplicationMemberCharts(member, set["MEMBER_ROLE"] != "")
+		}
+
+		state := strings.ToLower(set["MEMBER_STATE"])
+		states[state]++
+		for _, v := range groupReplicationMemberStates {
+			collected[prefix+"member_state_"+v] = boolToInt(state == v)
+		}
+
+		if role, ok := set["MEMBER_ROLE"]; ok && role != "" {
+			role = strings.ToLower(role)
+			collected[prefix+"member_role_primary"] = boolToInt(role == "primary")
+			collected[prefix+"member_role_secondary"] = boolToInt(role == "secondary")
+		}
+
+		for _, name := range groupReplicationMemberStatsMetrics {
+			v, ok := set[name]
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			collected[prefix+strings.ToLower(strings.TrimPrefix(name, "COUNT_"))] = value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(seen) > 0 {
+		for _, v := range groupReplicationMemberStates {
+			collected["group_replication_members_"+v] = states[v]
+		}
+	}
+
+	for member := range m.collectedGRMembers {
+		if !seen[member] {
+			delete(m.collectedGRMembers, member)
+			m.removeGroupReplicationMemberCharts(member)
+		}
+	}
+	return nil
+}
+
+func groupReplicationMemberKey(member string) string {
+	return strings.ToLower(strings.ReplaceAll(member, ":", "_"))
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,182 @@
+package mysql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const queryStatementDigests = "SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, SUM_TIMER_WAIT, SUM_ROWS_EXAMINED, SUM_ERRORS " +
+	"FROM performance_schema.events_statements_summary_by_digest WHERE DIGEST IS NOT NULL"
+
+type statementDigest struct {
+	key    string
+	schema string
+	text   string
+
+	latency      int64 // microseconds
+	rowsExamined int64
+	errors       int64
+}
+
+// digestTop is a chart of the top-N statement digests by one metric.
+// The digests are ranked by the metric increase since the previous collection. A digest that leaves the top-N
+// stays on the chart until it has been out of the top-N for 'retention', so the dimensions don't flap as digests churn.
+type digestTop struct {
+	chart  *Chart
+	metric string
+	value  func(d *statementDigest) int64
+	// dims are the digests on the chart and the last time they were in the top-N
+	dims map[string]time.Time
+}
+
+func newDigestTops() []*digestTop {
+	return []*digestTop{
+		{
+			chart:  digestsLatencyChart.Copy(),
+			metric: "latency",
+			value:  func(d *statementDigest) int64 { return d.latency },
+			dims:   make(map[string]time.Time),
+		},
+		{
+			chart:  digestsRowsExaminedChart.Copy(),
+			metric: "rows_examined",
+			value:  func(d *statementDigest) int64 { return d.rowsExamined },
+			dims:   make(map[string]time.Time),
+		},
+		{
+			chart:  digestsErrorsChart.Copy(),
+			metric: "errors",
+			value:  func(d *statementDigest) int64 { return d.errors },
+			dims:   make(map[string]time.Time),
+		},
+	}
+}
+
+func (m *MySQL) collectStatementDigests(collected map[string]int64) error {
+	// https://dev.mysql.com/doc/refman/8.0/en/performance-schema-statement-summary-tables.html
+	m.Debugf("executing query: '%s'", queryStatementDigests)
+
+	rows, err := m.db.Query(queryStatementDigests)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := nullStringsFromColumns(columns)
+	digests := make(map[string]*statementDigest)
+
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		set := rowAsMap(columns, values)
+
+		d := &statementDigest{
+			schema: set["SCHEMA_NAME"],
+			text:   set["DIGEST_TEXT"],
+		}
+		if m.digestSchemaFilter != nil && !m.digestSchemaFilter.MatchString(d.schema) {
+			continue
+		}
+		if m.digestTextFilter != nil && !m.digestTextFilter.MatchString(d.text) {
+			continue
+		}
+
+		d.key = digestKey(d.schema, set["DIGEST"])
+		// timers are in picoseconds
+		d.latency = int64(parseDigestValue(set["SUM_TIMER_WAIT"]) / 1e6)
+		d.rowsExamined = int64(parseDigestValue(set["SUM_ROWS_EXAMINED"]))
+		d.errors = int64(parseDigestValue(set["SUM_ERRORS"]))
+		digests[d.key] = d
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, top := range m.digestTops {
+		m.updateDigestTop(top, digests, now)
+		for key := range top.dims {
+			collected[digestDimID(key, top.metric)] = top.value(digests[key])
+		}
+	}
+
+	m.digestsPrev = digests
+	return nil
+}
+
+func (m *MySQL) updateDigestTop(top *digestTop, digests map[string]*statementDigest, now time.Time) {
+	type ranked struct {
+		d     *statementDigest
+		delta int64
+	}
+
+	var rank []ranked
+	for key, d := range digests {
+		delta := top.value(d)
+		// no previous value or the statistics were reset (TRUNCATE TABLE)
+		if prev, ok := m.digestsPrev[key]; ok && top.value(prev) <= delta {
+			delta -= top.value(prev)
+		}
+		if delta > 0 {
+			rank = append(rank, ranked{d: d, delta: delta})
+		}
+	}
+	sort.Slice(rank, func(i, j int) bool {
+		if rank[i].delta == rank[j].delta {
+			return rank[i].d.key < rank[j].d.key
+		}
+		return rank[i].delta > rank[j].delta
+	})
+
+	for i := 0; i < len(rank) && i < m.StatementDigests.TopN; i++ {
+		d := rank[i].d
+		if _, ok := top.dims[d.key]; !ok {
+			m.addDigestDim(top, d)
+		}
+		top.dims[d.key] = now
+	}
+
+	for key, lastTop := range top.dims {
+		// a digest evicted from the summary table has no data anymore
+		if _, ok := digests[key]; !ok || now.Sub(lastTop) > m.StatementDigests.Retention.Duration {
+			delete(top.dims, key)
+			m.removeDigestDim(top, key)
+		}
+	}
+}
+
+func digestKey(schema, digest string) string {
+	if schema == "" {
+		return digest
+	}
+	return strings.ToLower(schema) + "_" + digest
+}
+
+func digestDimID(key, metric string) string {
+	return "digest_" + key + "_" + metric
+}
+
+func digestDimName(d *statementDigest) string {
+	const maxLen = 80
+	text := strings.Join(strings.Fields(d.text), " ")
+	if runes := []rune(text); len(runes) > maxLen {
+		text = string(runes[:maxLen-3]) + "..."
+	}
+	if d.schema == "" {
+		return text
+	}
+	return d.schema + ": " + text
+}
+
+func parseDigestValue(value string) uint64 {
+	v, _ := strconv.ParseUint(value, 10, 64)
+	return v
+}
@@ -0,0 +1,201 @@
+package mysql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tableStatsSystemSchemas = "('mysql', 'performance_schema', 'information_schema', 'sys')"
+
+const (
+	// https://dev.mysql.com/doc/refman/8.0/en/performance-schema-table-wait-summary-tables.html
+	queryTableIOWaits = "SELECT OBJECT_SCHEMA, OBJECT_NAME, COUNT_FETCH, COUNT_INSERT, COUNT_UPDATE, COUNT_DELETE, " +
+		"SUM_TIMER_READ, SUM_TIMER_WRITE " +
+		"FROM performance_schema.table_io_waits_summary_by_table " +
+		"WHERE OBJECT_TYPE = 'TABLE' AND OBJECT_SCHEMA NOT IN " + tableStatsSystemSchemas
+	queryTableLockWaits = "SELECT OBJECT_SCHEMA, OBJECT_NAME, SUM_TIMER_READ, SUM_TIMER_WRITE " +
+		"FROM performance_schema.table_lock_waits_summary_by_table " +
+		"WHERE OBJECT_TYPE = 'TABLE' AND OBJECT_SCHEMA NOT IN " + tableStatsSystemSchemas
+	// https://dev.mysql.com/doc/refman/8.0/en/information-schema-tables-table.html
+	queryTableSizes = "SELECT TABLE_SCHEMA AS OBJECT_SCHEMA, TABLE_NAME AS OBJECT_NAME, DATA_LENGTH, INDEX_LENGTH, DATA_FREE " +
+		"FROM information_schema.TABLES " +
+		"WHERE TABLE_TYPE = 'BASE TABLE' AND TABLE_SCHEMA NOT IN " + tableStatsSystemSchemas
+)
+
+type tableMetric struct {
+	column string
+	metric string
+	// timers are in picoseconds, they are collected in microseconds
+	timer bool
+}
+
+var (
+	tableIOWaitsMetrics = []tableMetric{
+		{column: "COUNT_FETCH", metric: "io_fetch"},
+		{column: "COUNT_INSERT", metric: "io_insert"},
+		{column: "COUNT_UPDATE", metric: "io_update"},
+		{column: "COUNT_DELETE", metric: "io_delete"},
+		{column: "SUM_TIMER_READ", metric: "io_read_latency", timer: true},
+		{column: "SUM_TIMER_WRITE", metric: "io_write_latency", timer: true},
+	}
+	tableLockWaitsMetrics = []tableMetric{
+		{column: "SUM_TIMER_READ", metric: "lock_read_wait", timer: true},
+		{column: "SUM_TIMER_WRITE", metric: "lock_write_wait", timer: true},
+	}
+	tableSizesMetrics = []tableMetric{
+		{column: "DATA_LENGTH", metric: "data_length"},
+		{column: "INDEX_LENGTH", metric: "index_length"},
+		{column: "DATA_FREE", metric: "data_free"},
+	}
+)
+
+type table struct {
+	schema string
+	name   string
+}
+
+func (t table) String() string { return t.schema + "." + t.name }
+
+func (m *MySQL) collectTableStats(collected map[string]int64) error {
+	seen := make(map[table]bool)
+
+	if err := m.collectTableMetrics(collected, queryTableIOWaits, tableIOWaitsMetrics, seen); err != nil {
+		return err
+	}
+	if err := m.collectTableMetrics(collected, queryTableLockWaits, tableLockWaitsMetrics, seen); err != nil {
+		return err
+	}
+
+	// information_schema.TABLES may be expensive to query if there are many tables
+	now := time.Now()
+	if m.tableSizes == nil || now.Sub(m.tableSizesUpdated) >= m.TableMetrics.SizeUpdateEvery.Duration {
+		sizes, tables := make(map[string]int64), make(map[table]bool)
+		if err := m.collectTableMetrics(sizes, queryTableSizes, tableSizesMetrics, tables); err != nil {
+			return err
+		}
+		m.tableSizes, m.tableSizesTables = sizes, tables
+		m.tableSizesUpdated = now
+	}
+	for k, v := range m.tableSizes {
+		collected[k] = v
+	}
+	for tbl := range m.tableSizesTables {
+		seen[tbl] = true
+	}
+	m.limitTables(collected, seen)
+
+	for tbl := range seen {
+		if !m.collectedTables[tbl] {
+			m.collectedTables[tbl] = true
+			m.addTableCharts(tbl)
+		}
+	}
+	for tbl := range m.collectedTables {
+		if !seen[tbl] {
+			delete(m.collectedTables, tbl)
+			m.removeTableCharts(tbl)
+		}
+	}
+	return nil
+}
+
+func (m *MySQL) collectTableMetrics(collected map[string]int64, query string, metrics []tableMetric, seen map[table]bool) error {
+	m.Debugf("executing query: '%s'", query)
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := nullStringsFromColumns(columns)
+
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		set := rowAsMap(columns, values)
+
+		tbl := table{schema: set["OBJECT_SCHEMA"], name: set["OBJECT_NAME"]}
+		if !m.matchTable(tbl) {
+			continue
+		}
+		seen[tbl] = true
+
+		prefix := tableMetricPrefix(tbl)
+		for _, mt := range metrics {
+			v, ok := set[mt.column]
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			if mt.timer {
+				value /= 1e6
+			}
+			collected[prefix+mt.metric] = int64(value)
+		}
+	}
+	return rows.Err()
+}
+
+func (m *MySQL) matchTable(tbl table) bool {
+	if m.tableSchemaFilter != nil && !m.tableSchemaFilter.MatchString(tbl.schema) {
+		return false
+	}
+	if m.tableNameFilter != nil && !m.tableNameFilter.MatchString(tbl.name) {
+		return false
+	}
+	return true
+}
+
+// limitTables keeps the first 'max_tables' tables ordered by schema and name,
+// the metrics of the rest of the tables are removed.
+func (m *MySQL) limitTables(collected map[string]int64, seen map[table]bool) {
+	if m.TableMetrics.MaxTables <= 0 || len(seen) <= m.TableMetrics.MaxTables {
+		return
+	}
+
+	tables := make([]table, 0, len(seen))
+	for tbl := range seen {
+		tables = append(tables, tbl)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].schema != tables[j].schema {
+			return tables[i].schema < tables[j].schema
+		}
+		return tables[i].name < tables[j].name
+	})
+
+	m.Debugf("collecting %d tables out of %d (max_tables)", m.TableMetrics.MaxTables, len(tables))
+	for _, tbl := range tables[m.TableMetrics.MaxTables:] {
+		delete(seen, tbl)
+		prefix := tableMetricPrefix(tbl)
+		for _, metrics := range [][]tableMetric{tableIOWaitsMetrics, tableLockWaitsMetrics, tableSizesMetrics} {
+			for _, mt := range metrics {
+				delete(collected, prefix+mt.metric)
+			}
+		}
+	}
+}
+
+// tableKeyReplacer escapes the schema/table separator, so 'a_b.c' and 'a.b_c' don't produce the same ids.
+// Quoted MySQL identifiers may contain any character (except NUL), so it can't be just a separator.
+var tableKeyReplacer = strings.NewReplacer("%", "%25", ".", "%2E")
+
+func tableKey(tbl table) string {
+	return tableKeyReplacer.Replace(tbl.schema) + "." + tableKeyReplacer.Replace(tbl.name)
+}
+
+func tableMetricPrefix(tbl table) string {
+	return "table_" + tableKey(tbl) + "_"
+}
@@ -2,12 +2,16 @@ package mysql
 
 import (
 	"database/sql"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/blang/semver/v4"
 	_ "github.com/go-sql-driver/mysql"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
 )
 
 func init() {
@@ -18,9 +22,25 @@ func init() {
 
 type (
 	Config struct {
-		DSN         string `yaml:"dsn"`
-		MyCNF       string `yaml:"my.cnf"`
-		UpdateEvery int    `yaml:"update_every"`
+		DSN              string                 `yaml:"dsn"`
+		MyCNF            string                 `yaml:"my.cnf"`
+		UpdateEvery      int                    `yaml:"update_every"`
+		StatementDigests statementDigestsConfig `yaml:"statement_digests"`
+		TableMetrics     tableMetricsConfig     `yaml:"table_metrics"`
+	}
+	statementDigestsConfig struct {
+		Enabled      bool               `yaml:"enabled"`
+		TopN         int                `yaml:"top_n"`
+		Retention    web.Duration       `yaml:"retention"`
+		SchemaFilter matcher.SimpleExpr `yaml:"schema_filter"`
+		DigestFilter matcher.SimpleExpr `yaml:"digest_filter"`
+	}
+	tableMetricsConfig struct {
+		Enabled         bool               `yaml:"enabled"`
+		SizeUpdateEvery web.Duration       `yaml:"size_update_every"`
+		MaxTables       int                `yaml:"max_tables"`
+		SchemaFilter    matcher.SimpleExpr `yaml:"schema_filter"`
+		TableFilter     matcher.SimpleExpr `yaml:"table_filter"`
 	}
 	MySQL struct {
 		module.Base
@@ -30,16 +50,34 @@ type (
 		isMariaDB bool
 		version   *semver.Version
 
-		addInnodbDeadlocksOnce *sync.Once
-		addGaleraOnce          *sync.Once
-		addQCacheOnce          *sync.Once
-		addUserStatsCPUOnce    *sync.Once
+		addInnodbDeadlocksOnce  *sync.Once
+		addGaleraOnce           *sync.Once
+		addQCacheOnce           *sync.Once
+		addUserStatsCPUOnce     *sync.Once
+		addGroupReplicationOnce *sync.Once
 
 		doSlaveStatus      bool
 		collectedReplConns map[string]bool
 		doUserStatistics   bool
 		collectedUsers     map[string]bool
 
+		doStatementDigests bool
+		digestSchemaFilter matcher.Matcher
+		digestTextFilter   matcher.Matcher
+		digestsPrev        map[string]*statementDigest
+		digestTops         []*digestTop
+
+		doGroupReplication bool
+		collectedGRMembers map[string]bool
+
+		doTableMetrics    bool
+		tableSchemaFilter matcher.Matcher
+		tableNameFilter   matcher.Matcher
+		collectedTables   map[table]bool
+		tableSizes        map[string]int64
+		tableSizesTables  map[table]bool
+		tableSizesUpdated time.Time
+
 		charts *Charts
 	}
 )
@@ -48,17 +86,30 @@ func New() *MySQL {
 	return &MySQL{
 		Config: Config{
 			DSN: "root@tcp(localhost:3306)/",
+			StatementDigests: statementDigestsConfig{
+				TopN:      10,
+				Retention: web.Duration{Duration: time.Minute * 10},
+			},
+			TableMetrics: tableMetricsConfig{
+				SizeUpdateEvery: web.Duration{Duration: time.Minute},
+				MaxTables:       50,
+			},
 		},
 
-		charts:                 charts.Copy(),
-		addInnodbDeadlocksOnce: &sync.Once{},
-		addGaleraOnce:          &sync.Once{},
-		addQCacheOnce:          &sync.Once{},
-		addUserStatsCPUOnce:    &sync.Once{},
-		doSlaveStatus:          true,
-		doUserStatistics:       true,
-		collectedReplConns:     make(map[string]bool),
-		collectedUsers:         make(map[string]bool),
+		charts:                  charts.Copy(),
+		addInnodbDeadlocksOnce:  &sync.Once{},
+		addGaleraOnce:           &sync.Once{},
+		addQCacheOnce:           &sync.Once{},
+		addUserStatsCPUOnce:     &sync.Once{},
+		addGroupReplicationOnce: &sync.Once{},
+		doSlaveStatus:           true,
+		doUserStatistics:        true,
+		collectedReplConns:      make(map[string]bool),
+		collectedUsers:          make(map[string]bool),
+		digestsPrev:             make(map[string]*statementDigest),
+		digestTops:              newDigestTops(),
+		collectedGRMembers:      make(map[string]bool),
+		collectedTables:         make(map[table]bool),
 	}
 }
 
@@ -87,10 +138,41 @@ func (m *MySQL) Init() bool {
 		return false
 	}
 
+	if err := m.initStatementDigests(); err != nil {
+		m.Errorf("error on statement digests initialization: %v", err)
+		return false
+	}
+
+	if err := m.initTableMetrics(); err != nil {
+		m.Errorf("error on table metrics initialization: %v", err)
+		return false
+	}
+
 	m.Debugf("using DSN [%s]", m.DSN)
 	return true
 }
 
+func (m *MySQL) initStatementDigests() error {
+	cfg := m.StatementDigests
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.TopN <= 0 {
+		return errors.New("'top_n' must be positive")
+	}
+
+	var err error
+	if m.digestSchemaFilter, err = cfg.SchemaFilter.Parse(); err != nil && err != matcher.ErrEmptyExpr {
+		return err
+	}
+	if m.digestTextFilter, err = cfg.DigestFilter.Parse(); err != nil && err != matcher.ErrEmptyExpr {
+		return err
+	}
+
+	m.doStatementDigests = true
+	return nil
+}
+
 func (m *MySQL) Check() bool {
 	return len(m.Collect()) > 0
 }
@@ -110,3 +192,21 @@ func (m *MySQL) Collect() map[string]int64 {
 	}
 	return mx
 }
+
+func (m *MySQL) initTableMetrics() error {
+	cfg := m.TableMetrics
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var err error
+	if m.tableSchemaFilter, err = cfg.SchemaFilter.Parse(); err != nil && err != matcher.ErrEmptyExpr {
+		return err
+	}
+	if m.tableNameFilter, err = cfg.TableFilter.Parse(); err != nil && err != matcher.ErrEmptyExpr {
+		return err
+	}
+
+	m.doTableMetrics = true
+	return nil
+}
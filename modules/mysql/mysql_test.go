@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/blang/semver/v4"
@@ -34,6 +37,15 @@ var (
 	mysqlV8021GlobalStatus, _    = ioutil.ReadFile("testdata/mysql/v8.0.21/global_status.txt")
 	mysqlV8021GlobalVariables, _ = ioutil.ReadFile("testdata/mysql/v8.0.21/global_variables.txt")
 	mysqlV8021SlaveStatus, _     = ioutil.ReadFile("testdata/mysql/v8.0.21/slave_status.txt")
+	mysqlV8021StmtDigests, _     = ioutil.ReadFile("testdata/mysql/v8.0.21/statement_digests.txt")
+	mysqlV8021GroupRepl, _       = ioutil.ReadFile("testdata/mysql/v8.0.21/group_replication.txt")
+	mysqlV8021TableIOWaits, _    = ioutil.ReadFile("testdata/mysql/v8.0.21/table_io_waits.txt")
+	mysqlV8021TableLockWaits, _  = ioutil.ReadFile("testdata/mysql/v8.0.21/table_lock_waits.txt")
+	mysqlV8021TableSizes, _      = ioutil.ReadFile("testdata/mysql/v8.0.21/table_sizes.txt")
+)
+
+var (
+	mysqlV8021QueryGroupRepl = regexp.QuoteMeta(queryGroupReplication(&semver.Version{Major: 8, Minor: 0, Patch: 21}))
 )
 
 var (
@@ -57,6 +69,11 @@ func Test_testDataIsValid(t *testing.T) {
 		"mysqlV8021GlobalStatus":    mysqlV8021GlobalStatus,
 		"mysqlV8021GlobalVariables": mysqlV8021GlobalVariables,
 		"mysqlV8021SlaveStatus":     mysqlV8021SlaveStatus,
+		"mysqlV8021StmtDigests":     mysqlV8021StmtDigests,
+		"mysqlV8021GroupRepl":       mysqlV8021GroupRepl,
+		"mysqlV8021TableIOWaits":    mysqlV8021TableIOWaits,
+		"mysqlV8021TableLockWaits":  mysqlV8021TableLockWaits,
+		"mysqlV8021TableSizes":      mysqlV8021TableSizes,
 	} {
 		require.NotNilf(t, data, fmt.Sprintf("read data: %s", name))
 		_, err := prepareMockRows(data)
@@ -77,6 +94,64 @@ func TestMySQL_Init(t *testing.T) {
 			config:   Config{DSN: ""},
 			wantFail: true,
 		},
+		"statement digests": {
+			config: Config{
+				DSN: "root@tcp(localhost:3306)/",
+				StatementDigests: statementDigestsConfig{
+					Enabled:      true,
+					TopN:         5,
+					SchemaFilter: matcher.SimpleExpr{Excludes: []string{"* sys", "* performance_schema"}},
+				},
+			},
+		},
+		"statement digests zero top_n": {
+			config: Config{
+				DSN:              "root@tcp(localhost:3306)/",
+				StatementDigests: statementDigestsConfig{Enabled: true},
+			},
+			wantFail: true,
+		},
+		"statement digests bad filter": {
+			config: Config{
+				DSN: "root@tcp(localhost:3306)/",
+				StatementDigests: statementDigestsConfig{
+					Enabled:      true,
+					TopN:         5,
+					DigestFilter: matcher.SimpleExpr{Includes: []string{"~ (SELECT"}},
+				},
+			},
+			wantFail: true,
+		},
+		"table metrics": {
+			config: Config{
+				DSN: "root@tcp(localhost:3306)/",
+				TableMetrics: tableMetricsConfig{
+					Enabled:      true,
+					SchemaFilter: matcher.SimpleExpr{Includes: []string{"* shop"}},
+					TableFilter:  matcher.SimpleExpr{Excludes: []string{"* *_tmp"}},
+				},
+			},
+		},
+		"table metrics bad schema filter": {
+			config: Config{
+				DSN: "root@tcp(localhost:3306)/",
+				TableMetrics: tableMetricsConfig{
+					Enabled:      true,
+					SchemaFilter: matcher.SimpleExpr{Includes: []string{"~ (shop"}},
+				},
+			},
+			wantFail: true,
+		},
+		"table metrics bad table filter": {
+			config: Config{
+				DSN: "root@tcp(localhost:3306)/",
+				TableMetrics: tableMetricsConfig{
+					Enabled:     true,
+					TableFilter: matcher.SimpleExpr{Excludes: []string{"bad_matcher"}},
+				},
+			},
+			wantFail: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -747,6 +822,9 @@ func TestMySQL_Collect(t *testing.T) {
 					WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
 				mock.ExpectQuery(querySlaveStatus).
 					WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+				mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+					WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE"}).
+						AddRow("", "", "", "OFFLINE"))
 
 				return mySQL, mock, cleanup
 			},
@@ -878,6 +956,415 @@ func TestMySQL_Collect(t *testing.T) {
 	}
 }
 
+func TestMySQL_Collect_StatementDigests(t *testing.T) {
+	const (
+		orders = "digest_shop_4a2ca1e5b5c7bd4b3e8a36b3e2b0fdf7c6b8e4a1a0e6c7f0a3b2e0d7f5b4c3a1_"
+		stock  = "digest_shop_9b1d7a3f0c2e4b6a8d0f2e4c6a8b0d2f4e6a8c0b2d4f6e8a0c2b4d6f8e0a2c4b_"
+		audit  = "digest_shop_7c5a3e1f9d7b5c3a1e9f7d5b3c1a9e7f5d3b1c9a7e5f3d1b9c7a5e3f1d9b7c5a_"
+		sys    = "digest_sys_1f3e5d7c9b1a3f5e7d9c1b3a5f7e9d1c3b5a7f9e1d3c5b7a9f1e3d5c7b9a1f3e_"
+	)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mySQL := New()
+	mySQL.DSN = "root@tcp(localhost:3306)/"
+	mySQL.StatementDigests.Enabled = true
+	mySQL.StatementDigests.TopN = 2
+	mySQL.StatementDigests.SchemaFilter = matcher.SimpleExpr{Excludes: []string{"* sys"}}
+	require.True(t, mySQL.Init())
+	mySQL.db = db
+
+	mock.ExpectQuery(queryVersion).
+		WillReturnRows(mustMockRows(t, mysqlV8021Version))
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID"}))
+	mock.ExpectQuery(queryStatementDigests).
+		WillReturnRows(mustMockRows(t, mysqlV8021StmtDigests))
+
+	collected := mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := map[string]int64{
+		orders + "latency":       9183520,
+		stock + "latency":        2200000,
+		orders + "rows_examined": 1520000,
+		stock + "rows_examined":  8300,
+		stock + "errors":         12,
+		audit + "errors":         3,
+	}
+	for id, value := range expected {
+		assert.Equalf(t, value, collected[id], "metric '%s'", id)
+	}
+	for id := range collected {
+		if strings.HasPrefix(id, "digest_") {
+			_, ok := expected[id]
+			assert.Truef(t, ok, "unexpected metric '%s'", id)
+		}
+	}
+	assert.NotContains(t, collected, sys+"latency")
+	ensureCollectedHasAllChartsDimsVarsIDs(t, mySQL, collected)
+
+	chart := mySQL.Charts().Get(digestsLatencyChart.ID)
+	require.NotNil(t, chart)
+	dim := chart.GetDim(orders + "latency")
+	require.NotNil(t, dim)
+	assert.Equal(t, "shop: SELECT * FROM `orders` WHERE `customer_id` = ? ORDER BY `created_at` DESC", dim.Name)
+	assert.Equal(t, module.Incremental, dim.Algo)
+
+	// the audit_log digest latency increases the most: it enters the top-2, while the stock digest
+	// stays on the chart until it has been out of the top-2 for the retention period.
+	columns := []string{"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "SUM_TIMER_WAIT", "SUM_ROWS_EXAMINED", "SUM_ERRORS"}
+	digestRows := func(ordersLatency, auditLatency string) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow("shop", "4a2ca1e5b5c7bd4b3e8a36b3e2b0fdf7c6b8e4a1a0e6c7f0a3b2e0d7f5b4c3a1", "SELECT 1", ordersLatency, "1520100", "0").
+			AddRow("shop", "9b1d7a3f0c2e4b6a8d0f2e4c6a8b0d2f4e6a8c0b2d4f6e8a0c2b4d6f8e0a2c4b", "UPDATE 1", "2200000000000", "8300", "12").
+			AddRow("shop", "7c5a3e1f9d7b5c3a1e9f7d5b3c1a9e7f5d3b1c9a7e5f3d1b9c7a5e3f1d9b7c5a", "INSERT 1", auditLatency, "0", "3")
+	}
+	prepareDigestsOnly := func(ordersLatency, auditLatency string) {
+		mock.ExpectQuery(queryGlobalStatus).
+			WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+		mock.ExpectQuery(queryGlobalVariables).
+			WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+		mock.ExpectQuery(querySlaveStatus).
+			WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+		mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+			WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID"}))
+		mock.ExpectQuery(queryStatementDigests).
+			WillReturnRows(digestRows(ordersLatency, auditLatency))
+	}
+
+	prepareDigestsOnly("9283520000000", "5730000000000")
+	collected = mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(5730000), collected[audit+"latency"])
+	assert.Equal(t, int64(2200000), collected[stock+"latency"], "stock is kept within the retention period")
+	assert.True(t, chart.HasDim(audit+"latency"))
+
+	mySQL.StatementDigests.Retention.Duration = 0
+	for _, top := range mySQL.digestTops {
+		for key := range top.dims {
+			top.dims[key] = top.dims[key].Add(-time.Second)
+		}
+	}
+	prepareDigestsOnly("9383520000000", "5830000000000")
+	collected = mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.NotContains(t, collected, stock+"latency", "stock is removed after the retention period")
+	assert.Contains(t, collected, orders+"latency")
+	assert.Contains(t, collected, audit+"latency")
+	assert.NotContains(t, collected, audit+"rows_examined", "no rows examined increase")
+	assert.True(t, chart.GetDim(stock+"latency").Obsolete)
+}
+
+func TestDigestDimName(t *testing.T) {
+	tests := map[string]struct {
+		digest   statementDigest
+		expected string
+	}{
+		"short text": {
+			digest:   statementDigest{text: "SELECT  *\n FROM t"},
+			expected: "SELECT * FROM t",
+		},
+		"with schema": {
+			digest:   statementDigest{schema: "shop", text: "SELECT 1"},
+			expected: "shop: SELECT 1",
+		},
+		"long text": {
+			digest:   statementDigest{text: strings.Repeat("a", 100)},
+			expected: strings.Repeat("a", 77) + "...",
+		},
+		"long multi-byte text": {
+			digest:   statementDigest{text: strings.Repeat("ä", 100)},
+			expected: strings.Repeat("ä", 77) + "...",
+		},
+		"multi-byte text within limit": {
+			digest:   statementDigest{text: strings.Repeat("ä", 80)},
+			expected: strings.Repeat("ä", 80),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, digestDimName(&test.digest))
+		})
+	}
+}
+
+func TestMySQL_Collect_GroupReplication(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mySQL := New()
+	mySQL.db = db
+
+	mock.ExpectQuery(queryVersion).
+		WillReturnRows(mustMockRows(t, mysqlV8021Version))
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(mustMockRows(t, mysqlV8021GroupRepl))
+
+	collected := mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := map[string]int64{
+		"group_replication_members_online":      2,
+		"group_replication_members_recovering":  1,
+		"group_replication_members_offline":     0,
+		"group_replication_members_error":       0,
+		"group_replication_members_unreachable": 0,
+
+		"group_replication_mysql-1_3306_member_state_online":                  1,
+		"group_replication_mysql-1_3306_member_state_recovering":              0,
+		"group_replication_mysql-1_3306_member_role_primary":                  1,
+		"group_replication_mysql-1_3306_member_role_secondary":                0,
+		"group_replication_mysql-1_3306_transactions_checked":                 18740,
+		"group_replication_mysql-1_3306_conflicts_detected":                   2,
+		"group_replication_mysql-1_3306_transactions_local_proposed":          18740,
+		"group_replication_mysql-1_3306_transactions_local_rollback":          2,
+		"group_replication_mysql-2_3306_member_role_secondary":                1,
+		"group_replication_mysql-2_3306_transactions_remote_applied":          18728,
+		"group_replication_mysql-3_3306_member_state_online":                  0,
+		"group_replication_mysql-3_3306_member_state_recovering":              1,
+		"group_replication_mysql-3_3306_transactions_in_queue":                4,
+		"group_replication_mysql-3_3306_transactions_remote_in_applier_queue": 1620,
+		"group_replication_mysql-3_3306_transactions_rows_validating":         298,
+	}
+	for id, value := range expected {
+		assert.Equalf(t, value, collected[id], "metric '%s'", id)
+	}
+	ensureCollectedHasAllChartsDimsVarsIDs(t, mySQL, collected)
+
+	assert.True(t, mySQL.Charts().Has(groupReplicationMembersChart.ID))
+	assert.True(t, mySQL.Charts().Has("group_replication_member_role_mysql-3_3306"))
+
+	// mysql-3 has left the group
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE"}).
+			AddRow("7c2e6b1a-0f3d-11eb-8a5f-0242ac120002", "mysql-1", "3306", "ONLINE").
+			AddRow("8d3f7c2b-0f3d-11eb-9b6a-0242ac120003", "mysql-2", "3306", "ONLINE"))
+
+	collected = mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(2), collected["group_replication_members_online"])
+	assert.Equal(t, int64(0), collected["group_replication_members_recovering"])
+	assert.NotContains(t, collected, "group_replication_mysql-3_3306_member_state_recovering")
+	for _, chart := range newGroupReplicationMemberCharts("mysql-3:3306", true) {
+		assert.Truef(t, mySQL.Charts().Get(chart.ID).Obsolete, "chart '%s' is not removed", chart.ID)
+	}
+	assert.False(t, mySQL.Charts().Get("group_replication_member_state_mysql-1_3306").Obsolete)
+}
+
+func TestMySQL_Collect_TableMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mySQL := New()
+	mySQL.DSN = "root@tcp(localhost:3306)/"
+	mySQL.TableMetrics.Enabled = true
+	mySQL.TableMetrics.SchemaFilter = matcher.SimpleExpr{Excludes: []string{"* staging"}}
+	require.True(t, mySQL.Init())
+	mySQL.db = db
+
+	mock.ExpectQuery(queryVersion).
+		WillReturnRows(mustMockRows(t, mysqlV8021Version))
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableIOWaits)).
+		WillReturnRows(mustMockRows(t, mysqlV8021TableIOWaits))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableLockWaits)).
+		WillReturnRows(mustMockRows(t, mysqlV8021TableLockWaits))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableSizes)).
+		WillReturnRows(mustMockRows(t, mysqlV8021TableSizes))
+
+	collected := mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := map[string]int64{
+		"table_shop.orders_io_fetch":         482100,
+		"table_shop.orders_io_insert":        12050,
+		"table_shop.orders_io_update":        3400,
+		"table_shop.orders_io_delete":        120,
+		"table_shop.orders_io_read_latency":  91835,
+		"table_shop.orders_io_write_latency": 22001,
+		"table_shop.orders_lock_read_wait":   1250,
+		"table_shop.orders_lock_write_wait":  870,
+		"table_shop.orders_data_length":      52428800,
+		"table_shop.orders_index_length":     10485760,
+		"table_shop.orders_data_free":        4194304,
+		"table_shop.stock_io_fetch":          98000,
+		"table_shop.stock_io_insert":         0,
+		"table_shop.stock_io_update":         45600,
+		"table_shop.stock_io_delete":         0,
+		"table_shop.stock_io_read_latency":   5400,
+		"table_shop.stock_io_write_latency":  31200,
+		"table_shop.stock_lock_read_wait":    310,
+		"table_shop.stock_lock_write_wait":   4120,
+		"table_shop.stock_data_length":       1572864,
+		"table_shop.stock_index_length":      524288,
+		"table_shop.stock_data_free":         0,
+	}
+	for id, value := range expected {
+		assert.Equalf(t, value, collected[id], "metric '%s'", id)
+	}
+	for id := range collected {
+		if strings.HasPrefix(id, "table_") && strings.Contains(id, ".") {
+			_, ok := expected[id]
+			assert.Truef(t, ok, "unexpected metric '%s'", id)
+		}
+	}
+	ensureCollectedHasAllChartsDimsVarsIDs(t, mySQL, collected)
+	assert.True(t, mySQL.Charts().Has("table_size_shop.orders"))
+	assert.False(t, mySQL.Charts().Has("table_size_staging.import_tmp"))
+
+	// table sizes are cached for 'size_update_every', the stock table is dropped
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableIOWaits)).
+		WillReturnRows(sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "COUNT_FETCH"}).
+			AddRow("shop", "orders", "482200"))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableLockWaits)).
+		WillReturnRows(sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "SUM_TIMER_READ"}).
+			AddRow("shop", "orders", "1350000000"))
+
+	collected = mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(482200), collected["table_shop.orders_io_fetch"])
+	assert.Equal(t, int64(1350), collected["table_shop.orders_lock_read_wait"])
+	assert.Equal(t, int64(52428800), collected["table_shop.orders_data_length"])
+	assert.False(t, mySQL.Charts().Get("table_size_shop.stock").Obsolete, "stock is still in the cached sizes")
+
+	mySQL.tableSizesUpdated = time.Now().Add(-mySQL.TableMetrics.SizeUpdateEvery.Duration)
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableIOWaits)).
+		WillReturnRows(sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "COUNT_FETCH"}).
+			AddRow("shop", "orders", "482300"))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableLockWaits)).
+		WillReturnRows(sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "SUM_TIMER_READ"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableSizes)).
+		WillReturnRows(sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "DATA_LENGTH"}).
+			AddRow("shop", "orders", "53477376"))
+
+	collected = mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(53477376), collected["table_shop.orders_data_length"])
+	assert.NotContains(t, collected, "table_shop.stock_data_length")
+	for _, chart := range newTableCharts(table{schema: "shop", name: "stock"}) {
+		assert.Truef(t, mySQL.Charts().Get(chart.ID).Obsolete, "chart '%s' is not removed", chart.ID)
+	}
+	assert.False(t, mySQL.Charts().Get("table_size_shop.orders").Obsolete)
+}
+
+func TestMySQL_Collect_TableMetrics_MaxTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mySQL := New()
+	mySQL.DSN = "root@tcp(localhost:3306)/"
+	mySQL.TableMetrics.Enabled = true
+	mySQL.TableMetrics.MaxTables = 1
+	require.True(t, mySQL.Init())
+	mySQL.db = db
+
+	mock.ExpectQuery(queryVersion).
+		WillReturnRows(mustMockRows(t, mysqlV8021Version))
+	mock.ExpectQuery(queryGlobalStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalStatus))
+	mock.ExpectQuery(queryGlobalVariables).
+		WillReturnRows(mustMockRows(t, mysqlV8021GlobalVariables))
+	mock.ExpectQuery(querySlaveStatus).
+		WillReturnRows(mustMockRows(t, mysqlV8021SlaveStatus))
+	mock.ExpectQuery(mysqlV8021QueryGroupRepl).
+		WillReturnRows(sqlmock.NewRows([]string{"MEMBER_ID"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableIOWaits)).
+		WillReturnRows(mustMockRows(t, mysqlV8021TableIOWaits))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableLockWaits)).
+		WillReturnRows(mustMockRows(t, mysqlV8021TableLockWaits))
+	mock.ExpectQuery(regexp.QuoteMeta(queryTableSizes)).
+		WillReturnRows(mustMockRows(t, mysqlV8021TableSizes))
+
+	collected := mySQL.Collect()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	for id := range collected {
+		if strings.HasPrefix(id, "table_") && strings.Contains(id, ".") {
+			assert.Truef(t, strings.HasPrefix(id, "table_shop.orders_"), "unexpected metric '%s'", id)
+		}
+	}
+	assert.Equal(t, int64(482100), collected["table_shop.orders_io_fetch"])
+	assert.True(t, mySQL.Charts().Has("table_size_shop.orders"))
+	assert.False(t, mySQL.Charts().Has("table_size_shop.stock"))
+	assert.False(t, mySQL.Charts().Has("table_size_staging.import_tmp"))
+	ensureCollectedHasAllChartsDimsVarsIDs(t, mySQL, collected)
+}
+
+func TestTableMetricPrefix(t *testing.T) {
+	tests := map[string]struct {
+		tables []table
+	}{
+		"underscore in schema and table": {
+			tables: []table{{schema: "a_b", name: "c"}, {schema: "a", name: "b_c"}},
+		},
+		"dot in schema and table": {
+			tables: []table{{schema: "a.b", name: "c"}, {schema: "a", name: "b.c"}},
+		},
+		"escaped dot": {
+			tables: []table{{schema: "a%2E", name: "b"}, {schema: "a.", name: "b"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEqual(t, tableMetricPrefix(test.tables[0]), tableMetricPrefix(test.tables[1]))
+		})
+	}
+}
+
 func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, mySQL *MySQL, collected map[string]int64) {
 	for _, chart := range *mySQL.Charts() {
 		// https://mariadb.com/kb/en/server-status-variables/#connection_errors_accept
@@ -0,0 +1,99 @@
+package ping
+
+import (
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+type (
+	// Charts is an alias for module.Charts
+	Charts = module.Charts
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
+	// Dims is an alias for module.Dims
+	Dims = module.Dims
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
+)
+
+var hostCharts = Charts{
+	{
+		ID:    "host_%s_rtt",
+		Title: "Ping Round-Trip Time",
+		Units: "milliseconds",
+		Ctx:   "ping.host_rtt",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "host_%s_min_rtt", Name: "min", Div: 1e3},
+			{ID: "host_%s_max_rtt", Name: "max", Div: 1e3},
+			{ID: "host_%s_avg_rtt", Name: "avg", Div: 1e3},
+		},
+	},
+	{
+		ID:    "host_%s_std_dev_rtt",
+		Title: "Ping Round-Trip Time Standard Deviation",
+		Units: "milliseconds",
+		Ctx:   "ping.host_std_dev_rtt",
+		Dims: Dims{
+			{ID: "host_%s_std_dev_rtt", Name: "std_dev", Div: 1e3},
+		},
+	},
+	{
+		ID:    "host_%s_packet_loss",
+		Title: "Ping Packet Loss",
+		Units: "percentage",
+		Ctx:   "ping.host_packet_loss",
+		Dims: Dims{
+			{ID: "host_%s_packet_loss", Name: "loss", Div: 1e3},
+		},
+	},
+	{
+		ID:    "host_%s_packets",
+		Title: "Ping Packets",
+		Units: "packets",
+		Ctx:   "ping.host_packets",
+		Dims: Dims{
+			{ID: "host_%s_packets_received", Name: "received"},
+			{ID: "host_%s_packets_sent", Name: "sent"},
+		},
+	},
+	{
+		ID:    "host_%s_rtt_distribution",
+		Title: "Ping Round-Trip Time Histogram",
+		Units: "packets/s",
+		Ctx:   "ping.host_rtt_distribution",
+	},
+}
+
+func newHostCharts(host string, histogram []float64) (*Charts, error) {
+	cs := hostCharts.Copy()
+	hist := cs.Get("host_%s_rtt_distribution")
+	for i, v := range histogram {
+		dim := &Dim{
+			ID:   fmt.Sprintf("host_%%s_rtt_hist_bucket_%d", i+1),
+			Name: fmt.Sprintf("%.3f", v),
+			Algo: module.Incremental,
+		}
+		if err := hist.AddDim(dim); err != nil {
+			return nil, err
+		}
+	}
+	if err := hist.AddDim(&Dim{
+		ID:   "host_%s_rtt_hist_count",
+		Name: "+Inf",
+		Algo: module.Incremental,
+	}); err != nil {
+		return nil, err
+	}
+
+	id := cleanHost(host)
+	for _, chart := range *cs {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Fam = host
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+	return cs, nil
+}
@@ -0,0 +1,95 @@
+package ping
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/metrics"
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+type hostMetrics struct {
+	MinRTT          *int64 `stm:"min_rtt"`
+	MaxRTT          *int64 `stm:"max_rtt"`
+	AvgRTT          *int64 `stm:"avg_rtt"`
+	StdDevRTT       *int64 `stm:"std_dev_rtt"`
+	PacketsSent     int64  `stm:"packets_sent"`
+	PacketsReceived int64  `stm:"packets_received"`
+	PacketLoss      int64  `stm:"packet_loss"`
+
+	RTTHist metrics.Histogram `stm:"rtt_hist"`
+}
+
+func (p *Ping) collect() (map[string]int64, error) {
+	stats := make([]*pingStats, len(p.Hosts))
+	wg := &sync.WaitGroup{}
+
+	for i, host := range p.Hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			s, err := p.pinger.ping(host)
+			if err != nil {
+				p.Warningf("host '%s': %v", host, err)
+				s = &pingStats{sent: p.Packets}
+			}
+			stats[i] = s
+		}(i, host)
+	}
+	wg.Wait()
+
+	mx := make(map[string]int64)
+	for i, host := range p.Hosts {
+		p.collectHost(mx, host, stats[i])
+	}
+	return mx, nil
+}
+
+func (p *Ping) collectHost(mx map[string]int64, host string, s *pingStats) {
+	hm := hostMetrics{
+		PacketsSent:     int64(s.sent),
+		PacketsReceived: int64(len(s.rtts)),
+		RTTHist:         p.histograms[host],
+	}
+	if s.sent > 0 {
+		hm.PacketLoss = int64(float64(s.sent-len(s.rtts)) / float64(s.sent) * 100 * 1000)
+	}
+
+	if len(s.rtts) > 0 {
+		min, max, avg, stdDev := rttStats(s.rtts)
+		hm.MinRTT, hm.MaxRTT, hm.AvgRTT, hm.StdDevRTT = &min, &max, &avg, &stdDev
+		for _, rtt := range s.rtts {
+			hm.RTTHist.Observe(float64(rtt.Microseconds()))
+		}
+	}
+
+	prefix := "host_" + cleanHost(host)
+	for k, v := range stm.ToMap(hm) {
+		mx[prefix+"_"+k] = v
+	}
+}
+
+// rttStats returns min, max, avg and standard deviation of the round-trip times in microseconds.
+func rttStats(rtts []time.Duration) (min, max, avg, stdDev int64) {
+	min, max = math.MaxInt64, math.MinInt64
+	var sum float64
+	for _, rtt := range rtts {
+		v := rtt.Microseconds()
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		sum += float64(v)
+	}
+	mean := sum / float64(len(rtts))
+
+	var sqSum float64
+	for _, rtt := range rtts {
+		d := float64(rtt.Microseconds()) - mean
+		sqSum += d * d
+	}
+	return min, max, int64(mean), int64(math.Sqrt(sqSum / float64(len(rtts))))
+}
@@ -0,0 +1,77 @@
+package ping
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (p Ping) validateConfig() error {
+	if len(p.Hosts) == 0 {
+		return errors.New("'hosts' not set")
+	}
+	seen := make(map[string]bool)
+	for _, host := range p.Hosts {
+		if host == "" {
+			return errors.New("'hosts' contains an empty host")
+		}
+		if seen[host] {
+			return fmt.Errorf("'hosts' contains duplicate host '%s'", host)
+		}
+		seen[host] = true
+	}
+	if p.Packets <= 0 {
+		return fmt.Errorf("'packets' must be positive (%d)", p.Packets)
+	}
+	if p.Interval.Duration <= 0 {
+		return fmt.Errorf("'interval' must be positive (%s)", p.Interval)
+	}
+	if p.Timeout.Duration <= 0 {
+		return fmt.Errorf("'timeout' must be positive (%s)", p.Timeout)
+	}
+	if len(p.Histogram) == 0 {
+		return errors.New("'histogram' not set")
+	}
+	for i, v := range p.Histogram {
+		if v <= 0 || (i > 0 && v <= p.Histogram[i-1]) {
+			return errors.New("'histogram' buckets must be positive and sorted in increasing order")
+		}
+	}
+	return nil
+}
+
+func (p *Ping) initPinger() (pinger, error) {
+	cfg := icmpPingerConfig{
+		privileged: p.Privileged,
+		packets:    p.Packets,
+		interval:   p.Interval.Duration,
+		timeout:    p.Timeout.Duration,
+	}
+	return p.newPinger(cfg)
+}
+
+func (p *Ping) initCharts() error {
+	for _, host := range p.Hosts {
+		charts, err := newHostCharts(host, p.Histogram)
+		if err != nil {
+			return err
+		}
+		if err := p.charts.Add(*charts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func histogramToMicroseconds(histogram []float64) []float64 {
+	var buckets []float64
+	for _, value := range histogram {
+		buckets = append(buckets, value*1e3)
+	}
+	return buckets
+}
+
+func cleanHost(host string) string {
+	r := strings.NewReplacer(".", "_", ":", "_", " ", "_", "%", "_")
+	return r.Replace(host)
+}
@@ -0,0 +1,126 @@
+package ping
+
+import (
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/metrics"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	creator := module.Creator{
+		Defaults: module.Defaults{
+			UpdateEvery: 5,
+		},
+		Create: func() module.Module { return New() },
+	}
+
+	module.Register("ping", creator)
+}
+
+// New creates Ping with default values.
+func New() *Ping {
+	config := Config{
+		Packets:   5,
+		Interval:  web.Duration{Duration: time.Millisecond * 100},
+		Timeout:   web.Duration{Duration: time.Second},
+		Histogram: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+	}
+	return &Ping{
+		Config:     config,
+		charts:     &module.Charts{},
+		histograms: make(map[string]metrics.Histogram),
+		newPinger:  newICMPPinger,
+	}
+}
+
+// Config is the Ping module configuration.
+type Config struct {
+	Hosts      []string     `yaml:"hosts"`
+	Packets    int          `yaml:"packets"`
+	Interval   web.Duration `yaml:"interval"`
+	Timeout    web.Duration `yaml:"timeout"`
+	Privileged bool         `yaml:"privileged"`
+	Histogram  []float64    `yaml:"histogram"`
+}
+
+type pinger interface {
+	ping(host string) (*pingStats, error)
+}
+
+type icmpPingerConfig struct {
+	privileged bool
+	packets    int
+	interval   time.Duration
+	timeout    time.Duration
+}
+
+type pingStats struct {
+	sent int
+	rtts []time.Duration
+}
+
+// Ping Ping module.
+type Ping struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	charts *module.Charts
+
+	newPinger  func(icmpPingerConfig) (pinger, error)
+	pinger     pinger
+	histograms map[string]metrics.Histogram
+}
+
+// Cleanup makes cleanup.
+func (Ping) Cleanup() {}
+
+// Init makes initialization.
+func (p *Ping) Init() bool {
+	if err := p.validateConfig(); err != nil {
+		p.Errorf("config validation: %v", err)
+		return false
+	}
+
+	pr, err := p.initPinger()
+	if err != nil {
+		p.Errorf("init pinger: %v", err)
+		return false
+	}
+	p.pinger = pr
+
+	for _, host := range p.Hosts {
+		p.histograms[host] = metrics.NewHistogram(histogramToMicroseconds(p.Histogram))
+	}
+
+	if err := p.initCharts(); err != nil {
+		p.Errorf("init charts: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// Check makes check.
+func (p *Ping) Check() bool {
+	return len(p.Collect()) > 0
+}
+
+// Charts returns Charts.
+func (p *Ping) Charts() *module.Charts {
+	return p.charts
+}
+
+// Collect collects metrics.
+func (p *Ping) Collect() map[string]int64 {
+	mx, err := p.collect()
+	if err != nil {
+		p.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
@@ -0,0 +1,217 @@
+package ping
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestPing_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   func(*Config)
+		wantFail bool
+	}{
+		"success on default config with hosts": {
+			config: func(cfg *Config) {},
+		},
+		"fails when hosts not set": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Hosts = nil },
+		},
+		"fails on duplicate hosts": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Hosts = []string{"127.0.0.1", "127.0.0.1"} },
+		},
+		"fails on zero packets": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Packets = 0 },
+		},
+		"fails on zero interval": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Interval.Duration = 0 },
+		},
+		"fails on zero timeout": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Timeout.Duration = 0 },
+		},
+		"fails on empty histogram": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Histogram = nil },
+		},
+		"fails on not sorted histogram": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Histogram = []float64{10, 5} },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := newTestPing(&mockPinger{})
+			test.config(&p.Config)
+
+			if test.wantFail {
+				assert.False(t, p.Init())
+			} else {
+				assert.True(t, p.Init())
+			}
+		})
+	}
+}
+
+func TestPing_Init_FailsIfPingerNotCreated(t *testing.T) {
+	p := New()
+	p.Hosts = []string{"127.0.0.1"}
+	p.newPinger = func(icmpPingerConfig) (pinger, error) { return nil, errors.New("mock error") }
+
+	assert.False(t, p.Init())
+}
+
+func TestPing_Charts(t *testing.T) {
+	p := newTestPing(&mockPinger{})
+	require.True(t, p.Init())
+
+	assert.Len(t, *p.Charts(), len(hostCharts)*len(p.Hosts))
+	hist := p.Charts().Get("host_127_0_0_1_rtt_distribution")
+	require.NotNil(t, hist)
+	assert.Len(t, hist.Dims, len(p.Histogram)+1)
+}
+
+func TestPing_Cleanup(t *testing.T) {
+	assert.NotPanics(t, New().Cleanup)
+}
+
+func TestPing_Check(t *testing.T) {
+	tests := map[string]struct {
+		pinger    *mockPinger
+		wantCheck bool
+	}{
+		"success when hosts reply":     {wantCheck: true, pinger: &mockPinger{}},
+		"success when hosts not reply": {wantCheck: true, pinger: &mockPinger{errHosts: map[string]bool{"127.0.0.1": true}}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := newTestPing(test.pinger)
+			require.True(t, p.Init())
+
+			assert.Equal(t, test.wantCheck, p.Check())
+		})
+	}
+}
+
+func TestPing_Collect(t *testing.T) {
+	p := newTestPing(&mockPinger{errHosts: map[string]bool{"unreachable.local": true}})
+	p.Hosts = append(p.Hosts, "unreachable.local")
+	require.True(t, p.Init())
+
+	expected := map[string]int64{
+		"host_127_0_0_1_avg_rtt":                    2000,
+		"host_127_0_0_1_max_rtt":                    3000,
+		"host_127_0_0_1_min_rtt":                    1000,
+		"host_127_0_0_1_packet_loss":                25000,
+		"host_127_0_0_1_packets_received":           3,
+		"host_127_0_0_1_packets_sent":               4,
+		"host_127_0_0_1_rtt_hist_bucket_1":          1,
+		"host_127_0_0_1_rtt_hist_bucket_10":         3,
+		"host_127_0_0_1_rtt_hist_bucket_2":          2,
+		"host_127_0_0_1_rtt_hist_bucket_3":          3,
+		"host_127_0_0_1_rtt_hist_bucket_4":          3,
+		"host_127_0_0_1_rtt_hist_bucket_5":          3,
+		"host_127_0_0_1_rtt_hist_bucket_6":          3,
+		"host_127_0_0_1_rtt_hist_bucket_7":          3,
+		"host_127_0_0_1_rtt_hist_bucket_8":          3,
+		"host_127_0_0_1_rtt_hist_bucket_9":          3,
+		"host_127_0_0_1_rtt_hist_count":             3,
+		"host_127_0_0_1_rtt_hist_sum":               6000,
+		"host_127_0_0_1_std_dev_rtt":                816,
+		"host_unreachable_local_packet_loss":        100000,
+		"host_unreachable_local_packets_received":   0,
+		"host_unreachable_local_packets_sent":       5,
+		"host_unreachable_local_rtt_hist_bucket_1":  0,
+		"host_unreachable_local_rtt_hist_bucket_10": 0,
+		"host_unreachable_local_rtt_hist_bucket_2":  0,
+		"host_unreachable_local_rtt_hist_bucket_3":  0,
+		"host_unreachable_local_rtt_hist_bucket_4":  0,
+		"host_unreachable_local_rtt_hist_bucket_5":  0,
+		"host_unreachable_local_rtt_hist_bucket_6":  0,
+		"host_unreachable_local_rtt_hist_bucket_7":  0,
+		"host_unreachable_local_rtt_hist_bucket_8":  0,
+		"host_unreachable_local_rtt_hist_bucket_9":  0,
+		"host_unreachable_local_rtt_hist_count":     0,
+		"host_unreachable_local_rtt_hist_sum":       0,
+	}
+
+	mx := p.Collect()
+
+	assert.Equal(t, expected, mx)
+}
+
+func TestPing_Collect_HasAllChartsDims(t *testing.T) {
+	p := newTestPing(&mockPinger{})
+	p.Hosts = append(p.Hosts, "::1")
+	require.True(t, p.Init())
+
+	mx := p.Collect()
+
+	require.NotNil(t, mx)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, p, mx)
+}
+
+func TestPing_Collect_Localhost(t *testing.T) {
+	p := New()
+	p.Hosts = []string{"127.0.0.1"}
+	p.Packets = 3
+	p.Interval.Duration = time.Millisecond * 10
+
+	if _, err := p.newPinger(icmpPingerConfig{}); err != nil {
+		t.Skipf("can't create ICMP socket: %v", err)
+	}
+	require.True(t, p.Init())
+
+	mx := p.Collect()
+
+	require.NotNil(t, mx)
+	assert.Equal(t, int64(3), mx["host_127_0_0_1_packets_sent"])
+	assert.Equal(t, int64(3), mx["host_127_0_0_1_packets_received"])
+	assert.Equal(t, int64(0), mx["host_127_0_0_1_packet_loss"])
+	assert.Equal(t, int64(3), mx["host_127_0_0_1_rtt_hist_count"])
+	ensureCollectedHasAllChartsDimsVarsIDs(t, p, mx)
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, p *Ping, mx map[string]int64) {
+	for _, chart := range *p.Charts() {
+		for _, dim := range chart.Dims {
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+		}
+	}
+}
+
+func newTestPing(pr pinger) *Ping {
+	p := New()
+	p.Hosts = []string{"127.0.0.1"}
+	p.newPinger = func(icmpPingerConfig) (pinger, error) { return pr, nil }
+	return p
+}
+
+type mockPinger struct {
+	errHosts map[string]bool
+}
+
+func (m mockPinger) ping(host string) (*pingStats, error) {
+	if m.errHosts[host] {
+		return nil, errors.New("mock.ping() error")
+	}
+	return &pingStats{
+		sent: 4,
+		rtts: []time.Duration{time.Millisecond, time.Millisecond * 2, time.Millisecond * 3},
+	}, nil
+}
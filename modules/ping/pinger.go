@@ -0,0 +1,183 @@
+// +build !windows
+
+package ping
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+
+	payloadSize = 56
+)
+
+// newICMPPinger creates a pinger that uses unprivileged datagram ICMP sockets,
+// if they are not permitted (Linux 'net.ipv4.ping_group_range') it falls back to raw sockets.
+func newICMPPinger(cfg icmpPingerConfig) (pinger, error) {
+	p := &icmpPinger{icmpPingerConfig: cfg}
+	if p.privileged {
+		return p, checkListen("ip4:icmp", "0.0.0.0")
+	}
+
+	err := checkListen("udp4", "0.0.0.0")
+	if err == nil {
+		return p, nil
+	}
+	if rawErr := checkListen("ip4:icmp", "0.0.0.0"); rawErr != nil {
+		return nil, fmt.Errorf("can't create neither unprivileged (%v) nor raw (%v) ICMP socket", err, rawErr)
+	}
+	p.privileged = true
+	return p, nil
+}
+
+func checkListen(network, address string) error {
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+var echoID uint32
+
+type icmpPinger struct {
+	icmpPingerConfig
+}
+
+type echoProto struct {
+	network     string
+	address     string
+	protocol    int
+	requestType icmp.Type
+	replyType   icmp.Type
+}
+
+func (p *icmpPinger) proto(ip net.IP) echoProto {
+	if ip.To4() != nil {
+		if p.privileged {
+			return echoProto{"ip4:icmp", "0.0.0.0", protocolICMP, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply}
+		}
+		return echoProto{"udp4", "0.0.0.0", protocolICMP, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply}
+	}
+	if p.privileged {
+		return echoProto{"ip6:ipv6-icmp", "::", protocolIPv6ICMP, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply}
+	}
+	return echoProto{"udp6", "::", protocolIPv6ICMP, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply}
+}
+
+func (p *icmpPinger) ping(host string) (*pingStats, error) {
+	dst, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	proto := p.proto(dst.IP)
+	conn, err := icmp.ListenPacket(proto.network, proto.address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var addr net.Addr = dst
+	if !p.privileged {
+		addr = &net.UDPAddr{IP: dst.IP, Zone: dst.Zone}
+	}
+
+	// the kernel replaces the ID of unprivileged datagram sockets, raw sockets receive all the ICMP traffic
+	id := int((uint32(os.Getpid()) + atomic.AddUint32(&echoID, 1)) & 0xffff)
+
+	deadline := time.Now().Add(p.interval*time.Duration(p.packets-1) + p.timeout)
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	sent := make([]time.Time, p.packets)
+	stats := &pingStats{}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		received := make(map[int]bool)
+		buf := make([]byte, 1500)
+
+		for len(received) < p.packets {
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			now := time.Now()
+
+			msg, err := icmp.ParseMessage(proto.protocol, buf[:n])
+			if err != nil || msg.Type != proto.replyType {
+				continue
+			}
+			echo, ok := msg.Body.(*icmp.Echo)
+			if !ok || (p.privileged && (echo.ID != id || !peerIP(peer).Equal(dst.IP))) {
+				continue
+			}
+
+			mu.Lock()
+			if echo.Seq >= 0 && echo.Seq < p.packets && !sent[echo.Seq].IsZero() && !received[echo.Seq] {
+				received[echo.Seq] = true
+				stats.rtts = append(stats.rtts, now.Sub(sent[echo.Seq]))
+			}
+			mu.Unlock()
+		}
+	}()
+
+	var writeErr error
+	for seq := 0; seq < p.packets; seq++ {
+		if seq > 0 {
+			time.Sleep(p.interval)
+		}
+
+		msg := icmp.Message{
+			Type: proto.requestType,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: make([]byte, payloadSize)},
+		}
+		bs, err := msg.Marshal(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		mu.Lock()
+		sent[seq] = time.Now()
+		stats.sent++
+		mu.Unlock()
+
+		if _, err := conn.WriteTo(bs, addr); err != nil {
+			writeErr = err
+		}
+	}
+
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(stats.rtts) == 0 && writeErr != nil {
+		return nil, writeErr
+	}
+	return stats, nil
+}
+
+func peerIP(addr net.Addr) net.IP {
+	switch v := addr.(type) {
+	case *net.IPAddr:
+		return v.IP
+	case *net.UDPAddr:
+		return v.IP
+	}
+	return nil
+}
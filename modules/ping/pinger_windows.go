@@ -0,0 +1,8 @@
+package ping
+
+import "errors"
+
+// newICMPPinger fails, the ICMP pinger is not built on Windows (golang.org/x/net/icmp doesn't link there).
+func newICMPPinger(_ icmpPingerConfig) (pinger, error) {
+	return nil, errors.New("ICMP ping is not supported on Windows")
+}
@@ -2,6 +2,7 @@ package portcheck
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 )
@@ -9,53 +10,93 @@ import (
 type (
 	// Charts is an alias for module.Charts
 	Charts = module.Charts
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
 	// Dims is an alias for module.Dims
 	Dims = module.Dims
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
 )
 
 var portCharts = Charts{
 	{
-		ID:    "port_%d_status",
-		Title: "TCP Check Status",
+		ID:    "%s_status",
+		Title: "%s Check Status",
 		Units: "boolean",
-		Fam:   "port %d",
 		Ctx:   "portcheck.status",
 		Dims: Dims{
-			{ID: "port_%d_success", Name: "success"},
-			{ID: "port_%d_failed", Name: "failed"},
-			{ID: "port_%d_timeout", Name: "timeout"},
+			{ID: "%s_success", Name: "success"},
+			{ID: "%s_failed", Name: "failed"},
+			{ID: "%s_timeout", Name: "timeout"},
 		},
 	},
 	{
-		ID:    "port_%d_current_state_duration",
+		ID:    "%s_current_state_duration",
 		Title: "Current State Duration",
 		Units: "seconds",
-		Fam:   "port %d",
 		Ctx:   "portcheck.state_duration",
 		Dims: Dims{
-			{ID: "port_%d_current_state_duration", Name: "time"},
+			{ID: "%s_current_state_duration", Name: "time"},
 		},
 	},
 	{
-		ID:    "port_%d_connection_latency",
+		ID:    "%s_connection_latency",
 		Title: "TCP Connection Latency",
 		Units: "ms",
-		Fam:   "port %d",
 		Ctx:   "portcheck.latency",
 		Dims: Dims{
-			{ID: "port_%d_latency", Name: "time"},
+			{ID: "%s_latency", Name: "time"},
 		},
 	},
 }
 
-func newPortCharts(port int) *Charts {
+var tlsHandshakeLatencyChart = Chart{
+	ID:    "%s_tls_handshake_latency",
+	Title: "TLS Handshake Latency",
+	Units: "ms",
+	Ctx:   "portcheck.tls_handshake_latency",
+	Dims: Dims{
+		{ID: "%s_tls_handshake_latency", Name: "time"},
+	},
+}
+
+var (
+	tlsFailedDim   = Dim{ID: "%s_tls_failed", Name: "tls failed"}
+	badResponseDim = Dim{ID: "%s_bad_response", Name: "bad response"}
+)
+
+func newPortCharts(p *port) *Charts {
 	cs := portCharts.Copy()
-	for _, chart := range *cs {
-		chart.ID = fmt.Sprintf(chart.ID, port)
-		chart.Fam = fmt.Sprintf(chart.Fam, port)
+	status := cs.Get("%s_status")
+	if p.tlsConf != nil {
+		dim := tlsFailedDim
+		_ = status.AddDim(&dim)
+		_ = cs.Add(tlsHandshakeLatencyChart.Copy())
+	}
+	if p.reExpect != nil {
+		dim := badResponseDim
+		_ = status.AddDim(&dim)
+	}
+
+	proto := strings.ToUpper(p.proto)
+	fam := fmt.Sprintf("port %d", p.number)
+	if p.proto == protoUDP {
+		fam = "udp " + fam
+	}
+	if strings.HasPrefix(p.id, "host_") {
+		fam = p.host + " " + fam
+	}
 
+	status.Title = fmt.Sprintf(status.Title, proto)
+	if p.proto == protoUDP {
+		cs.Get("%s_connection_latency").Title = "UDP Response Latency"
+	}
+
+	for _, chart := range *cs {
+		chart.ID = fmt.Sprintf(chart.ID, p.id)
+		chart.Fam = fam
 		for _, dim := range chart.Dims {
-			dim.ID = fmt.Sprintf(dim.ID, port)
+			dim.ID = fmt.Sprintf(dim.ID, p.id)
 		}
 	}
 	return cs
@@ -1,7 +1,9 @@
 package portcheck
 
 import (
-	"fmt"
+	"crypto/tls"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -21,20 +23,35 @@ func (pc PortCheck) collect() (map[string]int64, error) {
 	mx := make(map[string]int64)
 
 	for _, p := range pc.ports {
-		mx[fmt.Sprintf("port_%d_current_state_duration", p.number)] = int64(p.inState)
-		mx[fmt.Sprintf("port_%d_latency", p.number)] = int64(p.latency)
-		mx[fmt.Sprintf("port_%d_%s", p.number, success)] = 0
-		mx[fmt.Sprintf("port_%d_%s", p.number, timeout)] = 0
-		mx[fmt.Sprintf("port_%d_%s", p.number, failed)] = 0
-		mx[fmt.Sprintf("port_%d_%s", p.number, p.state)] = 1
+		mx[p.id+"_current_state_duration"] = int64(p.inState)
+		mx[p.id+"_latency"] = int64(p.latency)
+		mx[p.id+"_"+string(success)] = 0
+		mx[p.id+"_"+string(timeout)] = 0
+		mx[p.id+"_"+string(failed)] = 0
+		if p.tlsConf != nil {
+			mx[p.id+"_tls_handshake_latency"] = int64(p.tlsLatency)
+			mx[p.id+"_"+string(tlsFailed)] = 0
+		}
+		if p.reExpect != nil {
+			mx[p.id+"_"+string(badResponse)] = 0
+		}
+		mx[p.id+"_"+string(p.state)] = 1
 	}
 
 	return mx, nil
 }
 
 func (pc PortCheck) checkPort(p *port) {
+	if p.proto == protoUDP {
+		pc.checkUDPPort(p)
+	} else {
+		pc.checkTCPPort(p)
+	}
+}
+
+func (pc PortCheck) checkTCPPort(p *port) {
 	start := time.Now()
-	conn, err := pc.dial("tcp", fmt.Sprintf("%s:%d", pc.Host, p.number), pc.Timeout.Duration)
+	conn, err := pc.dial("tcp", net.JoinHostPort(p.host, strconv.Itoa(p.number)), pc.Timeout.Duration)
 	dur := time.Since(start)
 
 	defer func() {
@@ -44,20 +61,117 @@ func (pc PortCheck) checkPort(p *port) {
 	}()
 
 	if err != nil {
-		v, ok := err.(interface{ Timeout() bool })
-		if ok && v.Timeout() {
-			pc.setPortState(p, timeout)
-		} else {
-			pc.setPortState(p, failed)
+		pc.setPortState(p, errorState(err, failed))
+		return
+	}
+
+	p.latency = durationToMs(dur)
+
+	if p.tlsConf != nil {
+		tlsConn := tls.Client(conn, p.tlsConf)
+		_ = tlsConn.SetDeadline(time.Now().Add(pc.Timeout.Duration))
+
+		start = time.Now()
+		err = tlsConn.Handshake()
+		p.tlsLatency = durationToMs(time.Since(start))
+		if err != nil {
+			pc.Debugf("tcp port %s:%d: TLS handshake: %v", p.host, p.number, err)
+			pc.setPortState(p, errorState(err, tlsFailed))
+			return
+		}
+		conn = tlsConn
+	}
+
+	if len(p.send) == 0 && p.reExpect == nil {
+		pc.setPortState(p, success)
+		return
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(pc.Timeout.Duration))
+	if len(p.send) > 0 {
+		if _, err := conn.Write(p.send); err != nil {
+			pc.setPortState(p, errorState(err, failed))
+			return
 		}
+	}
+	if p.reExpect != nil && !pc.readMatch(p, conn) {
+		pc.setPortState(p, badResponse)
 		return
 	}
 	pc.setPortState(p, success)
+}
+
+func (pc PortCheck) checkUDPPort(p *port) {
+	conn, err := pc.dial("udp", net.JoinHostPort(p.host, strconv.Itoa(p.number)), pc.Timeout.Duration)
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+	if err != nil {
+		pc.setPortState(p, errorState(err, failed))
+		return
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(pc.Timeout.Duration))
+
+	start := time.Now()
+	if _, err := conn.Write(p.send); err != nil {
+		pc.setPortState(p, errorState(err, failed))
+		return
+	}
+
+	// UDP is connectionless, the port is reachable only if there is a response.
+	// A closed port usually results in 'connection refused' (ICMP port unreachable).
+	buf := make([]byte, maxReadSize)
+	n, err := conn.Read(buf)
+	dur := time.Since(start)
+	if err != nil {
+		pc.setPortState(p, errorState(err, failed))
+		return
+	}
+
 	p.latency = durationToMs(dur)
+
+	if p.reExpect != nil && !p.reExpect.Match(buf[:n]) {
+		pc.Debugf("udp port %s:%d: response doesn't match %s", p.host, p.number, p.reExpect)
+		pc.setPortState(p, badResponse)
+		return
+	}
+	pc.setPortState(p, success)
+}
+
+const maxReadSize = 4096
+
+// readMatch reads the response until it matches the expect regexp, the read deadline or EOF.
+func (pc PortCheck) readMatch(p *port, conn net.Conn) bool {
+	var resp []byte
+	buf := make([]byte, 1024)
+
+	for len(resp) < maxReadSize {
+		n, err := conn.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if p.reExpect.Match(resp) {
+			return true
+		}
+		if err != nil {
+			break
+		}
+	}
+	pc.Debugf("tcp port %s:%d: response doesn't match %s", p.host, p.number, p.reExpect)
+	return false
+}
+
+func errorState(err error, s state) state {
+	if v, ok := err.(interface{ Timeout() bool }); ok && v.Timeout() {
+		return timeout
+	}
+	return s
 }
 
 func (pc PortCheck) setPortState(p *port, s state) {
 	changed := p.state != s
+
 	if changed {
 		p.inState = pc.UpdateEvery
 		p.state = s
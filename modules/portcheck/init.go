@@ -0,0 +1,138 @@
+package portcheck
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
+)
+
+func (pc PortCheck) hosts() []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, h := range append([]string{pc.Host}, pc.Hosts...) {
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+func (pc PortCheck) initPorts() ([]*port, error) {
+	var checks []CheckConfig
+	for _, p := range pc.Ports {
+		checks = append(checks, CheckConfig{Port: p})
+	}
+	checks = append(checks, pc.Checks...)
+
+	seen := make(map[string]bool)
+	for _, c := range checks {
+		key := fmt.Sprintf("%s/%d", checkProto(c), c.Port)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate check for %s port %d", checkProto(c), c.Port)
+		}
+		seen[key] = true
+	}
+
+	// the chart IDs are not prefixed with the host if only 'host' is set (backward compatibility)
+	hosts := pc.hosts()
+	withHostID := len(pc.Hosts) > 0
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+
+	var ports []*port
+	for _, host := range hosts {
+		for _, c := range checks {
+			p, err := newPort(host, c)
+			if err != nil {
+				return nil, err
+			}
+			if withHostID {
+				p.id = "host_" + cleanHost(host) + "_" + p.id
+			}
+			ports = append(ports, p)
+		}
+	}
+	return ports, nil
+}
+
+func newPort(host string, cfg CheckConfig) (*port, error) {
+	proto := checkProto(cfg)
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return nil, fmt.Errorf("invalid port number %d", cfg.Port)
+	}
+	if proto != protoTCP && proto != protoUDP {
+		return nil, fmt.Errorf("port %d: unknown protocol '%s' (tcp, udp)", cfg.Port, cfg.Protocol)
+	}
+
+	p := &port{
+		id:     fmt.Sprintf("port_%d", cfg.Port),
+		host:   host,
+		number: cfg.Port,
+		proto:  proto,
+	}
+	if proto == protoUDP {
+		p.id = "udp_" + p.id
+	}
+
+	if cfg.Send != "" && cfg.SendHex != "" {
+		return nil, fmt.Errorf("%s port %d: 'send' and 'send_hex' are mutually exclusive", proto, cfg.Port)
+	}
+	if cfg.Send != "" {
+		p.send = []byte(cfg.Send)
+	}
+	if cfg.SendHex != "" {
+		bs, err := hex.DecodeString(strings.Join(strings.Fields(cfg.SendHex), ""))
+		if err != nil {
+			return nil, fmt.Errorf("%s port %d: error on decoding 'send_hex': %v", proto, cfg.Port, err)
+		}
+		p.send = bs
+	}
+	if proto == protoUDP && len(p.send) == 0 {
+		return nil, fmt.Errorf("udp port %d: 'send' or 'send_hex' is required", cfg.Port)
+	}
+
+	if cfg.Expect != "" {
+		re, err := regexp.Compile(cfg.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("%s port %d: error on creating 'expect' regexp: %v", proto, cfg.Port, err)
+		}
+		p.reExpect = re
+	}
+
+	if cfg.TLS {
+		if proto == protoUDP {
+			return nil, fmt.Errorf("udp port %d: TLS is not supported", cfg.Port)
+		}
+		tlsConf, err := tlscfg.NewTLSConfig(cfg.TLSConfig)
+		if err != nil {
+			return nil, fmt.Errorf("tcp port %d: error on creating TLS config: %v", cfg.Port, err)
+		}
+		if tlsConf == nil {
+			tlsConf = &tls.Config{}
+		}
+		if tlsConf.ServerName == "" {
+			tlsConf.ServerName = host
+		}
+		p.tlsConf = tlsConf
+	}
+	return p, nil
+}
+
+func checkProto(cfg CheckConfig) string {
+	if cfg.Protocol == "" {
+		return protoTCP
+	}
+	return strings.ToLower(cfg.Protocol)
+}
+
+func cleanHost(host string) string {
+	r := strings.NewReplacer(".", "_", ":", "_", " ", "_", "[", "", "]", "")
+	return r.Replace(host)
+}
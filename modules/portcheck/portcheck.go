@@ -1,10 +1,13 @@
 package portcheck
 
 import (
+	"crypto/tls"
 	"net"
+	"regexp"
 	"sort"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -37,9 +40,22 @@ func New() *PortCheck {
 
 /// Config is the Portcheck module configuration file.
 type Config struct {
-	Host    string       `yaml:"host"`
-	Ports   []int        `yaml:"ports"`
-	Timeout web.Duration `yaml:"timeout"`
+	Host    string        `yaml:"host"`
+	Hosts   []string      `yaml:"hosts"`
+	Ports   []int         `yaml:"ports"`
+	Checks  []CheckConfig `yaml:"checks"`
+	Timeout web.Duration  `yaml:"timeout"`
+}
+
+// CheckConfig is the configuration of a port check with a protocol, TLS handshake or response matching.
+type CheckConfig struct {
+	Port             int    `yaml:"port"`
+	Protocol         string `yaml:"protocol"`
+	TLS              bool   `yaml:"tls"`
+	tlscfg.TLSConfig `yaml:",inline"`
+	Send             string `yaml:"send"`
+	SendHex          string `yaml:"send_hex"`
+	Expect           string `yaml:"expect"`
 }
 
 type dialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
@@ -47,16 +63,31 @@ type dialFunc func(network, address string, timeout time.Duration) (net.Conn, er
 type state string
 
 const (
-	success state = "success"
-	timeout state = "timeout"
-	failed  state = "failed"
+	success     state = "success"
+	timeout     state = "timeout"
+	failed      state = "failed"
+	tlsFailed   state = "tls_failed"
+	badResponse state = "bad_response"
+)
+
+const (
+	protoTCP = "tcp"
+	protoUDP = "udp"
 )
 
 type port struct {
-	number  int
-	state   state
-	inState int
-	latency int
+	id       string
+	host     string
+	number   int
+	proto    string
+	tlsConf  *tls.Config
+	send     []byte
+	reExpect *regexp.Regexp
+
+	state      state
+	inState    int
+	latency    int
+	tlsLatency int
 }
 
 // PortCheck portcheck module.
@@ -73,25 +104,29 @@ func (PortCheck) Cleanup() {}
 
 // Init makes initialization.
 func (pc *PortCheck) Init() bool {
-	if pc.Host == "" {
+	if pc.Host == "" && len(pc.Hosts) == 0 {
 		pc.Error("host parameter is not set")
 		return false
 	}
 
-	if len(pc.Ports) == 0 {
+	if len(pc.Ports) == 0 && len(pc.Checks) == 0 {
 		pc.Error("ports parameter is not set")
 		return false
 	}
 
 	sort.Ints(pc.Ports)
 
-	for _, p := range pc.Ports {
-		pc.ports = append(pc.ports, &port{number: p})
+	ports, err := pc.initPorts()
+	if err != nil {
+		pc.Error(err)
+		return false
 	}
+	pc.ports = ports
 
-	pc.Debugf("using host %s", pc.Host)
+	pc.Debugf("using hosts %v", pc.hosts())
 	pc.Debugf("using ports %v", pc.Ports)
-	pc.Debugf("using TCP connection timeout: %s", pc.Timeout)
+	pc.Debugf("using %d additional checks", len(pc.Checks))
+	pc.Debugf("using connection timeout: %s", pc.Timeout)
 
 	return true
 }
@@ -101,10 +136,15 @@ func (PortCheck) Check() bool { return true }
 
 // Charts creates charts.
 func (pc PortCheck) Charts() *Charts {
+	ports := pc.ports
+	if ports == nil {
+		ports, _ = pc.initPorts()
+	}
+
 	charts := &Charts{}
 
-	for _, port := range pc.Ports {
-		_ = charts.Add(*newPortCharts(port)...)
+	for _, p := range ports {
+		_ = charts.Add(*newPortCharts(p)...)
 	}
 
 	return charts
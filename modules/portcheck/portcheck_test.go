@@ -3,10 +3,16 @@ package portcheck
 import (
 	"errors"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/tlscfg"
+
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -150,3 +156,230 @@ type timeoutError struct{}
 
 func (timeoutError) Error() string { return "timeout" }
 func (timeoutError) Timeout() bool { return true }
+
+func TestPortCheck_InitChecks(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"hosts without host": {
+			config: Config{Hosts: []string{"127.0.0.1", "127.0.0.2"}, Ports: []int{22}},
+		},
+		"checks without ports": {
+			config: Config{Host: "127.0.0.1", Checks: []CheckConfig{
+				{Port: 22, Expect: "^SSH-"},
+				{Port: 443, TLS: true},
+				{Port: 53, Protocol: "udp", SendHex: "00 01"},
+			}},
+		},
+		"same port tcp and udp": {
+			config: Config{Host: "127.0.0.1", Ports: []int{53}, Checks: []CheckConfig{{Port: 53, Protocol: "udp", Send: "ping"}}},
+		},
+		"duplicate port": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Ports: []int{22}, Checks: []CheckConfig{{Port: 22, Expect: "^SSH-"}}},
+		},
+		"invalid port": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 0}}},
+		},
+		"unknown protocol": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 22, Protocol: "sctp"}}},
+		},
+		"udp without payload": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 53, Protocol: "udp"}}},
+		},
+		"udp with tls": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 53, Protocol: "udp", Send: "a", TLS: true}}},
+		},
+		"send and send_hex": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 22, Send: "a", SendHex: "61"}}},
+		},
+		"bad send_hex": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 22, SendHex: "zz"}}},
+		},
+		"bad expect regexp": {
+			wantFail: true,
+			config:   Config{Host: "127.0.0.1", Checks: []CheckConfig{{Port: 22, Expect: "(?:qwe))"}}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			test.config.Timeout = job.Timeout
+			job.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestPortCheck_ChartsChecks(t *testing.T) {
+	job := New()
+	job.Hosts = []string{"10.0.0.1", "example.com"}
+	job.Ports = []int{80}
+	job.Checks = []CheckConfig{
+		{Port: 443, TLS: true, Expect: "^HTTP"},
+		{Port: 53, Protocol: "udp", Send: "ping"},
+	}
+	require.True(t, job.Init())
+
+	charts := job.Charts()
+
+	// 3 charts for every check + TLS handshake latency chart, for every host
+	assert.Len(t, *charts, (len(portCharts)*3+1)*2)
+	for _, id := range []string{
+		"host_10_0_0_1_port_80_status",
+		"host_example_com_port_443_tls_handshake_latency",
+		"host_example_com_udp_port_53_connection_latency",
+	} {
+		assert.Truef(t, charts.Has(id), "chart '%s' not found", id)
+	}
+
+	status := charts.Get("host_10_0_0_1_port_443_status")
+	require.NotNil(t, status)
+	assert.True(t, status.HasDim("host_10_0_0_1_port_443_tls_failed"))
+	assert.True(t, status.HasDim("host_10_0_0_1_port_443_bad_response"))
+	assert.Equal(t, "UDP Check Status", charts.Get("host_10_0_0_1_udp_port_53_status").Title)
+}
+
+func TestPortCheck_CollectChecks(t *testing.T) {
+	banner := startTCPServer(t, func(conn net.Conn) { _, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.9\r\n")) })
+	echo := startTCPServer(t, func(conn net.Conn) {
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		_, _ = conn.Write(buf[:n])
+	})
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsSrv.Close()
+	udpEcho := startUDPServer(t)
+	closedUDP := closedUDPPort(t)
+
+	tests := map[string]struct {
+		check     CheckConfig
+		wantState state
+	}{
+		"banner match":       {check: CheckConfig{Port: banner, Expect: `^SSH-2\.0-`}, wantState: success},
+		"banner mismatch":    {check: CheckConfig{Port: banner, Expect: `^220 `}, wantState: badResponse},
+		"send and expect":    {check: CheckConfig{Port: echo, Send: "PING\r\n", Expect: "PING"}, wantState: success},
+		"tls handshake":      {check: CheckConfig{Port: portOf(t, tlsSrv.Listener.Addr()), TLS: true, TLSConfig: tlscfg.TLSConfig{InsecureSkipVerify: true}}, wantState: success},
+		"tls verify failed":  {check: CheckConfig{Port: portOf(t, tlsSrv.Listener.Addr()), TLS: true}, wantState: tlsFailed},
+		"tls on plain port":  {check: CheckConfig{Port: banner, TLS: true, TLSConfig: tlscfg.TLSConfig{InsecureSkipVerify: true}}, wantState: tlsFailed},
+		"udp response":       {check: CheckConfig{Port: udpEcho, Protocol: "udp", SendHex: "50 49 4e 47", Expect: "^PING$"}, wantState: success},
+		"udp bad response":   {check: CheckConfig{Port: udpEcho, Protocol: "udp", Send: "PONG", Expect: "^PING$"}, wantState: badResponse},
+		"udp port is closed": {check: CheckConfig{Port: closedUDP, Protocol: "udp", Send: "PING"}, wantState: failed},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Host = "127.0.0.1"
+			job.Timeout.Duration = time.Millisecond * 500
+			job.Checks = []CheckConfig{test.check}
+			job.UpdateEvery = 5
+			require.True(t, job.Init())
+
+			mx := job.Collect()
+			require.NotNil(t, mx)
+
+			p := job.ports[0]
+			assert.Equal(t, test.wantState, p.state)
+			assert.Equal(t, int64(1), mx[p.id+"_"+string(test.wantState)])
+			assert.Equal(t, int64(job.UpdateEvery), mx[p.id+"_current_state_duration"])
+			for _, chart := range *job.Charts() {
+				for _, dim := range chart.Dims {
+					_, ok := mx[dim.ID]
+					assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestPortCheck_CollectHosts(t *testing.T) {
+	job := New()
+	job.Hosts = []string{"10.0.0.1", "10.0.0.2"}
+	job.Ports = []int{22}
+	job.UpdateEvery = 5
+
+	var dialed []string
+	var mu sync.Mutex
+	job.dial = func(_, address string, _ time.Duration) (net.Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dialed = append(dialed, address)
+		return &net.TCPConn{}, nil
+	}
+	require.True(t, job.Init())
+
+	mx := job.Collect()
+
+	assert.ElementsMatch(t, []string{"10.0.0.1:22", "10.0.0.2:22"}, dialed)
+	assert.Equal(t, int64(1), mx["host_10_0_0_1_port_22_success"])
+	assert.Equal(t, int64(1), mx["host_10_0_0_2_port_22_success"])
+}
+
+func startTCPServer(t *testing.T, handle func(conn net.Conn)) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(time.Second))
+				handle(conn)
+			}()
+		}
+	}()
+	return portOf(t, ln.Addr())
+}
+
+func startUDPServer(t *testing.T) int {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = pc.WriteTo(buf[:n], addr)
+		}
+	}()
+	return portOf(t, pc.LocalAddr())
+}
+
+func closedUDPPort(t *testing.T) int {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := portOf(t, pc.LocalAddr())
+	_ = pc.Close()
+	return port
+}
+
+func portOf(t *testing.T, addr net.Addr) int {
+	_, port, err := net.SplitHostPort(addr.String())
+	require.NoError(t, err)
+	v, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return v
+}
@@ -0,0 +1,452 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+type (
+	Charts = module.Charts
+	Chart  = module.Chart
+	Dims   = module.Dims
+)
+
+const precision = 1000
+
+var globalCharts = Charts{
+	{
+		ID:    "connections_utilization",
+		Title: "Connections Utilization",
+		Units: "percentage",
+		Fam:   "connections",
+		Ctx:   "postgres.connections_utilization",
+		Dims: Dims{
+			{ID: "connections_utilization", Name: "used", Div: precision},
+		},
+	},
+	{
+		ID:    "connections",
+		Title: "Connections",
+		Units: "connections",
+		Fam:   "connections",
+		Ctx:   "postgres.connections",
+		Dims: Dims{
+			{ID: "connections", Name: "connections"},
+			{ID: "max_connections", Name: "max"},
+		},
+	},
+	{
+		ID:    "connections_state",
+		Title: "Connections By State",
+		Units: "connections",
+		Fam:   "connections",
+		Ctx:   "postgres.connections_state",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "conn_state_active", Name: "active"},
+			{ID: "conn_state_idle", Name: "idle"},
+			{ID: "conn_state_idle_in_transaction", Name: "idle_in_transaction"},
+			{ID: "conn_state_idle_in_transaction_aborted", Name: "idle_in_transaction_aborted"},
+			{ID: "conn_state_fastpath_function_call", Name: "fastpath_function_call"},
+			{ID: "conn_state_disabled", Name: "disabled"},
+		},
+	},
+	{
+		ID:    "locks_held",
+		Title: "Held Locks",
+		Units: "locks",
+		Fam:   "locks",
+		Ctx:   "postgres.locks_held",
+		Type:  module.Stacked,
+		Dims:  newLocksDims("held"),
+	},
+	{
+		ID:    "locks_awaited",
+		Title: "Awaited Locks",
+		Units: "locks",
+		Fam:   "locks",
+		Ctx:   "postgres.locks_awaited",
+		Type:  module.Stacked,
+		Dims:  newLocksDims("awaited"),
+	},
+	{
+		ID:    "wal_writes",
+		Title: "Write-Ahead Log Writes",
+		Units: "B/s",
+		Fam:   "wal",
+		Ctx:   "postgres.wal_writes",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "wal_written", Name: "written", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "checkpoints",
+		Title: "Checkpoints",
+		Units: "checkpoints/s",
+		Fam:   "checkpoints",
+		Ctx:   "postgres.checkpoints",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "checkpoints_timed", Name: "scheduled", Algo: module.Incremental},
+			{ID: "checkpoints_req", Name: "requested", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "checkpoint_time",
+		Title: "Checkpoint Time",
+		Units: "milliseconds",
+		Fam:   "checkpoints",
+		Ctx:   "postgres.checkpoint_time",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "checkpoint_write_time", Name: "write", Algo: module.Incremental},
+			{ID: "checkpoint_sync_time", Name: "sync", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "buffers_written",
+		Title: "Buffers Written",
+		Units: "buffers/s",
+		Fam:   "bgwriter",
+		Ctx:   "postgres.buffers_written",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "buffers_checkpoint", Name: "checkpoint", Algo: module.Incremental},
+			{ID: "buffers_clean", Name: "bgwriter", Algo: module.Incremental},
+			{ID: "buffers_backend", Name: "backend", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "buffers_allocated",
+		Title: "Buffers Allocated",
+		Units: "buffers/s",
+		Fam:   "bgwriter",
+		Ctx:   "postgres.buffers_allocated",
+		Dims: Dims{
+			{ID: "buffers_alloc", Name: "allocated", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "bgwriter_halts",
+		Title: "Background Writer Scan Halts",
+		Units: "halts/s",
+		Fam:   "bgwriter",
+		Ctx:   "postgres.bgwriter_halts",
+		Dims: Dims{
+			{ID: "maxwritten_clean", Name: "maxwritten", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "buffers_backend_fsync",
+		Title: "Backend Fsync Calls",
+		Units: "operations/s",
+		Fam:   "bgwriter",
+		Ctx:   "postgres.buffers_backend_fsync",
+		Dims: Dims{
+			{ID: "buffers_backend_fsync", Name: "fsync", Algo: module.Incremental},
+		},
+	},
+}
+
+var standbyDelayChart = Chart{
+	ID:    "standby_delay",
+	Title: "Standby Replay Delay",
+	Units: "seconds",
+	Fam:   "replication",
+	Ctx:   "postgres.standby_delay",
+	Dims: Dims{
+		{ID: "standby_delay", Name: "delay", Div: precision},
+	},
+}
+
+var databaseChartsTmpl = Charts{
+	{
+		ID:    "db_%s_connections",
+		Title: "Database Connections",
+		Units: "connections",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_connections",
+		Dims: Dims{
+			{ID: "db_%s_numbackends", Name: "connections"},
+		},
+	},
+	{
+		ID:    "db_%s_transactions",
+		Title: "Database Transactions",
+		Units: "transactions/s",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_transactions",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "db_%s_xact_commit", Name: "committed", Algo: module.Incremental},
+			{ID: "db_%s_xact_rollback", Name: "rollback", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "db_%s_cache_hit_ratio",
+		Title: "Database Buffer Cache Hit Ratio",
+		Units: "percentage",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_cache_hit_ratio",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "db_%s_blks_hit", Name: "hit", Algo: module.PercentOfIncremental},
+			{ID: "db_%s_blks_read", Name: "miss", Algo: module.PercentOfIncremental},
+		},
+	},
+	{
+		ID:    "db_%s_blocks",
+		Title: "Database Blocks",
+		Units: "blocks/s",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_blocks",
+		Dims: Dims{
+			{ID: "db_%s_blks_hit", Name: "hit", Algo: module.Incremental},
+			{ID: "db_%s_blks_read", Name: "read", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "db_%s_tuples",
+		Title: "Database Tuples",
+		Units: "tuples/s",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_tuples",
+		Dims: Dims{
+			{ID: "db_%s_tup_returned", Name: "returned", Algo: module.Incremental},
+			{ID: "db_%s_tup_fetched", Name: "fetched", Algo: module.Incremental},
+			{ID: "db_%s_tup_inserted", Name: "inserted", Algo: module.Incremental},
+			{ID: "db_%s_tup_updated", Name: "updated", Algo: module.Incremental},
+			{ID: "db_%s_tup_deleted", Name: "deleted", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "db_%s_temp_files",
+		Title: "Database Temporary Files",
+		Units: "files/s",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_temp_files",
+		Dims: Dims{
+			{ID: "db_%s_temp_files", Name: "written", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "db_%s_temp_bytes",
+		Title: "Database Temporary Files Data",
+		Units: "B/s",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_temp_bytes",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "db_%s_temp_bytes", Name: "written", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "db_%s_conflicts_deadlocks",
+		Title: "Database Conflicts And Deadlocks",
+		Units: "events/s",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_conflicts_deadlocks",
+		Dims: Dims{
+			{ID: "db_%s_conflicts", Name: "conflicts", Algo: module.Incremental},
+			{ID: "db_%s_deadlocks", Name: "deadlocks", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "db_%s_size",
+		Title: "Database Size",
+		Units: "B",
+		Fam:   "db %s",
+		Ctx:   "postgres.db_size",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "db_%s_size", Name: "size"},
+		},
+	},
+}
+
+var tableChartsTmpl = Charts{
+	{
+		ID:    "table_%s_rows",
+		Title: "Table Rows",
+		Units: "rows",
+		Fam:   "table %s",
+		Ctx:   "postgres.table_rows",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "table_%s_n_live_tup", Name: "live"},
+			{ID: "table_%s_n_dead_tup", Name: "dead"},
+		},
+	},
+	{
+		ID:    "table_%s_scans",
+		Title: "Table Scans",
+		Units: "scans/s",
+		Fam:   "table %s",
+		Ctx:   "postgres.table_scans",
+		Dims: Dims{
+			{ID: "table_%s_seq_scan", Name: "sequential", Algo: module.Incremental},
+			{ID: "table_%s_idx_scan", Name: "index", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "table_%s_operations",
+		Title: "Table Row Operations",
+		Units: "rows/s",
+		Fam:   "table %s",
+		Ctx:   "postgres.table_operations",
+		Dims: Dims{
+			{ID: "table_%s_n_tup_ins", Name: "inserted", Algo: module.Incremental},
+			{ID: "table_%s_n_tup_upd", Name: "updated", Algo: module.Incremental},
+			{ID: "table_%s_n_tup_del", Name: "deleted", Algo: module.Incremental},
+			{ID: "table_%s_n_tup_hot_upd", Name: "hot_updated", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "table_%s_size",
+		Title: "Table Total Size",
+		Units: "B",
+		Fam:   "table %s",
+		Ctx:   "postgres.table_size",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "table_%s_total_size", Name: "size"},
+		},
+	},
+}
+
+var standbyChartsTmpl = Charts{
+	{
+		ID:    "standby_%s_lag_bytes",
+		Title: "Standby Replay Lag Size",
+		Units: "B",
+		Fam:   "replication",
+		Ctx:   "postgres.standby_lag_bytes",
+		Dims: Dims{
+			{ID: "standby_%s_replay_lag_bytes", Name: "replay"},
+		},
+	},
+	{
+		ID:    "standby_%s_lag_time",
+		Title: "Standby Lag Time",
+		Units: "milliseconds",
+		Fam:   "replication",
+		Ctx:   "postgres.standby_lag_time",
+		Dims: Dims{
+			{ID: "standby_%s_write_lag", Name: "write"},
+			{ID: "standby_%s_flush_lag", Name: "flush"},
+			{ID: "standby_%s_replay_lag", Name: "replay"},
+		},
+	},
+}
+
+var replSlotChartsTmpl = Charts{
+	{
+		ID:    "repl_slot_%s_retained_bytes",
+		Title: "Replication Slot Retained WAL",
+		Units: "B",
+		Fam:   "replication",
+		Ctx:   "postgres.repl_slot_retained_bytes",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "repl_slot_%s_retained_bytes", Name: "retained"},
+		},
+	},
+}
+
+func newLocksDims(kind string) Dims {
+	var dims Dims
+	for _, mode := range lockModes {
+		dims = append(dims, &module.Dim{
+			ID:   "locks_" + kind + "_" + strings.ToLower(mode),
+			Name: mode,
+		})
+	}
+	return dims
+}
+
+func (p *Postgres) addGlobalCharts() {
+	charts := globalCharts.Copy()
+	if p.serverVersion >= pgVersion17 {
+		// backend writes statistics moved to pg_stat_io in v17.
+		_ = charts.Remove("buffers_backend_fsync")
+		_ = charts.Get("buffers_written").RemoveDim("buffers_backend")
+	}
+	if err := p.Charts().Add(*charts...); err != nil {
+		p.Warning(err)
+	}
+}
+
+func (p *Postgres) addStandbyDelayChart() {
+	if err := p.Charts().Add(standbyDelayChart.Copy()); err != nil {
+		p.Warning(err)
+	}
+}
+
+func (p *Postgres) addDatabaseCharts(name string) {
+	p.addChartsFromTemplate(databaseChartsTmpl, name)
+}
+
+func (p *Postgres) removeDatabaseCharts(name string) {
+	p.removeChartsFromTemplate(databaseChartsTmpl, name)
+}
+
+func (p *Postgres) addTableCharts(name string) {
+	p.addChartsFromTemplate(tableChartsTmpl, name)
+}
+
+func (p *Postgres) removeTableCharts(name string) {
+	p.removeChartsFromTemplate(tableChartsTmpl, name)
+}
+
+func (p *Postgres) addStandbyCharts(app string) {
+	charts := standbyChartsTmpl
+	if p.serverVersion < pgVersion10 {
+		// lag time is not available prior to v10.
+		charts = charts[:1]
+	}
+	p.addChartsFromTemplate(charts, app)
+}
+
+func (p *Postgres) removeStandbyCharts(app string) {
+	p.removeChartsFromTemplate(standbyChartsTmpl, app)
+}
+
+func (p *Postgres) addReplSlotCharts(slot string) {
+	p.addChartsFromTemplate(replSlotChartsTmpl, slot)
+}
+
+func (p *Postgres) removeReplSlotCharts(slot string) {
+	p.removeChartsFromTemplate(replSlotChartsTmpl, slot)
+}
+
+func (p *Postgres) addChartsFromTemplate(tmpl Charts, name string) {
+	charts := tmpl.Copy()
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, cleanChartID(name))
+		chart.Fam = fmt.Sprintf(chart.Fam, name)
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, name)
+		}
+	}
+	if err := p.Charts().Add(*charts...); err != nil {
+		p.Warning(err)
+	}
+}
+
+func (p *Postgres) removeChartsFromTemplate(tmpl Charts, name string) {
+	for _, chart := range tmpl {
+		id := fmt.Sprintf(chart.ID, cleanChartID(name))
+		if chart := p.Charts().Get(id); chart != nil {
+			chart.MarkRemove()
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func cleanChartID(name string) string {
+	r := strings.NewReplacer(" ", "_", ".", "_", ",", "_")
+	return strings.ToLower(r.Replace(name))
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// https://www.postgresql.org/docs/current/monitoring-stats.html
+
+func (p *Postgres) collect() (map[string]int64, error) {
+	if p.db == nil {
+		if err := p.openConnection(); err != nil {
+			return nil, err
+		}
+	}
+	if p.serverVersion == 0 {
+		ver, err := p.collectServerVersion()
+		if err != nil {
+			return nil, err
+		}
+		p.serverVersion = ver
+	}
+	p.addGlobalChartsOnce.Do(p.addGlobalCharts)
+
+	collected := make(map[string]int64)
+
+	if err := p.collectConnections(collected); err != nil {
+		return nil, fmt.Errorf("error on collecting connections: %v", err)
+	}
+	if err := p.collectDatabases(collected); err != nil {
+		return nil, fmt.Errorf("error on collecting databases statistics: %v", err)
+	}
+	if err := p.collectLocks(collected); err != nil {
+		return nil, fmt.Errorf("error on collecting locks: %v", err)
+	}
+	if err := p.collectBGWriter(collected); err != nil {
+		return nil, fmt.Errorf("error on collecting background writer statistics: %v", err)
+	}
+	if err := p.collectWAL(collected); err != nil {
+		return nil, fmt.Errorf("error on collecting WAL statistics: %v", err)
+	}
+
+	if p.isInRecovery {
+		p.addStandbyDelayOnce.Do(p.addStandbyDelayChart)
+	} else if p.doReplication {
+		if err := p.collectReplication(collected); err != nil {
+			p.Errorf("error on collecting replication statistics: %v", err)
+			// TODO: shouldn't disable on any error
+			p.doReplication = false
+		}
+	}
+
+	if p.doTables {
+		if err := p.collectTables(collected); err != nil {
+			p.Errorf("error on collecting tables statistics: %v", err)
+			// TODO: shouldn't disable on any error
+			p.doTables = false
+		}
+	}
+
+	return collected, nil
+}
+
+func (p *Postgres) openConnection() error {
+	db, err := sql.Open("postgres", p.DSN)
+	if err != nil {
+		return fmt.Errorf("error on opening a connection with the postgres database [%s]: %v", p.DSN, err)
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(10 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout.Duration)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("error on pinging the postgres database [%s]: %v", p.DSN, err)
+	}
+
+	p.db = db
+	return nil
+}
+
+// doQuery executes the query and calls assign for every returned row.
+// Values are passed as strings, NULL values are omitted.
+func (p *Postgres) doQuery(query string, assign func(row map[string]string)) error {
+	p.Debugf("executing query: '%s'", query)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout.Duration)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := nullStringsFromColumns(columns)
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		assign(rowAsMap(columns, values))
+	}
+	return rows.Err()
+}
+
+func rowAsMap(columns []string, values []interface{}) map[string]string {
+	set := make(map[string]string, len(columns))
+	for i, name := range columns {
+		if v, ok := values[i].(*sql.NullString); ok && v.Valid {
+			set[name] = v.String
+		}
+	}
+	return set
+}
+
+func nullStringsFromColumns(columns []string) []interface{} {
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		values[i] = &sql.NullString{}
+	}
+	return values
+}
+
+// parseInt parses integer and numeric values, fractional part is truncated.
+func parseInt(s string) int64 {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	return int64(v)
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
@@ -0,0 +1,50 @@
+package postgres
+
+const (
+	queryBGWriter = `
+SELECT checkpoints_timed,
+       checkpoints_req,
+       checkpoint_write_time,
+       checkpoint_sync_time,
+       buffers_checkpoint,
+       buffers_clean,
+       maxwritten_clean,
+       buffers_backend,
+       buffers_backend_fsync,
+       buffers_alloc
+FROM pg_stat_bgwriter;
+`
+	// checkpoints statistics were moved to pg_stat_checkpointer in v17, backend statistics to pg_stat_io.
+	queryBGWriterV17 = `
+SELECT buffers_clean,
+       maxwritten_clean,
+       buffers_alloc
+FROM pg_stat_bgwriter;
+`
+	queryCheckpointer = `
+SELECT num_timed      AS checkpoints_timed,
+       num_requested  AS checkpoints_req,
+       write_time     AS checkpoint_write_time,
+       sync_time      AS checkpoint_sync_time,
+       buffers_written AS buffers_checkpoint
+FROM pg_stat_checkpointer;
+`
+)
+
+func (p *Postgres) collectBGWriter(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-BGWRITER-VIEW
+	// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-CHECKPOINTER-VIEW
+	assign := func(row map[string]string) {
+		for name, value := range row {
+			collected[name] = parseInt(value)
+		}
+	}
+
+	if p.serverVersion < pgVersion17 {
+		return p.doQuery(queryBGWriter, assign)
+	}
+	if err := p.doQuery(queryBGWriterV17, assign); err != nil {
+		return err
+	}
+	return p.doQuery(queryCheckpointer, assign)
+}
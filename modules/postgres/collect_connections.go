@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"strings"
+)
+
+const (
+	queryConnectionsState = `
+SELECT state,
+       COUNT(*) AS count
+FROM pg_stat_activity
+WHERE backend_type = 'client backend'
+GROUP BY state;
+`
+	// 'backend_type' column was added in v10, prior to it pg_stat_activity shows only client backends.
+	queryConnectionsStateV96 = `
+SELECT state,
+       COUNT(*) AS count
+FROM pg_stat_activity
+GROUP BY state;
+`
+	queryMaxConnections = "SHOW max_connections"
+)
+
+var connectionStates = []string{
+	"active",
+	"idle",
+	"idle_in_transaction",
+	"idle_in_transaction_aborted",
+	"fastpath_function_call",
+	"disabled",
+}
+
+func (p *Postgres) collectConnections(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-ACTIVITY-VIEW
+	query := queryConnectionsState
+	if p.serverVersion < pgVersion10 {
+		query = queryConnectionsStateV96
+	}
+
+	for _, state := range connectionStates {
+		collected["conn_state_"+state] = 0
+	}
+
+	var total int64
+	err := p.doQuery(query, func(row map[string]string) {
+		state, ok := row["state"]
+		if !ok {
+			return
+		}
+		state = strings.NewReplacer(" ", "_", "(", "", ")", "").Replace(state)
+		count := parseInt(row["count"])
+		collected["conn_state_"+state] += count
+		total += count
+	})
+	if err != nil {
+		return err
+	}
+
+	var maxConns int64
+	err = p.doQuery(queryMaxConnections, func(row map[string]string) {
+		maxConns = parseInt(row["max_connections"])
+	})
+	if err != nil {
+		return err
+	}
+
+	collected["connections"] = total
+	collected["max_connections"] = maxConns
+	if maxConns > 0 {
+		collected["connections_utilization"] = total * 100 * precision / maxConns
+	} else {
+		collected["connections_utilization"] = 0
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package postgres
+
+const queryDatabaseStats = `
+SELECT datname,
+       numbackends,
+       xact_commit,
+       xact_rollback,
+       blks_read,
+       blks_hit,
+       tup_returned,
+       tup_fetched,
+       tup_inserted,
+       tup_updated,
+       tup_deleted,
+       conflicts,
+       temp_files,
+       temp_bytes,
+       deadlocks,
+       pg_database_size(datname) AS size
+FROM pg_stat_database
+WHERE datname IS NOT NULL
+  AND datname NOT IN ('template0', 'template1');
+`
+
+var databaseStatsMetrics = []string{
+	"numbackends",
+	"xact_commit",
+	"xact_rollback",
+	"blks_read",
+	"blks_hit",
+	"tup_returned",
+	"tup_fetched",
+	"tup_inserted",
+	"tup_updated",
+	"tup_deleted",
+	"conflicts",
+	"temp_files",
+	"temp_bytes",
+	"deadlocks",
+	"size",
+}
+
+func (p *Postgres) collectDatabases(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-DATABASE-VIEW
+	seen := make(map[string]bool)
+	err := p.doQuery(queryDatabaseStats, func(row map[string]string) {
+		name := row["datname"]
+		if name == "" {
+			return
+		}
+		seen[name] = true
+
+		px := "db_" + name + "_"
+		for _, metric := range databaseStatsMetrics {
+			if v, ok := row[metric]; ok {
+				collected[px+metric] = parseInt(v)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for name := range seen {
+		if !p.collectedDatabases[name] {
+			p.collectedDatabases[name] = true
+			p.addDatabaseCharts(name)
+		}
+	}
+	for name := range p.collectedDatabases {
+		if !seen[name] {
+			delete(p.collectedDatabases, name)
+			p.removeDatabaseCharts(name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"strings"
+)
+
+const queryLocks = `
+SELECT mode,
+       granted,
+       COUNT(*) AS count
+FROM pg_locks
+GROUP BY mode, granted;
+`
+
+// https://www.postgresql.org/docs/current/explicit-locking.html#LOCKING-TABLES
+var lockModes = []string{
+	"AccessShareLock",
+	"RowShareLock",
+	"RowExclusiveLock",
+	"ShareUpdateExclusiveLock",
+	"ShareLock",
+	"ShareRowExclusiveLock",
+	"ExclusiveLock",
+	"AccessExclusiveLock",
+}
+
+func (p *Postgres) collectLocks(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/view-pg-locks.html
+	for _, mode := range lockModes {
+		mode = strings.ToLower(mode)
+		collected["locks_held_"+mode] = 0
+		collected["locks_awaited_"+mode] = 0
+	}
+
+	return p.doQuery(queryLocks, func(row map[string]string) {
+		mode := strings.ToLower(row["mode"])
+		if mode == "" {
+			return
+		}
+		if row["granted"] == "true" || row["granted"] == "t" {
+			collected["locks_held_"+mode] += parseInt(row["count"])
+		} else {
+			collected["locks_awaited_"+mode] += parseInt(row["count"])
+		}
+	})
+}
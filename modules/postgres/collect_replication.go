@@ -0,0 +1,120 @@
+package postgres
+
+const (
+	queryStandbys = `
+SELECT application_name,
+       COALESCE(pg_wal_lsn_diff(pg_current_wal_lsn(), replay_lsn), 0)  AS replay_lag_bytes,
+       COALESCE(EXTRACT(EPOCH FROM write_lag) * 1000, 0)               AS write_lag,
+       COALESCE(EXTRACT(EPOCH FROM flush_lag) * 1000, 0)               AS flush_lag,
+       COALESCE(EXTRACT(EPOCH FROM replay_lag) * 1000, 0)              AS replay_lag
+FROM pg_stat_replication;
+`
+	// lag time columns were added in v10.
+	queryStandbysV96 = `
+SELECT application_name,
+       COALESCE(pg_xlog_location_diff(pg_current_xlog_location(), replay_location), 0) AS replay_lag_bytes
+FROM pg_stat_replication;
+`
+	queryReplicationSlots = `
+SELECT slot_name,
+       pg_wal_lsn_diff(pg_current_wal_lsn(), restart_lsn) AS retained_bytes
+FROM pg_replication_slots
+WHERE restart_lsn IS NOT NULL;
+`
+	queryReplicationSlotsV96 = `
+SELECT slot_name,
+       pg_xlog_location_diff(pg_current_xlog_location(), restart_lsn) AS retained_bytes
+FROM pg_replication_slots
+WHERE restart_lsn IS NOT NULL;
+`
+)
+
+var standbyMetrics = []string{
+	"replay_lag_bytes",
+	"write_lag",
+	"flush_lag",
+	"replay_lag",
+}
+
+func (p *Postgres) collectReplication(collected map[string]int64) error {
+	if err := p.collectStandbys(collected); err != nil {
+		return err
+	}
+	return p.collectReplicationSlots(collected)
+}
+
+func (p *Postgres) collectStandbys(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-REPLICATION-VIEW
+	query := queryStandbys
+	if p.serverVersion < pgVersion10 {
+		query = queryStandbysV96
+	}
+
+	seen := make(map[string]bool)
+	err := p.doQuery(query, func(row map[string]string) {
+		app := row["application_name"]
+		if app == "" {
+			return
+		}
+		seen[app] = true
+
+		px := "standby_" + app + "_"
+		for _, metric := range standbyMetrics {
+			if v, ok := row[metric]; ok {
+				collected[px+metric] = parseInt(v)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for app := range seen {
+		if !p.collectedStandbys[app] {
+			p.collectedStandbys[app] = true
+			p.addStandbyCharts(app)
+		}
+	}
+	for app := range p.collectedStandbys {
+		if !seen[app] {
+			delete(p.collectedStandbys, app)
+			p.removeStandbyCharts(app)
+		}
+	}
+	return nil
+}
+
+func (p *Postgres) collectReplicationSlots(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/view-pg-replication-slots.html
+	query := queryReplicationSlots
+	if p.serverVersion < pgVersion10 {
+		query = queryReplicationSlotsV96
+	}
+
+	seen := make(map[string]bool)
+	err := p.doQuery(query, func(row map[string]string) {
+		slot := row["slot_name"]
+		if slot == "" {
+			return
+		}
+		seen[slot] = true
+		collected["repl_slot_"+slot+"_retained_bytes"] = parseInt(row["retained_bytes"])
+	})
+	if err != nil {
+		return err
+	}
+
+	for slot := range seen {
+		if !p.collectedReplSlots[slot] {
+			p.collectedReplSlots[slot] = true
+			p.addReplSlotCharts(slot)
+		}
+	}
+	for slot := range p.collectedReplSlots {
+		if !seen[slot] {
+			delete(p.collectedReplSlots, slot)
+			p.removeReplSlotCharts(slot)
+		}
+	}
+	return nil
+}
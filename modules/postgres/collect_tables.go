@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"fmt"
+)
+
+const queryTableStats = `
+SELECT schemaname,
+       relname,
+       seq_scan,
+       COALESCE(idx_scan, 0)           AS idx_scan,
+       n_tup_ins,
+       n_tup_upd,
+       n_tup_del,
+       n_tup_hot_upd,
+       n_live_tup,
+       n_dead_tup,
+       pg_total_relation_size(relid)   AS total_size
+FROM pg_stat_user_tables
+ORDER BY schemaname, relname
+LIMIT %d;
+`
+
+var tableStatsMetrics = []string{
+	"seq_scan",
+	"idx_scan",
+	"n_tup_ins",
+	"n_tup_upd",
+	"n_tup_del",
+	"n_tup_hot_upd",
+	"n_live_tup",
+	"n_dead_tup",
+	"total_size",
+}
+
+func (p *Postgres) collectTables(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-ALL-TABLES-VIEW
+	// statistics are collected only for the database we are connected to.
+	seen := make(map[string]bool)
+	err := p.doQuery(fmt.Sprintf(queryTableStats, p.MaxTables), func(row map[string]string) {
+		if row["relname"] == "" {
+			return
+		}
+		table := row["schemaname"] + "." + row["relname"]
+		seen[table] = true
+
+		px := "table_" + table + "_"
+		for _, metric := range tableStatsMetrics {
+			if v, ok := row[metric]; ok {
+				collected[px+metric] = parseInt(v)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for table := range seen {
+		if !p.collectedTables[table] {
+			p.collectedTables[table] = true
+			p.addTableCharts(table)
+		}
+	}
+	for table := range p.collectedTables {
+		if !seen[table] {
+			delete(p.collectedTables, table)
+			p.removeTableCharts(table)
+		}
+	}
+	return nil
+}
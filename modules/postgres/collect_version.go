@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const queryServerVersion = "SHOW server_version_num"
+
+const (
+	pgVersion10 = 100000
+	pgVersion17 = 170000
+)
+
+func (p *Postgres) collectServerVersion() (int, error) {
+	// https://www.postgresql.org/docs/current/runtime-config-preset.html#GUC-SERVER-VERSION-NUM
+	var ver int
+	err := p.doQuery(queryServerVersion, func(row map[string]string) {
+		ver, _ = strconv.Atoi(row["server_version_num"])
+	})
+	if err != nil {
+		return 0, err
+	}
+	if ver == 0 {
+		return 0, fmt.Errorf("couldn't parse server version num")
+	}
+
+	p.Debugf("server version num: %d", ver)
+	return ver, nil
+}
@@ -0,0 +1,35 @@
+package postgres
+
+const (
+	queryWAL = `
+SELECT pg_is_in_recovery()::int AS in_recovery,
+       CASE
+           WHEN pg_is_in_recovery() THEN 0
+           ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), '0/0')
+           END                      AS wal_written,
+       COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) AS standby_delay;
+`
+	// WAL functions were renamed in v10 ('xlog' => 'wal', 'location' => 'lsn').
+	queryWALV96 = `
+SELECT pg_is_in_recovery()::int AS in_recovery,
+       CASE
+           WHEN pg_is_in_recovery() THEN 0
+           ELSE pg_xlog_location_diff(pg_current_xlog_location(), '0/0')
+           END                      AS wal_written,
+       COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) AS standby_delay;
+`
+)
+
+func (p *Postgres) collectWAL(collected map[string]int64) error {
+	// https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
+	query := queryWAL
+	if p.serverVersion < pgVersion10 {
+		query = queryWALV96
+	}
+
+	return p.doQuery(query, func(row map[string]string) {
+		p.isInRecovery = row["in_recovery"] == "1"
+		collected["wal_written"] = parseInt(row["wal_written"])
+		collected["standby_delay"] = int64(parseFloat(row["standby_delay"]) * precision)
+	})
+}
@@ -0,0 +1,624 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pgV9624ServerVersionNum, _ = ioutil.ReadFile("testdata/v9.6.24/server_version_num.txt")
+	pgV9624ConnectionsState, _ = ioutil.ReadFile("testdata/v9.6.24/connections_state.txt")
+	pgV9624MaxConnections, _   = ioutil.ReadFile("testdata/v9.6.24/max_connections.txt")
+	pgV9624DatabaseStats, _    = ioutil.ReadFile("testdata/v9.6.24/database_stats.txt")
+	pgV9624Locks, _            = ioutil.ReadFile("testdata/v9.6.24/locks.txt")
+	pgV9624BGWriter, _         = ioutil.ReadFile("testdata/v9.6.24/bgwriter.txt")
+	pgV9624WAL, _              = ioutil.ReadFile("testdata/v9.6.24/wal.txt")
+	pgV9624Standbys, _         = ioutil.ReadFile("testdata/v9.6.24/standbys.txt")
+	pgV9624ReplicationSlots, _ = ioutil.ReadFile("testdata/v9.6.24/replication_slots.txt")
+	pgV9624TableStats, _       = ioutil.ReadFile("testdata/v9.6.24/table_stats.txt")
+
+	pgV134ServerVersionNum, _ = ioutil.ReadFile("testdata/v13.4/server_version_num.txt")
+	pgV134ConnectionsState, _ = ioutil.ReadFile("testdata/v13.4/connections_state.txt")
+	pgV134MaxConnections, _   = ioutil.ReadFile("testdata/v13.4/max_connections.txt")
+	pgV134DatabaseStats, _    = ioutil.ReadFile("testdata/v13.4/database_stats.txt")
+	pgV134Locks, _            = ioutil.ReadFile("testdata/v13.4/locks.txt")
+	pgV134BGWriter, _         = ioutil.ReadFile("testdata/v13.4/bgwriter.txt")
+	pgV134WAL, _              = ioutil.ReadFile("testdata/v13.4/wal.txt")
+	pgV134WALStandby, _       = ioutil.ReadFile("testdata/v13.4/wal_standby.txt")
+	pgV134Standbys, _         = ioutil.ReadFile("testdata/v13.4/standbys.txt")
+	pgV134ReplicationSlots, _ = ioutil.ReadFile("testdata/v13.4/replication_slots.txt")
+	pgV134TableStats, _       = ioutil.ReadFile("testdata/v13.4/table_stats.txt")
+
+	pgV170ServerVersionNum, _ = ioutil.ReadFile("testdata/v17.0/server_version_num.txt")
+	pgV170BGWriter, _         = ioutil.ReadFile("testdata/v17.0/bgwriter.txt")
+	pgV170Checkpointer, _     = ioutil.ReadFile("testdata/v17.0/checkpointer.txt")
+)
+
+var (
+	errPermissionDenied = errors.New("permission denied for function pg_total_relation_size")
+)
+
+func Test_testDataIsValid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"pgV9624ServerVersionNum": pgV9624ServerVersionNum,
+		"pgV9624ConnectionsState": pgV9624ConnectionsState,
+		"pgV9624MaxConnections":   pgV9624MaxConnections,
+		"pgV9624DatabaseStats":    pgV9624DatabaseStats,
+		"pgV9624Locks":            pgV9624Locks,
+		"pgV9624BGWriter":         pgV9624BGWriter,
+		"pgV9624WAL":              pgV9624WAL,
+		"pgV9624Standbys":         pgV9624Standbys,
+		"pgV9624ReplicationSlots": pgV9624ReplicationSlots,
+		"pgV9624TableStats":       pgV9624TableStats,
+
+		"pgV134ServerVersionNum": pgV134ServerVersionNum,
+		"pgV134ConnectionsState": pgV134ConnectionsState,
+		"pgV134MaxConnections":   pgV134MaxConnections,
+		"pgV134DatabaseStats":    pgV134DatabaseStats,
+		"pgV134Locks":            pgV134Locks,
+		"pgV134BGWriter":         pgV134BGWriter,
+		"pgV134WAL":              pgV134WAL,
+		"pgV134WALStandby":       pgV134WALStandby,
+		"pgV134Standbys":         pgV134Standbys,
+		"pgV134ReplicationSlots": pgV134ReplicationSlots,
+		"pgV134TableStats":       pgV134TableStats,
+
+		"pgV170ServerVersionNum": pgV170ServerVersionNum,
+		"pgV170BGWriter":         pgV170BGWriter,
+		"pgV170Checkpointer":     pgV170Checkpointer,
+	} {
+		require.NotNilf(t, data, fmt.Sprintf("read data: %s", name))
+		_, err := prepareMockRows(data)
+		require.NoErrorf(t, err, fmt.Sprintf("prepare mock rows: %s", name))
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestPostgres_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"default config": {
+			config: New().Config,
+		},
+		"empty DSN": {
+			config:   Config{DSN: ""},
+			wantFail: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pg := New()
+			pg.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, pg.Init())
+			} else {
+				assert.True(t, pg.Init())
+			}
+		})
+	}
+}
+
+func TestPostgres_Cleanup(t *testing.T) {
+	tests := map[string]func(t *testing.T) (pg *Postgres, cleanup func()){
+		"db connection not initialized": func(t *testing.T) (pg *Postgres, cleanup func()) {
+			return New(), func() {}
+		},
+		"db connection initialized": func(t *testing.T) (pg *Postgres, cleanup func()) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			mock.ExpectClose()
+			pg = New()
+			pg.db = db
+			cleanup = func() { _ = db.Close() }
+
+			return pg, cleanup
+		},
+	}
+
+	for name, prepare := range tests {
+		t.Run(name, func(t *testing.T) {
+			pg, cleanup := prepare(t)
+			defer cleanup()
+
+			assert.NotPanics(t, pg.Cleanup)
+			assert.Nil(t, pg.db)
+		})
+	}
+}
+
+func TestPostgres_Charts(t *testing.T) {
+	assert.NotNil(t, New().Charts())
+}
+
+func TestPostgres_Check(t *testing.T) {
+	tests := map[string]struct {
+		prepare   func(t *testing.T, mock sqlmock.Sqlmock)
+		wantFalse bool
+	}{
+		"all queries success": {
+			prepare: expectV134Primary,
+		},
+		"fail when server version query error": {
+			wantFalse: true,
+			prepare: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(queryServerVersion).
+					WillReturnError(errPermissionDenied)
+			},
+		},
+		"fail when database stats query error": {
+			wantFalse: true,
+			prepare: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(queryServerVersion).
+					WillReturnRows(mustMockRows(t, pgV134ServerVersionNum))
+				mock.ExpectQuery(queryConnectionsState).
+					WillReturnRows(mustMockRows(t, pgV134ConnectionsState))
+				mock.ExpectQuery(queryMaxConnections).
+					WillReturnRows(mustMockRows(t, pgV134MaxConnections))
+				mock.ExpectQuery(queryDatabaseStats).
+					WillReturnError(errPermissionDenied)
+			},
+		},
+		"success when tables query error": {
+			prepare: func(t *testing.T, mock sqlmock.Sqlmock) {
+				expectV134Common(t, mock, pgV134WAL)
+				mock.ExpectQuery(queryStandbys).
+					WillReturnRows(mustMockRows(t, pgV134Standbys))
+				mock.ExpectQuery(queryReplicationSlots).
+					WillReturnRows(mustMockRows(t, pgV134ReplicationSlots))
+				mock.ExpectQuery(fmt.Sprintf(queryTableStats, 50)).
+					WillReturnError(errPermissionDenied)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pg, mock, cleanup := prepareMockPostgres(t)
+			defer cleanup()
+
+			test.prepare(t, mock)
+			require.True(t, pg.Init())
+
+			if test.wantFalse {
+				assert.False(t, pg.Check())
+			} else {
+				assert.True(t, pg.Check())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgres_Collect(t *testing.T) {
+	tests := map[string]struct {
+		prepare        func(t *testing.T, mock sqlmock.Sqlmock)
+		expected       map[string]int64
+		expectedCharts []string
+	}{
+		"v13.4: primary": {
+			prepare: expectV134Primary,
+			expected: mergeMetrics(
+				commonMetrics(),
+				map[string]int64{
+					"buffers_backend":                      3215,
+					"buffers_backend_fsync":                0,
+					"buffers_clean":                        0,
+					"maxwritten_clean":                     0,
+					"wal_written":                          1623453224,
+					"standby_delay":                        0,
+					"standby_walreceiver_replay_lag_bytes": 1048576,
+					"standby_walreceiver_write_lag":        0,
+					"standby_walreceiver_flush_lag":        1,
+					"standby_walreceiver_replay_lag":       3,
+					"repl_slot_standby_1_retained_bytes":   16777216,
+					"table_public.orders_seq_scan":         12,
+					"table_public.orders_idx_scan":         3601,
+					"table_public.orders_n_tup_ins":        5000,
+					"table_public.orders_n_tup_upd":        120,
+					"table_public.orders_n_tup_del":        15,
+					"table_public.orders_n_tup_hot_upd":    80,
+					"table_public.orders_n_live_tup":       4985,
+					"table_public.orders_n_dead_tup":       135,
+					"table_public.orders_total_size":       819200,
+					"table_public.users_seq_scan":          40,
+					"table_public.users_idx_scan":          0,
+					"table_public.users_n_tup_ins":         200,
+					"table_public.users_n_tup_upd":         3,
+					"table_public.users_n_tup_del":         0,
+					"table_public.users_n_tup_hot_upd":     3,
+					"table_public.users_n_live_tup":        200,
+					"table_public.users_n_dead_tup":        3,
+					"table_public.users_total_size":        65536,
+				},
+			),
+			expectedCharts: []string{
+				"buffers_backend_fsync",
+				"db_postgres_transactions",
+				"db_production_size",
+				"standby_walreceiver_lag_bytes",
+				"standby_walreceiver_lag_time",
+				"repl_slot_standby_1_retained_bytes",
+				"table_public_orders_rows",
+				"table_public_users_size",
+			},
+		},
+		"v13.4: standby": {
+			prepare: func(t *testing.T, mock sqlmock.Sqlmock) {
+				expectV134Common(t, mock, pgV134WALStandby)
+				mock.ExpectQuery(fmt.Sprintf(queryTableStats, 50)).
+					WillReturnRows(sqlmock.NewRows([]string{"schemaname", "relname"}))
+			},
+			expected: mergeMetrics(
+				commonMetrics(),
+				map[string]int64{
+					"buffers_backend":       3215,
+					"buffers_backend_fsync": 0,
+					"buffers_clean":         0,
+					"maxwritten_clean":      0,
+					"wal_written":           0,
+					"standby_delay":         2512,
+				},
+			),
+			expectedCharts: []string{
+				"standby_delay",
+				"db_postgres_transactions",
+			},
+		},
+		"v9.6.24: primary": {
+			prepare: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(queryServerVersion).
+					WillReturnRows(mustMockRows(t, pgV9624ServerVersionNum))
+				mock.ExpectQuery(queryConnectionsStateV96).
+					WillReturnRows(mustMockRows(t, pgV9624ConnectionsState))
+				mock.ExpectQuery(queryMaxConnections).
+					WillReturnRows(mustMockRows(t, pgV9624MaxConnections))
+				mock.ExpectQuery(queryDatabaseStats).
+					WillReturnRows(mustMockRows(t, pgV9624DatabaseStats))
+				mock.ExpectQuery(queryLocks).
+					WillReturnRows(mustMockRows(t, pgV9624Locks))
+				mock.ExpectQuery(queryBGWriter).
+					WillReturnRows(mustMockRows(t, pgV9624BGWriter))
+				mock.ExpectQuery(queryWALV96).
+					WillReturnRows(mustMockRows(t, pgV9624WAL))
+				mock.ExpectQuery(queryStandbysV96).
+					WillReturnRows(mustMockRows(t, pgV9624Standbys))
+				mock.ExpectQuery(queryReplicationSlotsV96).
+					WillReturnRows(mustMockRows(t, pgV9624ReplicationSlots))
+				mock.ExpectQuery(fmt.Sprintf(queryTableStats, 50)).
+					WillReturnRows(mustMockRows(t, pgV9624TableStats))
+			},
+			expected: mergeMetrics(
+				commonMetrics(),
+				map[string]int64{
+					"buffers_backend":                      3215,
+					"buffers_backend_fsync":                0,
+					"buffers_clean":                        0,
+					"maxwritten_clean":                     0,
+					"wal_written":                          1623453224,
+					"standby_delay":                        0,
+					"standby_walreceiver_replay_lag_bytes": 1048576,
+					"repl_slot_standby_1_retained_bytes":   16777216,
+					"table_public.orders_seq_scan":         12,
+					"table_public.orders_idx_scan":         3601,
+					"table_public.orders_n_tup_ins":        5000,
+					"table_public.orders_n_tup_upd":        120,
+					"table_public.orders_n_tup_del":        15,
+					"table_public.orders_n_tup_hot_upd":    80,
+					"table_public.orders_n_live_tup":       4985,
+					"table_public.orders_n_dead_tup":       135,
+					"table_public.orders_total_size":       819200,
+					"table_public.users_seq_scan":          40,
+					"table_public.users_idx_scan":          0,
+					"table_public.users_n_tup_ins":         200,
+					"table_public.users_n_tup_upd":         3,
+					"table_public.users_n_tup_del":         0,
+					"table_public.users_n_tup_hot_upd":     3,
+					"table_public.users_n_live_tup":        200,
+					"table_public.users_n_dead_tup":        3,
+					"table_public.users_total_size":        65536,
+				},
+			),
+			expectedCharts: []string{
+				"buffers_backend_fsync",
+				"standby_walreceiver_lag_bytes",
+			},
+		},
+		"v17.0: checkpointer": {
+			prepare: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(queryServerVersion).
+					WillReturnRows(mustMockRows(t, pgV170ServerVersionNum))
+				mock.ExpectQuery(queryConnectionsState).
+					WillReturnRows(mustMockRows(t, pgV134ConnectionsState))
+				mock.ExpectQuery(queryMaxConnections).
+					WillReturnRows(mustMockRows(t, pgV134MaxConnections))
+				mock.ExpectQuery(queryDatabaseStats).
+					WillReturnRows(mustMockRows(t, pgV134DatabaseStats))
+				mock.ExpectQuery(queryLocks).
+					WillReturnRows(mustMockRows(t, pgV134Locks))
+				mock.ExpectQuery(queryBGWriterV17).
+					WillReturnRows(mustMockRows(t, pgV170BGWriter))
+				mock.ExpectQuery(queryCheckpointer).
+					WillReturnRows(mustMockRows(t, pgV170Checkpointer))
+				mock.ExpectQuery(queryWAL).
+					WillReturnRows(mustMockRows(t, pgV134WALStandby))
+				mock.ExpectQuery(fmt.Sprintf(queryTableStats, 50)).
+					WillReturnRows(sqlmock.NewRows([]string{"schemaname", "relname"}))
+			},
+			expected: mergeMetrics(
+				commonMetrics(),
+				map[string]int64{
+					"buffers_clean":    12,
+					"maxwritten_clean": 1,
+					"wal_written":      0,
+					"standby_delay":    2512,
+				},
+			),
+			expectedCharts: []string{
+				"buffers_written",
+				"standby_delay",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pg, mock, cleanup := prepareMockPostgres(t)
+			defer cleanup()
+
+			test.prepare(t, mock)
+			require.True(t, pg.Init())
+
+			collected := pg.Collect()
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Equal(t, test.expected, collected)
+			for _, id := range test.expectedCharts {
+				assert.Truef(t, pg.Charts().Has(id), "chart '%s' is not in charts", id)
+			}
+			ensureCollectedHasAllChartsDimsVarsIDs(t, pg, collected)
+		})
+	}
+}
+
+func TestPostgres_Collect_RemovesObsoleteCharts(t *testing.T) {
+	pg, mock, cleanup := prepareMockPostgres(t)
+	defer cleanup()
+
+	expectV134Primary(t, mock)
+	require.True(t, pg.Init())
+	require.NotNil(t, pg.Collect())
+	require.True(t, pg.Charts().Has("db_production_size"))
+
+	mock.ExpectQuery(queryConnectionsState).
+		WillReturnRows(mustMockRows(t, pgV134ConnectionsState))
+	mock.ExpectQuery(queryMaxConnections).
+		WillReturnRows(mustMockRows(t, pgV134MaxConnections))
+	mock.ExpectQuery(queryDatabaseStats).
+		WillReturnRows(sqlmock.NewRows([]string{"datname", "size"}).AddRow("postgres", "8602115"))
+	mock.ExpectQuery(queryLocks).
+		WillReturnRows(mustMockRows(t, pgV134Locks))
+	mock.ExpectQuery(queryBGWriter).
+		WillReturnRows(mustMockRows(t, pgV134BGWriter))
+	mock.ExpectQuery(queryWAL).
+		WillReturnRows(mustMockRows(t, pgV134WAL))
+	mock.ExpectQuery(queryStandbys).
+		WillReturnRows(sqlmock.NewRows([]string{"application_name"}))
+	mock.ExpectQuery(queryReplicationSlots).
+		WillReturnRows(mustMockRows(t, pgV134ReplicationSlots))
+	mock.ExpectQuery(fmt.Sprintf(queryTableStats, 50)).
+		WillReturnRows(mustMockRows(t, pgV134TableStats))
+
+	require.NotNil(t, pg.Collect())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	for _, id := range []string{"db_production_size", "standby_walreceiver_lag_bytes"} {
+		chart := pg.Charts().Get(id)
+		require.NotNil(t, chart)
+		assert.Truef(t, chart.Obsolete, "chart '%s' is not obsolete", id)
+	}
+	assert.False(t, pg.Charts().Get("db_postgres_size").Obsolete)
+}
+
+func prepareMockPostgres(t *testing.T) (pg *Postgres, mock sqlmock.Sqlmock, cleanup func()) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	pg = New()
+	pg.db = db
+	return pg, mock, func() { _ = db.Close() }
+}
+
+func expectV134Common(t *testing.T, mock sqlmock.Sqlmock, wal []byte) {
+	mock.ExpectQuery(queryServerVersion).
+		WillReturnRows(mustMockRows(t, pgV134ServerVersionNum))
+	mock.ExpectQuery(queryConnectionsState).
+		WillReturnRows(mustMockRows(t, pgV134ConnectionsState))
+	mock.ExpectQuery(queryMaxConnections).
+		WillReturnRows(mustMockRows(t, pgV134MaxConnections))
+	mock.ExpectQuery(queryDatabaseStats).
+		WillReturnRows(mustMockRows(t, pgV134DatabaseStats))
+	mock.ExpectQuery(queryLocks).
+		WillReturnRows(mustMockRows(t, pgV134Locks))
+	mock.ExpectQuery(queryBGWriter).
+		WillReturnRows(mustMockRows(t, pgV134BGWriter))
+	mock.ExpectQuery(queryWAL).
+		WillReturnRows(mustMockRows(t, wal))
+}
+
+func expectV134Primary(t *testing.T, mock sqlmock.Sqlmock) {
+	expectV134Common(t, mock, pgV134WAL)
+	mock.ExpectQuery(queryStandbys).
+		WillReturnRows(mustMockRows(t, pgV134Standbys))
+	mock.ExpectQuery(queryReplicationSlots).
+		WillReturnRows(mustMockRows(t, pgV134ReplicationSlots))
+	mock.ExpectQuery(fmt.Sprintf(queryTableStats, 50)).
+		WillReturnRows(mustMockRows(t, pgV134TableStats))
+}
+
+func commonMetrics() map[string]int64 {
+	return map[string]int64{
+		"conn_state_active":                      1,
+		"conn_state_idle":                        14,
+		"conn_state_idle_in_transaction":         7,
+		"conn_state_idle_in_transaction_aborted": 1,
+		"conn_state_fastpath_function_call":      0,
+		"conn_state_disabled":                    0,
+		"connections":                            23,
+		"max_connections":                        100,
+		"connections_utilization":                23000,
+		"db_postgres_numbackends":                3,
+		"db_postgres_xact_commit":                1438660,
+		"db_postgres_xact_rollback":              70,
+		"db_postgres_blks_read":                  5542,
+		"db_postgres_blks_hit":                   63936022,
+		"db_postgres_tup_returned":               36979281,
+		"db_postgres_tup_fetched":                1113457,
+		"db_postgres_tup_inserted":               10,
+		"db_postgres_tup_updated":                4,
+		"db_postgres_tup_deleted":                1,
+		"db_postgres_conflicts":                  0,
+		"db_postgres_temp_files":                 2,
+		"db_postgres_temp_bytes":                 16384,
+		"db_postgres_deadlocks":                  0,
+		"db_postgres_size":                       8602115,
+		"db_production_numbackends":              20,
+		"db_production_xact_commit":              4885,
+		"db_production_xact_rollback":            1,
+		"db_production_blks_read":                398,
+		"db_production_blks_hit":                 250476,
+		"db_production_tup_returned":             1356219,
+		"db_production_tup_fetched":              46752,
+		"db_production_tup_inserted":             304,
+		"db_production_tup_updated":              17,
+		"db_production_tup_deleted":              2,
+		"db_production_conflicts":                0,
+		"db_production_temp_files":               0,
+		"db_production_temp_bytes":               0,
+		"db_production_deadlocks":                1,
+		"db_production_size":                     22048547,
+		"locks_held_accesssharelock":             8,
+		"locks_held_rowsharelock":                0,
+		"locks_held_rowexclusivelock":            2,
+		"locks_held_shareupdateexclusivelock":    0,
+		"locks_held_sharelock":                   0,
+		"locks_held_sharerowexclusivelock":       0,
+		"locks_held_exclusivelock":               4,
+		"locks_held_accessexclusivelock":         0,
+		"locks_awaited_accesssharelock":          0,
+		"locks_awaited_rowsharelock":             0,
+		"locks_awaited_rowexclusivelock":         0,
+		"locks_awaited_shareupdateexclusivelock": 0,
+		"locks_awaited_sharelock":                1,
+		"locks_awaited_sharerowexclusivelock":    0,
+		"locks_awaited_exclusivelock":            0,
+		"locks_awaited_accessexclusivelock":      0,
+		"checkpoints_timed":                      1814,
+		"checkpoints_req":                        16,
+		"checkpoint_write_time":                  167095,
+		"checkpoint_sync_time":                   108,
+		"buffers_checkpoint":                     24735,
+		"buffers_alloc":                          5907,
+	}
+}
+
+func mergeMetrics(maps ...map[string]int64) map[string]int64 {
+	merged := make(map[string]int64)
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, pg *Postgres, collected map[string]int64) {
+	for _, chart := range *pg.Charts() {
+		if chart.Obsolete {
+			continue
+		}
+		for _, dim := range chart.Dims {
+			_, ok := collected[dim.ID]
+			assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+		}
+		for _, v := range chart.Vars {
+			_, ok := collected[v.ID]
+			assert.Truef(t, ok, "collected metrics has no data for var '%s' chart '%s'", v.ID, chart.ID)
+		}
+	}
+}
+
+func mustMockRows(t *testing.T, data []byte) *sqlmock.Rows {
+	rows, err := prepareMockRows(data)
+	require.NoError(t, err)
+	return rows
+}
+
+// prepareMockRows parses psql expanded display ('\x') output.
+func prepareMockRows(data []byte) (*sqlmock.Rows, error) {
+	r := bytes.NewReader(data)
+	sc := bufio.NewScanner(r)
+
+	set := make(map[string]bool)
+	var columns []string
+	var lines [][]driver.Value
+	var values []driver.Value
+
+	for sc.Scan() {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if isNewRow := strings.HasPrefix(text, "-[ RECORD"); isNewRow {
+			if len(values) != 0 {
+				lines = append(lines, values)
+				values = []driver.Value{}
+			}
+			continue
+		}
+
+		idx := strings.IndexByte(text, '|')
+		// not interested in multi line values
+		if idx == -1 {
+			continue
+		}
+
+		name := strings.TrimSpace(text[:idx])
+		value := strings.TrimSpace(text[idx+1:])
+		if !set[name] {
+			set[name] = true
+			columns = append(columns, name)
+		}
+		values = append(values, value)
+	}
+	if len(values) != 0 {
+		lines = append(lines, values)
+	}
+
+	rows := sqlmock.NewRows(columns)
+	for _, values := range lines {
+		if len(columns) != len(values) {
+			return nil, fmt.Errorf("columns != values (%d/%d)", len(columns), len(values))
+		}
+		rows.AddRow(values...)
+	}
+	return rows, nil
+}
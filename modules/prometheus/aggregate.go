@@ -0,0 +1,129 @@
+package prometheus
+
+import (
+	"math"
+	"sort"
+
+	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/selector"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+)
+
+const (
+	aggrSum   = "sum"
+	aggrAvg   = "avg"
+	aggrMin   = "min"
+	aggrMax   = "max"
+	aggrCount = "count"
+)
+
+type (
+	aggregation struct {
+		sr      selector.Selector
+		fn      string
+		by      []string
+		without []string
+	}
+	aggrGroup struct {
+		fn    string
+		lbs   labels.Labels
+		sum   float64
+		min   float64
+		max   float64
+		count int
+	}
+)
+
+// aggregate replaces the time series matched by the aggregation rules with the aggregated ones.
+// A time series is aggregated by the first matching rule. The metric name is always a part of the grouping,
+// so every metric is aggregated separately.
+func (p *Prometheus) aggregate(pms prometheus.Metrics) prometheus.Metrics {
+	if len(p.aggregations) == 0 {
+		return pms
+	}
+
+	res := make(prometheus.Metrics, 0, len(pms))
+	groups := make(map[uint64]*aggrGroup)
+	var order []*aggrGroup
+
+	for _, pm := range pms {
+		aggr := p.findAggregation(pm.Labels)
+		if aggr == nil {
+			res.Add(pm)
+			continue
+		}
+		if math.IsNaN(pm.Value) {
+			continue
+		}
+
+		lbs := aggr.groupLabels(pm.Labels)
+		hash := lbs.Hash()
+		grp, ok := groups[hash]
+		if !ok {
+			grp = &aggrGroup{fn: aggr.fn, lbs: lbs, min: pm.Value, max: pm.Value}
+			groups[hash] = grp
+			order = append(order, grp)
+		}
+		grp.observe(pm.Value)
+	}
+
+	for _, grp := range order {
+		res.Add(prometheus.Metric{Labels: grp.lbs, Value: grp.value()})
+	}
+	// aggregated time series are appended, restore the scrape order (sorted by name)
+	sort.Stable(res)
+	return res
+}
+
+func (p *Prometheus) findAggregation(lbs labels.Labels) *aggregation {
+	for i, aggr := range p.aggregations {
+		if aggr.sr.Matches(lbs) {
+			return &p.aggregations[i]
+		}
+	}
+	return nil
+}
+
+// groupLabels returns the metric name and the grouping labels.
+func (a aggregation) groupLabels(lbs labels.Labels) labels.Labels {
+	res := labels.Labels{lbs[0]}
+	for _, l := range lbs[1:] {
+		switch {
+		case len(a.without) > 0:
+			if contains(a.without, l.Name) {
+				continue
+			}
+		case !contains(a.by, l.Name):
+			continue
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+func (g *aggrGroup) observe(v float64) {
+	g.sum += v
+	g.count++
+	if v < g.min {
+		g.min = v
+	}
+	if v > g.max {
+		g.max = v
+	}
+}
+
+func (g aggrGroup) value() float64 {
+	switch g.fn {
+	case aggrAvg:
+		return g.sum / float64(g.count)
+	case aggrMin:
+		return g.min
+	case aggrMax:
+		return g.max
+	case aggrCount:
+		return float64(g.count)
+	default:
+		return g.sum
+	}
+}
@@ -31,7 +31,10 @@ var statsCharts = Charts{
 }
 
 func anyChart(id string, pm prometheus.Metric, meta prometheus.Metadata) *module.Chart {
-	units := extractUnits(pm.Name())
+	units := meta.Unit(pm.Name())
+	if units == "" {
+		units = extractUnits(pm.Name())
+	}
 	if isIncremental(pm, meta) && !isIncrementalUnitsException(units) {
 		units += "/s"
 	}
@@ -72,6 +75,17 @@ func histogramChart(id string, pm prometheus.Metric, meta prometheus.Metadata) *
 	return summaryChart(id, pm, meta)
 }
 
+func statesetChart(id string, pm prometheus.Metric, meta prometheus.Metadata) *module.Chart {
+	return &module.Chart{
+		ID:    id,
+		Title: chartTitle(pm, meta),
+		Units: "state",
+		Fam:   chartFamily(pm),
+		Ctx:   "prometheus." + pm.Name(),
+		Type:  module.Line,
+	}
+}
+
 func chartTitle(pm prometheus.Metric, meta prometheus.Metadata) string {
 	if help := meta.Help(pm.Name()); help != "" {
 		// ' used to wrap external plugins api messages, netdata parser cant handle ' inside ''
@@ -130,6 +144,14 @@ func histogramChartDim(id, name string) *module.Dim {
 	}
 }
 
+func statesetChartDim(id, name string) *module.Dim {
+	return &module.Dim{
+		ID:   id,
+		Name: name,
+		Algo: module.Absolute,
+	}
+}
+
 func extractUnits(metric string) string {
 	// https://prometheus.io/docs/practices/naming/#metric-names
 	// ...must have a single unit (i.e. do not mix seconds with milliseconds, or seconds with bytes).
@@ -20,6 +20,8 @@ func (p *Prometheus) collect() (map[string]int64, error) {
 		return nil, err
 	}
 
+	pms = p.aggregate(pms)
+
 	switch {
 	case len(pms) == 0:
 		p.Warningf("endpoint '%s' returned 0 time series", p.URL)
@@ -48,8 +50,10 @@ func (p *Prometheus) collect() (map[string]int64, error) {
 		}
 
 		switch meta.Type(name) {
-		case textparse.MetricTypeGauge, textparse.MetricTypeCounter:
+		case textparse.MetricTypeGauge, textparse.MetricTypeCounter, textparse.MetricTypeInfo:
 			p.collectAny(mx, metrics, meta)
+		case textparse.MetricTypeStateset:
+			p.collectStateset(mx, metrics, meta)
 		case textparse.MetricTypeSummary:
 			p.collectSummary(mx, metrics, meta)
 		case textparse.MetricTypeHistogram:
@@ -58,6 +62,10 @@ func (p *Prometheus) collect() (map[string]int64, error) {
 			p.collectUnknown(mx, metrics, meta)
 		}
 	}
+	if len(p.Derived.Quantiles) > 0 || p.Derived.Average {
+		p.collectDerived(mx, names, metricSet, meta)
+	}
+
 	p.Debugf("time series: %d, metrics: %d, charts: %d", len(pms), len(names), len(*p.Charts()))
 	mx["series"] = int64(len(pms))
 	mx["metrics"] = int64(len(names))
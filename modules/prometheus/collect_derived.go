@@ -0,0 +1,220 @@
+package prometheus
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/prometheus"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+)
+
+// derivedValues are the previous cumulative values of a histogram or summary time series.
+type derivedValues struct {
+	buckets  prometheus.Buckets
+	sum      float64
+	count    float64
+	hasAvg   bool
+	hasQuant bool
+}
+
+// collectDerived collects quantiles estimated from histogram buckets and average values (from '_sum' and '_count')
+// of histograms and summaries. The values are calculated from the increase since the previous collection.
+func (p *Prometheus) collectDerived(mx map[string]int64, names []string, metricSet map[string]prometheus.Metrics, meta prometheus.Metadata) {
+	for _, name := range names {
+		pms := metricSet[name]
+		if len(pms) == 0 || p.skipMetrics[name] {
+			continue
+		}
+		switch meta.Type(name) {
+		case textparse.MetricTypeHistogram, textparse.MetricTypeSummary, textparse.MetricTypeUnknown:
+		default:
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(name, "_bucket") && pms[0].Labels.Has("le"):
+			base := strings.TrimSuffix(name, "_bucket")
+			p.collectDerivedSet(mx, base, pms, metricSet[base+"_sum"], metricSet[base+"_count"], meta)
+		case pms[0].Labels.Has("quantile") && p.Derived.Average:
+			p.collectDerivedSet(mx, name, nil, metricSet[name+"_sum"], metricSet[name+"_count"], meta)
+		}
+	}
+}
+
+func (p *Prometheus) collectDerivedSet(mx map[string]int64, base string, buckets, sums, counts prometheus.Metrics, meta prometheus.Metadata) {
+	cacheName := base + "_derived"
+	if !p.cache.has(cacheName) {
+		p.cache.put(cacheName, newCacheEntry(nil))
+	}
+	defer p.cleanupStaleDerivedCharts(cacheName)
+
+	cache := p.cache.get(cacheName)
+	set := make(map[string]*derivedValues)
+	var keys []string
+	get := func(pm prometheus.Metric) *derivedValues {
+		key := derivedSeriesKey(pm)
+		v, ok := set[key]
+		if !ok {
+			v = &derivedValues{}
+			set[key] = v
+			keys = append(keys, key)
+		}
+		return v
+	}
+
+	if len(p.Derived.Quantiles) > 0 {
+		bucketSet := make(map[*derivedValues]prometheus.Metrics)
+		for _, pm := range buckets {
+			v := get(pm)
+			bucketSet[v] = append(bucketSet[v], pm)
+		}
+		for v, pms := range bucketSet {
+			v.buckets = pms.Buckets()
+			v.hasQuant = true
+		}
+	}
+	if p.Derived.Average {
+		counted := make(map[*derivedValues]bool)
+		for _, pm := range counts {
+			v := get(pm)
+			v.count = pm.Value
+			counted[v] = true
+		}
+		for _, pm := range sums {
+			if v := get(pm); counted[v] {
+				v.sum = pm.Value
+				v.hasAvg = true
+			}
+		}
+	}
+
+	for _, key := range keys {
+		cur := set[key]
+		if !cur.hasQuant && !cur.hasAvg {
+			continue
+		}
+
+		chartID := cacheName
+		if key != "" {
+			chartID += "|" + key
+		}
+		p.ensureDerivedChart(cache, chartID, base, cur, meta)
+
+		prev, ok := p.derivedPrev[chartID]
+		p.derivedPrev[chartID] = cur
+		if !ok {
+			continue
+		}
+
+		if cur.hasQuant && prev.hasQuant {
+			if delta, ok := cur.buckets.Delta(prev.buckets); ok {
+				for _, q := range p.Derived.Quantiles {
+					if v := delta.Quantile(q); !math.IsNaN(v) && !math.IsInf(v, 0) {
+						mx[chartID+"_"+quantileDimName(q)] = int64(v * precision)
+					}
+				}
+			}
+		}
+		if cur.hasAvg && prev.hasAvg {
+			sum, ok1 := prometheus.CounterDelta(cur.sum, prev.sum)
+			count, ok2 := prometheus.CounterDelta(cur.count, prev.count)
+			if ok1 && ok2 && count > 0 {
+				mx[chartID+"_avg"] = int64(sum / count * precision)
+			}
+		}
+	}
+}
+
+func (p *Prometheus) ensureDerivedChart(cache *cacheEntry, chartID, base string, v *derivedValues, meta prometheus.Metadata) {
+	if !cache.hasChart(chartID) {
+		chart := derivedChart(chartID, base, meta)
+		cache.putChart(chartID, chart)
+		if err := p.Charts().Add(chart); err != nil {
+			p.Warning(err)
+		}
+	}
+
+	var dimNames []string
+	if v.hasQuant {
+		for _, q := range p.Derived.Quantiles {
+			dimNames = append(dimNames, quantileDimName(q))
+		}
+	}
+	if v.hasAvg {
+		dimNames = append(dimNames, "avg")
+	}
+
+	for _, dimName := range dimNames {
+		dimID := chartID + "_" + dimName
+		if cache.hasDim(dimID) {
+			continue
+		}
+		cache.putDim(dimID)
+		chart := cache.getChart(chartID)
+		if err := chart.AddDim(derivedChartDim(dimID, dimName)); err != nil {
+			p.Warning(err)
+		}
+		chart.MarkNotCreated()
+	}
+}
+
+func (p *Prometheus) cleanupStaleDerivedCharts(name string) {
+	if !p.cache.has(name) {
+		return
+	}
+	cache := p.cache.get(name)
+	for _, chart := range cache.charts {
+		if chart.Retries < 10 {
+			continue
+		}
+
+		for _, dim := range chart.Dims {
+			cache.removeDim(dim.ID)
+			_ = chart.MarkDimRemove(dim.ID, true)
+		}
+		cache.removeChart(chart.ID)
+		delete(p.derivedPrev, chart.ID)
+
+		chart.MarkRemove()
+		chart.MarkNotCreated()
+	}
+}
+
+func derivedChart(id, base string, meta prometheus.Metadata) *module.Chart {
+	pm := prometheus.Metric{Labels: labels.Labels{{Name: labels.MetricName, Value: base}}}
+	units := meta.Unit(base)
+	if units == "" {
+		units = extractUnits(base)
+	}
+	return &module.Chart{
+		ID:    id,
+		Title: chartTitle(pm, meta),
+		Units: units,
+		Fam:   chartFamily(pm),
+		Ctx:   "prometheus." + base + "_derived",
+		Type:  module.Line,
+	}
+}
+
+func derivedChartDim(id, name string) *module.Dim {
+	return &module.Dim{
+		ID:   id,
+		Name: name,
+		Algo: module.Absolute,
+		Div:  precision,
+	}
+}
+
+// derivedSeriesKey returns the labels of the time series except the metric name, 'le' and 'quantile'.
+func derivedSeriesKey(pm prometheus.Metric) string {
+	return joinLabelsExcept(pm, labels.MetricName, "le", "quantile")
+}
+
+// quantileDimName returns the percentile name of the quantile, 0.99 => p99.
+func quantileDimName(q float64) string {
+	return "p" + strconv.FormatFloat(math.Round(q*1e8)/1e6, 'f', -1, 64)
+}
@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"github.com/netdata/go.d.plugin/pkg/prometheus"
+)
+
+// collectStateset collects OpenMetrics stateset metrics.
+// A stateset series has a label named after the metric, its value is the state name.
+// Every label set (without the state label) is a chart, every state is a dimension.
+func (p *Prometheus) collectStateset(mx map[string]int64, pms prometheus.Metrics, meta prometheus.Metadata) {
+	name := pms[0].Name()
+	if !pms[0].Labels.Has(name) {
+		p.collectAny(mx, pms, meta)
+		return
+	}
+
+	if !p.cache.has(name) {
+		p.cache.put(name, newCacheEntry(nil))
+	}
+
+	defer p.cleanupStaleStatesetCharts(name)
+
+	grp := statesetGrouping(name)
+	cache := p.cache.get(name)
+
+	for _, pm := range pms {
+		chartID := grp.chartID(pm)
+		dimID := grp.dimID(pm)
+		dimName := grp.dimName(pm)
+
+		mx[dimID] = int64(pm.Value)
+
+		if !cache.hasChart(chartID) {
+			chart := statesetChart(chartID, pm, meta)
+			cache.putChart(chartID, chart)
+			if err := p.Charts().Add(chart); err != nil {
+				p.Warning(err)
+			}
+		}
+		if !cache.hasDim(dimID) {
+			cache.putDim(dimID)
+			chart := cache.getChart(chartID)
+			dim := statesetChartDim(dimID, dimName)
+			if err := chart.AddDim(dim); err != nil {
+				p.Warning(err)
+			}
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func (p *Prometheus) cleanupStaleStatesetCharts(name string) {
+	if !p.cache.has(name) {
+		return
+	}
+	cache := p.cache.get(name)
+	for _, chart := range cache.charts {
+		if chart.Retries < 10 {
+			continue
+		}
+
+		for _, dim := range chart.Dims {
+			cache.removeDim(dim.ID)
+			_ = chart.MarkDimRemove(dim.ID, true)
+		}
+		cache.removeChart(chart.ID)
+
+		chart.MarkRemove()
+		chart.MarkNotCreated()
+	}
+}
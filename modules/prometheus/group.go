@@ -42,6 +42,14 @@ var (
 	}
 )
 
+func statesetGrouping(stateLabel string) grouper {
+	return anyGrouper{
+		chartIDFunc: func(pm prometheus.Metric) string { return joinLabelsExcept(pm, stateLabel) },
+		dimIDFunc:   func(pm prometheus.Metric) string { return joinLabels(pm) },
+		dimNameFunc: func(pm prometheus.Metric) string { return pm.Labels.Get(stateLabel) },
+	}
+}
+
 func newGroupingSplitN(grp grouper, numOfGroups uint64) grouper {
 	if numOfGroups <= 1 {
 		return grp
@@ -18,6 +18,11 @@ func (p Prometheus) validateConfig() error {
 	if p.URL == "" {
 		return errors.New("URL not set")
 	}
+	for _, q := range p.Derived.Quantiles {
+		if q < 0 || q > 1 {
+			return fmt.Errorf("derived quantile %v is out of range [0, 1]", q)
+		}
+	}
 	return nil
 }
 
@@ -41,6 +46,14 @@ func (p Prometheus) initPrometheusClient() (prometheus.Prometheus, error) {
 		return nil, fmt.Errorf("parsing selector: %v", err)
 	}
 
+	rl, err := p.Configs.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("parsing relabel configs: %v", err)
+	}
+
+	if rl != nil {
+		return prometheus.NewWithRelabeling(client, req, sr, rl), nil
+	}
 	if sr != nil {
 		return prometheus.NewWithSelector(client, req, sr), nil
 	}
@@ -78,6 +91,43 @@ func (p Prometheus) initOptionalGrouping() ([]optionalGrouping, error) {
 	return optGrps, nil
 }
 
+func (p Prometheus) initAggregations() ([]aggregation, error) {
+	var aggrs []aggregation
+	for _, item := range p.Aggregation {
+		if item.Selector == "" {
+			return nil, errors.New("empty aggregation selector")
+		}
+
+		switch item.Func {
+		case aggrSum, aggrAvg, aggrMin, aggrMax, aggrCount:
+		case "":
+			return nil, fmt.Errorf("aggregation selector '%s' has no 'func'", item.Selector)
+		default:
+			return nil, fmt.Errorf("aggregation selector '%s' has unknown func '%s'", item.Selector, item.Func)
+		}
+
+		if item.By != "" && item.Without != "" {
+			return nil, fmt.Errorf("aggregation selector '%s' has both 'by' and 'without'", item.Selector)
+		}
+
+		sr, err := selector.Parse(item.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("parse aggregation selector '%s': %v", item.Selector, err)
+		}
+		if sr == nil {
+			continue
+		}
+
+		aggrs = append(aggrs, aggregation{
+			sr:      sr,
+			fn:      item.Func,
+			by:      strings.Fields(item.By),
+			without: strings.Fields(item.Without),
+		})
+	}
+	return aggrs, nil
+}
+
 func (p Prometheus) initForceAbsoluteAlgorithm() (matcher.Matcher, error) {
 	mr := matcher.FALSE()
 	for _, v := range p.ForceAbsoluteAlgorithm {
@@ -6,6 +6,7 @@ import (
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/prometheus/selector"
 	"github.com/netdata/go.d.plugin/pkg/web"
 )
@@ -35,6 +36,7 @@ func New() *Prometheus {
 		Config:      config,
 		cache:       make(collectCache),
 		skipMetrics: make(map[string]bool),
+		derivedPrev: make(map[string]*derivedValues),
 		charts:      statsCharts.Copy(),
 	}
 }
@@ -42,11 +44,14 @@ func New() *Prometheus {
 type (
 	Config struct {
 		web.HTTP               `yaml:",inline"`
+		relabel.Configs        `yaml:",inline"`
 		BearerTokenFile        string        `yaml:"bearer_token_file"` // TODO: part of web.Request?
 		MaxTS                  int           `yaml:"max_time_series"`
 		MaxTSPerMetric         int           `yaml:"max_time_series_per_metric"`
 		Selector               selector.Expr `yaml:"selector"`
 		Grouping               []GroupOption `yaml:"group"`
+		Aggregation            []AggrOption  `yaml:"aggregate"`
+		Derived                DerivedOption `yaml:"derived"`
 		ExpectedPrefix         string        `yaml:"expected_prefix"`
 		ForceAbsoluteAlgorithm []string      `yaml:"force_absolute_algorithm"`
 	}
@@ -55,6 +60,18 @@ type (
 		ByLabel  string `yaml:"by_label"`
 	}
 
+	AggrOption struct {
+		Selector string `yaml:"selector"`
+		Func     string `yaml:"func"`
+		By       string `yaml:"by"`
+		Without  string `yaml:"without"`
+	}
+
+	DerivedOption struct {
+		Quantiles []float64 `yaml:"quantiles"`
+		Average   bool      `yaml:"average"`
+	}
+
 	Prometheus struct {
 		module.Base
 		Config `yaml:",inline"`
@@ -64,8 +81,10 @@ type (
 
 		forceAbsoluteAlgorithm matcher.Matcher
 		optGroupings           []optionalGrouping
+		aggregations           []aggregation
 		cache                  collectCache
 		skipMetrics            map[string]bool
+		derivedPrev            map[string]*derivedValues
 	}
 	optionalGrouping struct {
 		sr  selector.Selector
@@ -95,6 +114,13 @@ func (p *Prometheus) Init() bool {
 	}
 	p.optGroupings = optGrps
 
+	aggrs, err := p.initAggregations()
+	if err != nil {
+		p.Errorf("init aggregation: %v", err)
+		return false
+	}
+	p.aggregations = aggrs
+
 	mr, err := p.initForceAbsoluteAlgorithm()
 	if err != nil {
 		p.Errorf("init force_absolute_algorithm (%v): %v", p.ForceAbsoluteAlgorithm, err)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/prometheus/selector"
 	"github.com/netdata/go.d.plugin/pkg/tlscfg"
 	"github.com/netdata/go.d.plugin/pkg/web"
@@ -63,6 +64,50 @@ func TestPrometheus_Init(t *testing.T) {
 			},
 			wantFail: true,
 		},
+		"valid aggregation": {
+			config: Config{
+				HTTP: web.HTTP{Request: web.Request{URL: "http://127.0.0.1:9090/metric"}},
+				Aggregation: []AggrOption{
+					{Selector: "name", Func: "sum", By: "code"},
+				},
+			},
+		},
+		"empty aggregation selector": {
+			config: Config{
+				HTTP: web.HTTP{Request: web.Request{URL: "http://127.0.0.1:9090/metric"}},
+				Aggregation: []AggrOption{
+					{Selector: "", Func: "sum", By: "code"},
+				},
+			},
+			wantFail: true,
+		},
+		"empty aggregation func": {
+			config: Config{
+				HTTP: web.HTTP{Request: web.Request{URL: "http://127.0.0.1:9090/metric"}},
+				Aggregation: []AggrOption{
+					{Selector: "name", By: "code"},
+				},
+			},
+			wantFail: true,
+		},
+		"unknown aggregation func": {
+			config: Config{
+				HTTP: web.HTTP{Request: web.Request{URL: "http://127.0.0.1:9090/metric"}},
+				Aggregation: []AggrOption{
+					{Selector: "name", Func: "median", By: "code"},
+				},
+			},
+			wantFail: true,
+		},
+		"aggregation with both 'by' and 'without'": {
+			config: Config{
+				HTTP: web.HTTP{Request: web.Request{URL: "http://127.0.0.1:9090/metric"}},
+				Aggregation: []AggrOption{
+					{Selector: "name", Func: "sum", By: "code", Without: "handler"},
+				},
+			},
+			wantFail: true,
+		},
 		"default": {
 			config:   New().Config,
 			wantFail: true,
@@ -593,6 +638,138 @@ func TestPrometheus_Collect_WithSelector(t *testing.T) {
 	}
 }
 
+func TestPrometheus_Collect_WithAggregation(t *testing.T) {
+	input := [][]string{
+		{
+			`# HELP http_requests_total Total number of HTTP requests.`,
+			`# TYPE http_requests_total counter`,
+			`http_requests_total{code="200",handler="/a",method="get"} 1`,
+			`http_requests_total{code="200",handler="/b",method="get"} 2`,
+			`http_requests_total{code="200",handler="/c",method="post"} 3`,
+			`http_requests_total{code="500",handler="/a",method="get"} 4`,
+			`http_requests_total{code="500",handler="/b",method="post"} 5`,
+			`# TYPE jvm_threads gauge`,
+			`jvm_threads{state="runnable"} 10`,
+			`jvm_threads{state="blocked"} 20`,
+		},
+	}
+
+	tests := map[string]struct {
+		aggregation   []AggrOption
+		wantCollected map[string]int64
+	}{
+		"sum by": {
+			aggregation: []AggrOption{{Selector: "http_requests_total", Func: "sum", By: "code"}},
+			wantCollected: map[string]int64{
+				"http_requests_total|code=200": 6000,
+				"http_requests_total|code=500": 9000,
+				"jvm_threads|state=runnable":   10000,
+				"jvm_threads|state=blocked":    20000,
+				"series":                       4,
+				"metrics":                      2,
+				"charts":                       int64(2 + len(statsCharts)),
+			},
+		},
+		"sum without": {
+			aggregation: []AggrOption{{Selector: "http_requests_total", Func: "sum", Without: "handler"}},
+			wantCollected: map[string]int64{
+				"http_requests_total|code=200,method=get":  3000,
+				"http_requests_total|code=200,method=post": 3000,
+				"http_requests_total|code=500,method=get":  4000,
+				"http_requests_total|code=500,method=post": 5000,
+				"jvm_threads|state=runnable":               10000,
+				"jvm_threads|state=blocked":                20000,
+				"series":                                   6,
+				"metrics":                                  2,
+				"charts":                                   int64(2 + len(statsCharts)),
+			},
+		},
+		"avg, min, max, count by multiple labels": {
+			aggregation: []AggrOption{
+				{Selector: `http_requests_total{code="200"}`, Func: "avg", By: "code method"},
+				{Selector: `http_requests_total{code="500"}`, Func: "max", By: "code"},
+				{Selector: "jvm_threads", Func: "count"},
+			},
+			wantCollected: map[string]int64{
+				"http_requests_total|code=200,method=get":  1500,
+				"http_requests_total|code=200,method=post": 3000,
+				"http_requests_total|code=500":             5000,
+				"jvm_threads":                              2000,
+				"series":                                   4,
+				"metrics":                                  2,
+				"charts":                                   int64(2 + len(statsCharts)),
+			},
+		},
+		"min without all labels": {
+			aggregation: []AggrOption{{Selector: "*", Func: "min"}},
+			wantCollected: map[string]int64{
+				"http_requests_total": 1000,
+				"jvm_threads":         10000,
+				"series":              2,
+				"metrics":             2,
+				"charts":              int64(2 + len(statsCharts)),
+			},
+		},
+		"first matching rule wins": {
+			aggregation: []AggrOption{
+				{Selector: "http_requests_total", Func: "sum", By: "code"},
+				{Selector: "http_requests_total", Func: "count", By: "code"},
+			},
+			wantCollected: map[string]int64{
+				"http_requests_total|code=200": 6000,
+				"http_requests_total|code=500": 9000,
+				"jvm_threads|state=runnable":   10000,
+				"jvm_threads|state=blocked":    20000,
+				"series":                       4,
+				"metrics":                      2,
+				"charts":                       int64(2 + len(statsCharts)),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := preparePrometheusEndpoint(input)
+			defer srv.Close()
+
+			prom := New()
+			prom.URL = srv.URL
+			prom.Aggregation = test.aggregation
+			require.True(t, prom.Init())
+
+			collected := prom.Collect()
+
+			assert.Equal(t, test.wantCollected, collected)
+			ensureCollectedHasAllChartsDimsVarsIDs(t, prom, collected)
+		})
+	}
+}
+
+func TestPrometheus_Collect_WithAggregation_BelowPerMetricLimit(t *testing.T) {
+	var input []string
+	for i := 0; i < 10; i++ {
+		input = append(input, fmt.Sprintf(`http_requests_total{code="%d",request_id="%d"} 1`, 200+i%2, i))
+	}
+
+	srv := preparePrometheusEndpoint([][]string{input})
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL
+	prom.MaxTSPerMetric = 5
+	prom.Aggregation = []AggrOption{{Selector: "http_requests_total", Func: "sum", Without: "request_id"}}
+	require.True(t, prom.Init())
+
+	expected := map[string]int64{
+		"http_requests_total|code=200": 5000,
+		"http_requests_total|code=201": 5000,
+		"series":                       2,
+		"metrics":                      1,
+		"charts":                       int64(1 + len(statsCharts)),
+	}
+	assert.Equal(t, expected, prom.Collect())
+}
+
 func TestPrometheus_Collect_DefaultGrouping(t *testing.T) {
 	type testGroup map[string]struct {
 		input            [][]string
@@ -1187,3 +1364,182 @@ func preparePrometheusEndpoint(metrics [][]string) *httptest.Server {
 			i++
 		}))
 }
+
+func TestPrometheus_Collect_OpenMetrics(t *testing.T) {
+	input := strings.Join([]string{
+		`# HELP http_requests Total number of HTTP requests.`,
+		`# TYPE http_requests counter`,
+		`http_requests_total{code="200"} 10`,
+		`http_requests_created{code="200"} 1.6e+09`,
+		`# TYPE build info`,
+		`build_info{version="1.2.3"} 1`,
+		`# TYPE service_state stateset`,
+		`service_state{service="web",service_state="running"} 1`,
+		`service_state{service="web",service_state="stopped"} 0`,
+		`# TYPE process_memory_bytes gauge`,
+		`# UNIT process_memory_bytes bytes`,
+		`process_memory_bytes 1024`,
+		`# EOF`,
+	}, "\n") + "\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=0.0.1; charset=utf-8")
+			_, _ = w.Write([]byte(input))
+		}))
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL
+	require.True(t, prom.Init())
+
+	collected := prom.Collect()
+
+	expected := map[string]int64{
+		"http_requests_total|code=200":                    10000,
+		"build_info|version=1.2.3":                        1000,
+		"service_state|service=web,service_state=running": 1,
+		"service_state|service=web,service_state=stopped": 0,
+		"process_memory_bytes":                            1024000,
+		"series":                                          5,
+		"metrics":                                         4,
+		"charts":                                          int64(4 + len(statsCharts)),
+	}
+	assert.Equal(t, expected, collected)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, prom, collected)
+
+	stateChart := prom.Charts().Get("service_state|service=web")
+	require.NotNil(t, stateChart)
+	assert.Equal(t, "state", stateChart.Units)
+	assert.Len(t, stateChart.Dims, 2)
+
+	memChart := prom.Charts().Get("process_memory_bytes")
+	require.NotNil(t, memChart)
+	assert.Equal(t, "bytes", memChart.Units)
+
+	infoChart := prom.Charts().Get("build_info")
+	require.NotNil(t, infoChart)
+	assert.Equal(t, module.Absolute, infoChart.Dims[0].Algo)
+}
+
+func TestPrometheus_Collect_WithRelabeling(t *testing.T) {
+	input := [][]string{
+		{
+			`# HELP prometheus_sd_kubernetes_events_total The number of Kubernetes events handled.`,
+			`# TYPE prometheus_sd_kubernetes_events_total counter`,
+			`prometheus_sd_kubernetes_events_total{event="add",role="endpoints",request_id="1"} 1`,
+			`prometheus_sd_kubernetes_events_total{event="add",role="ingress",request_id="2"} 2`,
+			`prometheus_sd_kubernetes_events_total{event="delete",role="node",request_id="3"} 3`,
+		},
+	}
+	regex := func(s string) *string { return &s }
+
+	srv := preparePrometheusEndpoint(input)
+	defer srv.Close()
+	prom := New()
+	prom.URL = srv.URL
+	prom.MetricRelabelConfigs = []relabel.Config{
+		{Action: relabel.ActionDrop, SourceLabels: []string{"event"}, Regex: regex("delete")},
+		{Action: relabel.ActionLabelDrop, Regex: regex("request_id")},
+	}
+	require.True(t, prom.Init())
+
+	expected := map[string]int64{
+		"prometheus_sd_kubernetes_events_total|event=add,role=endpoints": 1000,
+		"prometheus_sd_kubernetes_events_total|event=add,role=ingress":   2000,
+		"series":  2,
+		"metrics": 1,
+		"charts":  int64(1 + len(statsCharts)),
+	}
+	collected := prom.Collect()
+
+	assert.Equal(t, expected, collected)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, prom, collected)
+}
+
+func TestPrometheus_Init_InvalidRelabelConfig(t *testing.T) {
+	prom := New()
+	prom.URL = "http://127.0.0.1:38001/metrics"
+	prom.RelabelConfigs = []relabel.Config{{Action: "unknown"}}
+
+	assert.False(t, prom.Init())
+}
+
+func TestPrometheus_Collect_Derived(t *testing.T) {
+	scrape := func(b01, b05, bInf, hSum, hCount, sSum, sCount int) []string {
+		return []string{
+			`# HELP http_request_duration_seconds Request duration.`,
+			`# TYPE http_request_duration_seconds histogram`,
+			fmt.Sprintf(`http_request_duration_seconds_bucket{handler="/",le="0.1"} %d`, b01),
+			fmt.Sprintf(`http_request_duration_seconds_bucket{handler="/",le="0.5"} %d`, b05),
+			fmt.Sprintf(`http_request_duration_seconds_bucket{handler="/",le="+Inf"} %d`, bInf),
+			fmt.Sprintf(`http_request_duration_seconds_sum{handler="/"} %d`, hSum),
+			fmt.Sprintf(`http_request_duration_seconds_count{handler="/"} %d`, hCount),
+			`# TYPE rpc_duration_seconds summary`,
+			`rpc_duration_seconds{quantile="0.5"} 0.2`,
+			`rpc_duration_seconds{quantile="0.9"} 0.4`,
+			fmt.Sprintf(`rpc_duration_seconds_sum %d`, sSum),
+			fmt.Sprintf(`rpc_duration_seconds_count %d`, sCount),
+		}
+	}
+	input := [][]string{
+		scrape(10, 20, 20, 3, 20, 10, 50),
+		scrape(60, 110, 120, 23, 120, 16, 80),
+		scrape(1, 1, 1, 1, 1, 1, 1), // reset
+	}
+	derivedKeys := []string{
+		"http_request_duration_seconds_derived|handler=/_p50",
+		"http_request_duration_seconds_derived|handler=/_p90",
+		"http_request_duration_seconds_derived|handler=/_p99.9",
+		"http_request_duration_seconds_derived|handler=/_avg",
+		"rpc_duration_seconds_derived_avg",
+	}
+
+	srv := preparePrometheusEndpoint(input)
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL
+	prom.Derived = DerivedOption{Quantiles: []float64{0.5, 0.9, 0.999}, Average: true}
+	require.True(t, prom.Init())
+
+	collected := prom.Collect()
+	require.NotNil(t, collected)
+	for _, key := range derivedKeys {
+		assert.NotContainsf(t, collected, key, "first collection has no previous values")
+	}
+
+	collected = prom.Collect()
+	expected := map[string]int64{
+		"http_request_duration_seconds_derived|handler=/_p50":   100,
+		"http_request_duration_seconds_derived|handler=/_p90":   500,
+		"http_request_duration_seconds_derived|handler=/_p99.9": 500,
+		"http_request_duration_seconds_derived|handler=/_avg":   200,
+		"rpc_duration_seconds_derived_avg":                      200,
+	}
+	for key, value := range expected {
+		assert.Equalf(t, value, collected[key], key)
+	}
+	ensureCollectedHasAllChartsDimsVarsIDs(t, prom, collected)
+
+	chart := prom.Charts().Get("http_request_duration_seconds_derived|handler=/")
+	require.NotNil(t, chart)
+	assert.Equal(t, "seconds", chart.Units)
+	assert.Len(t, chart.Dims, 4)
+	chart = prom.Charts().Get("rpc_duration_seconds_derived")
+	require.NotNil(t, chart)
+	assert.Len(t, chart.Dims, 1)
+
+	collected = prom.Collect()
+	for _, key := range derivedKeys {
+		assert.NotContainsf(t, collected, key, "no values after a reset")
+	}
+}
+
+func TestPrometheus_Init_InvalidDerivedQuantile(t *testing.T) {
+	prom := New()
+	prom.URL = "http://127.0.0.1:38001/metrics"
+	prom.Derived.Quantiles = []float64{0.5, 99}
+
+	assert.False(t, prom.Init())
+}
@@ -2,11 +2,13 @@ package pulsar
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -50,8 +52,9 @@ func New() *Pulsar {
 
 type (
 	Config struct {
-		web.HTTP   `yaml:",inline"`
-		TopicFiler matcher.SimpleExpr `yaml:"topic_filter"`
+		web.HTTP        `yaml:",inline"`
+		relabel.Configs `yaml:",inline"`
+		TopicFiler      matcher.SimpleExpr `yaml:"topic_filter"`
 	}
 
 	Pulsar struct {
@@ -96,7 +99,12 @@ func (p *Pulsar) initClient() error {
 		return err
 	}
 
-	p.prom = prometheus.New(client, p.Request)
+	rl, err := p.Configs.Parse()
+	if err != nil {
+		return fmt.Errorf("parsing relabel configs: %v", err)
+	}
+
+	p.prom = prometheus.NewWithRelabeling(client, p.Request, nil, rl)
 	return nil
 }
 
@@ -0,0 +1,58 @@
+package snmp
+
+import (
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+type chartDef struct {
+	cfg  ChartConfig
+	dims []DimConfig
+
+	// chart is the scalar chart, it is created on the first collected value
+	chart *module.Chart
+	// instances are the table charts by the row index
+	instances map[string]*module.Chart
+}
+
+type tableDef struct {
+	name     string
+	labelOID string
+	charts   []*chartDef
+}
+
+func newChart(cfg ChartConfig, id, label string) *module.Chart {
+	chart := cfg.New("snmp.")
+	chart.ID = id
+	// table charts are grouped by the row
+	if cfg.Family == "" && label != "" {
+		chart.Fam = label
+	}
+	return chart
+}
+
+// newDim creates the dimension, the algorithm is chosen by the value type unless it is set explicitly:
+// counters are incremental, the rest is absolute.
+func newDim(chartID string, cfg DimConfig, typ gosnmp.Asn1BER) *module.Dim {
+	dim := cfg.New(dimID(chartID, cfg.Name), cfg.Name)
+	// the values are integers, they are not multiplied by the precision
+	dim.Div = cfg.Divisor
+	if cfg.Algorithm == "" && (typ == gosnmp.Counter32 || typ == gosnmp.Counter64) {
+		dim.Algo = module.Incremental
+	}
+	return dim
+}
+
+func dimID(chartID, name string) string {
+	return chartID + "_" + name
+}
+
+var idReplacer = strings.NewReplacer(" ", "_", ".", "_", ",", "_", "/", "_", ":", "_")
+
+// cleanID makes a table row index usable as a part of a chart ID.
+func cleanID(s string) string {
+	return idReplacer.Replace(s)
+}
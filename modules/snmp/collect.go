@@ -0,0 +1,247 @@
+package snmp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+func (s *SNMP) collect() (map[string]int64, error) {
+	if !s.connected {
+		if err := s.snmpClient.Connect(); err != nil {
+			return nil, fmt.Errorf("error on connecting to %s:%d: %v", s.Hostname, s.Port, err)
+		}
+		s.connected = true
+	}
+
+	mx := make(map[string]int64)
+
+	if err := s.collectScalars(mx); err != nil {
+		return nil, err
+	}
+	for _, table := range s.tables {
+		if err := s.collectTable(mx, table); err != nil {
+			return nil, err
+		}
+	}
+	return mx, nil
+}
+
+func (s *SNMP) collectScalars(mx map[string]int64) error {
+	if len(s.scalars) == 0 {
+		return nil
+	}
+
+	type dimRef struct {
+		def *chartDef
+		dim DimConfig
+	}
+	refs := make(map[string][]dimRef)
+	var oids []string
+	for _, def := range s.scalars {
+		for _, dim := range def.dims {
+			if _, ok := refs[dim.OID]; !ok {
+				oids = append(oids, dim.OID)
+			}
+			refs[dim.OID] = append(refs[dim.OID], dimRef{def: def, dim: dim})
+		}
+	}
+
+	for len(oids) > 0 {
+		n := s.snmpClient.MaxOids
+		if n > len(oids) {
+			n = len(oids)
+		}
+		chunk := oids[:n]
+		oids = oids[n:]
+
+		resp, err := s.snmpClient.Get(chunk)
+		if err != nil {
+			return fmt.Errorf("error on SNMP GET from %s: %v", s.Hostname, err)
+		}
+		// SNMPv1 fails the whole request if there is a missing OID
+		if resp.Error != gosnmp.NoError && resp.Error != gosnmp.NoSuchName {
+			return fmt.Errorf("SNMP GET from %s: %v", s.Hostname, resp.Error)
+		}
+
+		for _, pdu := range resp.Variables {
+			v, ok := pduValue(pdu)
+			if !ok {
+				continue
+			}
+			for _, ref := range refs[pdu.Name] {
+				if ref.def.chart == nil {
+					ref.def.chart = newChart(ref.def.cfg, ref.def.cfg.ID, "")
+					if err := s.charts.Add(ref.def.chart); err != nil {
+						s.Warning(err)
+					}
+				}
+				s.addDimOnce(ref.def.chart, ref.dim, pdu.Type)
+				mx[dimID(ref.def.chart.ID, ref.dim.Name)] = v
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SNMP) collectTable(mx map[string]int64, table *tableDef) error {
+	labels := make(map[string]string)
+	if table.labelOID != "" {
+		pdus, err := s.walk(table.labelOID)
+		if err != nil {
+			return fmt.Errorf("error on walking table '%s' label OID %s: %v", table.name, table.labelOID, err)
+		}
+		for _, pdu := range pdus {
+			if label := pduString(pdu); label != "" {
+				labels[rowIndex(table.labelOID, pdu.Name)] = label
+			}
+		}
+	}
+
+	columns := make(map[string]map[string]gosnmp.SnmpPDU)
+	for _, def := range table.charts {
+		for _, dim := range def.dims {
+			if _, ok := columns[dim.OID]; ok {
+				continue
+			}
+			pdus, err := s.walk(dim.OID)
+			if err != nil {
+				return fmt.Errorf("error on walking table '%s' OID %s: %v", table.name, dim.OID, err)
+			}
+			rows := make(map[string]gosnmp.SnmpPDU)
+			for _, pdu := range pdus {
+				rows[rowIndex(dim.OID, pdu.Name)] = pdu
+			}
+			columns[dim.OID] = rows
+		}
+	}
+
+	for _, def := range table.charts {
+		for _, index := range tableIndexes(def, columns) {
+			for _, dim := range def.dims {
+				pdu, ok := columns[dim.OID][index]
+				if !ok {
+					continue
+				}
+				v, ok := pduValue(pdu)
+				if !ok {
+					continue
+				}
+
+				chart, ok := def.instances[index]
+				if !ok {
+					label := labels[index]
+					if label == "" {
+						label = index
+					}
+					// the label is not unique (e.g. ifName), the chart is identified by the row index
+					chart = newChart(def.cfg, def.cfg.ID+"_"+cleanID(index), label)
+					chart.Title += " (" + label + ")"
+					if err := s.charts.Add(chart); err != nil {
+						s.Warning(err)
+						continue
+					}
+					def.instances[index] = chart
+				}
+				s.addDimOnce(chart, dim, pdu.Type)
+				mx[dimID(chart.ID, dim.Name)] = v
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SNMP) addDimOnce(chart *module.Chart, dim DimConfig, typ gosnmp.Asn1BER) {
+	if chart.HasDim(dimID(chart.ID, dim.Name)) {
+		return
+	}
+	if err := chart.AddDim(newDim(chart.ID, dim, typ)); err != nil {
+		s.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
+}
+
+func (s *SNMP) walk(oid string) ([]gosnmp.SnmpPDU, error) {
+	if s.snmpClient.Version == gosnmp.Version1 {
+		return s.snmpClient.WalkAll(oid)
+	}
+	return s.snmpClient.BulkWalkAll(oid)
+}
+
+// tableIndexes returns the sorted row indexes that have a value for any of the chart dimensions.
+func tableIndexes(def *chartDef, columns map[string]map[string]gosnmp.SnmpPDU) []string {
+	seen := make(map[string]bool)
+	var indexes []string
+	for _, dim := range def.dims {
+		for index := range columns[dim.OID] {
+			if !seen[index] {
+				seen[index] = true
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	sort.Slice(indexes, func(i, j int) bool { return lessOID(indexes[i], indexes[j]) })
+	return indexes
+}
+
+// rowIndex returns the table row index, it is the OID suffix after the column OID.
+func rowIndex(columnOID, oid string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(oid, columnOID), ".")
+}
+
+func lessOID(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, errX := strconv.ParseUint(as[i], 10, 64)
+		y, errY := strconv.ParseUint(bs[i], 10, 64)
+		if errX != nil || errY != nil {
+			if as[i] != bs[i] {
+				return as[i] < bs[i]
+			}
+			continue
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// pduValue converts the PDU value to int64.
+// Counter64 values are wrapped at 2^63, that is handled by the incremental algorithm as a counter reset.
+// Strings holding a number and floats are truncated to an integer.
+func pduValue(pdu gosnmp.SnmpPDU) (int64, bool) {
+	switch pdu.Type {
+	case gosnmp.Counter64:
+		return int64(gosnmp.ToBigInt(pdu.Value).Uint64() & math.MaxInt64), true
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		return gosnmp.ToBigInt(pdu.Value).Int64(), true
+	case gosnmp.OpaqueFloat:
+		v, ok := pdu.Value.(float32)
+		return int64(v), ok
+	case gosnmp.OpaqueDouble:
+		v, ok := pdu.Value.(float64)
+		return int64(v), ok
+	case gosnmp.OctetString:
+		v, err := strconv.ParseFloat(strings.TrimSpace(pduString(pdu)), 64)
+		return int64(v), err == nil
+	}
+	return 0, false
+}
+
+func pduString(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
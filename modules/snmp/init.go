@@ -0,0 +1,211 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+func (s SNMP) validateConfig() error {
+	if s.Hostname == "" {
+		return errors.New("'hostname' not set")
+	}
+	if s.Port <= 0 || s.Port > 65535 {
+		return fmt.Errorf("bad port: %d", s.Port)
+	}
+	if len(s.Profiles) == 0 && len(s.Config.Charts) == 0 && len(s.Tables) == 0 {
+		return errors.New("'profiles', 'charts' and 'tables' not set")
+	}
+	for _, name := range s.Profiles {
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("unknown profile '%s'", name)
+		}
+	}
+	return nil
+}
+
+func (s SNMP) initSNMPClient() (*gosnmp.GoSNMP, error) {
+	client := &gosnmp.GoSNMP{
+		Target:         s.Hostname,
+		Port:           uint16(s.Port),
+		Community:      s.Community,
+		Timeout:        s.Timeout.Duration,
+		Retries:        s.Retries,
+		MaxOids:        gosnmp.MaxOids,
+		MaxRepetitions: uint32(s.MaxRepetitions),
+	}
+
+	switch s.Version {
+	case "1":
+		client.Version = gosnmp.Version1
+	case "2c", "2":
+		client.Version = gosnmp.Version2c
+	case "3":
+		client.Version = gosnmp.Version3
+		if err := s.initSNMPv3(client); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown SNMP version '%s'", s.Version)
+	}
+	return client, nil
+}
+
+func (s SNMP) initSNMPv3(client *gosnmp.GoSNMP) error {
+	if s.User.Name == "" {
+		return errors.New("SNMPv3: 'user.name' not set")
+	}
+
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:                 s.User.Name,
+		AuthenticationProtocol:   gosnmp.NoAuth,
+		PrivacyProtocol:          gosnmp.NoPriv,
+		AuthenticationPassphrase: s.User.AuthKey,
+		PrivacyPassphrase:        s.User.PrivKey,
+	}
+
+	switch strings.ToLower(s.User.Level) {
+	case "noauthnopriv":
+		client.MsgFlags = gosnmp.NoAuthNoPriv
+	case "authnopriv":
+		client.MsgFlags = gosnmp.AuthNoPriv
+	case "authpriv":
+		client.MsgFlags = gosnmp.AuthPriv
+	default:
+		return fmt.Errorf("SNMPv3: unknown security level '%s'", s.User.Level)
+	}
+
+	if client.MsgFlags != gosnmp.NoAuthNoPriv {
+		proto, ok := authProtocols[strings.ToLower(s.User.AuthProto)]
+		if !ok {
+			return fmt.Errorf("SNMPv3: unknown authentication protocol '%s'", s.User.AuthProto)
+		}
+		params.AuthenticationProtocol = proto
+	}
+	if client.MsgFlags == gosnmp.AuthPriv {
+		proto, ok := privProtocols[strings.ToLower(s.User.PrivProto)]
+		if !ok {
+			return fmt.Errorf("SNMPv3: unknown privacy protocol '%s'", s.User.PrivProto)
+		}
+		params.PrivacyProtocol = proto
+	}
+
+	client.SecurityModel = gosnmp.UserSecurityModel
+	client.SecurityParameters = params
+	return nil
+}
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"md5":    gosnmp.MD5,
+	"sha":    gosnmp.SHA,
+	"sha224": gosnmp.SHA224,
+	"sha256": gosnmp.SHA256,
+	"sha384": gosnmp.SHA384,
+	"sha512": gosnmp.SHA512,
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"des":     gosnmp.DES,
+	"aes":     gosnmp.AES,
+	"aes192":  gosnmp.AES192,
+	"aes256":  gosnmp.AES256,
+	"aes192c": gosnmp.AES192C,
+	"aes256c": gosnmp.AES256C,
+}
+
+func (s *SNMP) initDefinitions() error {
+	chartConfigs := s.Config.Charts
+	tableConfigs := s.Tables
+	for _, name := range s.Profiles {
+		chartConfigs = append(chartConfigs, profiles[name].Charts...)
+		tableConfigs = append(tableConfigs, profiles[name].Tables...)
+	}
+
+	chartIDs := make(map[string]bool)
+	for i, cfg := range chartConfigs {
+		def, err := newChartDef(cfg, chartIDs)
+		if err != nil {
+			return fmt.Errorf("chart[%d]: %v", i+1, err)
+		}
+		s.scalars = append(s.scalars, def)
+	}
+
+	for i, cfg := range tableConfigs {
+		if cfg.Name == "" {
+			return fmt.Errorf("table[%d]: 'name' not set", i+1)
+		}
+		if len(cfg.Charts) == 0 {
+			return fmt.Errorf("table '%s': 'charts' not set", cfg.Name)
+		}
+		table := &tableDef{name: cfg.Name}
+		if cfg.LabelOID != "" {
+			if !isValidOID(cfg.LabelOID) {
+				return fmt.Errorf("table '%s': bad label OID '%s'", cfg.Name, cfg.LabelOID)
+			}
+			table.labelOID = normalizeOID(cfg.LabelOID)
+		}
+		for j, chartCfg := range cfg.Charts {
+			def, err := newChartDef(chartCfg, chartIDs)
+			if err != nil {
+				return fmt.Errorf("table '%s': chart[%d]: %v", cfg.Name, j+1, err)
+			}
+			def.instances = make(map[string]*module.Chart)
+			table.charts = append(table.charts, def)
+		}
+		s.tables = append(s.tables, table)
+	}
+	return nil
+}
+
+func newChartDef(cfg ChartConfig, chartIDs map[string]bool) (*chartDef, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if chartIDs[cfg.ID] {
+		return nil, fmt.Errorf("duplicate chart id '%s'", cfg.ID)
+	}
+	chartIDs[cfg.ID] = true
+
+	if len(cfg.Dimensions) == 0 {
+		return nil, fmt.Errorf("chart '%s': 'dimensions' not set", cfg.ID)
+	}
+
+	def := &chartDef{cfg: cfg}
+	names := make(map[string]bool)
+	for i, dim := range cfg.Dimensions {
+		if dim.Name == "" {
+			return nil, fmt.Errorf("chart '%s': dimension[%d]: 'name' not set", cfg.ID, i+1)
+		}
+		if names[dim.Name] {
+			return nil, fmt.Errorf("chart '%s': duplicate dimension name '%s'", cfg.ID, dim.Name)
+		}
+		names[dim.Name] = true
+		if !isValidOID(dim.OID) {
+			return nil, fmt.Errorf("chart '%s': dimension '%s': bad OID '%s'", cfg.ID, dim.Name, dim.OID)
+		}
+		if err := dim.Init(); err != nil {
+			return nil, fmt.Errorf("chart '%s': dimension '%s': %v", cfg.ID, dim.Name, err)
+		}
+		dim.OID = normalizeOID(dim.OID)
+		def.dims = append(def.dims, dim)
+	}
+	return def, nil
+}
+
+var reOID = regexp.MustCompile(`^\.?\d+(\.\d+)*$`)
+
+func isValidOID(oid string) bool {
+	return reOID.MatchString(oid)
+}
+
+// normalizeOID adds the leading dot, the OIDs in the responses have it.
+func normalizeOID(oid string) string {
+	if strings.HasPrefix(oid, ".") {
+		return oid
+	}
+	return "." + oid
+}
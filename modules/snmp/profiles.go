@@ -0,0 +1,82 @@
+package snmp
+
+import "github.com/netdata/go.d.plugin/pkg/chartdef"
+
+// Profile is a bundled set of charts and tables for a MIB.
+type Profile struct {
+	Charts []ChartConfig
+	Tables []TableConfig
+}
+
+var profiles = map[string]Profile{
+	"if-mib": ifMIBProfile,
+}
+
+// https://www.net-snmp.org/docs/mibs/IF-MIB.txt
+// The 64-bit (ifXTable) counters are used for traffic and packets.
+var ifMIBProfile = Profile{
+	Tables: []TableConfig{
+		{
+			Name:     "interfaces",
+			LabelOID: "1.3.6.1.2.1.31.1.1.1.1", // ifName
+			Charts: []ChartConfig{
+				{
+					Chart: chartdef.Chart{
+						ID:    "if_traffic",
+						Title: "Interface Traffic",
+						Units: "kilobits/s",
+						Type:  "area",
+					},
+					Dimensions: []DimConfig{
+						{Dim: chartdef.Dim{Name: "in", Algorithm: "incremental", Multiplier: 8, Divisor: 1000}, OID: "1.3.6.1.2.1.31.1.1.1.6"},    // ifHCInOctets
+						{Dim: chartdef.Dim{Name: "out", Algorithm: "incremental", Multiplier: -8, Divisor: 1000}, OID: "1.3.6.1.2.1.31.1.1.1.10"}, // ifHCOutOctets
+					},
+				},
+				{
+					Chart: chartdef.Chart{
+						ID:    "if_packets",
+						Title: "Interface Unicast Packets",
+						Units: "packets/s",
+					},
+					Dimensions: []DimConfig{
+						{Dim: chartdef.Dim{Name: "in", Algorithm: "incremental"}, OID: "1.3.6.1.2.1.31.1.1.1.7"},                   // ifHCInUcastPkts
+						{Dim: chartdef.Dim{Name: "out", Algorithm: "incremental", Multiplier: -1}, OID: "1.3.6.1.2.1.31.1.1.1.11"}, // ifHCOutUcastPkts
+					},
+				},
+				{
+					Chart: chartdef.Chart{
+						ID:    "if_errors",
+						Title: "Interface Errors",
+						Units: "errors/s",
+					},
+					Dimensions: []DimConfig{
+						{Dim: chartdef.Dim{Name: "in", Algorithm: "incremental"}, OID: "1.3.6.1.2.1.2.2.1.14"},                  // ifInErrors
+						{Dim: chartdef.Dim{Name: "out", Algorithm: "incremental", Multiplier: -1}, OID: "1.3.6.1.2.1.2.2.1.20"}, // ifOutErrors
+					},
+				},
+				{
+					Chart: chartdef.Chart{
+						ID:    "if_discards",
+						Title: "Interface Discards",
+						Units: "discards/s",
+					},
+					Dimensions: []DimConfig{
+						{Dim: chartdef.Dim{Name: "in", Algorithm: "incremental"}, OID: "1.3.6.1.2.1.2.2.1.13"},                  // ifInDiscards
+						{Dim: chartdef.Dim{Name: "out", Algorithm: "incremental", Multiplier: -1}, OID: "1.3.6.1.2.1.2.2.1.19"}, // ifOutDiscards
+					},
+				},
+				{
+					Chart: chartdef.Chart{
+						ID:    "if_oper_status",
+						Title: "Interface Operational Status",
+						Units: "status",
+					},
+					Dimensions: []DimConfig{
+						// 1 up, 2 down, 3 testing, 4 unknown, 5 dormant, 6 notPresent, 7 lowerLayerDown
+						{Dim: chartdef.Dim{Name: "status", Algorithm: "absolute"}, OID: "1.3.6.1.2.1.2.2.1.8"}, // ifOperStatus
+					},
+				},
+			},
+		},
+	},
+}
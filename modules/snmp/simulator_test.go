@@ -0,0 +1,121 @@
+package snmp
+
+import (
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+)
+
+// agentSimulator is a minimal SNMPv1/v2c agent that serves a fixed set of OIDs.
+// It answers GET, GETNEXT and GETBULK requests and ignores requests with a wrong community.
+type agentSimulator struct {
+	conn      *net.UDPConn
+	community string
+	oids      []string
+	pdus      map[string]gosnmp.SnmpPDU
+}
+
+func newAgentSimulator(t *testing.T, community string, pdus []gosnmp.SnmpPDU) *agentSimulator {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	a := &agentSimulator{conn: conn, community: community, pdus: make(map[string]gosnmp.SnmpPDU)}
+	for _, pdu := range pdus {
+		pdu.Name = normalizeOID(pdu.Name)
+		a.oids = append(a.oids, pdu.Name)
+		a.pdus[pdu.Name] = pdu
+	}
+	sort.Slice(a.oids, func(i, j int) bool { return lessOID(a.oids[i][1:], a.oids[j][1:]) })
+
+	go a.serve()
+	return a
+}
+
+func (a *agentSimulator) port() int {
+	return a.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func (a *agentSimulator) close() {
+	_ = a.conn.Close()
+}
+
+func (a *agentSimulator) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		req, err := (&gosnmp.GoSNMP{}).SnmpDecodePacket(buf[:n])
+		if err != nil || req.Community != a.community {
+			continue
+		}
+
+		resp := &gosnmp.SnmpPacket{
+			Version:   req.Version,
+			Community: req.Community,
+			PDUType:   gosnmp.GetResponse,
+			RequestID: req.RequestID,
+		}
+		a.handle(req, resp)
+
+		if b, err := resp.MarshalMsg(); err == nil {
+			_, _ = a.conn.WriteToUDP(b, addr)
+		}
+	}
+}
+
+func (a *agentSimulator) handle(req, resp *gosnmp.SnmpPacket) {
+	for i, v := range req.Variables {
+		switch req.PDUType {
+		case gosnmp.GetRequest:
+			pdu, ok := a.pdus[v.Name]
+			if !ok {
+				pdu = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject}
+				if req.Version == gosnmp.Version1 {
+					resp.Error, resp.ErrorIndex = gosnmp.NoSuchName, uint8(i+1)
+				}
+			}
+			resp.Variables = append(resp.Variables, pdu)
+		case gosnmp.GetNextRequest:
+			next := a.next(v.Name, 1)
+			if len(next) == 0 {
+				if req.Version == gosnmp.Version1 {
+					resp.Error, resp.ErrorIndex = gosnmp.NoSuchName, uint8(i+1)
+					next = []gosnmp.SnmpPDU{{Name: v.Name, Type: gosnmp.Null}}
+				} else {
+					next = []gosnmp.SnmpPDU{{Name: v.Name, Type: gosnmp.EndOfMibView}}
+				}
+			}
+			resp.Variables = append(resp.Variables, next...)
+		case gosnmp.GetBulkRequest:
+			// the gosnmp decoder doesn't fill in the max-repetitions of requests
+			maxRep := int(req.MaxRepetitions)
+			if maxRep == 0 {
+				maxRep = 10
+			}
+			next := a.next(v.Name, maxRep)
+			if len(next) < maxRep {
+				next = append(next, gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.EndOfMibView})
+			}
+			resp.Variables = append(resp.Variables, next...)
+		}
+	}
+}
+
+// next returns up to n PDUs following the OID in the lexicographical order.
+func (a *agentSimulator) next(oid string, n int) []gosnmp.SnmpPDU {
+	oid = normalizeOID(oid)
+	i := sort.Search(len(a.oids), func(i int) bool { return lessOID(oid[1:], a.oids[i][1:]) })
+
+	var pdus []gosnmp.SnmpPDU
+	for ; i < len(a.oids) && len(pdus) < n; i++ {
+		pdus = append(pdus, a.pdus[a.oids[i]])
+	}
+	return pdus
+}
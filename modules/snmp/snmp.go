@@ -0,0 +1,133 @@
+package snmp
+
+import (
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	module.Register("snmp", module.Creator{
+		Defaults: module.Defaults{
+			UpdateEvery: 10,
+		},
+		Create: func() module.Module { return New() },
+	})
+}
+
+func New() *SNMP {
+	return &SNMP{
+		Config: Config{
+			Port:           161,
+			Community:      "public",
+			Version:        "2c",
+			Timeout:        web.Duration{Duration: time.Second},
+			Retries:        1,
+			MaxRepetitions: 25,
+		},
+		charts: &module.Charts{},
+	}
+}
+
+type (
+	Config struct {
+		Hostname       string        `yaml:"hostname"`
+		Port           int           `yaml:"port"`
+		Community      string        `yaml:"community"`
+		Version        string        `yaml:"version"`
+		User           User          `yaml:"user"`
+		Timeout        web.Duration  `yaml:"timeout"`
+		Retries        int           `yaml:"retries"`
+		MaxRepetitions int           `yaml:"max_repetitions"`
+		Profiles       []string      `yaml:"profiles"`
+		Charts         []ChartConfig `yaml:"charts"`
+		Tables         []TableConfig `yaml:"tables"`
+	}
+	User struct {
+		Name      string `yaml:"name"`
+		Level     string `yaml:"level"`
+		AuthProto string `yaml:"auth_proto"`
+		AuthKey   string `yaml:"auth_key"`
+		PrivProto string `yaml:"priv_proto"`
+		PrivKey   string `yaml:"priv_key"`
+	}
+	ChartConfig struct {
+		chartdef.Chart `yaml:",inline"`
+		Dimensions     []DimConfig `yaml:"dimensions"`
+	}
+	DimConfig struct {
+		chartdef.Dim `yaml:",inline"`
+		OID          string `yaml:"oid"`
+	}
+	TableConfig struct {
+		Name     string        `yaml:"name"`
+		LabelOID string        `yaml:"label_oid"`
+		Charts   []ChartConfig `yaml:"charts"`
+	}
+	SNMP struct {
+		module.Base
+		Config `yaml:",inline"`
+
+		snmpClient *gosnmp.GoSNMP
+		connected  bool
+		scalars    []*chartDef
+		tables     []*tableDef
+		charts     *module.Charts
+	}
+)
+
+func (s *SNMP) Cleanup() {
+	if s.snmpClient == nil || s.snmpClient.Conn == nil {
+		return
+	}
+	if err := s.snmpClient.Conn.Close(); err != nil {
+		s.Errorf("cleanup: error on closing the connection to %s: %v", s.Hostname, err)
+	}
+	s.connected = false
+}
+
+func (s *SNMP) Init() bool {
+	if err := s.validateConfig(); err != nil {
+		s.Errorf("config validation: %v", err)
+		return false
+	}
+
+	client, err := s.initSNMPClient()
+	if err != nil {
+		s.Errorf("error on initializing SNMP client: %v", err)
+		return false
+	}
+	s.snmpClient = client
+
+	if err := s.initDefinitions(); err != nil {
+		s.Errorf("error on initializing charts: %v", err)
+		return false
+	}
+
+	s.Debugf("using %s:%d, SNMP version %s", s.Hostname, s.Port, s.Version)
+	return true
+}
+
+func (s *SNMP) Check() bool {
+	return len(s.Collect()) > 0
+}
+
+func (s *SNMP) Charts() *module.Charts {
+	return s.charts
+}
+
+func (s *SNMP) Collect() map[string]int64 {
+	mx, err := s.collect()
+	if err != nil {
+		s.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
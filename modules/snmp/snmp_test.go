@@ -0,0 +1,335 @@
+package snmp
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPDUs is a switch with two interfaces and a UPS battery temperature.
+var testPDUs = []gosnmp.SnmpPDU{
+	{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(360000)},           // sysUpTime
+	{Name: "1.3.6.1.2.1.33.1.2.7.0", Type: gosnmp.Integer, Value: 41},                    // upsBatteryTemperature
+	{Name: "1.3.6.1.4.1.99999.1.0", Type: gosnmp.OctetString, Value: []byte("12.5")},     // numeric string
+	{Name: "1.3.6.1.2.1.31.1.1.1.1.1", Type: gosnmp.OctetString, Value: []byte("Gi0/1")}, // ifName
+	{Name: "1.3.6.1.2.1.31.1.1.1.1.2", Type: gosnmp.OctetString, Value: []byte("Gi0/2")}, // ifName
+	{Name: "1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(1000)},      // ifHCInOctets
+	{Name: "1.3.6.1.2.1.31.1.1.1.6.2", Type: gosnmp.Counter64, Value: uint64(1 << 63)},   // ifHCInOctets
+	{Name: "1.3.6.1.2.1.31.1.1.1.7.1", Type: gosnmp.Counter64, Value: uint64(10)},        // ifHCInUcastPkts
+	{Name: "1.3.6.1.2.1.31.1.1.1.7.2", Type: gosnmp.Counter64, Value: uint64(20)},        // ifHCInUcastPkts
+	{Name: "1.3.6.1.2.1.31.1.1.1.10.1", Type: gosnmp.Counter64, Value: uint64(2000)},     // ifHCOutOctets
+	{Name: "1.3.6.1.2.1.31.1.1.1.10.2", Type: gosnmp.Counter64, Value: uint64(3000)},     // ifHCOutOctets
+	{Name: "1.3.6.1.2.1.31.1.1.1.11.1", Type: gosnmp.Counter64, Value: uint64(11)},       // ifHCOutUcastPkts
+	{Name: "1.3.6.1.2.1.31.1.1.1.11.2", Type: gosnmp.Counter64, Value: uint64(21)},       // ifHCOutUcastPkts
+	{Name: "1.3.6.1.2.1.2.2.1.8.1", Type: gosnmp.Integer, Value: 1},                      // ifOperStatus
+	{Name: "1.3.6.1.2.1.2.2.1.8.2", Type: gosnmp.Integer, Value: 2},                      // ifOperStatus
+	{Name: "1.3.6.1.2.1.2.2.1.13.1", Type: gosnmp.Counter32, Value: uint(0)},             // ifInDiscards
+	{Name: "1.3.6.1.2.1.2.2.1.13.2", Type: gosnmp.Counter32, Value: uint(1)},             // ifInDiscards
+	{Name: "1.3.6.1.2.1.2.2.1.14.1", Type: gosnmp.Counter32, Value: uint(2)},             // ifInErrors
+	{Name: "1.3.6.1.2.1.2.2.1.14.2", Type: gosnmp.Counter32, Value: uint(3)},             // ifInErrors
+	{Name: "1.3.6.1.2.1.2.2.1.19.1", Type: gosnmp.Counter32, Value: uint(4)},             // ifOutDiscards
+	{Name: "1.3.6.1.2.1.2.2.1.19.2", Type: gosnmp.Counter32, Value: uint(5)},             // ifOutDiscards
+	{Name: "1.3.6.1.2.1.2.2.1.20.1", Type: gosnmp.Counter32, Value: uint(6)},             // ifOutErrors
+	{Name: "1.3.6.1.2.1.2.2.1.20.2", Type: gosnmp.Counter32, Value: uint(7)},             // ifOutErrors
+	{Name: "1.3.6.1.4.1.99999.2.1.1.5", Type: gosnmp.Gauge32, Value: uint(50)},           // custom table, no label
+	{Name: "1.3.6.1.4.1.99999.2.1.1.6", Type: gosnmp.Gauge32, Value: uint(60)},           // custom table, no label
+}
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestSNMP_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   func(cfg *Config)
+		wantFail bool
+	}{
+		"success on default test config": {
+			config: func(cfg *Config) {},
+		},
+		"success on SNMPv1": {
+			config: func(cfg *Config) { cfg.Version = "1" },
+		},
+		"success on SNMPv3 authPriv": {
+			config: func(cfg *Config) {
+				cfg.Version = "3"
+				cfg.User = User{Name: "netdata", Level: "authPriv", AuthProto: "sha256", AuthKey: "authkey1", PrivProto: "aes", PrivKey: "privkey1"}
+			},
+		},
+		"success on SNMPv3 noAuthNoPriv": {
+			config: func(cfg *Config) {
+				cfg.Version = "3"
+				cfg.User = User{Name: "netdata", Level: "noAuthNoPriv"}
+			},
+		},
+		"fails on unset hostname": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Hostname = "" },
+		},
+		"fails on unknown version": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Version = "4" },
+		},
+		"fails on SNMPv3 without user": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Version = "3"; cfg.User = User{Level: "noAuthNoPriv"} },
+		},
+		"fails on SNMPv3 unknown auth protocol": {
+			wantFail: true,
+			config: func(cfg *Config) {
+				cfg.Version = "3"
+				cfg.User = User{Name: "netdata", Level: "authNoPriv", AuthProto: "sha1024"}
+			},
+		},
+		"fails on SNMPv3 unknown priv protocol": {
+			wantFail: true,
+			config: func(cfg *Config) {
+				cfg.Version = "3"
+				cfg.User = User{Name: "netdata", Level: "authPriv", AuthProto: "sha", PrivProto: "rot13"}
+			},
+		},
+		"fails on nothing to collect": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Profiles, cfg.Charts, cfg.Tables = nil, nil, nil },
+		},
+		"fails on unknown profile": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Profiles = []string{"printer-mib"} },
+		},
+		"fails on duplicate chart id": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].ID = "if_traffic" },
+		},
+		"fails on bad OID": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Dimensions[0].OID = "iso.3.6" },
+		},
+		"fails on bad label OID": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Tables[0].LabelOID = "1..2" },
+		},
+		"fails on dimension without name": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Dimensions[0].Name = "" },
+		},
+		"fails on unknown algorithm": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Charts[0].Dimensions[0].Algorithm = "delta" },
+		},
+		"fails on table without charts": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Tables[0].Charts = nil },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config = prepareConfig(161)
+			test.config(&job.Config)
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestSNMP_Check(t *testing.T) {
+	tests := map[string]struct {
+		version   string
+		community string
+		wantFail  bool
+	}{
+		"success on SNMPv2c":           {version: "2c", community: "public"},
+		"success on SNMPv1":            {version: "1", community: "public"},
+		"fails on the wrong community": {version: "2c", community: "private", wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			agent := newAgentSimulator(t, "public", testPDUs)
+			defer agent.close()
+
+			job := New()
+			job.Config = prepareConfig(agent.port())
+			job.Version = test.version
+			job.Community = test.community
+			job.Timeout.Duration = job.Timeout.Duration / 10
+			job.Retries = 0
+			require.True(t, job.Init())
+			defer job.Cleanup()
+
+			if test.wantFail {
+				assert.False(t, job.Check())
+			} else {
+				assert.True(t, job.Check())
+			}
+		})
+	}
+}
+
+func TestSNMP_Charts(t *testing.T) {
+	assert.NotNil(t, New().Charts())
+}
+
+func TestSNMP_Cleanup(t *testing.T) {
+	assert.NotPanics(t, New().Cleanup)
+}
+
+func TestSNMP_Collect(t *testing.T) {
+	for _, version := range []string{"1", "2c"} {
+		t.Run("SNMPv"+version, func(t *testing.T) {
+			agent := newAgentSimulator(t, "public", testPDUs)
+			defer agent.close()
+
+			job := New()
+			job.Config = prepareConfig(agent.port())
+			job.Version = version
+			require.True(t, job.Init())
+			defer job.Cleanup()
+
+			expected := map[string]int64{
+				"system_uptime":           360000,
+				"ups_temperature":         41,
+				"ups_voltage":             12,
+				"if_traffic_1_in":         1000,
+				"if_traffic_1_out":        2000,
+				"if_traffic_2_in":         0,
+				"if_traffic_2_out":        3000,
+				"if_packets_1_in":         10,
+				"if_packets_1_out":        11,
+				"if_packets_2_in":         20,
+				"if_packets_2_out":        21,
+				"if_errors_1_in":          2,
+				"if_errors_1_out":         6,
+				"if_errors_2_in":          3,
+				"if_errors_2_out":         7,
+				"if_discards_1_in":        0,
+				"if_discards_1_out":       4,
+				"if_discards_2_in":        1,
+				"if_discards_2_out":       5,
+				"if_oper_status_1_status": 1,
+				"if_oper_status_2_status": 2,
+				"sensors_1_5_value":       50,
+				"sensors_1_6_value":       60,
+			}
+
+			assert.Equal(t, expected, job.Collect())
+
+			chart := job.Charts().Get("if_traffic_1")
+			require.NotNil(t, chart)
+			assert.Equal(t, "Gi0/1", chart.Fam)
+			assert.Equal(t, "snmp.if_traffic", chart.Ctx)
+			assert.Equal(t, "Interface Traffic (Gi0/1)", chart.Title)
+			assert.Equal(t, module.Incremental, chart.GetDim("if_traffic_1_in").Algo)
+
+			chart = job.Charts().Get("system")
+			require.NotNil(t, chart)
+			assert.Len(t, chart.Dims, 1)
+			assert.Equal(t, module.Absolute, chart.GetDim("system_uptime").Algo)
+
+			chart = job.Charts().Get("sensors_1_5")
+			require.NotNil(t, chart)
+			assert.Equal(t, module.Absolute, chart.GetDim("sensors_1_5_value").Algo)
+		})
+	}
+}
+
+func TestSNMP_Collect_DefaultAlgorithmByType(t *testing.T) {
+	agent := newAgentSimulator(t, "public", testPDUs)
+	defer agent.close()
+
+	job := New()
+	job.Config = prepareConfig(agent.port())
+	job.Profiles = nil
+	job.Tables = nil
+	job.Config.Charts = []ChartConfig{
+		{
+			Chart: chartdef.Chart{ID: "mixed"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Name: "counter"}, OID: "1.3.6.1.2.1.2.2.1.14.1"},
+				{Dim: chartdef.Dim{Name: "gauge"}, OID: "1.3.6.1.4.1.99999.2.1.1.5"},
+			},
+		},
+	}
+	require.True(t, job.Init())
+	defer job.Cleanup()
+
+	require.NotNil(t, job.Collect())
+
+	chart := job.Charts().Get("mixed")
+	require.NotNil(t, chart)
+	assert.Equal(t, module.Incremental, chart.GetDim("mixed_counter").Algo)
+	assert.Equal(t, module.Absolute, chart.GetDim("mixed_gauge").Algo)
+}
+
+func TestSNMP_Collect_DuplicateRowLabels(t *testing.T) {
+	pdus := append([]gosnmp.SnmpPDU(nil), testPDUs...)
+	for i, pdu := range pdus {
+		if pdu.Name == "1.3.6.1.2.1.31.1.1.1.1.2" {
+			pdus[i].Value = []byte("Gi0/1")
+		}
+	}
+	agent := newAgentSimulator(t, "public", pdus)
+	defer agent.close()
+
+	job := New()
+	job.Config = prepareConfig(agent.port())
+	require.True(t, job.Init())
+	defer job.Cleanup()
+
+	mx := job.Collect()
+
+	assert.Equal(t, int64(2000), mx["if_traffic_1_out"])
+	assert.Equal(t, int64(3000), mx["if_traffic_2_out"])
+	for _, id := range []string{"if_traffic_1", "if_traffic_2"} {
+		chart := job.Charts().Get(id)
+		require.NotNilf(t, chart, "chart '%s'", id)
+		assert.Equal(t, "Interface Traffic (Gi0/1)", chart.Title)
+	}
+}
+
+func prepareConfig(port int) Config {
+	cfg := New().Config
+	cfg.Hostname = "127.0.0.1"
+	cfg.Port = port
+	cfg.Profiles = []string{"if-mib"}
+	cfg.Charts = []ChartConfig{
+		{
+			Chart: chartdef.Chart{ID: "system"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Name: "uptime"}, OID: "1.3.6.1.2.1.1.3.0"},
+				{Dim: chartdef.Dim{Name: "missing"}, OID: "1.3.6.1.2.1.1.99.0"},
+			},
+		},
+		{
+			Chart: chartdef.Chart{ID: "ups"},
+			Dimensions: []DimConfig{
+				{Dim: chartdef.Dim{Name: "temperature"}, OID: ".1.3.6.1.2.1.33.1.2.7.0"},
+				{Dim: chartdef.Dim{Name: "voltage"}, OID: "1.3.6.1.4.1.99999.1.0"},
+			},
+		},
+	}
+	cfg.Tables = []TableConfig{
+		{
+			Name: "sensors",
+			Charts: []ChartConfig{
+				{
+					Chart: chartdef.Chart{ID: "sensors"},
+					Dimensions: []DimConfig{
+						{Dim: chartdef.Dim{Name: "value"}, OID: "1.3.6.1.4.1.99999.2.1"},
+					},
+				},
+			},
+		},
+	}
+	return cfg
+}
@@ -0,0 +1,48 @@
+package sqlquery
+
+import (
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+type (
+	Charts = module.Charts
+	Chart  = module.Chart
+	Dim    = module.Dim
+)
+
+type chartDef struct {
+	cfg  ChartConfig
+	dims []DimConfig
+	// dynamic is true if the chart has a dimension per row
+	dynamic bool
+	seen    map[string]bool
+
+	chart     *Chart
+	rowCharts map[string]*Chart
+}
+
+func newChart(cfg ChartConfig, id, label string) *Chart {
+	chart := cfg.New("sql_query.")
+	chart.ID = id
+	if label != "" {
+		chart.Title += " (" + label + ")"
+	}
+	return chart
+}
+
+func newDim(chartID, name string, cfg DimConfig) *Dim {
+	return cfg.New(dimID(chartID, name), name)
+}
+
+func dimID(chartID, name string) string {
+	return chartID + "_" + cleanID(name)
+}
+
+var idReplacer = strings.NewReplacer(" ", "_", ".", "_", ",", "_")
+
+// cleanID makes a row value usable as a part of a chart or a dimension ID.
+func cleanID(s string) string {
+	return idReplacer.Replace(s)
+}
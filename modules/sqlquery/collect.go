@@ -0,0 +1,205 @@
+package sqlquery
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+)
+
+type queryDef struct {
+	cfg      QueryConfig
+	timeout  time.Duration
+	interval time.Duration
+	charts   []*chartDef
+
+	lastRun time.Time
+	// cache holds the last collected values, they are reported until the next run
+	cache map[string]int64
+}
+
+func (s *SQLQuery) collect() (map[string]int64, error) {
+	if s.db == nil {
+		if err := s.openConnection(); err != nil {
+			return nil, err
+		}
+	}
+
+	mx := make(map[string]int64)
+	now := time.Now()
+
+	for _, q := range s.queries {
+		if q.cache == nil || now.Sub(q.lastRun) >= q.interval {
+			qmx, err := s.runQuery(q)
+			if err != nil {
+				s.Errorf("error on running query '%s': %v", q.cfg.ID, err)
+				continue
+			}
+			q.lastRun, q.cache = now, qmx
+		}
+
+		for k, v := range q.cache {
+			mx[k] = v
+		}
+	}
+	return mx, nil
+}
+
+func (s *SQLQuery) runQuery(q *queryDef) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, q.cfg.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	labelIdx := -1
+	if q.cfg.LabelColumn != "" {
+		if labelIdx = columnIndex(columns, q.cfg.LabelColumn); labelIdx == -1 {
+			return nil, fmt.Errorf("label column '%s' not found in the result", q.cfg.LabelColumn)
+		}
+	}
+
+	mx := make(map[string]int64)
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := resultRow{columns: columns, values: values}
+		if labelIdx == -1 {
+			for _, def := range q.charts {
+				s.collectRow(mx, def, "", row)
+			}
+			// without a label column only the first row is used
+			break
+		}
+
+		if !values[labelIdx].Valid || values[labelIdx].String == "" {
+			continue
+		}
+		for _, def := range q.charts {
+			s.collectRow(mx, def, values[labelIdx].String, row)
+		}
+	}
+	return mx, rows.Err()
+}
+
+func (s *SQLQuery) collectRow(mx map[string]int64, def *chartDef, label string, row resultRow) {
+	switch {
+	case def.cfg.PerRow:
+		chart, ok := def.rowCharts[label]
+		if !ok {
+			chart = newChart(def.cfg, def.cfg.ID+"_"+cleanID(label), label)
+			for _, dimCfg := range def.dims {
+				_ = chart.AddDim(newDim(chart.ID, dimCfg.Name, dimCfg))
+			}
+			if err := s.charts.Add(chart); err != nil {
+				s.Warning(err)
+			}
+			def.rowCharts[label] = chart
+		}
+		for _, dimCfg := range def.dims {
+			if v, ok := row.value(dimCfg.Column); ok {
+				mx[dimID(chart.ID, dimCfg.Name)] = v
+			}
+		}
+	case def.dynamic:
+		for _, dimCfg := range def.dims {
+			v, ok := row.value(dimCfg.Column)
+			if !ok {
+				continue
+			}
+			name := label
+			if dimCfg.Name != "" {
+				name = dimCfg.Name + "_" + label
+			}
+			if !def.seen[name] {
+				def.seen[name] = true
+				if err := def.chart.AddDim(newDim(def.chart.ID, name, dimCfg)); err != nil {
+					s.Warning(err)
+				}
+				def.chart.MarkNotCreated()
+			}
+			mx[dimID(def.chart.ID, name)] = v
+		}
+	default:
+		for _, dimCfg := range def.dims {
+			if v, ok := row.value(dimCfg.Column); ok {
+				mx[dimID(def.chart.ID, dimCfg.Name)] = v
+			}
+		}
+	}
+}
+
+func (s *SQLQuery) openConnection() error {
+	db, err := sql.Open(s.driverName, s.DSN)
+	if err != nil {
+		return fmt.Errorf("error on opening a connection with the %s database: %v", s.Driver, err)
+	}
+
+	db.SetConnMaxLifetime(10 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout.Duration)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return fmt.Errorf("error on pinging the %s database: %v", s.Driver, err)
+	}
+
+	s.db = db
+	return nil
+}
+
+type resultRow struct {
+	columns []string
+	values  []sql.NullString
+}
+
+// value returns the column value multiplied by chartdef.Precision.
+// Booleans are converted to 1/0, NULLs and non numeric values are skipped.
+func (r resultRow) value(column string) (int64, bool) {
+	idx := columnIndex(r.columns, column)
+	if idx == -1 || !r.values[idx].Valid {
+		return 0, false
+	}
+
+	s := strings.TrimSpace(r.values[idx].String)
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(v * chartdef.Precision), true
+	}
+	if v, err := strconv.ParseBool(s); err == nil {
+		if v {
+			return chartdef.Precision, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// columnIndex returns the index of the column, column names are case insensitive.
+func columnIndex(columns []string, name string) int {
+	for i, c := range columns {
+		if strings.EqualFold(c, name) {
+			return i
+		}
+	}
+	return -1
+}
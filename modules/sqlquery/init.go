@@ -0,0 +1,134 @@
+package sqlquery
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// driverNames maps the 'driver' option values to the database/sql driver names.
+var driverNames = map[string]string{
+	"mysql":      "mysql",
+	"postgres":   "postgres",
+	"postgresql": "postgres",
+	"sqlite":     "sqlite",
+	"sqlite3":    "sqlite",
+}
+
+func (s SQLQuery) validateConfig() (string, error) {
+	driverName, ok := driverNames[s.Driver]
+	if !ok {
+		return "", fmt.Errorf("unknown driver '%s'", s.Driver)
+	}
+	if s.DSN == "" {
+		return "", errors.New("DSN not set")
+	}
+	if len(s.Queries) == 0 {
+		return "", errors.New("'queries' not set")
+	}
+	return driverName, nil
+}
+
+func (s *SQLQuery) initQueries() error {
+	seen := make(map[string]bool)
+	for i, cfg := range s.Queries {
+		q, err := s.newQueryDef(cfg)
+		if err != nil {
+			return fmt.Errorf("query[%d]: %v", i+1, err)
+		}
+		if seen[cfg.ID] {
+			return fmt.Errorf("query[%d]: duplicate id '%s'", i+1, cfg.ID)
+		}
+		seen[cfg.ID] = true
+
+		for _, def := range q.charts {
+			if def.cfg.PerRow {
+				continue
+			}
+			if err := s.charts.Add(def.chart); err != nil {
+				return fmt.Errorf("query '%s': %v", cfg.ID, err)
+			}
+		}
+		s.queries = append(s.queries, q)
+	}
+	return nil
+}
+
+func (s SQLQuery) newQueryDef(cfg QueryConfig) (*queryDef, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("'id' not set")
+	}
+	if cfg.Query == "" {
+		return nil, fmt.Errorf("query '%s': 'query' not set", cfg.ID)
+	}
+	if cfg.UpdateEvery < 0 {
+		return nil, fmt.Errorf("query '%s': bad update_every: %d", cfg.ID, cfg.UpdateEvery)
+	}
+	if len(cfg.Charts) == 0 {
+		return nil, fmt.Errorf("query '%s': 'charts' not set", cfg.ID)
+	}
+
+	q := &queryDef{
+		cfg:      cfg,
+		timeout:  cfg.Timeout.Duration,
+		interval: time.Duration(cfg.UpdateEvery) * time.Second,
+	}
+	if q.timeout <= 0 {
+		q.timeout = s.Timeout.Duration
+	}
+
+	for i, chartCfg := range cfg.Charts {
+		def, err := newChartDef(chartCfg, cfg.LabelColumn != "")
+		if err != nil {
+			return nil, fmt.Errorf("query '%s': chart[%d]: %v", cfg.ID, i+1, err)
+		}
+		q.charts = append(q.charts, def)
+	}
+	return q, nil
+}
+
+func newChartDef(cfg ChartConfig, hasLabel bool) (*chartDef, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.PerRow && !hasLabel {
+		return nil, fmt.Errorf("chart '%s': 'per_row' requires the query 'label_column'", cfg.ID)
+	}
+	if len(cfg.Dimensions) == 0 {
+		return nil, fmt.Errorf("chart '%s': 'dimensions' not set", cfg.ID)
+	}
+
+	// without a label column every dimension is a single column value, the same is true for per row charts
+	dynamic := hasLabel && !cfg.PerRow
+
+	def := &chartDef{
+		cfg:       cfg,
+		dynamic:   dynamic,
+		seen:      make(map[string]bool),
+		rowCharts: make(map[string]*Chart),
+	}
+	for i, dimCfg := range cfg.Dimensions {
+		if dimCfg.Column == "" {
+			return nil, fmt.Errorf("chart '%s': dimension[%d]: 'column' not set", cfg.ID, i+1)
+		}
+		if dimCfg.Name == "" && !dynamic {
+			dimCfg.Name = dimCfg.Column
+		}
+		if err := dimCfg.Init(); err != nil {
+			return nil, fmt.Errorf("chart '%s': dimension[%d]: %v", cfg.ID, i+1, err)
+		}
+		def.dims = append(def.dims, dimCfg)
+	}
+
+	if !cfg.PerRow {
+		def.chart = newChart(cfg, cfg.ID, "")
+		if !dynamic {
+			for _, dimCfg := range def.dims {
+				if err := def.chart.AddDim(newDim(def.chart.ID, dimCfg.Name, dimCfg)); err != nil {
+					return nil, fmt.Errorf("chart '%s': %v", cfg.ID, err)
+				}
+			}
+		}
+	}
+	return def, nil
+}
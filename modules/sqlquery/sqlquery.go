@@ -0,0 +1,111 @@
+package sqlquery
+
+import (
+	"database/sql"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+func init() {
+	module.Register("sql_query", module.Creator{
+		Create: func() module.Module { return New() },
+	})
+}
+
+func New() *SQLQuery {
+	return &SQLQuery{
+		Config: Config{
+			Timeout: web.Duration{Duration: time.Second * 5},
+		},
+		charts: &module.Charts{},
+	}
+}
+
+type (
+	Config struct {
+		Driver  string        `yaml:"driver"`
+		DSN     string        `yaml:"dsn"`
+		Timeout web.Duration  `yaml:"timeout"`
+		Queries []QueryConfig `yaml:"queries"`
+	}
+	QueryConfig struct {
+		ID          string        `yaml:"id"`
+		Query       string        `yaml:"query"`
+		Timeout     web.Duration  `yaml:"timeout"`
+		UpdateEvery int           `yaml:"update_every"`
+		LabelColumn string        `yaml:"label_column"`
+		Charts      []ChartConfig `yaml:"charts"`
+	}
+	ChartConfig struct {
+		chartdef.Chart `yaml:",inline"`
+		PerRow         bool        `yaml:"per_row"`
+		Dimensions     []DimConfig `yaml:"dimensions"`
+	}
+	DimConfig struct {
+		chartdef.Dim `yaml:",inline"`
+		Column       string `yaml:"column"`
+	}
+	SQLQuery struct {
+		module.Base
+		Config `yaml:",inline"`
+
+		driverName string
+		db         *sql.DB
+		queries    []*queryDef
+		charts     *module.Charts
+	}
+)
+
+func (s *SQLQuery) Cleanup() {
+	if s.db == nil {
+		return
+	}
+	if err := s.db.Close(); err != nil {
+		s.Errorf("cleanup: error on closing the %s database: %v", s.Driver, err)
+	}
+	s.db = nil
+}
+
+func (s *SQLQuery) Init() bool {
+	driverName, err := s.validateConfig()
+	if err != nil {
+		s.Errorf("config validation: %v", err)
+		return false
+	}
+	s.driverName = driverName
+
+	if err := s.initQueries(); err != nil {
+		s.Errorf("error on initializing queries: %v", err)
+		return false
+	}
+
+	s.Debugf("using driver '%s', %d queries", s.Driver, len(s.queries))
+	return true
+}
+
+func (s *SQLQuery) Check() bool {
+	return len(s.Collect()) > 0
+}
+
+func (s *SQLQuery) Charts() *module.Charts {
+	return s.charts
+}
+
+func (s *SQLQuery) Collect() map[string]int64 {
+	mx, err := s.collect()
+	if err != nil {
+		s.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
@@ -0,0 +1,253 @@
+package sqlquery
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/chartdef"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, amount REAL, paid BOOLEAN);
+INSERT INTO orders (status, amount, paid) VALUES
+  ('new', 10.5, 0),
+  ('new', 4.25, 0),
+  ('shipped', 100, 1),
+  ('in progress', 1, 1);
+`
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestSQLQuery_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   func(cfg *Config)
+		wantFail bool
+	}{
+		"success on default test config": {
+			config: func(cfg *Config) {},
+		},
+		"success on postgres driver": {
+			config: func(cfg *Config) { cfg.Driver = "postgresql" },
+		},
+		"fails on unknown driver": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Driver = "oracle" },
+		},
+		"fails on unset DSN": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.DSN = "" },
+		},
+		"fails on unset queries": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries = nil },
+		},
+		"fails on duplicate query id": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[1].ID = cfg.Queries[0].ID },
+		},
+		"fails on unset query": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].Query = "" },
+		},
+		"fails on negative update_every": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].UpdateEvery = -1 },
+		},
+		"fails on duplicate chart id": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[1].Charts[0].ID = cfg.Queries[0].Charts[0].ID },
+		},
+		"fails on per row chart without label column": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].Charts[0].PerRow = true },
+		},
+		"fails on unknown chart type": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].Charts[0].Type = "pie" },
+		},
+		"fails on dimension without column": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].Charts[0].Dimensions[0].Column = "" },
+		},
+		"fails on unknown algorithm": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].Charts[0].Dimensions[0].Algorithm = "delta" },
+		},
+		"fails on negative divisor": {
+			wantFail: true,
+			config:   func(cfg *Config) { cfg.Queries[0].Charts[0].Dimensions[0].Divisor = -1 },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config = prepareConfig("file:test.db")
+			test.config(&job.Config)
+
+			if test.wantFail {
+				assert.False(t, job.Init())
+			} else {
+				assert.True(t, job.Init())
+			}
+		})
+	}
+}
+
+func TestSQLQuery_Check(t *testing.T) {
+	job := prepareSQLQueryWithDB(t)
+	defer job.Cleanup()
+
+	assert.True(t, job.Check())
+}
+
+func TestSQLQuery_Check_ReturnsFalseOnQueryError(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig(filepath.Join(t.TempDir(), "empty.db"))
+	require.True(t, job.Init())
+	defer job.Cleanup()
+
+	assert.False(t, job.Check())
+}
+
+func TestSQLQuery_Charts(t *testing.T) {
+	job := New()
+	job.Config = prepareConfig("file:test.db")
+	require.True(t, job.Init())
+
+	charts := job.Charts()
+	require.Len(t, *charts, 2)
+
+	chart := charts.Get("orders")
+	require.NotNil(t, chart)
+	assert.Equal(t, "sql_query.orders", chart.Ctx)
+	require.Len(t, chart.Dims, 2)
+	assert.Equal(t, "total", chart.Dims[0].Name)
+	assert.Equal(t, "count", chart.Dims[1].Name)
+	assert.Equal(t, 1000, chart.Dims[0].Div)
+
+	chart = charts.Get("orders_by_status")
+	require.NotNil(t, chart)
+	assert.Len(t, chart.Dims, 0)
+}
+
+func TestSQLQuery_Cleanup(t *testing.T) {
+	assert.NotPanics(t, New().Cleanup)
+}
+
+func TestSQLQuery_Collect(t *testing.T) {
+	job := prepareSQLQueryWithDB(t)
+	defer job.Cleanup()
+
+	expected := map[string]int64{
+		"orders_count":                     4000,
+		"orders_total":                     115750,
+		"orders_by_status_in_progress":     1000,
+		"orders_by_status_new":             2000,
+		"orders_by_status_shipped":         1000,
+		"orders_amount_in_progress_amount": 1000,
+		"orders_amount_in_progress_paid":   1000,
+		"orders_amount_new_amount":         14750,
+		"orders_amount_new_paid":           0,
+		"orders_amount_shipped_amount":     100000,
+		"orders_amount_shipped_paid":       1000,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+
+	chart := job.Charts().Get("orders_by_status")
+	require.NotNil(t, chart)
+	assert.Len(t, chart.Dims, 3)
+	assert.Equal(t, "in progress", chart.GetDim("orders_by_status_in_progress").Name)
+
+	chart = job.Charts().Get("orders_amount_in_progress")
+	require.NotNil(t, chart)
+	assert.Equal(t, "Orders Amount (in progress)", chart.Title)
+	assert.Len(t, chart.Dims, 2)
+}
+
+func TestSQLQuery_Collect_CachesValuesBetweenRuns(t *testing.T) {
+	job := prepareSQLQueryWithDB(t)
+	defer job.Cleanup()
+	job.queries[0].interval = time.Hour
+
+	mx := job.Collect()
+	require.Equal(t, int64(4000), mx["orders_count"])
+	require.Equal(t, int64(2000), mx["orders_by_status_new"])
+
+	_, err := job.db.Exec("INSERT INTO orders (status, amount, paid) VALUES ('new', 1, 0)")
+	require.NoError(t, err)
+
+	mx = job.Collect()
+	assert.Equal(t, int64(4000), mx["orders_count"])
+	assert.Equal(t, int64(3000), mx["orders_by_status_new"])
+}
+
+func prepareSQLQueryWithDB(t *testing.T) *SQLQuery {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	_, err = db.Exec(testSchema)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	job := New()
+	job.Config = prepareConfig(dsn)
+	require.True(t, job.Init())
+
+	return job
+}
+
+func prepareConfig(dsn string) Config {
+	cfg := New().Config
+	cfg.Driver = "sqlite"
+	cfg.DSN = dsn
+	cfg.Queries = []QueryConfig{
+		{
+			ID:    "totals",
+			Query: "SELECT COUNT(*) AS count, SUM(amount) AS total FROM orders",
+			Charts: []ChartConfig{
+				{
+					Chart: chartdef.Chart{ID: "orders"},
+					Dimensions: []DimConfig{
+						{Dim: chartdef.Dim{Name: "total"}, Column: "TOTAL"},
+						{Column: "count"},
+					},
+				},
+			},
+		},
+		{
+			ID: "by_status",
+			Query: `SELECT status, COUNT(*) AS count, SUM(amount) AS amount, MAX(paid) AS paid
+FROM orders GROUP BY status ORDER BY status`,
+			LabelColumn: "status",
+			Charts: []ChartConfig{
+				{
+					Chart: chartdef.Chart{ID: "orders_by_status"},
+					Dimensions: []DimConfig{
+						{Column: "count"},
+					},
+				},
+				{
+					Chart:  chartdef.Chart{ID: "orders_amount", Title: "Orders Amount"},
+					PerRow: true,
+					Dimensions: []DimConfig{
+						{Column: "amount"},
+						{Column: "paid"},
+					},
+				},
+			},
+		},
+	}
+	return cfg
+}
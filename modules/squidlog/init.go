@@ -12,19 +12,28 @@ func (s *SquidLog) createLogReader() error {
 	s.Cleanup()
 	s.Debug("starting log reader creating")
 
-	reader, err := logs.Open(s.Path, s.ExcludePath, s.Logger)
+	reader, err := s.openLogSource()
 	if err != nil {
 		return fmt.Errorf("creating log reader: %v", err)
 	}
 
-	s.Debugf("created log reader, current file '%s'", reader.CurrentFilename())
+	s.Debugf("created log reader, %s", reader.Info())
 	s.file = reader
 	return nil
 }
 
+func (s *SquidLog) openLogSource() (logs.Source, error) {
+	switch s.Source.Type {
+	case "", logs.SourceFile:
+		return logs.Open(s.Path, s.ExcludePath, s.Logger)
+	default:
+		return logs.OpenSource(s.Source, s.Logger)
+	}
+}
+
 func (s *SquidLog) createParser() error {
 	s.Debug("starting parser creating")
-	lastLine, err := logs.ReadLastLine(s.file.CurrentFilename(), 0)
+	lastLine, err := s.file.LastLine()
 	if err != nil {
 		return fmt.Errorf("read last line: %v", err)
 	}
@@ -30,6 +30,7 @@ func New() *SquidLog {
 			Path:        "/var/log/squid/access.log",
 			ExcludePath: "*.gz",
 			Parser:      cfg,
+			Source:      logs.SourceConfig{Type: logs.SourceFile},
 		},
 	}
 }
@@ -37,6 +38,7 @@ func New() *SquidLog {
 type (
 	Config struct {
 		Parser      logs.ParserConfig `yaml:",inline"`
+		Source      logs.SourceConfig `yaml:",inline"`
 		Path        string            `yaml:"path"`
 		ExcludePath string            `yaml:"exclude_path"`
 	}
@@ -45,7 +47,7 @@ type (
 		module.Base
 		Config `yaml:",inline"`
 
-		file   *logs.Reader
+		file   logs.Source
 		parser logs.Parser
 		line   *logLine
 
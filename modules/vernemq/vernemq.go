@@ -2,9 +2,11 @@ package vernemq
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -39,7 +41,8 @@ func New() *VerneMQ {
 
 type (
 	Config struct {
-		web.HTTP `yaml:",inline"`
+		web.HTTP        `yaml:",inline"`
+		relabel.Configs `yaml:",inline"`
 	}
 
 	VerneMQ struct {
@@ -69,7 +72,12 @@ func (v *VerneMQ) initClient() error {
 		return err
 	}
 
-	v.prom = prometheus.New(client, v.Request)
+	rl, err := v.Configs.Parse()
+	if err != nil {
+		return fmt.Errorf("parsing relabel configs: %v", err)
+	}
+
+	v.prom = prometheus.NewWithRelabeling(client, v.Request, nil, rl)
 	return nil
 }
 
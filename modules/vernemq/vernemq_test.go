@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -44,6 +45,13 @@ func TestVerneMQ_Init_ReturnsFalseIfClientWrongTLSCA(t *testing.T) {
 	assert.False(t, verneMQ.Init())
 }
 
+func TestVerneMQ_Init_ReturnsFalseIfRelabelConfigIsInvalid(t *testing.T) {
+	verneMQ := prepareVerneMQ()
+	verneMQ.MetricRelabelConfigs = []relabel.Config{{Action: "unknown"}}
+
+	assert.False(t, verneMQ.Init())
+}
+
 func TestVerneMQ_Check(t *testing.T) {
 	verneMQ, srv := prepareClientServerV1101(t)
 	defer srv.Close()
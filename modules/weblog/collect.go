@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"io"
 	"runtime"
-	"strconv"
-	"strings"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
+	"github.com/netdata/go.d.plugin/pkg/metrics"
 	"github.com/netdata/go.d.plugin/pkg/stm"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -44,309 +43,106 @@ func (w *WebLog) collect() (map[string]int64, error) {
 }
 
 func (w *WebLog) collectLogLines() (int, error) {
-	logOnce := true
+	defer w.mergeShards()
+
 	var n int
 	for {
-		w.line.reset()
-		err := w.parser.ReadLine(w.line)
+		rows, err := w.batch.ReadBatch()
+		n += len(rows)
+		logs.ProcessSharded(rows, len(w.shards), func(shard int, rows [][]byte) {
+			w.shards[shard].collectRows(rows)
+		})
 		if err != nil {
 			if err == io.EOF {
 				return n, nil
 			}
-			if !logs.IsParseError(err) {
-				return n, err
-			}
-			n++
-			if logOnce {
-				w.Infof("unmatched line: %v (parser: %s)", err, w.parser.Info())
-				logOnce = false
-			}
-			w.collectUnmatched()
-			continue
-		}
-		n++
-		if w.line.empty() {
-			w.collectUnmatched()
-		} else {
-			w.collectLogLine()
+			return n, err
 		}
 	}
 }
 
-func (w *WebLog) collectLogLine() {
-	w.mx.Requests.Inc()
-	w.collectVhost()
-	w.collectPort()
-	w.collectReqScheme()
-	w.collectReqClient()
-	w.collectReqMethod()
-	w.collectReqURL()
-	w.collectReqProto()
-	w.collectRespCode()
-	w.collectReqSize()
-	w.collectRespSize()
-	w.collectReqProcTime()
-	w.collectUpsRespTime()
-	w.collectSSLProto()
-	w.collectSSLCipherSuite()
-	w.collectCustomFields()
-}
-
-func (w *WebLog) collectUnmatched() {
-	w.mx.Requests.Inc()
-	w.mx.ReqUnmatched.Inc()
-}
-
-func (w *WebLog) collectVhost() {
-	if !w.line.hasVhost() {
-		return
-	}
-	c, ok := w.mx.ReqVhost.GetP(w.line.vhost)
-	if !ok {
-		w.addDimToVhostChart(w.line.vhost)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectPort() {
-	if !w.line.hasPort() {
-		return
-	}
-	c, ok := w.mx.ReqPort.GetP(w.line.port)
-	if !ok {
-		w.addDimToPortChart(w.line.port)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectReqClient() {
-	if !w.line.hasReqClient() {
-		return
-	}
-	if strings.ContainsRune(w.line.reqClient, ':') {
-		w.mx.ReqIPv6.Inc()
-		w.mx.UniqueIPv6.Insert(w.line.reqClient)
-		return
-	}
-	// NOTE: count hostname as IPv4 address
-	w.mx.ReqIPv4.Inc()
-	w.mx.UniqueIPv4.Insert(w.line.reqClient)
-}
-
-func (w *WebLog) collectReqScheme() {
-	if !w.line.hasReqScheme() {
-		return
-	}
-	if w.line.reqScheme == "https" {
-		w.mx.ReqHTTPSScheme.Inc()
-	} else {
-		w.mx.ReqHTTPScheme.Inc()
-	}
-}
-
-func (w *WebLog) collectReqMethod() {
-	if !w.line.hasReqMethod() {
-		return
-	}
-	c, ok := w.mx.ReqMethod.GetP(w.line.reqMethod)
-	if !ok {
-		w.addDimToReqMethodChart(w.line.reqMethod)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectReqURL() {
-	if !w.line.hasReqURL() {
-		return
-	}
-	for _, p := range w.urlPatterns {
-		if !p.MatchString(w.line.reqURL) {
-			continue
+func (w *WebLog) mergeShards() {
+	logOnce := true
+	for _, s := range w.shards {
+		if s.parseErr != nil && logOnce {
+			w.Infof("unmatched line: %v (parser: %s)", s.parseErr, w.parser.Info())
+			logOnce = false
 		}
-		c, _ := w.mx.ReqURLPattern.GetP(p.name)
-		c.Inc()
-
-		w.collectURLPatternStats(p.name)
-		return
+		s.parseErr = nil
+		w.mergeMetrics(s.mx)
+		s.mx.clear()
 	}
 }
 
-func (w *WebLog) collectReqProto() {
-	if !w.line.hasReqProto() {
-		return
-	}
-	c, ok := w.mx.ReqVersion.GetP(w.line.reqProto)
-	if !ok {
-		w.addDimToReqVersionChart(w.line.reqProto)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectRespCode() {
-	if !w.line.hasRespCode() {
-		return
-	}
-
-	code := w.line.respCode
-	switch {
-	case code >= 100 && code < 300, code == 304, code == 401:
-		w.mx.ReqSuccess.Inc()
-	case code >= 300 && code < 400:
-		w.mx.ReqRedirect.Inc()
-	case code >= 400 && code < 500:
-		w.mx.ReqBad.Inc()
-	case code >= 500 && code < 600:
-		w.mx.ReqError.Inc()
-	}
-
-	switch code / 100 {
-	case 1:
-		w.mx.Resp1xx.Inc()
-	case 2:
-		w.mx.Resp2xx.Inc()
-	case 3:
-		w.mx.Resp3xx.Inc()
-	case 4:
-		w.mx.Resp4xx.Inc()
-	case 5:
-		w.mx.Resp5xx.Inc()
-	}
-
-	codeStr := strconv.Itoa(code)
-	c, ok := w.mx.RespCode.GetP(codeStr)
-	if !ok {
-		w.addDimToRespCodesChart(codeStr)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectReqSize() {
-	if !w.line.hasReqSize() {
-		return
-	}
-	w.mx.BytesReceived.Add(float64(w.line.reqSize))
-}
-
-func (w *WebLog) collectRespSize() {
-	if !w.line.hasRespSize() {
-		return
-	}
-	w.mx.BytesSent.Add(float64(w.line.respSize))
-}
-
-func (w *WebLog) collectReqProcTime() {
-	if !w.line.hasReqProcTime() {
-		return
-	}
-	w.mx.ReqProcTime.Observe(w.line.reqProcTime)
-	if w.mx.ReqProcTimeHist == nil {
-		return
-	}
-	w.mx.ReqProcTimeHist.Observe(w.line.reqProcTime)
-}
-
-func (w *WebLog) collectUpsRespTime() {
-	if !w.line.hasUpsRespTime() {
-		return
-	}
-	w.mx.UpsRespTime.Observe(w.line.upsRespTime)
-	if w.mx.UpsRespTimeHist == nil {
-		return
-	}
-	w.mx.UpsRespTimeHist.Observe(w.line.upsRespTime)
-}
-
-func (w *WebLog) collectSSLProto() {
-	if !w.line.hasSSLProto() {
-		return
-	}
-	c, ok := w.mx.ReqSSLProto.GetP(w.line.sslProto)
-	if !ok {
-		w.addDimToSSLProtoChart(w.line.sslProto)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectSSLCipherSuite() {
-	if !w.line.hasSSLCipherSuite() {
-		return
-	}
-	c, ok := w.mx.ReqSSLCipherSuite.GetP(w.line.sslCipherSuite)
-	if !ok {
-		w.addDimToSSLCipherSuiteChart(w.line.sslCipherSuite)
-	}
-	c.Inc()
-}
-
-func (w *WebLog) collectURLPatternStats(name string) {
-	v, ok := w.mx.URLPatternStats[name]
-	if !ok {
-		return
-	}
-	if w.line.hasRespCode() {
-		status := strconv.Itoa(w.line.respCode)
-		c, ok := v.RespCode.GetP(status)
-		if !ok {
-			w.addDimToURLPatternRespCodesChart(name, status)
+// mergeMetrics adds the shard metrics to the job metrics, dimensions are added for not yet seen values.
+func (w *WebLog) mergeMetrics(mx *metricsData) {
+	w.mx.Requests.Merge(mx.Requests)
+	w.mx.ReqUnmatched.Merge(mx.ReqUnmatched)
+
+	mergeCounterVec(w.mx.RespCode, mx.RespCode, w.addDimToRespCodesChart)
+	w.mx.Resp1xx.Merge(mx.Resp1xx)
+	w.mx.Resp2xx.Merge(mx.Resp2xx)
+	w.mx.Resp3xx.Merge(mx.Resp3xx)
+	w.mx.Resp4xx.Merge(mx.Resp4xx)
+	w.mx.Resp5xx.Merge(mx.Resp5xx)
+
+	w.mx.ReqSuccess.Merge(mx.ReqSuccess)
+	w.mx.ReqRedirect.Merge(mx.ReqRedirect)
+	w.mx.ReqBad.Merge(mx.ReqBad)
+	w.mx.ReqError.Merge(mx.ReqError)
+
+	w.mx.UniqueIPv4.Merge(mx.UniqueIPv4)
+	w.mx.UniqueIPv6.Merge(mx.UniqueIPv6)
+	w.mx.BytesSent.Merge(mx.BytesSent)
+	w.mx.BytesReceived.Merge(mx.BytesReceived)
+	w.mx.ReqProcTime.Merge(mx.ReqProcTime)
+	w.mx.ReqProcTimeHist.Merge(mx.ReqProcTimeHist)
+	w.mx.UpsRespTime.Merge(mx.UpsRespTime)
+	w.mx.UpsRespTimeHist.Merge(mx.UpsRespTimeHist)
+
+	mergeCounterVec(w.mx.ReqVhost, mx.ReqVhost, w.addDimToVhostChart)
+	mergeCounterVec(w.mx.ReqPort, mx.ReqPort, w.addDimToPortChart)
+	mergeCounterVec(w.mx.ReqMethod, mx.ReqMethod, w.addDimToReqMethodChart)
+	mergeCounterVec(w.mx.ReqURLPattern, mx.ReqURLPattern, nil)
+	mergeCounterVec(w.mx.ReqVersion, mx.ReqVersion, w.addDimToReqVersionChart)
+	mergeCounterVec(w.mx.ReqSSLProto, mx.ReqSSLProto, w.addDimToSSLProtoChart)
+	mergeCounterVec(w.mx.ReqSSLCipherSuite, mx.ReqSSLCipherSuite, w.addDimToSSLCipherSuiteChart)
+	w.mx.ReqHTTPScheme.Merge(mx.ReqHTTPScheme)
+	w.mx.ReqHTTPSScheme.Merge(mx.ReqHTTPSScheme)
+	w.mx.ReqIPv4.Merge(mx.ReqIPv4)
+	w.mx.ReqIPv6.Merge(mx.ReqIPv6)
+
+	for name, vec := range mx.ReqCustomField {
+		if v, ok := w.mx.ReqCustomField[name]; ok {
+			mergeCounterVec(v, vec, nil)
 		}
-		c.Inc()
 	}
-
-	if w.line.hasReqMethod() {
-		c, ok := v.ReqMethod.GetP(w.line.reqMethod)
+	for name, stats := range mx.URLPatternStats {
+		v, ok := w.mx.URLPatternStats[name]
 		if !ok {
-			w.addDimToURLPatternReqMethodsChart(name, w.line.reqMethod)
+			continue
+		}
+		mergeCounterVec(v.RespCode, stats.RespCode, func(code string) { w.addDimToURLPatternRespCodesChart(name, code) })
+		mergeCounterVec(v.ReqMethod, stats.ReqMethod, func(method string) { w.addDimToURLPatternReqMethodsChart(name, method) })
+		v.BytesSent.Merge(stats.BytesSent)
+		v.BytesReceived.Merge(stats.BytesReceived)
+		v.ReqProcTime.Merge(stats.ReqProcTime)
+	}
+	for name, ctf := range mx.ReqCustomTimeField {
+		if v, ok := w.mx.ReqCustomTimeField[name]; ok {
+			v.Time.Merge(ctf.Time)
+			v.TimeHist.Merge(ctf.TimeHist)
 		}
-		c.Inc()
-	}
-
-	if w.line.hasReqSize() {
-		v.BytesReceived.Add(float64(w.line.reqSize))
-	}
-
-	if w.line.hasRespSize() {
-		v.BytesSent.Add(float64(w.line.respSize))
-	}
-
-	if w.line.hasReqProcTime() {
-		v.ReqProcTime.Observe(w.line.reqProcTime)
 	}
 }
 
-func (w *WebLog) collectCustomFields() {
-	if !w.line.hasCustomFields() {
-		return
-	}
-
-	for _, cv := range w.line.custom.values {
-		if patterns, ok := w.customFields[cv.name]; ok {
-			for _, pattern := range patterns {
-				if !pattern.MatchString(cv.value) {
-					continue
-				}
-				v, ok := w.mx.ReqCustomField[cv.name]
-				if !ok {
-					break
-				}
-				c, _ := v.GetP(pattern.name)
-				c.Inc()
-				break
-			}
-		} else if histogram, ok := w.customTimeFields[cv.name]; ok {
-			v, ok := w.mx.ReqCustomTimeField[cv.name]
-			if !ok {
-				continue
-			}
-			ctf, err := strconv.ParseFloat(cv.value, 64)
-			if err != nil || !isTimeValid(ctf) {
-				continue
-			}
-			v.Time.Observe(ctf)
-			if histogram != nil {
-				v.TimeHist.Observe(ctf * timeMultiplier(cv.value))
-			}
-
+func mergeCounterVec(dst, src metrics.CounterVec, addDim func(name string)) {
+	for name, c := range src {
+		v, ok := dst.GetP(name)
+		if !ok && addDim != nil {
+			addDim(name)
 		}
+		v.Merge(*c)
 	}
 }
 
@@ -89,30 +89,45 @@ func (w *WebLog) createCustomTimeFields() error {
 }
 
 func (w *WebLog) createLogLine() {
-	w.line = newEmptyLogLine()
+	w.line = w.newLogLine()
+}
+
+func (w *WebLog) newLogLine() *logLine {
+	line := newEmptyLogLine()
 	for v := range w.customFields {
-		w.line.custom.fields[v] = struct{}{}
+		line.custom.fields[v] = struct{}{}
 	}
 	for v := range w.customTimeFields {
-		w.line.custom.fields[v] = struct{}{}
+		line.custom.fields[v] = struct{}{}
 	}
+	return line
 }
 
 func (w *WebLog) createLogReader() error {
 	w.Cleanup()
 	w.Debug("starting log reader creating")
-	reader, err := logs.Open(w.Path, w.ExcludePath, w.Logger)
+	reader, err := w.openLogSource()
 	if err != nil {
 		return fmt.Errorf("creating log reader: %v", err)
 	}
-	w.Debugf("created log reader, current file '%s'", reader.CurrentFilename())
+	w.Debugf("created log reader, %s", reader.Info())
 	w.file = reader
+	w.batch = logs.NewBatchReader(reader, 0)
 	return nil
 }
 
+func (w *WebLog) openLogSource() (logs.Source, error) {
+	switch w.Source.Type {
+	case "", logs.SourceFile:
+		return logs.Open(w.Path, w.ExcludePath, w.Logger)
+	default:
+		return logs.OpenSource(w.Source, w.Logger)
+	}
+}
+
 func (w *WebLog) createParser() error {
 	w.Debug("starting parser creating")
-	lastLine, err := logs.ReadLastLine(w.file.CurrentFilename(), 0)
+	lastLine, err := w.file.LastLine()
 	if err != nil {
 		return fmt.Errorf("read last line: %v", err)
 	}
@@ -135,3 +150,20 @@ func (w *WebLog) createParser() error {
 	}
 	return nil
 }
+
+func (w *WebLog) createShards() error {
+	num := w.ParserWorkers
+	if num < 1 {
+		num = 1
+	}
+	w.shards = w.shards[:0]
+	for i := 0; i < num; i++ {
+		shard, err := w.newLogShard()
+		if err != nil {
+			return fmt.Errorf("create parser: %v", err)
+		}
+		w.shards = append(w.shards, shard)
+	}
+	w.Debugf("created %d parser shard(s)", len(w.shards))
+	return nil
+}
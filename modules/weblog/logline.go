@@ -150,6 +150,10 @@ func (l *logLine) Assign(field string, value string) (err error) {
 	return err
 }
 
+// ZeroCopy marks the line as logs.ZeroCopyLogLine: assigned values share memory with the parsed row.
+// The line is reset before parsing the next row, values that outlive it (e.g. metric names) are copied.
+func (l *logLine) ZeroCopy() {}
+
 const hyphen = "-"
 
 func (l *logLine) assignVhost(vhost string) error {
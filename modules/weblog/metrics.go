@@ -22,6 +22,13 @@ func (s weblogSummary) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+func (s weblogSummary) Merge(other metrics.Summary) {
+	if v, ok := other.(*weblogSummary); ok {
+		other = v.Summary
+	}
+	s.Summary.Merge(other)
+}
+
 type (
 	metricsData struct {
 		Requests     metrics.Counter `stm:"requests"`
@@ -113,6 +120,46 @@ func (m *metricsData) reset() {
 	}
 }
 
+// clear zeroes all the metrics, it is used for the shard metrics after they are merged.
+// Vector keys are kept to avoid allocations.
+func (m *metricsData) clear() {
+	m.reset()
+	for _, c := range []*metrics.Counter{
+		&m.Requests, &m.ReqUnmatched,
+		&m.Resp1xx, &m.Resp2xx, &m.Resp3xx, &m.Resp4xx, &m.Resp5xx,
+		&m.ReqSuccess, &m.ReqRedirect, &m.ReqBad, &m.ReqError,
+		&m.BytesSent, &m.BytesReceived,
+		&m.ReqHTTPScheme, &m.ReqHTTPSScheme, &m.ReqIPv4, &m.ReqIPv6,
+	} {
+		*c = metrics.Counter{}
+	}
+	for _, vec := range []metrics.CounterVec{
+		m.RespCode, m.ReqVhost, m.ReqPort, m.ReqMethod, m.ReqURLPattern, m.ReqVersion, m.ReqSSLProto, m.ReqSSLCipherSuite,
+	} {
+		clearCounterVec(vec)
+	}
+	m.ReqProcTimeHist.Reset()
+	m.UpsRespTimeHist.Reset()
+	for _, vec := range m.ReqCustomField {
+		clearCounterVec(vec)
+	}
+	for _, v := range m.URLPatternStats {
+		clearCounterVec(v.RespCode)
+		clearCounterVec(v.ReqMethod)
+		v.BytesSent = metrics.Counter{}
+		v.BytesReceived = metrics.Counter{}
+	}
+	for _, v := range m.ReqCustomTimeField {
+		v.TimeHist.Reset()
+	}
+}
+
+func clearCounterVec(vec metrics.CounterVec) {
+	for _, c := range vec {
+		*c = metrics.Counter{}
+	}
+}
+
 func newCounterVecFromPatterns(patterns []userPattern) metrics.CounterVec {
 	c := metrics.NewCounterVec()
 	for _, p := range patterns {
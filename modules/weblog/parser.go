@@ -66,15 +66,16 @@ const (
 )
 
 var (
-	reLTSV = regexp.MustCompile(`^[a-zA-Z0-9]+:[^\t]*(\t[a-zA-Z0-9]+:[^\t]*)*$`)
-	reJSON = regexp.MustCompile(`^[[:space:]]*{.*}[[:space:]]*$`)
+	reLTSV   = regexp.MustCompile(`^[a-zA-Z0-9]+:[^\t]*(\t[a-zA-Z0-9]+:[^\t]*)*$`)
+	reJSON   = regexp.MustCompile(`^[[:space:]]*{.*}[[:space:]]*$`)
+	reLogfmt = regexp.MustCompile(`^[[:space:]]*[a-zA-Z_][a-zA-Z0-9_.]*=("([^"\\]|\\.)*"|[^"[:space:]]*)([[:space:]]+[a-zA-Z_][a-zA-Z0-9_.]*=("([^"\\]|\\.)*"|[^"[:space:]]*))*[[:space:]]*$`)
 )
 
 func (w *WebLog) newParser(record []byte) (logs.Parser, error) {
 	if w.Parser.LogType == typeAuto {
 		w.Debugf("log_type is %s, will try format auto-detection", typeAuto)
 		if len(record) == 0 {
-			return nil, fmt.Errorf("empty line, can't auto-detect format (%s)", w.file.CurrentFilename())
+			return nil, fmt.Errorf("empty line, can't auto-detect format (%s)", w.file.Info())
 		}
 		return w.guessParser(record)
 	}
@@ -90,19 +91,40 @@ func (w *WebLog) newParser(record []byte) (logs.Parser, error) {
 		w.Debugf("config: %+v", w.Parser.RegExp)
 	case logs.TypeJSON:
 		w.Debugf("config: %+v", w.Parser.JSON)
+	case logs.TypeLogfmt:
+		w.Debugf("config: %+v", w.Parser.Logfmt)
+	case logs.TypeGrok:
+		w.Debugf("config: %+v", w.Parser.Grok)
+	case logs.TypeLogFormat:
+		w.Debugf("config: %+v", w.Parser.LogFormat.Format)
 	}
-	return logs.NewParser(w.Parser, w.file)
+	return w.newParserOfType(w.Parser.LogType, w.Parser)
+}
+
+// newParserOfType creates a parser and remembers its config, the config is used to create parsers for the collection shards.
+func (w *WebLog) newParserOfType(logType string, config logs.ParserConfig) (logs.Parser, error) {
+	config.LogType = logType
+	parser, err := logs.NewParser(config, w.file)
+	if err != nil {
+		return nil, err
+	}
+	w.parserConfig = config
+	return parser, nil
 }
 
 func (w *WebLog) guessParser(record []byte) (logs.Parser, error) {
 	w.Debug("starting log type auto-detection")
 	if reLTSV.Match(record) {
 		w.Debug("log type is LTSV")
-		return logs.NewLTSVParser(w.Parser.LTSV, w.file)
+		return w.newParserOfType(logs.TypeLTSV, w.Parser)
 	}
 	if reJSON.Match(record) {
 		w.Debug("log type is JSON")
-		return logs.NewJSONParser(w.Parser.JSON, w.file)
+		return w.newParserOfType(logs.TypeJSON, w.Parser)
+	}
+	if reLogfmt.Match(record) {
+		w.Debug("log type is logfmt")
+		return w.newParserOfType(logs.TypeLogfmt, w.Parser)
 	}
 	w.Debug("log type is CSV")
 	return w.guessCSVParser(record)
@@ -132,6 +154,9 @@ func (w *WebLog) guessCSVParser(record []byte) (logs.Parser, error) {
 			w.Debug("verify: ", err)
 			continue
 		}
+		w.parserConfig = w.Parser
+		w.parserConfig.LogType = logs.TypeCSV
+		w.parserConfig.CSV = cfg
 		return parser, nil
 	}
 	return nil, errors.New("cannot auto-detect log format, use custom log format")
@@ -149,6 +174,24 @@ func checkCSVFormatField(field string) (newName string, offset int, valid bool)
 	return field[1:], 0, true
 }
 
+func checkLogFormatField(field string) (newName string, valid bool) {
+	if isTimeField(field) || !isFieldValid(field) {
+		return "", false
+	}
+	return field[1:], true
+}
+
+// defaultGrokMapping maps the built-in apache grok patterns (COMMONAPACHELOG, COMBINEDAPACHELOG) field names to known fields.
+func defaultGrokMapping() map[string]string {
+	return map[string]string{
+		"clientip": "remote_addr",
+		"verb":     "request_method",
+		"request":  "request_uri",
+		"response": "status",
+		"bytes":    "body_bytes_sent",
+	}
+}
+
 func isTimeField(field string) bool {
 	return field == "[$time_local]" || field == "$time_local" || field == "%t"
 }
@@ -53,6 +53,14 @@ func TestWebLog_guessParser(t *testing.T) {
 				` {"host": "example.com","time": "2020-08-04T20:23:27+03:00", "upstream_response_time": "0.776", "remote_addr": "1.2.3.4"}	`,
 			},
 		},
+		{
+			name:           "guessed logfmt",
+			wantParserType: logs.TypeLogfmt,
+			inputs: []string{
+				`host=example.com remote_addr=1.2.3.4 request="GET / HTTP/1.0" status=200 request_time=0.123`,
+				` remote_addr=1.2.3.4 request="GET /\"quoted\" HTTP/1.0" status=200 ssl_protocol= `,
+			},
+		},
 		{
 			name:    "unknown",
 			wantErr: true,
@@ -85,6 +93,8 @@ func TestWebLog_guessParser(t *testing.T) {
 						require.IsType(t, (*logs.CSVParser)(nil), p)
 					case logs.TypeJSON:
 						require.IsType(t, (*logs.JSONParser)(nil), p)
+					case logs.TypeLogfmt:
+						require.IsType(t, (*logs.LogfmtParser)(nil), p)
 					}
 				}
 			})
@@ -200,6 +210,65 @@ func TestWebLog_guessCSVParser(t *testing.T) {
 	}
 }
 
+func TestWebLog_newParser_LogFormatAndGrok(t *testing.T) {
+	tests := map[string]struct {
+		parser logs.ParserConfig
+		input  string
+	}{
+		"nginx log_format": {
+			parser: logs.ParserConfig{
+				LogType: logs.TypeLogFormat,
+				LogFormat: logs.LogFormatConfig{
+					Format: `'$host:$server_port $remote_addr - $remote_user [$time_local] '
+                             '"$request" $status $body_bytes_sent $request_length $request_time '
+                             '"$http_referer" "$http_user_agent"'`,
+				},
+			},
+			input: `example.com:80 88.191.254.20 - - [22/Mar/2009:09:30:31 +0100] "GET / HTTP/1.0" 200 8674 1000 0.123 "-" "Mozilla/5.0 (X11)"`,
+		},
+		"apache LogFormat": {
+			parser: logs.ParserConfig{
+				LogType: logs.TypeLogFormat,
+				LogFormat: logs.LogFormatConfig{
+					Format: `%v:%p %h %l %u %t \"%r\" %>s %O %I %D \"%{Referer}i\" \"%{User-Agent}i\"`,
+				},
+			},
+			input: `example.com:80 88.191.254.20 - - [22/Mar/2009:09:30:31 +0100] "GET / HTTP/1.0" 200 8674 1000 123000 "-" "Mozilla/5.0 (X11)"`,
+		},
+		"grok combined apache log": {
+			parser: logs.ParserConfig{
+				LogType: logs.TypeGrok,
+				Grok:    logs.GrokConfig{Pattern: "%{COMBINEDAPACHELOG}"},
+			},
+			input: `88.191.254.20 - - [22/Mar/2009:09:30:31 +0100] "GET / HTTP/1.0" 200 8674 "-" "Mozilla/5.0 (X11)"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			weblog := New()
+			test.parser.LogFormat.CheckField = weblog.Parser.LogFormat.CheckField
+			if test.parser.Grok.Mapping == nil {
+				test.parser.Grok.Mapping = weblog.Parser.Grok.Mapping
+			}
+			weblog.Parser = test.parser
+
+			p, err := weblog.newParser([]byte(test.input))
+			require.NoError(t, err)
+
+			line := newEmptyLogLine()
+			require.NoError(t, p.Parse([]byte(test.input), line))
+			require.NoError(t, line.verify())
+
+			assert.Equal(t, "88.191.254.20", line.reqClient)
+			assert.Equal(t, "GET", line.reqMethod)
+			assert.Equal(t, "/", line.reqURL)
+			assert.Equal(t, 200, line.respCode)
+			assert.Equal(t, 8674, line.respSize)
+		})
+	}
+}
+
 func prepareWebLog() *WebLog {
 	cfg := logs.ParserConfig{
 		LogType: typeAuto,
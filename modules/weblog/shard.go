@@ -0,0 +1,333 @@
+package weblog
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/logs"
+	"github.com/netdata/go.d.plugin/pkg/metrics"
+)
+
+// logShard parses and collects a part of a batch of log lines.
+// Every shard has its own parser, log line and metrics, so shards run concurrently without locking.
+// Shard metrics are merged into the job metrics on the collection goroutine.
+type logShard struct {
+	parser logs.Parser
+	line   *logLine
+	mx     *metricsData
+
+	urlPatterns      []*pattern
+	customFields     map[string][]*pattern
+	customTimeFields map[string][]float64
+
+	// parseErr is the first parse error since the last merge
+	parseErr error
+}
+
+func (w *WebLog) newLogShard() (*logShard, error) {
+	parser, err := logs.NewParser(w.parserConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &logShard{
+		parser:           parser,
+		line:             w.newLogLine(),
+		mx:               newMetricsData(w.Config),
+		urlPatterns:      w.urlPatterns,
+		customFields:     w.customFields,
+		customTimeFields: w.customTimeFields,
+	}, nil
+}
+
+func (s *logShard) collectRows(rows [][]byte) {
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		s.line.reset()
+		if err := s.parser.Parse(row, s.line); err != nil {
+			if s.parseErr == nil {
+				s.parseErr = err
+			}
+			s.collectUnmatched()
+			continue
+		}
+		if s.line.empty() {
+			s.collectUnmatched()
+		} else {
+			s.collectLogLine()
+		}
+	}
+}
+
+func (s *logShard) collectLogLine() {
+	s.mx.Requests.Inc()
+	s.collectVhost()
+	s.collectPort()
+	s.collectReqScheme()
+	s.collectReqClient()
+	s.collectReqMethod()
+	s.collectReqURL()
+	s.collectReqProto()
+	s.collectRespCode()
+	s.collectReqSize()
+	s.collectRespSize()
+	s.collectReqProcTime()
+	s.collectUpsRespTime()
+	s.collectSSLProto()
+	s.collectSSLCipherSuite()
+	s.collectCustomFields()
+}
+
+func (s *logShard) collectUnmatched() {
+	s.mx.Requests.Inc()
+	s.mx.ReqUnmatched.Inc()
+}
+
+func (s *logShard) collectVhost() {
+	if !s.line.hasVhost() {
+		return
+	}
+	counterOf(s.mx.ReqVhost, s.line.vhost).Inc()
+}
+
+func (s *logShard) collectPort() {
+	if !s.line.hasPort() {
+		return
+	}
+	counterOf(s.mx.ReqPort, s.line.port).Inc()
+}
+
+func (s *logShard) collectReqClient() {
+	if !s.line.hasReqClient() {
+		return
+	}
+	if strings.ContainsRune(s.line.reqClient, ':') {
+		s.mx.ReqIPv6.Inc()
+		s.mx.UniqueIPv6.Insert(s.line.reqClient)
+		return
+	}
+	// NOTE: count hostname as IPv4 address
+	s.mx.ReqIPv4.Inc()
+	s.mx.UniqueIPv4.Insert(s.line.reqClient)
+}
+
+func (s *logShard) collectReqScheme() {
+	if !s.line.hasReqScheme() {
+		return
+	}
+	if s.line.reqScheme == "https" {
+		s.mx.ReqHTTPSScheme.Inc()
+	} else {
+		s.mx.ReqHTTPScheme.Inc()
+	}
+}
+
+func (s *logShard) collectReqMethod() {
+	if !s.line.hasReqMethod() {
+		return
+	}
+	counterOf(s.mx.ReqMethod, s.line.reqMethod).Inc()
+}
+
+func (s *logShard) collectReqURL() {
+	if !s.line.hasReqURL() {
+		return
+	}
+	for _, p := range s.urlPatterns {
+		if !p.MatchString(s.line.reqURL) {
+			continue
+		}
+		c, _ := s.mx.ReqURLPattern.GetP(p.name)
+		c.Inc()
+
+		s.collectURLPatternStats(p.name)
+		return
+	}
+}
+
+func (s *logShard) collectReqProto() {
+	if !s.line.hasReqProto() {
+		return
+	}
+	counterOf(s.mx.ReqVersion, s.line.reqProto).Inc()
+}
+
+func (s *logShard) collectRespCode() {
+	if !s.line.hasRespCode() {
+		return
+	}
+
+	code := s.line.respCode
+	switch {
+	case code >= 100 && code < 300, code == 304, code == 401:
+		s.mx.ReqSuccess.Inc()
+	case code >= 300 && code < 400:
+		s.mx.ReqRedirect.Inc()
+	case code >= 400 && code < 500:
+		s.mx.ReqBad.Inc()
+	case code >= 500 && code < 600:
+		s.mx.ReqError.Inc()
+	}
+
+	switch code / 100 {
+	case 1:
+		s.mx.Resp1xx.Inc()
+	case 2:
+		s.mx.Resp2xx.Inc()
+	case 3:
+		s.mx.Resp3xx.Inc()
+	case 4:
+		s.mx.Resp4xx.Inc()
+	case 5:
+		s.mx.Resp5xx.Inc()
+	}
+
+	c, _ := s.mx.RespCode.GetP(respCodeString(code))
+	c.Inc()
+}
+
+func (s *logShard) collectReqSize() {
+	if !s.line.hasReqSize() {
+		return
+	}
+	s.mx.BytesReceived.Add(float64(s.line.reqSize))
+}
+
+func (s *logShard) collectRespSize() {
+	if !s.line.hasRespSize() {
+		return
+	}
+	s.mx.BytesSent.Add(float64(s.line.respSize))
+}
+
+func (s *logShard) collectReqProcTime() {
+	if !s.line.hasReqProcTime() {
+		return
+	}
+	s.mx.ReqProcTime.Observe(s.line.reqProcTime)
+	if s.mx.ReqProcTimeHist == nil {
+		return
+	}
+	s.mx.ReqProcTimeHist.Observe(s.line.reqProcTime)
+}
+
+func (s *logShard) collectUpsRespTime() {
+	if !s.line.hasUpsRespTime() {
+		return
+	}
+	s.mx.UpsRespTime.Observe(s.line.upsRespTime)
+	if s.mx.UpsRespTimeHist == nil {
+		return
+	}
+	s.mx.UpsRespTimeHist.Observe(s.line.upsRespTime)
+}
+
+func (s *logShard) collectSSLProto() {
+	if !s.line.hasSSLProto() {
+		return
+	}
+	counterOf(s.mx.ReqSSLProto, s.line.sslProto).Inc()
+}
+
+func (s *logShard) collectSSLCipherSuite() {
+	if !s.line.hasSSLCipherSuite() {
+		return
+	}
+	counterOf(s.mx.ReqSSLCipherSuite, s.line.sslCipherSuite).Inc()
+}
+
+func (s *logShard) collectURLPatternStats(name string) {
+	v, ok := s.mx.URLPatternStats[name]
+	if !ok {
+		return
+	}
+	if s.line.hasRespCode() {
+		c, _ := v.RespCode.GetP(respCodeString(s.line.respCode))
+		c.Inc()
+	}
+
+	if s.line.hasReqMethod() {
+		counterOf(v.ReqMethod, s.line.reqMethod).Inc()
+	}
+
+	if s.line.hasReqSize() {
+		v.BytesReceived.Add(float64(s.line.reqSize))
+	}
+
+	if s.line.hasRespSize() {
+		v.BytesSent.Add(float64(s.line.respSize))
+	}
+
+	if s.line.hasReqProcTime() {
+		v.ReqProcTime.Observe(s.line.reqProcTime)
+	}
+}
+
+func (s *logShard) collectCustomFields() {
+	if !s.line.hasCustomFields() {
+		return
+	}
+
+	for _, cv := range s.line.custom.values {
+		if patterns, ok := s.customFields[cv.name]; ok {
+			for _, pattern := range patterns {
+				if !pattern.MatchString(cv.value) {
+					continue
+				}
+				v, ok := s.mx.ReqCustomField[cv.name]
+				if !ok {
+					break
+				}
+				c, _ := v.GetP(pattern.name)
+				c.Inc()
+				break
+			}
+		} else if histogram, ok := s.customTimeFields[cv.name]; ok {
+			v, ok := s.mx.ReqCustomTimeField[cv.name]
+			if !ok {
+				continue
+			}
+			ctf, err := strconv.ParseFloat(cv.value, 64)
+			if err != nil || !isTimeValid(ctf) {
+				continue
+			}
+			v.Time.Observe(ctf)
+			if histogram != nil {
+				v.TimeHist.Observe(ctf * timeMultiplier(cv.value))
+			}
+		}
+	}
+}
+
+// counterOf returns the named counter of the vector.
+// Log line values share memory with the read buffer, so the name is copied when the counter is created.
+func counterOf(vec metrics.CounterVec, name string) *metrics.Counter {
+	if c, ok := vec[name]; ok {
+		return c
+	}
+	return vec.Get(cloneString(name))
+}
+
+func cloneString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	sb.WriteString(s)
+	return sb.String()
+}
+
+var respCodeStrings = func() []string {
+	codes := make([]string, 600)
+	for i := range codes {
+		codes[i] = strconv.Itoa(i)
+	}
+	return codes
+}()
+
+// respCodeString returns the code string without allocation for valid response codes.
+func respCodeString(code int) string {
+	if code >= 0 && code < len(respCodeStrings) {
+		return respCodeStrings[code]
+	}
+	return strconv.Itoa(code)
+}
@@ -29,12 +29,21 @@ func New() *WebLog {
 		},
 		RegExp: logs.RegExpConfig{},
 		JSON:   logs.JSONConfig{},
+		Logfmt: logs.LogfmtConfig{},
+		Grok: logs.GrokConfig{
+			Mapping: defaultGrokMapping(),
+		},
+		LogFormat: logs.LogFormatConfig{
+			CheckField: checkLogFormatField,
+		},
 	}
 	return &WebLog{
 		Config: Config{
 			ExcludePath:    "*.gz",
 			GroupRespCodes: true,
+			ParserWorkers:  1,
 			Parser:         cfg,
+			Source:         logs.SourceConfig{Type: logs.SourceFile},
 		},
 	}
 }
@@ -55,6 +64,7 @@ type (
 
 	Config struct {
 		Parser           logs.ParserConfig `yaml:",inline"`
+		Source           logs.SourceConfig `yaml:",inline"`
 		Path             string            `yaml:"path"`
 		ExcludePath      string            `yaml:"exclude_path"`
 		URLPatterns      []userPattern     `yaml:"url_patterns"`
@@ -62,15 +72,19 @@ type (
 		CustomTimeFields []customTimeField `yaml:"custom_time_fields"`
 		Histogram        []float64         `yaml:"histogram"`
 		GroupRespCodes   bool              `yaml:"group_response_codes"`
+		ParserWorkers    int               `yaml:"parser_workers"`
 	}
 
 	WebLog struct {
 		module.Base
 		Config `yaml:",inline"`
 
-		file             *logs.Reader
+		file             logs.Source
+		batch            *logs.BatchReader
 		parser           logs.Parser
+		parserConfig     logs.ParserConfig
 		line             *logLine
+		shards           []*logShard
 		urlPatterns      []*pattern
 		customFields     map[string][]*pattern
 		customTimeFields map[string][]float64
@@ -113,6 +127,11 @@ func (w *WebLog) Check() bool {
 		return false
 	}
 
+	if err := w.createShards(); err != nil {
+		w.Warning("check failed: ", err)
+		return false
+	}
+
 	if err := w.createCharts(w.line); err != nil {
 		w.Warning("check failed: ", err)
 		return false
@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/logs"
 	"github.com/netdata/go.d.plugin/pkg/metrics"
@@ -93,6 +98,25 @@ func TestWebLog_Check_ErrorOnCreatingParserEmptyLine(t *testing.T) {
 	assert.False(t, weblog.Check())
 }
 
+func TestWebLog_Check_SyslogSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netdata-weblog-test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	weblog := New()
+	defer weblog.Cleanup()
+	weblog.Source.Type = logs.SourceSyslog
+	weblog.Source.Syslog.Listen = "unixgram://" + filepath.Join(dir, "syslog.sock")
+	require.True(t, weblog.Init())
+
+	done := make(chan struct{})
+	defer close(done)
+	go sendSyslogMessages(filepath.Join(dir, "syslog.sock"), testCommonLog, done)
+
+	require.True(t, weblog.Check())
+	assert.Eventually(t, func() bool { return weblog.Collect()["requests"] > 0 }, time.Second*5, time.Millisecond*50)
+}
+
 func TestWebLog_Charts(t *testing.T) {
 	weblog := New()
 	defer weblog.Cleanup()
@@ -304,6 +328,33 @@ func TestWebLog_Collect(t *testing.T) {
 	testCharts(t, weblog, mx)
 }
 
+func TestWebLog_Collect_ParserWorkers(t *testing.T) {
+	data := bytes.Repeat(testFullLog, 50)
+
+	single := prepareWebLogCollectFull(t)
+	single.batch = logs.NewBatchReader(bytes.NewReader(data), 0)
+	expected := single.Collect()
+
+	for _, workers := range []int{2, 4, 8} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			weblog := prepareWebLogCollectFull(t)
+			weblog.ParserWorkers = workers
+			require.NoError(t, weblog.createShards())
+			weblog.batch = logs.NewBatchReader(bytes.NewReader(data), 0)
+
+			mx := weblog.Collect()
+
+			require.Len(t, mx, len(expected))
+			for k, v := range expected {
+				// float sums are added in a different order
+				assert.InDeltaf(t, v, mx[k], 1, "metric '%s'", k)
+			}
+			assert.Equal(t, collectedDimIDs(single), collectedDimIDs(weblog))
+			testCharts(t, weblog, mx)
+		})
+	}
+}
+
 func TestWebLog_Collect_CommonLogFormat(t *testing.T) {
 	weblog := prepareWebLogCollectCommon(t)
 
@@ -934,6 +985,45 @@ var (
 	emptyHistogram = metrics.NewHistogram(metrics.DefBuckets)
 )
 
+func sendSyslogMessages(path string, data []byte, done chan struct{}) {
+	var conn net.Conn
+	for conn == nil {
+		select {
+		case <-done:
+			return
+		case <-time.After(time.Millisecond * 10):
+			conn, _ = net.Dial("unixgram", path)
+		}
+	}
+	defer func() { _ = conn.Close() }()
+
+	var lines [][]byte
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("Unmatched")) {
+			lines = append(lines, line)
+		}
+	}
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		case <-time.After(time.Millisecond * 10):
+			_, _ = fmt.Fprintf(conn, "<190>Oct 19 10:00:00 web01 nginx: %s", lines[i%len(lines)])
+		}
+	}
+}
+
+func collectedDimIDs(w *WebLog) []string {
+	var ids []string
+	for _, chart := range *w.Charts() {
+		for _, dim := range chart.Dims {
+			ids = append(ids, dim.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 func isEmptySummary(s metrics.Summary) bool     { return reflect.DeepEqual(s, emptySummary) }
 func isEmptyHistogram(h metrics.Histogram) bool { return reflect.DeepEqual(h, emptyHistogram) }
 
@@ -946,7 +1036,7 @@ func isEmptyCounterVec(cv metrics.CounterVec) bool {
 	return true
 }
 
-func prepareWebLogCollectFull(t *testing.T) *WebLog {
+func prepareWebLogCollectFull(t testing.TB) *WebLog {
 	t.Helper()
 	format := strings.Join([]string{
 		"$host:$server_port",
@@ -1018,9 +1108,7 @@ func prepareWebLogCollectFull(t *testing.T) *WebLog {
 	require.True(t, weblog.Check())
 	defer weblog.Cleanup()
 
-	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testFullLog))
-	require.NoError(t, err)
-	weblog.parser = p
+	weblog.batch = logs.NewBatchReader(bytes.NewReader(testFullLog), 0)
 	return weblog
 }
 
@@ -1061,9 +1149,7 @@ func prepareWebLogCollectCommon(t *testing.T) *WebLog {
 	require.True(t, weblog.Check())
 	defer weblog.Cleanup()
 
-	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testCommonLog))
-	require.NoError(t, err)
-	weblog.parser = p
+	weblog.batch = logs.NewBatchReader(bytes.NewReader(testCommonLog), 0)
 	return weblog
 }
 
@@ -1113,9 +1199,7 @@ func prepareWebLogCollectCustom(t *testing.T) *WebLog {
 	require.True(t, weblog.Check())
 	defer weblog.Cleanup()
 
-	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testCustomLog))
-	require.NoError(t, err)
-	weblog.parser = p
+	weblog.batch = logs.NewBatchReader(bytes.NewReader(testCustomLog), 0)
 	return weblog
 }
 
@@ -1159,9 +1243,7 @@ func prepareWebLogCollectCustomTimeFields(t *testing.T) *WebLog {
 	require.True(t, weblog.Check())
 	defer weblog.Cleanup()
 
-	p, err := logs.NewCSVParser(weblog.Parser.CSV, bytes.NewReader(testCustomTimeFieldLog))
-	require.NoError(t, err)
-	weblog.parser = p
+	weblog.batch = logs.NewBatchReader(bytes.NewReader(testCustomTimeFieldLog), 0)
 	return weblog
 }
 
@@ -1222,3 +1304,35 @@ func prepareWebLogCollectCustomTimeFields(t *testing.T) *WebLog {
 //func randFromString(s []string) string { return s[r.Intn(len(s))] }
 //func randFromInt(s []int) int          { return s[r.Intn(len(s))] }
 //func randInt(min, max int) int         { return r.Intn(max-min) + min }
+
+func BenchmarkWebLog_Collect(b *testing.B) {
+	data := bytes.Repeat(testFullLog, 100)
+	var rows int
+	for _, c := range data {
+		if c == '\n' {
+			rows++
+		}
+	}
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			weblog := prepareWebLogCollectFull(b)
+			weblog.ParserWorkers = workers
+			require.NoError(b, weblog.createShards())
+			r := bytes.NewReader(data)
+			weblog.batch = logs.NewBatchReader(r, 0)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				r.Reset(data)
+				if _, err := weblog.collectLogLines(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(rows*b.N)/time.Since(start).Seconds(), "lines/s")
+		})
+	}
+}
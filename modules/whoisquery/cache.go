@@ -0,0 +1,85 @@
+package whoisquery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The whois data is cached in the 'cache_dir' (one file per domain), so it survives restarts
+// and the domains are not queried again until 'refresh_every' passes.
+
+type cacheEntry struct {
+	Domain             string      `json:"domain"`
+	QueriedAt          time.Time   `json:"queried_at"`
+	Info               *domainInfo `json:"info"`
+	RegistrarChanged   bool        `json:"registrar_changed,omitempty"`
+	NameServersChanged bool        `json:"name_servers_changed,omitempty"`
+}
+
+func (wq *WhoisQuery) cacheFile(d *domain) string {
+	if wq.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(wq.CacheDir, d.name+".json")
+}
+
+func (wq *WhoisQuery) loadCache(d *domain) error {
+	path := wq.cacheFile(d)
+	if path == "" {
+		return nil
+	}
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(bs, &entry); err != nil {
+		return err
+	}
+	if entry.Domain != d.name || entry.Info == nil {
+		return nil
+	}
+
+	d.info = entry.Info
+	d.queriedAt = entry.QueriedAt
+	d.nextQuery = entry.QueriedAt.Add(wq.RefreshEvery.Duration)
+	d.registrarChanged = entry.RegistrarChanged
+	d.nameServersChanged = entry.NameServersChanged
+	return nil
+}
+
+func (wq *WhoisQuery) saveCache(d *domain) error {
+	path := wq.cacheFile(d)
+	if path == "" {
+		return nil
+	}
+
+	bs, err := json.Marshal(cacheEntry{
+		Domain:             d.name,
+		QueriedAt:          d.queriedAt,
+		Info:               d.info,
+		RegistrarChanged:   d.registrarChanged,
+		NameServersChanged: d.nameServersChanged,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(wq.CacheDir, 0755); err != nil {
+		return err
+	}
+	// write to a temporary file and rename it to not leave a partially written cache file
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bs, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
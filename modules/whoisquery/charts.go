@@ -1,28 +1,99 @@
 package whoisquery
 
-import "github.com/netdata/go.d.plugin/agent/module"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
 
 type (
 	Charts = module.Charts
+	Chart  = module.Chart
 	Dims   = module.Dims
 	Vars   = module.Vars
 	Opts   = module.Opts
 )
 
-var charts = Charts{
-	{
-		ID:    "time_until_expiration",
+var (
+	expirationChartTmpl = Chart{
+		ID:    "%stime_until_expiration",
 		Title: "Time Until Domain Expiration",
 		Units: "seconds",
 		Fam:   "expiration time",
 		Ctx:   "whoisquery.time_until_expiration",
 		Opts:  Opts{StoreFirst: true},
 		Dims: Dims{
-			{ID: "expiry"},
+			{ID: "%sexpiry", Name: "expiry"},
 		},
 		Vars: Vars{
-			{ID: "days_until_expiration_warning"},
-			{ID: "days_until_expiration_critical"},
+			{ID: "%sdays_until_expiration_warning"},
+			{ID: "%sdays_until_expiration_critical"},
+		},
+	}
+	statusChartTmpl = Chart{
+		ID:    "%sdomain_status",
+		Title: "Domain Status Flags",
+		Units: "boolean",
+		Fam:   "status",
+		Ctx:   "whoisquery.domain_status",
+		Opts:  Opts{StoreFirst: true},
+	}
+	changesChartTmpl = Chart{
+		ID:    "%sregistration_changes",
+		Title: "Domain Registration Changes Since the Previous Whois Query",
+		Units: "boolean",
+		Fam:   "registration",
+		Ctx:   "whoisquery.registration_changes",
+		Opts:  Opts{StoreFirst: true},
+		Dims: Dims{
+			{ID: "%sregistrar_changed", Name: "registrar"},
+			{ID: "%snameservers_changed", Name: "nameservers"},
 		},
-	},
+	}
+)
+
+// domainStatuses are the EPP status codes (RFC 5731) that are charted, they are matched against the normalized
+// domain statuses, see normalizeStatus.
+var domainStatuses = []string{
+	"client_delete_prohibited",
+	"client_hold",
+	"client_renew_prohibited",
+	"client_transfer_prohibited",
+	"client_update_prohibited",
+	"server_delete_prohibited",
+	"server_hold",
+	"server_renew_prohibited",
+	"server_transfer_prohibited",
+	"server_update_prohibited",
+	"pending_delete",
+	"pending_transfer",
+	"redemption_period",
+}
+
+func newDomainCharts(d *domain) *Charts {
+	status := statusChartTmpl.Copy()
+	for _, v := range domainStatuses {
+		_ = status.AddDim(&module.Dim{ID: "%sstatus_" + v, Name: strings.ReplaceAll(v, "_", " ")})
+	}
+
+	charts := &Charts{
+		expirationChartTmpl.Copy(),
+		status,
+		changesChartTmpl.Copy(),
+	}
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, d.prefix)
+		if d.prefix != "" {
+			chart.Fam = d.name + " " + chart.Fam
+		}
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, d.prefix)
+		}
+		for _, v := range chart.Vars {
+			v.ID = fmt.Sprintf(v.ID, d.prefix)
+		}
+	}
+	return charts
 }
@@ -1,19 +1,71 @@
 package whoisquery
 
+import (
+	"strings"
+	"time"
+)
+
 func (wq *WhoisQuery) collect() (map[string]int64, error) {
-	remainingTime, err := wq.prov.remainingTime()
-	if err != nil {
-		return nil, err
-	}
+	wq.startScheduler()
+
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
 
 	mx := make(map[string]int64)
-	wq.collectExpiration(mx, remainingTime)
+	for _, d := range wq.domains {
+		if d.info == nil {
+			continue
+		}
+
+		if !d.charted {
+			d.charted = true
+			if err := wq.charts.Add(*newDomainCharts(d)...); err != nil {
+				wq.Warning(err)
+			}
+		}
+
+		wq.collectExpiration(mx, d)
+		wq.collectStatus(mx, d)
+		wq.collectChanges(mx, d)
+	}
 	return mx, nil
 }
 
-func (wq WhoisQuery) collectExpiration(mx map[string]int64, remainingTime float64) {
-	mx["expiry"] = int64(remainingTime)
-	mx["days_until_expiration_warning"] = wq.DaysUntilWarn
-	mx["days_until_expiration_critical"] = wq.DaysUntilCrit
+func (wq *WhoisQuery) collectExpiration(mx map[string]int64, d *domain) {
+	mx[d.prefix+"expiry"] = int64(time.Until(d.info.Expiration).Seconds())
+	mx[d.prefix+"days_until_expiration_warning"] = wq.DaysUntilWarn
+	mx[d.prefix+"days_until_expiration_critical"] = wq.DaysUntilCrit
+}
 
+func (wq *WhoisQuery) collectStatus(mx map[string]int64, d *domain) {
+	set := make(map[string]bool, len(d.info.Statuses))
+	for _, v := range d.info.Statuses {
+		set[normalizeStatus(v)] = true
+	}
+	for _, v := range domainStatuses {
+		mx[d.prefix+"status_"+v] = boolToInt(set[strings.ReplaceAll(v, "_", "")])
+	}
+}
+
+// normalizeStatus converts the status to the form the charted statuses are matched against:
+// 'clientTransferProhibited https://icann.org/epp#clientTransferProhibited', 'client transfer prohibited'
+// and 'client_transfer_prohibited' are all 'clienttransferprohibited'.
+func normalizeStatus(status string) string {
+	s := strings.ToLower(strings.TrimSpace(status))
+	if i := strings.Index(s, " http"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.NewReplacer(" ", "", "_", "").Replace(s)
+}
+
+func (wq *WhoisQuery) collectChanges(mx map[string]int64, d *domain) {
+	mx[d.prefix+"registrar_changed"] = boolToInt(d.registrarChanged)
+	mx[d.prefix+"nameservers_changed"] = boolToInt(d.nameServersChanged)
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
 }
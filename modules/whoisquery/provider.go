@@ -1,56 +1,122 @@
 package whoisquery
 
 import (
-	"fmt"
-	"regexp"
+	"errors"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/araddon/dateparse"
 	"github.com/likexian/whois"
 	whoisparser "github.com/likexian/whois-parser"
+	"golang.org/x/net/proxy"
 )
 
+type domainInfo struct {
+	Expiration  time.Time `json:"expiration"`
+	Registrar   string    `json:"registrar,omitempty"`
+	Statuses    []string  `json:"statuses,omitempty"`
+	NameServers []string  `json:"name_servers,omitempty"`
+}
+
 type provider interface {
-	remainingTime() (float64, error)
+	whois(domain string) (*domainInfo, error)
 }
 
 type fromNet struct {
-	domainAddress string
-	client        *whois.Client
+	server string
+	client *whois.Client
 }
 
-var reValidDomain = regexp.MustCompile(`^[a-zA-Z0-9\-]+\.[a-zA-Z0-9]+$`)
-
 func newProvider(config Config) (provider, error) {
-	domain := config.Source
-	if valid := reValidDomain.MatchString(domain); !valid {
-		return nil, fmt.Errorf("incorrect domain: %s, expected pattern: %s", domain, reValidDomain)
-	}
-
 	client := whois.NewClient()
 	client.SetTimeout(config.Timeout.Duration)
 
-	return &fromNet{
-		domainAddress: domain,
-		client:        client,
-	}, nil
+	p := &fromNet{client: client}
+	if config.Server == "" {
+		return p, nil
+	}
+
+	host, port, err := net.SplitHostPort(config.Server)
+	if err != nil {
+		// no port, the client uses the default whois port
+		p.server = config.Server
+		return p, nil
+	}
+	if host == "" || port == "" {
+		return nil, errors.New("incorrect whois server: " + config.Server)
+	}
+	p.server = host
+	client.SetDialer(&portDialer{port: port, dialer: &net.Dialer{Timeout: config.Timeout.Duration}})
+	return p, nil
 }
 
-func (f *fromNet) remainingTime() (float64, error) {
-	raw, err := f.client.Whois(f.domainAddress)
+func (f *fromNet) whois(domain string) (*domainInfo, error) {
+	raw, err := f.client.Whois(domain, f.server)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	result, err := whoisparser.Parse(raw)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	expire, err := dateparse.ParseAny(result.Domain.ExpirationDate)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	statuses := rawDomainStatuses(raw)
+	if len(statuses) == 0 {
+		statuses = result.Domain.Status
 	}
 
-	return time.Until(expire).Seconds(), nil
+	info := &domainInfo{
+		Expiration:  expire,
+		Statuses:    statuses,
+		NameServers: result.Domain.NameServers,
+	}
+	if result.Registrar != nil {
+		info.Registrar = result.Registrar.Name
+	}
+	return info, nil
+}
+
+// rawDomainStatuses returns the domain status values as they are in the whois response.
+// The whois parser keeps only the first word of a status, the statuses printed as words
+// ("client transfer prohibited") are lost.
+func rawDomainStatuses(raw string) []string {
+	var statuses []string
+	for _, line := range strings.Split(raw, "\n") {
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(line[:i])) {
+		case "domain status", "status", "state", "registration status":
+		default:
+			continue
+		}
+		for _, v := range strings.Split(line[i+1:], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				statuses = append(statuses, v)
+			}
+		}
+	}
+	return statuses
+}
+
+// portDialer connects to the configured port instead of the default whois port (43).
+type portDialer struct {
+	port   string
+	dialer proxy.Dialer
+}
+
+func (d *portDialer) Dial(network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	return d.dialer.Dial(network, net.JoinHostPort(host, d.port))
 }
@@ -0,0 +1,135 @@
+package whoisquery
+
+import (
+	"time"
+)
+
+// The whois servers throttle clients, the scheduler queries the domains one by one,
+// no more often than once per 'query_interval', starting with the most overdue one.
+
+func (wq *WhoisQuery) startScheduler() {
+	wq.schedStart.Do(func() {
+		wq.schedStop = make(chan struct{})
+		wq.schedDone = make(chan struct{})
+		go wq.runScheduler()
+	})
+}
+
+func (wq *WhoisQuery) stopScheduler() {
+	if wq.schedStop == nil {
+		return
+	}
+	close(wq.schedStop)
+	<-wq.schedDone
+	wq.schedStop = nil
+}
+
+func (wq *WhoisQuery) runScheduler() {
+	defer close(wq.schedDone)
+
+	tk := time.NewTicker(wq.QueryInterval.Duration)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-wq.schedStop:
+			return
+		case now := <-tk.C:
+			wq.queryNext(now)
+		}
+	}
+}
+
+// queryNext queries the most overdue domain. It returns false if there is no domain to query.
+func (wq *WhoisQuery) queryNext(now time.Time) bool {
+	d := wq.nextDue(now)
+	if d == nil {
+		return false
+	}
+
+	info, err := wq.prov.whois(d.name)
+
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
+
+	if err != nil {
+		wq.Warningf("domain '%s': whois query failed: %v", d.name, err)
+		d.nextQuery = now.Add(wq.retryInterval())
+		return true
+	}
+
+	wq.updateDomain(d, info, now)
+	if err := wq.saveCache(d); err != nil {
+		wq.Warningf("domain '%s': error on caching whois data: %v", d.name, err)
+	}
+	return true
+}
+
+func (wq *WhoisQuery) nextDue(now time.Time) *domain {
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
+
+	var next *domain
+	for _, d := range wq.domains {
+		if d.nextQuery.After(now) {
+			continue
+		}
+		if next == nil || d.nextQuery.Before(next.nextQuery) {
+			next = d
+		}
+	}
+	return next
+}
+
+func (wq *WhoisQuery) updateDomain(d *domain, info *domainInfo, now time.Time) {
+	if d.info != nil {
+		d.registrarChanged = d.info.Registrar != info.Registrar
+		d.nameServersChanged = !equalSets(d.info.NameServers, info.NameServers)
+		if d.registrarChanged {
+			wq.Infof("domain '%s': registrar changed from '%s' to '%s'", d.name, d.info.Registrar, info.Registrar)
+		}
+		if d.nameServersChanged {
+			wq.Infof("domain '%s': name servers changed from %v to %v", d.name, d.info.NameServers, info.NameServers)
+		}
+	}
+	d.info = info
+	d.queriedAt = now
+	d.nextQuery = now.Add(wq.RefreshEvery.Duration)
+}
+
+// retryInterval is the delay before querying a failed domain again.
+func (wq *WhoisQuery) retryInterval() time.Duration {
+	interval := wq.QueryInterval.Duration * time.Duration(len(wq.domains)) * 2
+	if interval > wq.RefreshEvery.Duration {
+		return wq.RefreshEvery.Duration
+	}
+	return interval
+}
+
+func (wq *WhoisQuery) hasData() bool {
+	wq.mu.Lock()
+	defer wq.mu.Unlock()
+
+	for _, d := range wq.domains {
+		if d.info != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func equalSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+	for _, v := range b {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,12 @@ package whoisquery
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
@@ -22,15 +28,24 @@ func New() *WhoisQuery {
 	return &WhoisQuery{
 		Config: Config{
 			Timeout:       web.Duration{Duration: time.Second * 5},
+			QueryInterval: web.Duration{Duration: time.Second * 5},
+			RefreshEvery:  web.Duration{Duration: time.Hour * 6},
+			CacheDir:      defaultCacheDir(),
 			DaysUntilWarn: 90,
 			DaysUntilCrit: 30,
 		},
+		charts: &Charts{},
 	}
 }
 
 type Config struct {
 	Source        string
+	Domains       []string     `yaml:"domains"`
+	Server        string       `yaml:"server"`
 	Timeout       web.Duration `yaml:"timeout"`
+	QueryInterval web.Duration `yaml:"query_interval"`
+	RefreshEvery  web.Duration `yaml:"refresh_every"`
+	CacheDir      string       `yaml:"cache_dir"`
 	DaysUntilWarn int64        `yaml:"days_until_expiration_warning"`
 	DaysUntilCrit int64        `yaml:"days_until_expiration_critical"`
 }
@@ -38,13 +53,58 @@ type Config struct {
 type WhoisQuery struct {
 	module.Base
 	Config `yaml:",inline"`
-	prov   provider
+
+	charts  *Charts
+	prov    provider
+	domains []*domain
+
+	mu         sync.Mutex
+	schedStop  chan struct{}
+	schedDone  chan struct{}
+	schedStart sync.Once
 }
 
-func (wq WhoisQuery) validateConfig() error {
-	if wq.Source == "" {
+// domain is a queried domain. Metrics and charts of the domains from the 'domains' list are prefixed with the domain id.
+type domain struct {
+	name   string
+	prefix string
+
+	info      *domainInfo
+	queriedAt time.Time
+	nextQuery time.Time
+	// the previous whois query result differs from the one before it
+	registrarChanged   bool
+	nameServersChanged bool
+
+	charted bool
+}
+
+var reValidDomain = regexp.MustCompile(`^[a-zA-Z0-9\-]+\.[a-zA-Z0-9]+$`)
+
+func (wq *WhoisQuery) validateConfig() error {
+	if wq.Source == "" && len(wq.Domains) == 0 {
 		return errors.New("source is not set")
 	}
+	names := wq.Domains
+	if wq.Source != "" {
+		names = append([]string{wq.Source}, names...)
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if valid := reValidDomain.MatchString(name); !valid {
+			return fmt.Errorf("incorrect domain: %s, expected pattern: %s", name, reValidDomain)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate domain '%s'", name)
+		}
+		seen[name] = true
+	}
+	if wq.QueryInterval.Duration <= 0 {
+		return errors.New("'query_interval' must be positive")
+	}
+	if wq.RefreshEvery.Duration <= 0 {
+		return errors.New("'refresh_every' must be positive")
+	}
 	return nil
 }
 
@@ -57,6 +117,21 @@ func (wq *WhoisQuery) initProvider() error {
 	return nil
 }
 
+func (wq *WhoisQuery) initDomains() {
+	if wq.Source != "" {
+		wq.domains = append(wq.domains, &domain{name: wq.Source})
+	}
+	for _, name := range wq.Domains {
+		wq.domains = append(wq.domains, &domain{name: name, prefix: "domain_" + domainID(name) + "_"})
+	}
+
+	for _, d := range wq.domains {
+		if err := wq.loadCache(d); err != nil {
+			wq.Warningf("domain '%s': error on loading cached whois data: %v", d.name, err)
+		}
+	}
+}
+
 func (wq *WhoisQuery) Init() bool {
 	if err := wq.validateConfig(); err != nil {
 		wq.Errorf("error on validating config: %v", err)
@@ -67,15 +142,20 @@ func (wq *WhoisQuery) Init() bool {
 		wq.Errorf("error on initializing whois provider: %v", err)
 		return false
 	}
+
+	wq.initDomains()
 	return true
 }
 
 func (wq *WhoisQuery) Check() bool {
+	if !wq.hasData() {
+		wq.queryNext(time.Now())
+	}
 	return len(wq.Collect()) > 0
 }
 
-func (wq WhoisQuery) Charts() *Charts {
-	return charts.Copy()
+func (wq *WhoisQuery) Charts() *Charts {
+	return wq.charts
 }
 
 func (wq *WhoisQuery) Collect() map[string]int64 {
@@ -90,4 +170,18 @@ func (wq *WhoisQuery) Collect() map[string]int64 {
 	return mx
 }
 
-func (WhoisQuery) Cleanup() {}
+func (wq *WhoisQuery) Cleanup() {
+	wq.stopScheduler()
+}
+
+func domainID(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+func defaultCacheDir() string {
+	dir := os.Getenv("NETDATA_LIB_DIR")
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "whoisquery")
+}
@@ -1,10 +1,19 @@
 package whoisquery
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	stdnet "net"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -36,6 +45,14 @@ func TestWhoisQuery_Init(t *testing.T) {
 			config:       Config{Source: "example.org"},
 			providerType: net,
 		},
+		"ok domains": {
+			config:       Config{Domains: []string{"example.org", "example.com"}},
+			providerType: net,
+		},
+		"ok whois server with port": {
+			config:       Config{Source: "example.org", Server: "127.0.0.1:4343"},
+			providerType: net,
+		},
 		"empty source": {
 			config: Config{Source: ""},
 			err:    true,
@@ -48,11 +65,25 @@ func TestWhoisQuery_Init(t *testing.T) {
 			config: Config{Source: "https://example.org"},
 			err:    true,
 		},
+		"incorrect domain in domains": {
+			config: Config{Domains: []string{"example.org", "https://example.com"}},
+			err:    true,
+		},
+		"duplicate domains": {
+			config: Config{Source: "example.org", Domains: []string{"example.org"}},
+			err:    true,
+		},
+		"incorrect whois server": {
+			config: Config{Source: "example.org", Server: ":4343"},
+			err:    true,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			whoisquery := New()
+			test.config.QueryInterval = whoisquery.QueryInterval
+			test.config.RefreshEvery = whoisquery.RefreshEvery
 			whoisquery.Config = test.config
 
 			if test.err {
@@ -72,43 +103,224 @@ func TestWhoisQuery_Init(t *testing.T) {
 }
 
 func TestWhoisQuery_Check(t *testing.T) {
-	whoisquery := New()
-	whoisquery.prov = &mockProvider{remTime: 12345.678}
+	whoisquery := prepareWhoisQuery(t, Config{Source: "example.org"}, &mockProvider{remTime: 12345.678})
+	defer whoisquery.Cleanup()
 
 	assert.True(t, whoisquery.Check())
 }
 
 func TestWhoisQuery_Check_ReturnsFalseOnProviderError(t *testing.T) {
-	whoisquery := New()
-	whoisquery.prov = &mockProvider{err: true}
+	whoisquery := prepareWhoisQuery(t, Config{Source: "example.org"}, &mockProvider{err: true})
+	defer whoisquery.Cleanup()
 
 	assert.False(t, whoisquery.Check())
 }
 
 func TestWhoisQuery_Collect(t *testing.T) {
-	whoisquery := New()
-	whoisquery.prov = &mockProvider{remTime: 12345}
+	whoisquery := prepareWhoisQuery(t, Config{Source: "example.org"}, &mockProvider{remTime: 12345})
+	defer whoisquery.Cleanup()
+	require.True(t, whoisquery.Check())
 
 	collected := whoisquery.Collect()
 
 	expected := map[string]int64{
-		"expiry":                         12345,
-		"days_until_expiration_warning":  90,
-		"days_until_expiration_critical": 30,
+		"expiry":                            12345,
+		"days_until_expiration_warning":     90,
+		"days_until_expiration_critical":    30,
+		"registrar_changed":                 0,
+		"nameservers_changed":               0,
+		"status_client_delete_prohibited":   0,
+		"status_client_hold":                0,
+		"status_client_renew_prohibited":    0,
+		"status_client_transfer_prohibited": 1,
+		"status_client_update_prohibited":   0,
+		"status_server_delete_prohibited":   0,
+		"status_server_hold":                0,
+		"status_server_renew_prohibited":    0,
+		"status_server_transfer_prohibited": 0,
+		"status_server_update_prohibited":   0,
+		"status_pending_delete":             0,
+		"status_pending_transfer":           0,
+		"status_redemption_period":          0,
 	}
 
 	assert.NotZero(t, collected)
+	copyExpiry(expected, collected)
 	assert.Equal(t, expected, collected)
 	ensureCollectedHasAllChartsDimsVarsIDs(t, whoisquery, collected)
 }
 
 func TestWhoisQuery_Collect_ReturnsNilOnProviderError(t *testing.T) {
-	whoisquery := New()
-	whoisquery.prov = &mockProvider{err: true}
+	whoisquery := prepareWhoisQuery(t, Config{Source: "example.org"}, &mockProvider{err: true})
+	defer whoisquery.Cleanup()
 
 	assert.Nil(t, whoisquery.Collect())
 }
 
+func TestWhoisQuery_Collect_Domains(t *testing.T) {
+	prov := &mockProvider{
+		remTime: 86400,
+		failOn:  map[string]bool{"failed.org": true},
+	}
+	whoisquery := prepareWhoisQuery(t, Config{
+		Domains:       []string{"example.org", "example.com", "failed.org"},
+		QueryInterval: web.Duration{Duration: time.Millisecond * 10},
+	}, prov)
+	defer whoisquery.Cleanup()
+
+	require.True(t, whoisquery.Check())
+	require.Eventually(t, func() bool { return prov.numOfQueries() >= 3 }, time.Second*5, time.Millisecond*10)
+
+	collected := whoisquery.Collect()
+
+	for _, prefix := range []string{"domain_example_org_", "domain_example_com_"} {
+		assert.InDelta(t, 86400, collected[prefix+"expiry"], 10)
+		assert.Equal(t, int64(1), collected[prefix+"status_client_transfer_prohibited"])
+		assert.True(t, whoisquery.Charts().Has(prefix+"time_until_expiration"))
+	}
+	_, ok := collected["domain_failed_org_expiry"]
+	assert.False(t, ok)
+	assert.False(t, whoisquery.Charts().Has("domain_failed_org_time_until_expiration"))
+	_, ok = collected["expiry"]
+	assert.False(t, ok)
+	ensureCollectedHasAllChartsDimsVarsIDs(t, whoisquery, collected)
+
+	// successfully queried domains are not queried again until 'refresh_every' passes
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 2, prov.numOfQueriesOf("example.org")+prov.numOfQueriesOf("example.com"))
+}
+
+func TestWhoisQuery_queryNext_DetectsChanges(t *testing.T) {
+	prov := &mockProvider{remTime: 86400}
+	whoisquery := prepareWhoisQuery(t, Config{Domains: []string{"example.org"}}, prov)
+
+	now := time.Now()
+	require.True(t, whoisquery.queryNext(now))
+	assert.False(t, whoisquery.queryNext(now), "not due")
+
+	prov.registrar = "Other Registrar"
+	prov.nameServers = []string{"ns1.example.net", "ns3.example.net"}
+	prov.statuses = []string{"pendingdelete"}
+	require.True(t, whoisquery.queryNext(now.Add(whoisquery.RefreshEvery.Duration)))
+
+	collected, err := whoisquery.collect()
+	require.NoError(t, err)
+	whoisquery.Cleanup()
+
+	assert.Equal(t, int64(1), collected["domain_example_org_registrar_changed"])
+	assert.Equal(t, int64(1), collected["domain_example_org_nameservers_changed"])
+	assert.Equal(t, int64(1), collected["domain_example_org_status_pending_delete"])
+	assert.Equal(t, int64(0), collected["domain_example_org_status_client_transfer_prohibited"])
+}
+
+func TestWhoisQuery_Cache(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Domains: []string{"example.org", "example.com"}, CacheDir: dir}
+
+	prov := &mockProvider{remTime: 86400}
+	whoisquery := prepareWhoisQuery(t, cfg, prov)
+	for whoisquery.queryNext(time.Now()) {
+	}
+	assert.FileExists(t, filepath.Join(dir, "example.org.json"))
+	assert.FileExists(t, filepath.Join(dir, "example.com.json"))
+
+	// restart: the domains are collected from the cache without whois queries
+	prov = &mockProvider{err: true}
+	whoisquery = prepareWhoisQuery(t, cfg, prov)
+	defer whoisquery.Cleanup()
+
+	assert.False(t, whoisquery.queryNext(time.Now()), "cached domains are not due")
+	require.True(t, whoisquery.Check())
+	collected := whoisquery.Collect()
+
+	assert.InDelta(t, 86400, collected["domain_example_org_expiry"], 10)
+	assert.InDelta(t, 86400, collected["domain_example_com_expiry"], 10)
+	assert.Equal(t, 0, prov.numOfQueries())
+}
+
+func TestWhoisQuery_Collect_FakeWhoisServer(t *testing.T) {
+	srv := newFakeWhoisServer(t, map[string]string{
+		"example.org": fakeWhoisResponse("example.org", time.Now().Add(time.Hour*24*100),
+			"clientDeleteProhibited https://icann.org/epp#clientDeleteProhibited",
+			"clientTransferProhibited https://icann.org/epp#clientTransferProhibited",
+		),
+	})
+	defer srv.close()
+
+	whoisquery := New()
+	whoisquery.Source = "example.org"
+	whoisquery.Server = srv.address()
+	whoisquery.CacheDir = ""
+	require.True(t, whoisquery.Init())
+	defer whoisquery.Cleanup()
+
+	require.True(t, whoisquery.Check())
+	collected := whoisquery.Collect()
+
+	assert.InDelta(t, 100*86400, collected["expiry"], 60)
+	assert.Equal(t, int64(1), collected["status_client_transfer_prohibited"])
+	assert.Equal(t, int64(1), collected["status_client_delete_prohibited"])
+	assert.Equal(t, int64(0), collected["status_pending_delete"])
+	assert.Equal(t, []string{"example.org"}, srv.queries())
+}
+
+func TestWhoisQuery_Collect_FakeWhoisServerStatusesAsWords(t *testing.T) {
+	srv := newFakeWhoisServer(t, map[string]string{
+		"example.org": fakeWhoisResponse("example.org", time.Now().Add(time.Hour*24*100),
+			"client transfer prohibited",
+			"Server Update Prohibited",
+			"pending_delete",
+		),
+	})
+	defer srv.close()
+
+	whoisquery := New()
+	whoisquery.Source = "example.org"
+	whoisquery.Server = srv.address()
+	whoisquery.CacheDir = ""
+	require.True(t, whoisquery.Init())
+	defer whoisquery.Cleanup()
+
+	require.True(t, whoisquery.Check())
+	collected := whoisquery.Collect()
+
+	assert.Equal(t, int64(1), collected["status_client_transfer_prohibited"])
+	assert.Equal(t, int64(1), collected["status_server_update_prohibited"])
+	assert.Equal(t, int64(1), collected["status_pending_delete"])
+	assert.Equal(t, int64(0), collected["status_client_delete_prohibited"])
+	assert.Equal(t, int64(0), collected["status_client_hold"])
+}
+
+func TestNormalizeStatus(t *testing.T) {
+	tests := map[string]string{
+		"clientTransferProhibited https://icann.org/epp#clientTransferProhibited": "clienttransferprohibited",
+		"client transfer prohibited": "clienttransferprohibited",
+		"client_transfer_prohibited": "clienttransferprohibited",
+		" Server Hold ":              "serverhold",
+		"ok":                         "ok",
+	}
+
+	for status, expected := range tests {
+		t.Run(status, func(t *testing.T) {
+			assert.Equal(t, expected, normalizeStatus(status))
+		})
+	}
+}
+
+func TestWhoisQuery_Check_FakeWhoisServerDomainNotFound(t *testing.T) {
+	srv := newFakeWhoisServer(t, nil)
+	defer srv.close()
+
+	whoisquery := New()
+	whoisquery.Source = "example.org"
+	whoisquery.Server = srv.address()
+	whoisquery.CacheDir = ""
+	require.True(t, whoisquery.Init())
+	defer whoisquery.Cleanup()
+
+	assert.False(t, whoisquery.Check())
+}
+
 func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, whoisquery *WhoisQuery, collected map[string]int64) {
 	for _, chart := range *whoisquery.Charts() {
 		for _, dim := range chart.Dims {
@@ -122,14 +334,162 @@ func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, whoisquery *WhoisQuery
 	}
 }
 
+func prepareWhoisQuery(t *testing.T, cfg Config, prov provider) *WhoisQuery {
+	whoisquery := New()
+	if cfg.QueryInterval.Duration == 0 {
+		cfg.QueryInterval = whoisquery.QueryInterval
+	}
+	if cfg.RefreshEvery.Duration == 0 {
+		cfg.RefreshEvery = whoisquery.RefreshEvery
+	}
+	cfg.Timeout = whoisquery.Timeout
+	cfg.DaysUntilWarn = whoisquery.DaysUntilWarn
+	cfg.DaysUntilCrit = whoisquery.DaysUntilCrit
+	whoisquery.Config = cfg
+	require.True(t, whoisquery.Init())
+	whoisquery.prov = prov
+	return whoisquery
+}
+
+// expiry is calculated at collection time, it may differ from the expected value by a second.
+func copyExpiry(dst, src map[string]int64) {
+	for key := range dst {
+		if strings.HasSuffix(key, "expiry") && dst[key]-src[key] <= 1 {
+			dst[key] = src[key]
+		}
+	}
+}
+
 type mockProvider struct {
-	remTime float64
-	err     bool
+	remTime     float64
+	err         bool
+	failOn      map[string]bool
+	registrar   string
+	nameServers []string
+	statuses    []string
+
+	mu      sync.Mutex
+	queried []string
 }
 
-func (m mockProvider) remainingTime() (float64, error) {
-	if m.err {
-		return 0, errors.New("mock remaining time error")
+func (m *mockProvider) whois(domain string) (*domainInfo, error) {
+	m.mu.Lock()
+	m.queried = append(m.queried, domain)
+	m.mu.Unlock()
+
+	if m.err || m.failOn[domain] {
+		return nil, errors.New("mock whois error")
+	}
+
+	info := &domainInfo{
+		Expiration:  time.Now().Add(time.Duration(m.remTime * float64(time.Second))),
+		Registrar:   "Example Registrar",
+		Statuses:    []string{"clienttransferprohibited"},
+		NameServers: []string{"ns1.example.net", "ns2.example.net"},
+	}
+	if m.registrar != "" {
+		info.Registrar = m.registrar
+	}
+	if m.nameServers != nil {
+		info.NameServers = m.nameServers
+	}
+	if m.statuses != nil {
+		info.Statuses = m.statuses
+	}
+	return info, nil
+}
+
+func (m *mockProvider) numOfQueries() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.queried)
+}
+
+func (m *mockProvider) numOfQueriesOf(domain string) (n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, v := range m.queried {
+		if v == domain {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeWhoisServer struct {
+	ln        stdnet.Listener
+	responses map[string]string
+
+	mu      sync.Mutex
+	queried []string
+}
+
+func newFakeWhoisServer(t *testing.T, responses map[string]string) *fakeWhoisServer {
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &fakeWhoisServer{ln: ln, responses: responses}
+	go srv.serve()
+	return srv
+}
+
+func (s *fakeWhoisServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			_ = conn.SetDeadline(time.Now().Add(time.Second * 5))
+
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return
+			}
+			query := strings.TrimSpace(line)
+
+			s.mu.Lock()
+			s.queried = append(s.queried, query)
+			s.mu.Unlock()
+
+			resp, ok := s.responses[query]
+			if !ok {
+				resp = fmt.Sprintf("No match for \"%s\".\r\n", strings.ToUpper(query))
+			}
+			_, _ = conn.Write([]byte(resp))
+		}()
+	}
+}
+
+func (s *fakeWhoisServer) address() string { return s.ln.Addr().String() }
+
+func (s *fakeWhoisServer) close() { _ = s.ln.Close() }
+
+func (s *fakeWhoisServer) queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queried...)
+}
+
+func fakeWhoisResponse(domain string, expiration time.Time, statuses ...string) string {
+	lines := []string{
+		"Domain Name: " + strings.ToUpper(domain),
+		"Registry Domain ID: 123456789_DOMAIN_COM-VRSN",
+		"Updated Date: 2020-08-14T07:02:37Z",
+		"Creation Date: 1995-08-14T04:00:00Z",
+		"Registry Expiry Date: " + expiration.UTC().Format("2006-01-02T15:04:05Z"),
+		"Registrar: Example Registrar, Inc.",
+		"Registrar IANA ID: 376",
+	}
+	for _, status := range statuses {
+		lines = append(lines, "Domain Status: "+status)
 	}
-	return m.remTime, nil
+	lines = append(lines,
+		"Name Server: A.IANA-SERVERS.NET",
+		"Name Server: B.IANA-SERVERS.NET",
+		"DNSSEC: signedDelegation",
+		"",
+	)
+	return strings.Join(lines, "\r\n")
 }
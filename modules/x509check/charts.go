@@ -1,57 +1,131 @@
 package x509check
 
-import "github.com/netdata/go.d.plugin/agent/module"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
 
 type (
 	Charts = module.Charts
+	Chart  = module.Chart
 	Dims   = module.Dims
+	Dim    = module.Dim
 	Vars   = module.Vars
 	Opts   = module.Opts
 )
 
-var charts = Charts{
-	{
-		ID:    "time_until_expiration",
+var (
+	expirationChartTmpl = Chart{
+		ID:    "%stime_until_expiration",
 		Title: "Time Until Certificate Expiration",
 		Units: "seconds",
 		Fam:   "expiration time",
 		Ctx:   "x509check.time_until_expiration",
 		Opts:  Opts{StoreFirst: true},
 		Dims: Dims{
-			{ID: "expiry"},
+			{ID: "%sexpiry", Name: "expiry"},
 		},
 		Vars: Vars{
-			{ID: "days_until_expiration_warning"},
-			{ID: "days_until_expiration_critical"},
+			{ID: "%sdays_until_expiration_warning"},
+			{ID: "%sdays_until_expiration_critical"},
 		},
-	},
-}
-
-var withRevocationCharts = Charts{
-	{
-		ID:    "time_until_expiration",
-		Title: "Time Until Certificate Expiration",
-		Units: "seconds",
-		Fam:   "expiration time",
-		Ctx:   "x509check.time_until_expiration",
-		Opts:  Opts{StoreFirst: true},
-		Dims: Dims{
-			{ID: "expiry"},
-		},
-		Vars: Vars{
-			{ID: "days_until_expiration_warning"},
-			{ID: "days_until_expiration_critical"},
-		},
-	},
-	{
-		ID:    "revocation_status",
+	}
+	revocationChartTmpl = Chart{
+		ID:    "%srevocation_status",
 		Title: "Revocation Status",
 		Units: "boolean",
 		Fam:   "revocation",
 		Ctx:   "x509check.revocation_status",
 		Opts:  Opts{StoreFirst: true},
 		Dims: Dims{
-			{ID: "revoked"},
+			{ID: "%srevoked", Name: "revoked"},
 		},
-	},
+	}
+	chainExpirationChartTmpl = Chart{
+		ID:    "%schain_time_until_expiration",
+		Title: "Time Until Chain Certificates Expiration",
+		Units: "days",
+		Fam:   "chain",
+		Ctx:   "x509check.chain_time_until_expiration",
+		Opts:  Opts{StoreFirst: true},
+	}
+	validationChartTmpl = Chart{
+		ID:    "%svalidation_status",
+		Title: "Certificate Validation Problems",
+		Units: "boolean",
+		Fam:   "validation",
+		Ctx:   "x509check.validation_status",
+		Opts:  Opts{StoreFirst: true},
+	}
+	ocspStaplingChartTmpl = Chart{
+		ID:    "%socsp_stapling",
+		Title: "OCSP Stapling",
+		Units: "boolean",
+		Fam:   "validation",
+		Ctx:   "x509check.ocsp_stapling",
+		Opts:  Opts{StoreFirst: true},
+		Dims: Dims{
+			{ID: "%socsp_stapled", Name: "stapled"},
+		},
+	}
+)
+
+var (
+	chainInvalidDim     = Dim{ID: "%schain_invalid", Name: "chain invalid"}
+	hostnameMismatchDim = Dim{ID: "%shostname_mismatch", Name: "hostname mismatch"}
+	weakKeyDim          = Dim{ID: "%sweak_key", Name: "weak key"}
+	weakSignatureDim    = Dim{ID: "%sweak_signature", Name: "weak signature"}
+)
+
+func (x *X509Check) newSourceCharts(src *source, isTLS bool) *Charts {
+	charts := &Charts{
+		expirationChartTmpl.Copy(),
+		chainExpirationChartTmpl.Copy(),
+	}
+	if x.CheckRevocation {
+		_ = charts.Add(revocationChartTmpl.Copy())
+	}
+
+	validation := validationChartTmpl.Copy()
+	var dims []Dim
+	if !x.InsecureSkipVerify {
+		dims = append(dims, chainInvalidDim)
+		if src.host != "" {
+			dims = append(dims, hostnameMismatchDim)
+		}
+	}
+	dims = append(dims, weakKeyDim, weakSignatureDim)
+	for _, dim := range dims {
+		dim := dim
+		_ = validation.AddDim(&dim)
+	}
+	_ = charts.Add(validation)
+
+	if isTLS {
+		_ = charts.Add(ocspStaplingChartTmpl.Copy())
+	}
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, src.prefix)
+		if src.prefix != "" {
+			chart.Fam = src.name + " " + chart.Fam
+		}
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, src.prefix)
+		}
+		for _, v := range chart.Vars {
+			v.ID = fmt.Sprintf(v.ID, src.prefix)
+		}
+	}
+	return charts
+}
+
+func newChainCertDim(src *source, label string) *Dim {
+	return &Dim{
+		ID:   src.prefix + "chain_" + label + "_expiry",
+		Name: strings.ReplaceAll(label, "_", " "),
+		Div:  86400,
+	}
 }
@@ -1,6 +1,7 @@
 package x509check
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"time"
@@ -9,42 +10,133 @@ import (
 )
 
 func (x *X509Check) collect() (map[string]int64, error) {
-	certs, err := x.prov.certificates()
+	mx := make(map[string]int64)
+
+	for _, src := range x.sources {
+		if err := x.collectSource(mx, src); err != nil {
+			x.Error(err)
+		}
+	}
+
+	return mx, nil
+}
+
+func (x *X509Check) collectSource(mx map[string]int64, src *source) error {
+	var certs []*x509.Certificate
+	var state *tls.ConnectionState
+	var err error
+
+	if p, ok := src.prov.(tlsProvider); ok {
+		if state, err = p.connectionState(); err == nil {
+			certs = state.PeerCertificates
+		}
+	} else {
+		certs, err = src.prov.certificates()
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(certs) == 0 {
-		return nil, fmt.Errorf("no certificate was provided by '%s'", x.Config.Source)
+		return fmt.Errorf("no certificate was provided by '%s'", src.name)
 	}
 
-	mx := make(map[string]int64)
+	if !src.charted {
+		src.charted = true
+		if err := x.charts.Add(*x.newSourceCharts(src, state != nil)...); err != nil {
+			x.Warning(err)
+		}
+	}
 
-	x.collectExpiration(mx, certs)
+	x.collectExpiration(mx, src, certs)
 	if x.CheckRevocation {
-		x.collectRevocation(mx, certs)
+		x.collectRevocation(mx, src, certs)
+	}
+	x.collectValidation(mx, src, certs)
+	if state != nil {
+		mx[src.prefix+"ocsp_stapled"] = boolToInt(len(state.OCSPResponse) > 0)
 	}
 
-	return mx, nil
+	return nil
 }
 
-func (x X509Check) collectExpiration(mx map[string]int64, certs []*x509.Certificate) {
+func (x X509Check) collectExpiration(mx map[string]int64, src *source, certs []*x509.Certificate) {
 	expiry := time.Until(certs[0].NotAfter).Seconds()
-	mx["expiry"] = int64(expiry)
-	mx["days_until_expiration_warning"] = x.DaysUntilWarn
-	mx["days_until_expiration_critical"] = x.DaysUntilCritical
+	mx[src.prefix+"expiry"] = int64(expiry)
+	mx[src.prefix+"days_until_expiration_warning"] = x.DaysUntilWarn
+	mx[src.prefix+"days_until_expiration_critical"] = x.DaysUntilCritical
 
 }
 
-func (x X509Check) collectRevocation(mx map[string]int64, certs []*x509.Certificate) {
+func (x X509Check) collectRevocation(mx map[string]int64, src *source, certs []*x509.Certificate) {
 	rev, ok, err := revoke.VerifyCertificateError(certs[0])
 	if err != nil {
 		x.Debug(err)
 	}
 	switch {
 	case ok && rev:
-		mx["revoked"] = 1
+		mx[src.prefix+"revoked"] = 1
 	case ok && !rev:
-		mx["revoked"] = 0
+		mx[src.prefix+"revoked"] = 0
+	}
+}
+
+func (x *X509Check) collectValidation(mx map[string]int64, src *source, certs []*x509.Certificate) {
+	chain := certs
+	if !x.InsecureSkipVerify {
+		verified, err := verifyChain(certs, x.roots)
+		if err != nil {
+			x.Debugf("'%s' chain verification: %v", src.name, err)
+		} else {
+			chain = verified
+		}
+		mx[src.prefix+"chain_invalid"] = boolToInt(err != nil)
+
+		if src.host != "" {
+			err := certs[0].VerifyHostname(src.host)
+			if err != nil {
+				x.Debugf("'%s' hostname verification: %v", src.name, err)
+			}
+			mx[src.prefix+"hostname_mismatch"] = boolToInt(err != nil)
+		}
+	}
+
+	var weakKey, weakSignature bool
+	for _, cert := range certs {
+		weakKey = weakKey || hasWeakKey(cert)
+		weakSignature = weakSignature || hasWeakSignature(cert)
+	}
+	mx[src.prefix+"weak_key"] = boolToInt(weakKey)
+	mx[src.prefix+"weak_signature"] = boolToInt(weakSignature)
+
+	x.collectChainExpiration(mx, src, chain)
+}
+
+func (x *X509Check) collectChainExpiration(mx map[string]int64, src *source, chain []*x509.Certificate) {
+	for i, label := range chainLabels(chain) {
+		dim := newChainCertDim(src, label)
+		mx[dim.ID] = int64(time.Until(chain[i].NotAfter).Seconds())
+
+		if src.chainCerts[label] {
+			continue
+		}
+		src.chainCerts[label] = true
+
+		chart := x.charts.Get(fmt.Sprintf(chainExpirationChartTmpl.ID, src.prefix))
+		if chart == nil {
+			continue
+		}
+		if err := chart.AddDim(dim); err != nil {
+			x.Warning(err)
+			continue
+		}
+		chart.MarkNotCreated()
+	}
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
 	}
+	return 0
 }
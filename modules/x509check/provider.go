@@ -18,6 +18,12 @@ type provider interface {
 	certificates() ([]*x509.Certificate, error)
 }
 
+// tlsProvider is implemented by providers that get certificates from a TLS handshake.
+type tlsProvider interface {
+	provider
+	connectionState() (*tls.ConnectionState, error)
+}
+
 type fromFile struct {
 	path string
 }
@@ -34,8 +40,15 @@ type fromSMTP struct {
 	timeout   time.Duration
 }
 
-func newProvider(config Config) (provider, error) {
-	sourceURL, err := url.Parse(config.Source)
+type fromStartTLS struct {
+	url       *url.URL
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	startTLS  startTLSFunc
+}
+
+func newProvider(config Config, source string) (provider, error) {
+	sourceURL, err := url.Parse(source)
 	if err != nil {
 		return nil, fmt.Errorf("source parse: %v", err)
 	}
@@ -49,6 +62,8 @@ func newProvider(config Config) (provider, error) {
 		tlsCfg = &tls.Config{}
 	}
 	tlsCfg.ServerName = sourceURL.Hostname()
+	// the chain and the hostname are validated after the handshake, see collectValidation and verifyChain
+	tlsCfg.InsecureSkipVerify = true
 
 	switch sourceURL.Scheme {
 	case "file":
@@ -61,6 +76,10 @@ func newProvider(config Config) (provider, error) {
 	case "smtp":
 		sourceURL.Scheme = "tcp"
 		return &fromSMTP{url: sourceURL, tlsConfig: tlsCfg, timeout: config.Timeout.Duration}, nil
+	case "imap", "pop3", "ldap", "postgres", "postgresql":
+		startTLS := startTLSFuncs[sourceURL.Scheme]
+		sourceURL.Scheme = "tcp"
+		return &fromStartTLS{url: sourceURL, tlsConfig: tlsCfg, timeout: config.Timeout.Duration, startTLS: startTLS}, nil
 	default:
 		return nil, fmt.Errorf("unsupported scheme '%s'", sourceURL)
 	}
@@ -72,37 +91,58 @@ func (f fromFile) certificates() ([]*x509.Certificate, error) {
 		return nil, fmt.Errorf("error on reading '%s': %v", f.path, err)
 	}
 
-	block, _ := pem.Decode(content)
-	if block == nil {
-		return nil, fmt.Errorf("error on decoding '%s': %v", f.path, err)
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error on parsing certificate '%s': %v", f.path, err)
+		}
+		certs = append(certs, cert)
 	}
 
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("error on parsing certificate '%s': %v", f.path, err)
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("error on decoding '%s': no PEM certificates found", f.path)
 	}
 
-	return []*x509.Certificate{cert}, nil
+	return certs, nil
 }
 
 func (f fromNet) certificates() ([]*x509.Certificate, error) {
+	return peerCertificates(f)
+}
+
+func (f fromNet) connectionState() (*tls.ConnectionState, error) {
 	ipConn, err := net.DialTimeout(f.url.Scheme, f.url.Host, f.timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error on dial to '%s': %v", f.url, err)
 	}
 	defer func() { _ = ipConn.Close() }()
 
+	_ = ipConn.SetDeadline(time.Now().Add(f.timeout))
 	conn := tls.Client(ipConn, f.tlsConfig.Clone())
 	defer func() { _ = conn.Close() }()
 	if err := conn.Handshake(); err != nil {
 		return nil, fmt.Errorf("error on SSL handshake with '%s': %v", f.url, err)
 	}
 
-	certs := conn.ConnectionState().PeerCertificates
-	return certs, nil
+	state := conn.ConnectionState()
+	return &state, nil
 }
 
 func (f fromSMTP) certificates() ([]*x509.Certificate, error) {
+	return peerCertificates(f)
+}
+
+func (f fromSMTP) connectionState() (*tls.ConnectionState, error) {
 	ipConn, err := net.DialTimeout(f.url.Scheme, f.url.Host, f.timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error on dial to '%s': %v", f.url, err)
@@ -121,9 +161,43 @@ func (f fromSMTP) certificates() ([]*x509.Certificate, error) {
 		return nil, fmt.Errorf("error on startTLS with '%s': %v", f.url, err)
 	}
 
-	conn, ok := smtpClient.TLSConnectionState()
+	state, ok := smtpClient.TLSConnectionState()
 	if !ok {
 		return nil, fmt.Errorf("startTLS didn't succeed")
 	}
-	return conn.PeerCertificates, nil
+	return &state, nil
+}
+
+func (f fromStartTLS) certificates() ([]*x509.Certificate, error) {
+	return peerCertificates(f)
+}
+
+func (f fromStartTLS) connectionState() (*tls.ConnectionState, error) {
+	ipConn, err := net.DialTimeout(f.url.Scheme, f.url.Host, f.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error on dial to '%s': %v", f.url, err)
+	}
+	defer func() { _ = ipConn.Close() }()
+
+	_ = ipConn.SetDeadline(time.Now().Add(f.timeout))
+	if err := f.startTLS(ipConn); err != nil {
+		return nil, fmt.Errorf("error on startTLS with '%s': %v", f.url, err)
+	}
+
+	conn := tls.Client(ipConn, f.tlsConfig.Clone())
+	defer func() { _ = conn.Close() }()
+	if err := conn.Handshake(); err != nil {
+		return nil, fmt.Errorf("error on SSL handshake with '%s': %v", f.url, err)
+	}
+
+	state := conn.ConnectionState()
+	return &state, nil
+}
+
+func peerCertificates(p tlsProvider) ([]*x509.Certificate, error) {
+	state, err := p.connectionState()
+	if err != nil {
+		return nil, err
+	}
+	return state.PeerCertificates, nil
 }
@@ -0,0 +1,199 @@
+package x509check
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// startTLSFunc negotiates a TLS upgrade of a plaintext protocol connection.
+// After it returns without an error the next byte on the wire is the TLS handshake.
+type startTLSFunc func(conn net.Conn) error
+
+var startTLSFuncs = map[string]startTLSFunc{
+	"imap":       startTLSIMAP,
+	"pop3":       startTLSPOP3,
+	"ldap":       startTLSLDAP,
+	"postgres":   startTLSPostgres,
+	"postgresql": startTLSPostgres,
+}
+
+// startTLSIMAP implements RFC 3501 6.2.1.
+func startTLSIMAP(conn net.Conn) error {
+	// it is safe to buffer, the server sends nothing after the response until the client starts the handshake
+	r := bufio.NewReader(conn)
+
+	greeting, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected IMAP greeting '%s'", greeting)
+	}
+
+	if _, err := io.WriteString(conn, "a001 STARTTLS\r\n"); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a001 OK") {
+			return fmt.Errorf("unexpected IMAP STARTTLS response '%s'", line)
+		}
+		return nil
+	}
+}
+
+// startTLSPOP3 implements RFC 2595 4.
+func startTLSPOP3(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+
+	greeting, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return fmt.Errorf("unexpected POP3 greeting '%s'", greeting)
+	}
+
+	if _, err := io.WriteString(conn, "STLS\r\n"); err != nil {
+		return err
+	}
+
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected POP3 STLS response '%s'", line)
+	}
+	return nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// ldapStartTLSRequest is the BER encoded LDAPMessage (messageID 1) with the StartTLS ExtendedRequest (RFC 4511 4.14.1).
+var ldapStartTLSRequest = func() []byte {
+	name := append([]byte{0x80, byte(len(ldapStartTLSOID))}, ldapStartTLSOID...)
+	op := append([]byte{0x77, byte(len(name))}, name...)
+	msg := append([]byte{0x02, 0x01, 0x01}, op...)
+	return append([]byte{0x30, byte(len(msg))}, msg...)
+}()
+
+// startTLSLDAP implements RFC 4511 4.14.
+func startTLSLDAP(conn net.Conn) error {
+	if _, err := conn.Write(ldapStartTLSRequest); err != nil {
+		return err
+	}
+
+	msg, err := readBERElement(conn, 0x30)
+	if err != nil {
+		return fmt.Errorf("reading LDAP response: %v", err)
+	}
+
+	// messageID INTEGER
+	if _, msg, err = splitBERElement(msg, 0x02); err != nil {
+		return fmt.Errorf("parsing LDAP response: %v", err)
+	}
+	// protocolOp ExtendedResponse [APPLICATION 24]
+	resp, _, err := splitBERElement(msg, 0x78)
+	if err != nil {
+		return fmt.Errorf("parsing LDAP response: %v", err)
+	}
+	// resultCode ENUMERATED
+	code, _, err := splitBERElement(resp, 0x0a)
+	if err != nil {
+		return fmt.Errorf("parsing LDAP response: %v", err)
+	}
+	if len(code) != 1 || code[0] != 0 {
+		return fmt.Errorf("LDAP StartTLS result code %v", code)
+	}
+	return nil
+}
+
+const maxBERElementLength = 64 * 1024
+
+func readBERElement(r io.Reader, tag byte) ([]byte, error) {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	if hdr[0] != tag {
+		return nil, fmt.Errorf("unexpected tag 0x%x, want 0x%x", hdr[0], tag)
+	}
+
+	length := int(hdr[1])
+	if hdr[1]&0x80 != 0 {
+		n := int(hdr[1] & 0x7f)
+		if n == 0 || n > 3 {
+			return nil, fmt.Errorf("unsupported length of length %d", n)
+		}
+		bs := make([]byte, n)
+		if _, err := io.ReadFull(r, bs); err != nil {
+			return nil, err
+		}
+		length = 0
+		for _, b := range bs {
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxBERElementLength {
+		return nil, fmt.Errorf("element is too big (%d bytes)", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func splitBERElement(data []byte, tag byte) (body, rest []byte, err error) {
+	r := bytes.NewReader(data)
+	body, err = readBERElement(r, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, data[len(data)-r.Len():], nil
+}
+
+// postgresSSLRequestCode is the PostgreSQL SSLRequest message code, see 'Message Formats' in the protocol docs.
+const postgresSSLRequestCode = 80877103
+
+// startTLSPostgres sends the SSLRequest message, the server answers with a single 'S' byte if it is willing to perform SSL.
+func startTLSPostgres(conn net.Conn) error {
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return err
+	}
+	if resp[0] != 'S' {
+		return errors.New("PostgreSQL server doesn't support SSL")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package x509check
+
+import (
+	"bytes"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	minRSAKeySize   = 2048
+	minECDSAKeySize = 256
+)
+
+func loadTrustStore(path string) (*x509.CertPool, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if fi.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*")); err != nil {
+			return nil, err
+		}
+	}
+
+	pool := x509.NewCertPool()
+	var n int
+	for _, file := range files {
+		if fi, err := os.Stat(file); err != nil || fi.IsDir() {
+			continue
+		}
+		bs, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error on reading '%s': %v", file, err)
+		}
+		if pool.AppendCertsFromPEM(bs) {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no PEM certificates found in '%s'", path)
+	}
+	return pool, nil
+}
+
+// verifyChain verifies the leaf certificate against the trust store using the rest of the certificates as intermediates.
+// It returns the verified chain if the verification succeeds.
+func verifyChain(certs []*x509.Certificate, roots *x509.CertPool) ([]*x509.Certificate, error) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chains[0], nil
+}
+
+// chainLabels labels certificates by their position in the chain: leaf, intermediate_N and root.
+func chainLabels(chain []*x509.Certificate) []string {
+	labels := make([]string, len(chain))
+	for i, cert := range chain {
+		switch {
+		case i == 0:
+			labels[i] = "leaf"
+		case i == len(chain)-1 && isSelfSigned(cert):
+			labels[i] = "root"
+		default:
+			labels[i] = fmt.Sprintf("intermediate_%d", i)
+		}
+	}
+	return labels
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject)
+}
+
+func hasWeakKey(cert *x509.Certificate) bool {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen() < minRSAKeySize
+	case *ecdsa.PublicKey:
+		return key.Params().BitSize < minECDSAKeySize
+	case *dsa.PublicKey:
+		return true
+	}
+	return false
+}
+
+func hasWeakSignature(cert *x509.Certificate) bool {
+	// the signature of a trust anchor is not used in the validation
+	if isSelfSigned(cert) {
+		return false
+	}
+	switch cert.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.DSAWithSHA256, x509.ECDSAWithSHA1:
+		return true
+	}
+	return false
+}
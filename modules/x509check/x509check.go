@@ -1,7 +1,11 @@
 package x509check
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/tlscfg"
@@ -30,11 +34,14 @@ func New() *X509Check {
 			DaysUntilWarn:     14,
 			DaysUntilCritical: 7,
 		},
+		charts: &Charts{},
 	}
 }
 
 type Config struct {
 	Source            string
+	Sources           []string
+	TrustStore        string `yaml:"trust_store"`
 	Timeout           web.Duration
 	tlscfg.TLSConfig  `yaml:",inline"`
 	DaysUntilWarn     int64 `yaml:"days_until_expiration_warning"`
@@ -45,23 +52,86 @@ type Config struct {
 type X509Check struct {
 	module.Base
 	Config `yaml:",inline"`
+
+	charts  *Charts
+	sources []*source
+	roots   *x509.CertPool
+}
+
+// source is a certificate source. Metrics and charts of the sources from the 'sources' list are prefixed with the source id.
+type source struct {
+	name   string
+	prefix string
+	host   string
 	prov   provider
+
+	charted    bool
+	chainCerts map[string]bool
 }
 
 func (x X509Check) validateConfig() error {
-	if x.Source == "" {
+	if x.Source == "" && len(x.Sources) == 0 {
 		return errors.New("source is not set")
 	}
+	seen := make(map[string]bool)
+	for _, src := range x.Sources {
+		if src == "" {
+			return errors.New("'sources' contains an empty source")
+		}
+		if seen[src] {
+			return fmt.Errorf("'sources' contains duplicate source '%s'", src)
+		}
+		seen[src] = true
+	}
 	return nil
 }
 
 func (x *X509Check) initProvider() error {
-	p, err := newProvider(x.Config)
+	if x.Source != "" {
+		src, err := x.newSource(x.Source, "")
+		if err != nil {
+			return err
+		}
+		x.sources = append(x.sources, src)
+	}
+	for _, name := range x.Sources {
+		src, err := x.newSource(name, sourceID(name)+"_")
+		if err != nil {
+			return fmt.Errorf("source '%s': %v", name, err)
+		}
+		x.sources = append(x.sources, src)
+	}
+	return nil
+}
+
+func (x X509Check) newSource(name, prefix string) (*source, error) {
+	p, err := newProvider(x.Config, name)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	src := &source{name: name, prefix: prefix, prov: p, chainCerts: make(map[string]bool)}
+	if u, err := url.Parse(name); err == nil && u.Scheme != "file" {
+		src.host = u.Hostname()
+	}
+	return src, nil
+}
+
+func (x *X509Check) initTrustStore() error {
+	path := x.TrustStore
+	if path == "" {
+		path = x.TLSCA
+	}
+	if path == "" {
+		// nil means the system trust store
+		return nil
 	}
 
-	x.prov = p
+	roots, err := loadTrustStore(path)
+	if err != nil {
+		return err
+	}
+	x.roots = roots
 	return nil
 }
 
@@ -75,6 +145,11 @@ func (x *X509Check) Init() bool {
 		x.Errorf("error on initializing certificate provider: %v", err)
 		return false
 	}
+
+	if err := x.initTrustStore(); err != nil {
+		x.Errorf("error on loading trust store: %v", err)
+		return false
+	}
 	return true
 }
 
@@ -83,10 +158,7 @@ func (x *X509Check) Check() bool {
 }
 
 func (x X509Check) Charts() *Charts {
-	if x.CheckRevocation {
-		return withRevocationCharts.Copy()
-	}
-	return charts.Copy()
+	return x.charts
 }
 
 func (x *X509Check) Collect() map[string]int64 {
@@ -102,3 +174,10 @@ func (x *X509Check) Collect() map[string]int64 {
 }
 
 func (X509Check) Cleanup() {}
+
+func sourceID(name string) string {
+	name = strings.TrimPrefix(name, "file://")
+	return sourceIDReplacer.Replace(strings.Trim(name, "/"))
+}
+
+var sourceIDReplacer = strings.NewReplacer("://", "_", ".", "_", ":", "_", "/", "_")
@@ -1,9 +1,27 @@
 package x509check
 
 import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	stdnet "net"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/netdata/go.d.plugin/pkg/tlscfg"
@@ -33,6 +51,7 @@ func TestX509Check_Init(t *testing.T) {
 		file = iota
 		net
 		smtp
+		startTLS
 	)
 	tests := map[string]struct {
 		config       Config
@@ -55,6 +74,34 @@ func TestX509Check_Init(t *testing.T) {
 			config:       Config{Source: "smtp://smtp.my_mail.org:587"},
 			providerType: smtp,
 		},
+		"ok from imap": {
+			config:       Config{Source: "imap://imap.my_mail.org:143"},
+			providerType: startTLS,
+		},
+		"ok from pop3": {
+			config:       Config{Source: "pop3://pop3.my_mail.org:110"},
+			providerType: startTLS,
+		},
+		"ok from ldap": {
+			config:       Config{Source: "ldap://ldap.example.org:389"},
+			providerType: startTLS,
+		},
+		"ok from postgres": {
+			config:       Config{Source: "postgres://db.example.org:5432"},
+			providerType: startTLS,
+		},
+		"ok from sources": {
+			config:       Config{Sources: []string{"https://example.org:443", "file:///home/me/cert.pem"}},
+			providerType: net,
+		},
+		"duplicate sources": {
+			config: Config{Sources: []string{"https://example.org:443", "https://example.org:443"}},
+			err:    true,
+		},
+		"nonexistent trust store": {
+			config: Config{Source: "https://example.org", TrustStore: "testdata/tls"},
+			err:    true,
+		},
 		"empty source": {
 			config: Config{Source: ""},
 			err:    true},
@@ -81,11 +128,13 @@ func TestX509Check_Init(t *testing.T) {
 				var typeOK bool
 				switch test.providerType {
 				case file:
-					_, typeOK = x509Check.prov.(*fromFile)
+					_, typeOK = x509Check.sources[0].prov.(*fromFile)
 				case net:
-					_, typeOK = x509Check.prov.(*fromNet)
+					_, typeOK = x509Check.sources[0].prov.(*fromNet)
 				case smtp:
-					_, typeOK = x509Check.prov.(*fromSMTP)
+					_, typeOK = x509Check.sources[0].prov.(*fromSMTP)
+				case startTLS:
+					_, typeOK = x509Check.sources[0].prov.(*fromStartTLS)
 				}
 
 				assert.True(t, typeOK)
@@ -96,21 +145,21 @@ func TestX509Check_Init(t *testing.T) {
 
 func TestX509Check_Check(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{certs: []*x509.Certificate{{}}}
+	x509Check.sources = newMockSources(&mockProvider{certs: []*x509.Certificate{{}}})
 
 	assert.True(t, x509Check.Check())
 }
 
 func TestX509Check_Check_ReturnsFalseOnProviderError(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{err: true}
+	x509Check.sources = newMockSources(&mockProvider{err: true})
 
 	assert.False(t, x509Check.Check())
 }
 
 func TestX509Check_Collect(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{certs: []*x509.Certificate{{}}}
+	x509Check.sources = newMockSources(&mockProvider{certs: []*x509.Certificate{{}}})
 
 	collected := x509Check.Collect()
 
@@ -120,19 +169,280 @@ func TestX509Check_Collect(t *testing.T) {
 
 func TestX509Check_Collect_ReturnsNilOnProviderError(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{err: true}
+	x509Check.sources = newMockSources(&mockProvider{err: true})
 
 	assert.Nil(t, x509Check.Collect())
 }
 
 func TestX509Check_Collect_ReturnsNilOnZeroCertificates(t *testing.T) {
 	x509Check := New()
-	x509Check.prov = &mockProvider{certs: []*x509.Certificate{}}
+	x509Check.sources = newMockSources(&mockProvider{certs: []*x509.Certificate{}})
 	mx := x509Check.Collect()
 
 	assert.Nil(t, mx)
 }
 
+func TestX509Check_Collect_Validation(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := map[string]struct {
+		leaf       *testCert
+		chain      func(leaf *testCert) []*x509.Certificate
+		ocspStaple bool
+		config     func(cfg *Config)
+		expected   map[string]int64
+		wantChain  []string
+	}{
+		"valid chain": {
+			leaf:       pki.newLeaf(t, "127.0.0.1", false),
+			ocspStaple: true,
+			expected: map[string]int64{
+				"chain_invalid":     0,
+				"hostname_mismatch": 0,
+				"weak_key":          0,
+				"weak_signature":    0,
+				"ocsp_stapled":      1,
+			},
+			wantChain: []string{"leaf", "intermediate_1", "root"},
+		},
+		"untrusted root": {
+			leaf:   pki.newLeaf(t, "127.0.0.1", false),
+			config: func(cfg *Config) { cfg.TrustStore = "" },
+			expected: map[string]int64{
+				"chain_invalid":     1,
+				"hostname_mismatch": 0,
+				"weak_key":          0,
+				"weak_signature":    0,
+				"ocsp_stapled":      0,
+			},
+			wantChain: []string{"leaf", "intermediate_1"},
+		},
+		"missing intermediate": {
+			leaf:  pki.newLeaf(t, "127.0.0.1", false),
+			chain: func(leaf *testCert) []*x509.Certificate { return []*x509.Certificate{leaf.cert} },
+			expected: map[string]int64{
+				"chain_invalid":     1,
+				"hostname_mismatch": 0,
+				"weak_key":          0,
+				"weak_signature":    0,
+				"ocsp_stapled":      0,
+			},
+			wantChain: []string{"leaf"},
+		},
+		"hostname mismatch": {
+			leaf: pki.newLeaf(t, "example.org", false),
+			expected: map[string]int64{
+				"chain_invalid":     0,
+				"hostname_mismatch": 1,
+				"weak_key":          0,
+				"weak_signature":    0,
+				"ocsp_stapled":      0,
+			},
+			wantChain: []string{"leaf", "intermediate_1", "root"},
+		},
+		"weak key": {
+			leaf: pki.newLeaf(t, "127.0.0.1", true),
+			expected: map[string]int64{
+				"chain_invalid":     0,
+				"hostname_mismatch": 0,
+				"weak_key":          1,
+				"weak_signature":    0,
+				"ocsp_stapled":      0,
+			},
+			wantChain: []string{"leaf", "intermediate_1", "root"},
+		},
+		"skip chain and hostname validation": {
+			leaf:   pki.newLeaf(t, "example.org", false),
+			config: func(cfg *Config) { cfg.TrustStore, cfg.InsecureSkipVerify = "", true },
+			expected: map[string]int64{
+				"weak_key":       0,
+				"weak_signature": 0,
+				"ocsp_stapled":   0,
+			},
+			wantChain: []string{"leaf", "intermediate_1"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			chain := []*x509.Certificate{test.leaf.cert, pki.intermediate.cert}
+			if test.chain != nil {
+				chain = test.chain(test.leaf)
+			}
+			addr := newTestTLSServer(t, test.leaf.tlsCertificate(chain, test.ocspStaple), nil)
+
+			x509Check := New()
+			x509Check.Source = "tcp://" + addr
+			x509Check.TrustStore = pki.rootFile
+			if test.config != nil {
+				test.config(&x509Check.Config)
+			}
+			require.True(t, x509Check.Init())
+
+			mx := x509Check.Collect()
+			require.NotNil(t, mx)
+
+			for k, v := range test.expected {
+				assert.Equalf(t, v, mx[k], "metric '%s'", k)
+			}
+			for _, k := range []string{"chain_invalid", "hostname_mismatch"} {
+				_, ok := test.expected[k]
+				assert.Equalf(t, ok, x509Check.Charts().Get("validation_status").HasDim(k), "dim '%s'", k)
+			}
+			chart := x509Check.Charts().Get("chain_time_until_expiration")
+			require.NotNil(t, chart)
+			require.Len(t, chart.Dims, len(test.wantChain))
+			for i, label := range test.wantChain {
+				assert.Equal(t, "chain_"+label+"_expiry", chart.Dims[i].ID)
+			}
+			assert.InDelta(t, time.Until(test.leaf.cert.NotAfter).Seconds(), mx["chain_leaf_expiry"], 5)
+			ensureCollectedHasAllChartsDimsVarsIDs(t, x509Check, mx)
+		})
+	}
+}
+
+func TestX509Check_Collect_StartTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.newLeaf(t, "127.0.0.1", false)
+	cert := leaf.tlsCertificate([]*x509.Certificate{leaf.cert, pki.intermediate.cert}, false)
+
+	tests := map[string]func(conn stdnet.Conn) error{
+		"imap": func(conn stdnet.Conn) error {
+			r := bufio.NewReader(conn)
+			_, _ = io.WriteString(conn, "* OK [CAPABILITY IMAP4rev1 STARTTLS] ready\r\n")
+			if line, _ := r.ReadString('\n'); line != "a001 STARTTLS\r\n" {
+				return fmt.Errorf("unexpected command '%s'", line)
+			}
+			_, err := io.WriteString(conn, "a001 OK Begin TLS negotiation now\r\n")
+			return err
+		},
+		"pop3": func(conn stdnet.Conn) error {
+			r := bufio.NewReader(conn)
+			_, _ = io.WriteString(conn, "+OK POP3 ready\r\n")
+			if line, _ := r.ReadString('\n'); line != "STLS\r\n" {
+				return fmt.Errorf("unexpected command '%s'", line)
+			}
+			_, err := io.WriteString(conn, "+OK Begin TLS negotiation\r\n")
+			return err
+		},
+		"ldap": func(conn stdnet.Conn) error {
+			req, err := readBERElement(conn, 0x30)
+			if err != nil {
+				return err
+			}
+			if !bytes.Contains(req, []byte(ldapStartTLSOID)) {
+				return errors.New("not a StartTLS request")
+			}
+			_, err = conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00})
+			return err
+		},
+		"postgres": func(conn stdnet.Conn) error {
+			req := make([]byte, 8)
+			if _, err := io.ReadFull(conn, req); err != nil {
+				return err
+			}
+			if binary.BigEndian.Uint32(req[4:]) != postgresSSLRequestCode {
+				return errors.New("not a SSLRequest")
+			}
+			_, err := conn.Write([]byte{'S'})
+			return err
+		},
+	}
+
+	for scheme, startTLS := range tests {
+		t.Run(scheme, func(t *testing.T) {
+			addr := newTestTLSServer(t, cert, startTLS)
+
+			x509Check := New()
+			x509Check.Source = scheme + "://" + addr
+			x509Check.TrustStore = pki.rootFile
+			require.True(t, x509Check.Init())
+
+			mx := x509Check.Collect()
+			require.NotNil(t, mx)
+
+			assert.Equal(t, int64(0), mx["chain_invalid"])
+			assert.Equal(t, int64(0), mx["hostname_mismatch"])
+			assert.Contains(t, mx, "chain_root_expiry")
+			ensureCollectedHasAllChartsDimsVarsIDs(t, x509Check, mx)
+		})
+	}
+}
+
+func TestX509Check_Collect_MultipleSources(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.newLeaf(t, "127.0.0.1", false)
+	addr := newTestTLSServer(t, leaf.tlsCertificate([]*x509.Certificate{leaf.cert, pki.intermediate.cert}, false), nil)
+
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := ln.Addr().String()
+	_ = ln.Close()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.pem")
+	require.NoError(t, ioutil.WriteFile(bundle, append(leaf.pem, pki.intermediate.pem...), 0644))
+
+	x509Check := New()
+	x509Check.Sources = []string{"tcp://" + addr, "file://" + bundle, "tcp://" + closedAddr}
+	x509Check.TrustStore = pki.rootFile
+	x509Check.CheckRevocation = true
+	require.True(t, x509Check.Init())
+
+	mx := x509Check.Collect()
+	require.NotNil(t, mx)
+
+	netID := sourceID("tcp://"+addr) + "_"
+	fileID := sourceID("file://"+bundle) + "_"
+	closedID := sourceID("tcp://"+closedAddr) + "_"
+
+	assert.Equal(t, int64(0), mx[netID+"chain_invalid"])
+	assert.Equal(t, int64(0), mx[netID+"hostname_mismatch"])
+	assert.Contains(t, mx, netID+"ocsp_stapled")
+	assert.Equal(t, int64(0), mx[fileID+"chain_invalid"])
+	assert.NotContains(t, mx, fileID+"hostname_mismatch")
+	assert.NotContains(t, mx, fileID+"ocsp_stapled")
+	assert.Contains(t, mx, fileID+"chain_root_expiry")
+
+	for _, chart := range *x509Check.Charts() {
+		assert.NotContains(t, chart.ID, closedID)
+	}
+	assert.True(t, x509Check.Charts().Has(netID+"ocsp_stapling"))
+	assert.True(t, x509Check.Charts().Has(netID+"revocation_status"))
+	assert.True(t, x509Check.Charts().Has(fileID+"time_until_expiration"))
+	assert.False(t, x509Check.Charts().Has(fileID+"ocsp_stapling"))
+	assert.False(t, x509Check.Charts().Get(fileID+"validation_status").HasDim(fileID+"hostname_mismatch"))
+	for _, chart := range *x509Check.Charts() {
+		for _, dim := range chart.Dims {
+			if dim.ID == netID+"revoked" || dim.ID == fileID+"revoked" {
+				// revocation status is unknown for certificates without OCSP/CRL
+				continue
+			}
+			_, ok := mx[dim.ID]
+			assert.Truef(t, ok, "collected metrics has no data for dim '%s' chart '%s'", dim.ID, chart.ID)
+		}
+	}
+}
+
+func TestHasWeakSignature(t *testing.T) {
+	tests := map[string]struct {
+		cert *x509.Certificate
+		weak bool
+	}{
+		"SHA256 with RSA": {cert: &x509.Certificate{SignatureAlgorithm: x509.SHA256WithRSA, RawIssuer: []byte("ca")}},
+		"SHA1 with RSA":   {cert: &x509.Certificate{SignatureAlgorithm: x509.SHA1WithRSA, RawIssuer: []byte("ca")}, weak: true},
+		"MD5 with RSA":    {cert: &x509.Certificate{SignatureAlgorithm: x509.MD5WithRSA, RawIssuer: []byte("ca")}, weak: true},
+		"self-signed SHA1 with RSA": {
+			cert: &x509.Certificate{SignatureAlgorithm: x509.SHA1WithRSA, RawIssuer: []byte("ca"), RawSubject: []byte("ca")},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.weak, hasWeakSignature(test.cert))
+		})
+	}
+}
+
 func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, x509Check *X509Check, collected map[string]int64) {
 	for _, chart := range *x509Check.Charts() {
 		for _, dim := range chart.Dims {
@@ -146,6 +456,10 @@ func ensureCollectedHasAllChartsDimsVarsIDs(t *testing.T, x509Check *X509Check,
 	}
 }
 
+func newMockSources(p provider) []*source {
+	return []*source{{name: "mock", prov: p, chainCerts: make(map[string]bool)}}
+}
+
 type mockProvider struct {
 	certs []*x509.Certificate
 	err   bool
@@ -157,3 +471,124 @@ func (m mockProvider) certificates() ([]*x509.Certificate, error) {
 	}
 	return m.certs, nil
 }
+
+type testCert struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+	pem  []byte
+}
+
+func (c testCert) tlsCertificate(chain []*x509.Certificate, ocspStaple bool) tls.Certificate {
+	cert := tls.Certificate{PrivateKey: c.key, Leaf: c.cert}
+	for _, v := range chain {
+		cert.Certificate = append(cert.Certificate, v.Raw)
+	}
+	if ocspStaple {
+		cert.OCSPStaple = []byte("mock OCSP response")
+	}
+	return cert
+}
+
+type testPKI struct {
+	root         *testCert
+	intermediate *testCert
+	rootFile     string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	root := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test root"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, false)
+	intermediate := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test intermediate"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root, false)
+
+	rootFile := filepath.Join(t.TempDir(), "root.pem")
+	require.NoError(t, ioutil.WriteFile(rootFile, root.pem, 0644))
+
+	return &testPKI{root: root, intermediate: intermediate, rootFile: rootFile}
+}
+
+func (p testPKI) newLeaf(t *testing.T, host string, weakKey bool) *testCert {
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: host},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := stdnet.ParseIP(host); ip != nil {
+		tmpl.IPAddresses = []stdnet.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{host}
+	}
+	return newTestCert(t, tmpl, p.intermediate, weakKey)
+}
+
+var testSerial int64
+
+func newTestCert(t *testing.T, tmpl *x509.Certificate, issuer *testCert, weakKey bool) *testCert {
+	var key crypto.Signer
+	var err error
+	if weakKey {
+		key, err = rsa.GenerateKey(rand.Reader, 1024)
+	} else {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	require.NoError(t, err)
+
+	testSerial++
+	tmpl.SerialNumber = big.NewInt(testSerial)
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour * 24 * time.Duration(30*testSerial))
+
+	parent, signer := tmpl, key
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// newTestTLSServer starts a TLS server that serves a single connection at a time.
+// startTLS, if set, is called before the TLS handshake.
+func newTestTLSServer(t *testing.T, cert tls.Certificate, startTLS func(conn stdnet.Conn) error) string {
+	ln, err := stdnet.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.SetDeadline(time.Now().Add(time.Second * 2))
+			if startTLS != nil {
+				if err := startTLS(conn); err != nil {
+					_ = conn.Close()
+					continue
+				}
+			}
+			tlsConn := tls.Server(conn, cfg)
+			_ = tlsConn.Handshake()
+			_ = tlsConn.Close()
+		}
+	}()
+
+	return ln.Addr().String()
+}
@@ -0,0 +1,113 @@
+package chartdef
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+// Precision is used to keep the fractional part of the collected values.
+// Dimensions created with Dim.New expect the values to be multiplied by it.
+const Precision = 1000
+
+type (
+	// Chart is the common part of a user defined chart configuration.
+	// Modules embed it (yaml:",inline") and add the dimensions list.
+	Chart struct {
+		ID      string `yaml:"id"`
+		Title   string `yaml:"title"`
+		Units   string `yaml:"units"`
+		Family  string `yaml:"family"`
+		Context string `yaml:"context"`
+		Type    string `yaml:"type"`
+	}
+	// Dim is the common part of a user defined dimension configuration.
+	// Modules embed it (yaml:",inline") and add the value source (path, key, column, etc.).
+	Dim struct {
+		Name       string `yaml:"name"`
+		Algorithm  string `yaml:"algorithm"`
+		Multiplier int    `yaml:"multiplier"`
+		Divisor    int    `yaml:"divisor"`
+	}
+)
+
+// Validate checks the chart id and type.
+func (c Chart) Validate() error {
+	if c.ID == "" {
+		return errors.New("'id' not set")
+	}
+	if c.Type != "" && module.ChartType(c.Type).String() == "" {
+		return fmt.Errorf("chart '%s': unknown chart type '%s'", c.ID, c.Type)
+	}
+	return nil
+}
+
+// New creates a chart without dimensions. Not set title, units and family are defaulted,
+// the context is the ctxPrefix followed by the chart id unless set explicitly.
+func (c Chart) New(ctxPrefix string) *module.Chart {
+	chart := &module.Chart{
+		ID:    c.ID,
+		Title: c.Title,
+		Units: c.Units,
+		Fam:   c.Family,
+		Ctx:   c.Context,
+		Type:  module.Line,
+	}
+	if c.Type != "" {
+		chart.Type = module.ChartType(c.Type)
+	}
+	if chart.Title == "" {
+		chart.Title = c.ID
+	}
+	if chart.Units == "" {
+		chart.Units = "value"
+	}
+	if chart.Fam == "" {
+		chart.Fam = c.ID
+	}
+	if chart.Ctx == "" {
+		chart.Ctx = ctxPrefix + c.ID
+	}
+	return chart
+}
+
+// Init checks the algorithm and the divisor, the multiplier and the divisor default to 1.
+func (d *Dim) Init() error {
+	switch d.Algorithm {
+	case "", "absolute", "incremental", "percentage-of-absolute-row", "percentage-of-incremental-row":
+	default:
+		return fmt.Errorf("unknown algorithm '%s'", d.Algorithm)
+	}
+	if d.Multiplier == 0 {
+		d.Multiplier = 1
+	}
+	if d.Divisor == 0 {
+		d.Divisor = 1
+	}
+	if d.Divisor < 0 {
+		return fmt.Errorf("bad divisor: %d", d.Divisor)
+	}
+	return nil
+}
+
+// New creates a dimension for values multiplied by Precision. Not set algorithm is absolute.
+func (d Dim) New(id, name string) *module.Dim {
+	dim := &module.Dim{
+		ID:   id,
+		Name: name,
+		Mul:  d.Multiplier,
+		Div:  d.Divisor * Precision,
+	}
+	switch d.Algorithm {
+	case "incremental":
+		dim.Algo = module.Incremental
+	case "percentage-of-absolute-row":
+		dim.Algo = module.PercentOfAbsolute
+	case "percentage-of-incremental-row":
+		dim.Algo = module.PercentOfIncremental
+	default:
+		dim.Algo = module.Absolute
+	}
+	return dim
+}
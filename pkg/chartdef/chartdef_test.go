@@ -0,0 +1,115 @@
+package chartdef
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChart_Validate(t *testing.T) {
+	tests := map[string]struct {
+		chart   Chart
+		wantErr bool
+	}{
+		"valid":        {chart: Chart{ID: "id", Type: "stacked"}},
+		"default type": {chart: Chart{ID: "id"}},
+		"no id":        {chart: Chart{Type: "line"}, wantErr: true},
+		"unknown type": {chart: Chart{ID: "id", Type: "pie"}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.chart.Validate()
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChart_New(t *testing.T) {
+	tests := map[string]struct {
+		chart    Chart
+		expected *module.Chart
+	}{
+		"defaults": {
+			chart: Chart{ID: "requests"},
+			expected: &module.Chart{
+				ID: "requests", Title: "requests", Units: "value", Fam: "requests", Ctx: "test.requests", Type: module.Line,
+			},
+		},
+		"all set": {
+			chart: Chart{ID: "requests", Title: "Requests", Units: "requests/s", Family: "http", Context: "app.requests", Type: "area"},
+			expected: &module.Chart{
+				ID: "requests", Title: "Requests", Units: "requests/s", Fam: "http", Ctx: "app.requests", Type: module.Area,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.chart.New("test."))
+		})
+	}
+}
+
+func TestDim_Init(t *testing.T) {
+	tests := map[string]struct {
+		dim      Dim
+		expected Dim
+		wantErr  bool
+	}{
+		"defaults":          {dim: Dim{}, expected: Dim{Multiplier: 1, Divisor: 1}},
+		"set":               {dim: Dim{Algorithm: "incremental", Multiplier: -8, Divisor: 1000}, expected: Dim{Algorithm: "incremental", Multiplier: -8, Divisor: 1000}},
+		"unknown algorithm": {dim: Dim{Algorithm: "delta"}, wantErr: true},
+		"negative divisor":  {dim: Dim{Divisor: -1}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.dim.Init()
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, test.dim)
+			}
+		})
+	}
+}
+
+func TestDim_New(t *testing.T) {
+	tests := map[string]struct {
+		dim      Dim
+		expected *module.Dim
+	}{
+		"absolute": {
+			dim:      Dim{Multiplier: 1, Divisor: 1},
+			expected: &module.Dim{ID: "id", Name: "name", Algo: module.Absolute, Mul: 1, Div: Precision},
+		},
+		"incremental": {
+			dim:      Dim{Algorithm: "incremental", Multiplier: 8, Divisor: 1000},
+			expected: &module.Dim{ID: "id", Name: "name", Algo: module.Incremental, Mul: 8, Div: 1000 * Precision},
+		},
+		"percentage-of-absolute-row": {
+			dim:      Dim{Algorithm: "percentage-of-absolute-row", Multiplier: 1, Divisor: 1},
+			expected: &module.Dim{ID: "id", Name: "name", Algo: module.PercentOfAbsolute, Mul: 1, Div: Precision},
+		},
+		"percentage-of-incremental-row": {
+			dim:      Dim{Algorithm: "percentage-of-incremental-row", Multiplier: 1, Divisor: 1},
+			expected: &module.Dim{ID: "id", Name: "name", Algo: module.PercentOfIncremental, Mul: 1, Div: Precision},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.dim.New("id", "name"))
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Package jsonpath implements a subset of JSONPath to find values in decoded JSON documents.
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression.
+// Only a subset of the syntax is supported:
+//
+//	$            the root element (optional)
+//	.key         the child element
+//	['key']      the child element (bracket notation, for keys with dots or spaces)
+//	[N]          the array element
+//	.* and [*]   all the child/array elements (wildcard)
+type Path []pathStep
+
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Match is a value found by the path.
+// Keys are the object keys (array indexes) the wildcard steps matched, in path order.
+type Match struct {
+	Keys  []string
+	Value interface{}
+}
+
+// Parse compiles the JSONPath expression.
+func Parse(expr string) (Path, error) {
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return nil, errors.New("empty path")
+	}
+	s = strings.TrimPrefix(s, "$")
+	if s != "" && s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	var path Path
+	for s != "" {
+		var step pathStep
+		var err error
+		switch s[0] {
+		case '.':
+			step, s, err = parseDotStep(s[1:])
+		case '[':
+			step, s, err = parseBracketStep(s[1:])
+		default:
+			err = fmt.Errorf("unexpected character '%c'", s[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bad path '%s': %v", expr, err)
+		}
+		path = append(path, step)
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("bad path '%s': no elements", expr)
+	}
+	return path, nil
+}
+
+func parseDotStep(s string) (pathStep, string, error) {
+	end := strings.IndexAny(s, ".[")
+	if end == -1 {
+		end = len(s)
+	}
+	key := s[:end]
+	if key == "" {
+		return pathStep{}, "", errors.New("empty key")
+	}
+	if key == "*" {
+		return pathStep{wildcard: true}, s[end:], nil
+	}
+	return pathStep{key: key}, s[end:], nil
+}
+
+func parseBracketStep(s string) (pathStep, string, error) {
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		end := strings.IndexByte(s[1:], quote)
+		if end == -1 {
+			return pathStep{}, "", errors.New("unterminated quoted key")
+		}
+		key, rest := s[1:end+1], s[end+2:]
+		if !strings.HasPrefix(rest, "]") {
+			return pathStep{}, "", errors.New("missing ']'")
+		}
+		return pathStep{key: key}, rest[1:], nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end == -1 {
+		return pathStep{}, "", errors.New("missing ']'")
+	}
+	v, rest := strings.TrimSpace(s[:end]), s[end+1:]
+	if v == "*" {
+		return pathStep{wildcard: true}, rest, nil
+	}
+	idx, err := strconv.Atoi(v)
+	if err != nil || idx < 0 {
+		return pathStep{}, "", fmt.Errorf("bad array index '%s'", v)
+	}
+	return pathStep{index: idx, isIndex: true}, rest, nil
+}
+
+// HasWildcard returns true if the path can match more than one value.
+func (p Path) HasWildcard() bool {
+	for _, step := range p {
+		if step.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns all the values the path matches in the document.
+// Object keys matched by a wildcard are visited in sorted order.
+func (p Path) Find(doc interface{}) []Match {
+	var matches []Match
+	p.walk(doc, nil, &matches)
+	return matches
+}
+
+func (p Path) walk(v interface{}, keys []string, matches *[]Match) {
+	if len(p) == 0 {
+		*matches = append(*matches, Match{Keys: keys, Value: v})
+		return
+	}
+
+	step, rest := p[0], p[1:]
+	switch v := v.(type) {
+	case map[string]interface{}:
+		switch {
+		case step.wildcard:
+			names := make([]string, 0, len(v))
+			for name := range v {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				rest.walk(v[name], appendKey(keys, name), matches)
+			}
+		case !step.isIndex:
+			if child, ok := v[step.key]; ok {
+				rest.walk(child, keys, matches)
+			}
+		}
+	case []interface{}:
+		switch {
+		case step.wildcard:
+			for i, child := range v {
+				rest.walk(child, appendKey(keys, strconv.Itoa(i)), matches)
+			}
+		case step.isIndex:
+			if step.index < len(v) {
+				rest.walk(v[step.index], keys, matches)
+			}
+		}
+	}
+}
+
+func appendKey(keys []string, key string) []string {
+	// copy to not share the backing array between the branches
+	return append(keys[:len(keys):len(keys)], key)
+}
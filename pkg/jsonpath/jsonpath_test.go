@@ -0,0 +1,119 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `{
+  "requests": {"total": 1500, "failed": 12},
+  "queues": {
+    "email": {"size": 10, "workers": 2},
+    "sms": {"size": 3.5, "workers": 1}
+  },
+  "pools": [
+    {"name": "main", "active": 4},
+    {"name": "batch", "active": 1}
+  ],
+  "memory.stats": {"rss": 1048576}
+}`
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		expr     string
+		expected Path
+		wantErr  bool
+	}{
+		"root child":         {expr: "$.uptime", expected: Path{{key: "uptime"}}},
+		"no root":            {expr: "requests.total", expected: Path{{key: "requests"}, {key: "total"}}},
+		"bracket key":        {expr: "$['memory.stats'].rss", expected: Path{{key: "memory.stats"}, {key: "rss"}}},
+		"double quoted key":  {expr: `$["a b"]`, expected: Path{{key: "a b"}}},
+		"array index":        {expr: "$.pools[1].active", expected: Path{{key: "pools"}, {index: 1, isIndex: true}, {key: "active"}}},
+		"wildcards":          {expr: "$.queues.*.size", expected: Path{{key: "queues"}, {wildcard: true}, {key: "size"}}},
+		"bracket wildcard":   {expr: "$.pools[*]", expected: Path{{key: "pools"}, {wildcard: true}}},
+		"empty":              {expr: "", wantErr: true},
+		"root only":          {expr: "$", wantErr: true},
+		"empty key":          {expr: "$..a", wantErr: true},
+		"unterminated quote": {expr: "$['a]", wantErr: true},
+		"missing bracket":    {expr: "$.a[1", wantErr: true},
+		"negative index":     {expr: "$.a[-1]", wantErr: true},
+		"bad index":          {expr: "$.a[x]", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := Parse(test.expr)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, path)
+			}
+		})
+	}
+}
+
+func TestPath_Find(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(testDoc))
+	dec.UseNumber()
+	var doc interface{}
+	require.NoError(t, dec.Decode(&doc))
+
+	tests := map[string]struct {
+		expr     string
+		expected []Match
+	}{
+		"child": {
+			expr:     "$.requests.total",
+			expected: []Match{{Value: json.Number("1500")}},
+		},
+		"bracket key": {
+			expr:     "$['memory.stats'].rss",
+			expected: []Match{{Value: json.Number("1048576")}},
+		},
+		"array index": {
+			expr:     "$.pools[0].active",
+			expected: []Match{{Value: json.Number("4")}},
+		},
+		"object wildcard": {
+			expr: "$.queues.*.size",
+			expected: []Match{
+				{Keys: []string{"email"}, Value: json.Number("10")},
+				{Keys: []string{"sms"}, Value: json.Number("3.5")},
+			},
+		},
+		"nested wildcards": {
+			expr: "$.queues.*.*",
+			expected: []Match{
+				{Keys: []string{"email", "size"}, Value: json.Number("10")},
+				{Keys: []string{"email", "workers"}, Value: json.Number("2")},
+				{Keys: []string{"sms", "size"}, Value: json.Number("3.5")},
+				{Keys: []string{"sms", "workers"}, Value: json.Number("1")},
+			},
+		},
+		"array wildcard": {
+			expr: "$.pools[*].active",
+			expected: []Match{
+				{Keys: []string{"0"}, Value: json.Number("4")},
+				{Keys: []string{"1"}, Value: json.Number("1")},
+			},
+		},
+		"not found":          {expr: "$.requests.unknown"},
+		"index out of range": {expr: "$.pools[5]"},
+		"index on object":    {expr: "$.requests[0]"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := Parse(test.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, path.Find(doc))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package logs
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+const (
+	DefaultBatchBufferSize = 256 * 1024
+	maxBatchRowSize        = 4 * 1024 * 1024
+	maxEmptyReads          = 100
+	minRowsPerShard        = 256
+)
+
+// BatchReader reads newline-delimited rows in batches.
+// All rows of a batch share the reader buffer, they are valid until the next ReadBatch call.
+type BatchReader struct {
+	r     io.Reader
+	buf   []byte
+	start int // start of the unconsumed data
+	end   int // end of the unconsumed data
+	rows  [][]byte
+}
+
+func NewBatchReader(r io.Reader, bufSize int) *BatchReader {
+	if bufSize <= 0 {
+		bufSize = DefaultBatchBufferSize
+	}
+	return &BatchReader{r: r, buf: make([]byte, bufSize)}
+}
+
+// ReadBatch reads as many complete rows as fit into the buffer. The rows don't include the trailing '\n'.
+// A partial row at the end of the input is returned as is, the same way bufio.Reader.ReadSlice does.
+// It returns io.EOF along with the last rows when the input is exhausted.
+func (b *BatchReader) ReadBatch() ([][]byte, error) {
+	b.rows = b.rows[:0]
+	// the previous batch rows are not used anymore, move the partial row to the beginning
+	if b.start > 0 {
+		b.end = copy(b.buf, b.buf[b.start:b.end])
+		b.start = 0
+	}
+
+	var emptyReads int
+	for {
+		if i := bytes.IndexByte(b.buf[b.start:b.end], '\n'); i != -1 {
+			b.rows = append(b.rows, b.buf[b.start:b.start+i])
+			b.start += i + 1
+			continue
+		}
+
+		if b.end == len(b.buf) {
+			if len(b.rows) > 0 {
+				return b.rows, nil
+			}
+			// a row doesn't fit into the buffer
+			if len(b.buf) >= maxBatchRowSize {
+				b.rows = append(b.rows, b.buf[b.start:b.end])
+				b.start = b.end
+				return b.rows, nil
+			}
+			b.grow()
+		}
+
+		n, err := b.r.Read(b.buf[b.end:])
+		b.end += n
+		if err == io.EOF {
+			b.flush()
+		}
+		if err != nil {
+			return b.rows, err
+		}
+		if n == 0 {
+			if emptyReads++; emptyReads >= maxEmptyReads {
+				return b.rows, io.ErrNoProgress
+			}
+		}
+	}
+}
+
+// flush adds all the buffered data to the batch, including the partial row.
+func (b *BatchReader) flush() {
+	for b.start < b.end {
+		i := bytes.IndexByte(b.buf[b.start:b.end], '\n')
+		if i == -1 {
+			i = b.end - b.start
+		}
+		b.rows = append(b.rows, b.buf[b.start:b.start+i])
+		b.start += i + 1
+	}
+	if b.start > b.end {
+		b.start = b.end
+	}
+}
+
+func (b *BatchReader) grow() {
+	buf := make([]byte, len(b.buf)*2)
+	b.end = copy(buf, b.buf[b.start:b.end])
+	b.start = 0
+	b.buf = buf
+}
+
+// ProcessSharded splits rows into up to numShards contiguous shards and calls fn for every shard in its own goroutine.
+// Small batches use fewer shards, a single shard is processed in the calling goroutine.
+// It returns when all the shards are processed.
+func ProcessSharded(rows [][]byte, numShards int, fn func(shard int, rows [][]byte)) {
+	if n := len(rows) / minRowsPerShard; n < numShards {
+		numShards = n
+	}
+	if numShards <= 1 {
+		if len(rows) > 0 {
+			fn(0, rows)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	size := (len(rows) + numShards - 1) / numShards
+	for shard := 0; shard < numShards; shard++ {
+		lo, hi := shard*size, (shard+1)*size
+		if hi > len(rows) {
+			hi = len(rows)
+		}
+		if lo >= hi {
+			break
+		}
+		wg.Add(1)
+		go func(shard int, rows [][]byte) {
+			defer wg.Done()
+			fn(shard, rows)
+		}(shard, rows[lo:hi])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,137 @@
+package logs
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReader_ReadBatch(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		bufSize int
+		oneByte bool
+		want    []string
+	}{
+		"empty input":          {input: "", bufSize: 16},
+		"rows":                 {input: "a\nbb\nccc\n", bufSize: 16, want: []string{"a", "bb", "ccc"}},
+		"empty rows":           {input: "a\n\nb\n", bufSize: 16, want: []string{"a", "", "b"}},
+		"partial last row":     {input: "a\nbb\nccc", bufSize: 16, want: []string{"a", "bb", "ccc"}},
+		"rows span batches":    {input: "aaaa\nbbbb\ncccc\ndddd\n", bufSize: 8, want: []string{"aaaa", "bbbb", "cccc", "dddd"}},
+		"row longer buffer":    {input: "a\nbbbbbbbbbbbbbbbbbbbb\nc\n", bufSize: 4, want: []string{"a", "bbbbbbbbbbbbbbbbbbbb", "c"}},
+		"one byte reads":       {input: "a\nbb\nccc\n", bufSize: 4, oneByte: true, want: []string{"a", "bb", "ccc"}},
+		"one byte reads tail":  {input: "a\nbb\nccc", bufSize: 4, oneByte: true, want: []string{"a", "bb", "ccc"}},
+		"default buffer size":  {input: "a\nb\n", want: []string{"a", "b"}},
+		"carriage return kept": {input: "a\r\nb\n", bufSize: 16, want: []string{"a\r", "b"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var r io.Reader = strings.NewReader(test.input)
+			if test.oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			br := NewBatchReader(r, test.bufSize)
+
+			var rows []string
+			for {
+				batch, err := br.ReadBatch()
+				for _, row := range batch {
+					rows = append(rows, string(row))
+				}
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.want, rows)
+		})
+	}
+}
+
+func TestBatchReader_ReadBatch_ContinuesAfterEOF(t *testing.T) {
+	var sb strings.Builder
+	br := NewBatchReader(&appendReader{sb: &sb}, 16)
+
+	sb.WriteString("a\nb\n")
+	rows, err := br.ReadBatch()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"a", "b"}, rowsToStrings(rows))
+
+	rows, err = br.ReadBatch()
+	assert.Equal(t, io.EOF, err)
+	assert.Empty(t, rows)
+
+	sb.WriteString("c\n")
+	rows, err = br.ReadBatch()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"c"}, rowsToStrings(rows))
+}
+
+func TestProcessSharded(t *testing.T) {
+	tests := map[string]struct {
+		rows       int
+		shards     int
+		wantShards int
+	}{
+		"no rows":                {rows: 0, shards: 4, wantShards: 0},
+		"single shard":           {rows: minRowsPerShard * 4, shards: 1, wantShards: 1},
+		"small batch":            {rows: minRowsPerShard - 1, shards: 4, wantShards: 1},
+		"limited by batch size":  {rows: minRowsPerShard * 2, shards: 4, wantShards: 2},
+		"limited by shards":      {rows: minRowsPerShard * 8, shards: 4, wantShards: 4},
+		"uneven rows per shards": {rows: minRowsPerShard*3 + 1, shards: 3, wantShards: 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rows := make([][]byte, test.rows)
+			for i := range rows {
+				rows[i] = []byte{byte(i)}
+			}
+
+			var mu sync.Mutex
+			seen := make(map[int]int)
+			ProcessSharded(rows, test.shards, func(shard int, rows [][]byte) {
+				mu.Lock()
+				defer mu.Unlock()
+				seen[shard] += len(rows)
+			})
+
+			var total int
+			for _, n := range seen {
+				total += n
+			}
+			assert.Len(t, seen, test.wantShards)
+			assert.Equal(t, test.rows, total)
+		})
+	}
+}
+
+type appendReader struct {
+	sb  *strings.Builder
+	off int
+}
+
+func (r *appendReader) Read(p []byte) (int, error) {
+	s := r.sb.String()
+	if r.off >= len(s) {
+		return 0, io.EOF
+	}
+	n := copy(p, s[r.off:])
+	r.off += n
+	return n, nil
+}
+
+func rowsToStrings(rows [][]byte) []string {
+	var s []string
+	for _, row := range rows {
+		s = append(s, string(row))
+	}
+	return s
+}
@@ -23,6 +23,9 @@ type (
 		Config CSVConfig
 		reader *csv.Reader
 		format *csvFormat
+		// row and rowReader are reused by Parse
+		row       *bytes.Reader
+		rowReader *csv.Reader
 	}
 
 	csvFormat struct {
@@ -64,9 +67,25 @@ func (p *CSVParser) ReadLine(line LogLine) error {
 }
 
 func (p *CSVParser) Parse(row []byte, line LogLine) error {
-	r := newCSVReader(bytes.NewBuffer(row), p.Config)
-	record, err := r.Read()
+	if bytes.IndexByte(row, '\n') != -1 {
+		r := newCSVReader(bytes.NewBuffer(row), p.Config)
+		record, err := r.Read()
+		if err != nil {
+			return handleCSVReaderError(err)
+		}
+		return p.format.parse(record, line)
+	}
+
+	// a single line row is consumed entirely, so the reader can be reused for the next one
+	if p.rowReader == nil {
+		p.row = bytes.NewReader(nil)
+		p.rowReader = newCSVReader(p.row, p.Config)
+	}
+	p.row.Reset(row)
+
+	record, err := p.rowReader.Read()
 	if err != nil {
+		p.rowReader = nil
 		return handleCSVReaderError(err)
 	}
 	return p.format.parse(record, line)
@@ -0,0 +1,168 @@
+package logs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxGrokDepth = 32
+
+var (
+	reGrokRef       = regexp.MustCompile(`%\{(\w+)(?::([\w.@\-\[\]]+))?(?::\w+)?\}`)
+	reOnigurumaName = regexp.MustCompile(`\(\?<(\w+)>`)
+)
+
+type (
+	GrokConfig struct {
+		// Pattern is a grok expression, e.g. '%{IPORHOST:clientip} %{NOTSPACE:ident}'.
+		Pattern string `yaml:"pattern"`
+		// Patterns are user defined patterns, they take precedence over the built-in ones.
+		Patterns map[string]string `yaml:"patterns"`
+		Mapping  map[string]string `yaml:"mapping"`
+	}
+
+	GrokParser struct {
+		r       *bufio.Reader
+		pattern *regexp.Regexp
+		// fields holds a field name for every pattern subexpression, empty name means not assigned.
+		fields  []string
+		raw     string
+		mapping map[string]string
+	}
+)
+
+func NewGrokParser(config GrokConfig, in io.Reader) (*GrokParser, error) {
+	if config.Pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	c := grokCompiler{patterns: config.Patterns}
+	expr, err := c.expand(config.Pattern, 0)
+	if err != nil {
+		return nil, fmt.Errorf("expand: %w", err)
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("compile: %w", err)
+	}
+
+	fields := make([]string, 0, pattern.NumSubexp()+1)
+	var named int
+	for _, name := range pattern.SubexpNames() {
+		if strings.HasPrefix(name, grokSubexpPrefix) {
+			idx, _ := strconv.Atoi(name[len(grokSubexpPrefix):])
+			name = c.semantics[idx]
+		}
+		if mapped, ok := config.Mapping[name]; ok {
+			name = mapped
+		}
+		if name != "" {
+			named++
+		}
+		fields = append(fields, name)
+	}
+	if named == 0 {
+		return nil, errors.New("pattern has no named fields")
+	}
+
+	p := &GrokParser{
+		r:       bufio.NewReader(in),
+		pattern: pattern,
+		fields:  fields,
+		raw:     config.Pattern,
+		mapping: config.Mapping,
+	}
+	return p, nil
+}
+
+func (p *GrokParser) ReadLine(line LogLine) error {
+	row, err := p.r.ReadSlice('\n')
+	if err != nil && len(row) == 0 {
+		return err
+	}
+	if len(row) > 0 && row[len(row)-1] == '\n' {
+		row = row[:len(row)-1]
+	}
+	return p.Parse(row, line)
+}
+
+func (p *GrokParser) Parse(row []byte, line LogLine) error {
+	match := p.pattern.FindSubmatch(row)
+	if len(match) == 0 {
+		return &ParseError{msg: "grok parse: unmatched line"}
+	}
+
+	conv := stringConv(line)
+	for i, name := range p.fields {
+		if name == "" || match[i] == nil {
+			continue
+		}
+		if err := line.Assign(name, conv(match[i])); err != nil {
+			return &ParseError{msg: fmt.Sprintf("grok parse: %v", err), err: err}
+		}
+	}
+	return nil
+}
+
+func (p GrokParser) Info() string {
+	return fmt.Sprintf("grok: %s", p.raw)
+}
+
+const grokSubexpPrefix = "grok__"
+
+// grokCompiler expands grok expressions into regular expressions.
+// '%{SYNTAX:SEMANTIC}' becomes a capturing group, the semantic is kept aside
+// because it is not necessarily a valid subexpression name.
+type grokCompiler struct {
+	patterns  map[string]string
+	semantics []string
+}
+
+func (c *grokCompiler) expand(expr string, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", errors.New("max recursion depth exceeded, probably a pattern refers to itself")
+	}
+
+	var err error
+	expr = reGrokRef.ReplaceAllStringFunc(expr, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		m := reGrokRef.FindStringSubmatch(ref)
+		name, semantic := m[1], m[2]
+
+		def, ok := c.lookup(name)
+		if !ok {
+			err = fmt.Errorf("unknown pattern '%s'", name)
+			return ""
+		}
+		var sub string
+		if sub, err = c.expand(def, depth+1); err != nil {
+			return ""
+		}
+		if semantic == "" {
+			return "(?:" + sub + ")"
+		}
+		c.semantics = append(c.semantics, semantic)
+		return fmt.Sprintf("(?P<%s%d>%s)", grokSubexpPrefix, len(c.semantics)-1, sub)
+	})
+	if err != nil {
+		return "", err
+	}
+	// Oniguruma named groups syntax '(?<name>re)' isn't supported by RE2 (before go1.22).
+	return reOnigurumaName.ReplaceAllString(expr, "(?P<$1>"), nil
+}
+
+func (c *grokCompiler) lookup(name string) (string, bool) {
+	if def, ok := c.patterns[name]; ok {
+		return def, true
+	}
+	def, ok := grokPatterns[name]
+	return def, ok
+}
@@ -0,0 +1,189 @@
+package logs
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGrokParser(t *testing.T) {
+	tests := map[string]struct {
+		config  GrokConfig
+		wantErr bool
+	}{
+		"built-in pattern": {
+			config: GrokConfig{Pattern: "%{COMBINEDAPACHELOG}"},
+		},
+		"user defined pattern": {
+			config: GrokConfig{
+				Pattern:  "%{MYSTATUS:status}",
+				Patterns: map[string]string{"MYSTATUS": "[1-5][0-9]{2}"},
+			},
+		},
+		"empty pattern": {
+			config:  GrokConfig{},
+			wantErr: true,
+		},
+		"unknown pattern": {
+			config:  GrokConfig{Pattern: "%{NOT_EXISTS:field}"},
+			wantErr: true,
+		},
+		"no named fields": {
+			config:  GrokConfig{Pattern: "%{IP} %{WORD}"},
+			wantErr: true,
+		},
+		"recursive pattern": {
+			config: GrokConfig{
+				Pattern:  "%{A:a}",
+				Patterns: map[string]string{"A": "%{B}", "B": "%{A}"},
+			},
+			wantErr: true,
+		},
+		"bad regexp": {
+			config:  GrokConfig{Pattern: "%{WORD:word} (["},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewGrokParser(test.config, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestGrokParser_ReadLine(t *testing.T) {
+	input := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"` + "\n"
+	p, err := NewGrokParser(GrokConfig{Pattern: "%{COMBINEDAPACHELOG}"}, strings.NewReader(input))
+	require.NoError(t, err)
+
+	line := newLogLine()
+	require.NoError(t, p.ReadLine(line))
+
+	expected := map[string]string{
+		"clientip":    "127.0.0.1",
+		"ident":       "-",
+		"auth":        "frank",
+		"timestamp":   "10/Oct/2000:13:55:36 -0700",
+		"verb":        "GET",
+		"request":     "/apache_pb.gif",
+		"httpversion": "1.0",
+		"response":    "200",
+		"bytes":       "2326",
+		"referrer":    `"http://www.example.com/start.html"`,
+		"agent":       `"Mozilla/4.08"`,
+	}
+	assert.Equal(t, expected, line.assigned)
+}
+
+func TestGrokParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		config       GrokConfig
+		input        string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"ipv6 client": {
+			config: GrokConfig{Pattern: "%{IP:client} %{NUMBER:status}"},
+			input:  "2001:db8::ff00:42:8329 200",
+			wantAssigned: map[string]string{
+				"client": "2001:db8::ff00:42:8329",
+				"status": "200",
+			},
+		},
+		"syslog base": {
+			config: GrokConfig{Pattern: "%{SYSLOGBASE} %{GREEDYDATA:message}"},
+			input:  "Oct  9 10:00:00 web01 nginx[123]: hello world",
+			wantAssigned: map[string]string{
+				"timestamp": "Oct  9 10:00:00",
+				"logsource": "web01",
+				"program":   "nginx",
+				"pid":       "123",
+				"message":   "hello world",
+			},
+		},
+		"with mappings and type suffix": {
+			config: GrokConfig{
+				Pattern: "%{IPORHOST:clientip} %{NUMBER:response:int}",
+				Mapping: map[string]string{"clientip": "remote_addr", "response": "status"},
+			},
+			input: "example.com 404",
+			wantAssigned: map[string]string{
+				"remote_addr": "example.com",
+				"status":      "404",
+			},
+		},
+		"oniguruma named group": {
+			config: GrokConfig{Pattern: `(?<method>[A-Z]+) %{URIPATHPARAM:url}`},
+			input:  "POST /api?x=1",
+			wantAssigned: map[string]string{
+				"method": "POST",
+				"url":    "/api?x=1",
+			},
+		},
+		"semantic with brackets": {
+			config: GrokConfig{Pattern: `%{WORD:[http][method]}`},
+			input:  "GET",
+			wantAssigned: map[string]string{
+				"[http][method]": "GET",
+			},
+		},
+		"error on unmatched line": {
+			config:  GrokConfig{Pattern: "^%{IP:client}$"},
+			input:   "not an ip",
+			wantErr: true,
+		},
+		"error on assigning": {
+			config:  GrokConfig{Pattern: "%{WORD:ERR}"},
+			input:   "word",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewGrokParser(test.config, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.input), line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestGrokParser_Info(t *testing.T) {
+	p, err := NewGrokParser(GrokConfig{Pattern: "%{IP:ip}"}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
+
+func Test_grokPatterns_Compile(t *testing.T) {
+	for name := range grokPatterns {
+		t.Run(name, func(t *testing.T) {
+			c := grokCompiler{}
+			expr, err := c.expand("%{"+name+"}", 0)
+			require.NoError(t, err)
+			_, err = regexp.Compile(expr)
+			assert.NoError(t, err)
+		})
+	}
+}
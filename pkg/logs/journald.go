@@ -0,0 +1,251 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/logger"
+
+	"github.com/valyala/fastjson"
+)
+
+const (
+	defaultJournalctlPath = "journalctl"
+	journalctlTimeout     = time.Second * 5
+	maxJournalEntrySize   = 1024 * 1024
+
+	journalctlMinRestartBackoff = time.Second
+	journalctlMaxRestartBackoff = time.Minute
+)
+
+type (
+	JournaldConfig struct {
+		Units          []string `yaml:"units"`
+		Identifiers    []string `yaml:"identifiers"`
+		Matches        []string `yaml:"matches"`
+		Directory      string   `yaml:"directory"`
+		JournalctlPath string   `yaml:"journalctl_path"`
+		MaxBufferSize  int      `yaml:"max_buffer_size"`
+	}
+
+	// JournaldReader follows the systemd journal using journalctl and yields entries MESSAGE field.
+	JournaldReader struct {
+		*recordBuffer
+		config JournaldConfig
+		path   string
+		cmd    *exec.Cmd
+		log    *logger.Logger
+
+		mu        sync.Mutex
+		closed    bool
+		exited    bool
+		backoff   time.Duration
+		restartAt time.Time
+	}
+)
+
+// OpenJournald starts following the journal, only entries written after the call are read.
+func OpenJournald(config JournaldConfig, log *logger.Logger) (*JournaldReader, error) {
+	for _, m := range config.Matches {
+		if !strings.Contains(m, "=") || strings.HasPrefix(m, "=") {
+			return nil, fmt.Errorf("bad match syntax: %q (expected FIELD=VALUE)", m)
+		}
+	}
+	name := config.JournalctlPath
+	if name == "" {
+		name = defaultJournalctlPath
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("look up journalctl: %v", err)
+	}
+
+	r := &JournaldReader{
+		recordBuffer: newRecordBuffer(config.MaxBufferSize),
+		config:       config,
+		path:         path,
+		log:          log,
+	}
+	if err := r.start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *JournaldReader) Read(p []byte) (int, error) {
+	n, err := r.recordBuffer.Read(p)
+	if n > 0 || err == nil {
+		return n, err
+	}
+	if n := r.droppedRecords(); n > 0 {
+		r.log.Warningf("journald: dropped %d entries due to buffer overflow", n)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || !r.exited {
+		return 0, err
+	}
+	// journalctl is restarted with a backoff, the entries written while it was not running are not read
+	if time.Now().Before(r.restartAt) {
+		return 0, err
+	}
+	if startErr := r.start(); startErr != nil {
+		r.scheduleRestart(time.Now())
+		return 0, fmt.Errorf("restart journalctl: %v", startErr)
+	}
+	return 0, err
+}
+
+// LastLine returns the MESSAGE of the last matched journal entry.
+func (r *JournaldReader) LastLine() ([]byte, error) {
+	if line, _ := r.lastRecord(); line != nil {
+		return line, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), journalctlTimeout)
+	defer cancel()
+
+	args := append([]string{"--lines=1", "--output=json", "--no-pager", "--quiet"}, r.filterArgs()...)
+	bs, err := exec.CommandContext(ctx, r.path, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl: %v", err)
+	}
+
+	bs = bytes.TrimSpace(bs)
+	if len(bs) == 0 {
+		return nil, ErrNoRecords
+	}
+	if i := bytes.LastIndexByte(bs, '\n'); i != -1 {
+		bs = bs[i+1:]
+	}
+	return parseJournalEntryMessage(bs)
+}
+
+func (r *JournaldReader) Info() string {
+	return fmt.Sprintf("journald: units %q, identifiers %q, matches %q", r.config.Units, r.config.Identifiers, r.config.Matches)
+}
+
+func (r *JournaldReader) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.cmd == nil || r.cmd.Process == nil {
+		return nil
+	}
+	r.log.Debug("stop journalctl: ", r.path)
+	_ = r.cmd.Process.Kill()
+	r.cmd = nil
+	return nil
+}
+
+// start starts journalctl, it must be called with the mu held if the reader is in use.
+func (r *JournaldReader) start() error {
+	args := append([]string{"--follow", "--lines=0", "--output=json", "--no-pager", "--quiet"}, r.filterArgs()...)
+	cmd := exec.Command(r.path, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	r.log.Debugf("start journalctl: %s %s", r.path, strings.Join(args, " "))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start journalctl: %v", err)
+	}
+	r.cmd, r.exited = cmd, false
+	started := time.Now()
+
+	go func() {
+		sc := bufio.NewScanner(stdout)
+		sc.Buffer(make([]byte, 0, 64*1024), maxJournalEntrySize)
+		for sc.Scan() {
+			msg, err := parseJournalEntryMessage(sc.Bytes())
+			if err != nil {
+				r.log.Debugf("journald: %v", err)
+				continue
+			}
+			r.push(msg)
+		}
+		err := cmd.Wait()
+		if err == nil {
+			err = sc.Err()
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.closed || r.cmd != cmd {
+			return
+		}
+		r.cmd, r.exited = nil, true
+		// the backoff is reset if journalctl was running long enough
+		if time.Since(started) >= journalctlMaxRestartBackoff {
+			r.backoff = 0
+		}
+		r.scheduleRestart(time.Now())
+		r.log.Warningf("journalctl exited (%v), restart in %s", err, time.Until(r.restartAt).Round(time.Second))
+	}()
+	return nil
+}
+
+func (r *JournaldReader) scheduleRestart(now time.Time) {
+	switch {
+	case r.backoff == 0:
+		r.backoff = journalctlMinRestartBackoff
+	case r.backoff < journalctlMaxRestartBackoff:
+		r.backoff *= 2
+		if r.backoff > journalctlMaxRestartBackoff {
+			r.backoff = journalctlMaxRestartBackoff
+		}
+	}
+	r.restartAt = now.Add(r.backoff)
+}
+
+func (r *JournaldReader) filterArgs() []string {
+	var args []string
+	if r.config.Directory != "" {
+		args = append(args, "--directory="+r.config.Directory)
+	}
+	for _, u := range r.config.Units {
+		args = append(args, "--unit="+u)
+	}
+	for _, id := range r.config.Identifiers {
+		args = append(args, "--identifier="+id)
+	}
+	return append(args, r.config.Matches...)
+}
+
+func parseJournalEntryMessage(entry []byte) ([]byte, error) {
+	v, err := fastjson.ParseBytes(entry)
+	if err != nil {
+		return nil, fmt.Errorf("parse journal entry: %v", err)
+	}
+
+	msg := v.Get("MESSAGE")
+	if msg == nil {
+		return nil, errors.New("journal entry has no MESSAGE field")
+	}
+	switch msg.Type() {
+	case fastjson.TypeString:
+		return msg.GetStringBytes(), nil
+	case fastjson.TypeArray:
+		// journalctl encodes non UTF-8 fields as an array of bytes
+		var bs []byte
+		for _, b := range msg.GetArray() {
+			bs = append(bs, byte(b.GetUint()))
+		}
+		return bs, nil
+	}
+	return nil, fmt.Errorf("unexpected journal entry MESSAGE type: %s", msg.Type())
+}
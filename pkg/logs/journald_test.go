@@ -0,0 +1,162 @@
+package logs
+
+import (
+	"bufio"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJournalctlPath = "testdata/journalctl.sh"
+
+func TestOpenJournald(t *testing.T) {
+	tests := map[string]struct {
+		config  JournaldConfig
+		wantErr bool
+	}{
+		"units": {
+			config: JournaldConfig{JournalctlPath: testJournalctlPath, Units: []string{"nginx.service"}},
+		},
+		"matches": {
+			config: JournaldConfig{JournalctlPath: testJournalctlPath, Matches: []string{"_COMM=nginx"}},
+		},
+		"bad match": {
+			config:  JournaldConfig{JournalctlPath: testJournalctlPath, Matches: []string{"_COMM"}},
+			wantErr: true,
+		},
+		"journalctl not found": {
+			config:  JournaldConfig{JournalctlPath: "testdata/not-exists.sh"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := OpenJournald(test.config, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, r)
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, r.Close())
+			}
+		})
+	}
+}
+
+func TestJournaldReader_Read(t *testing.T) {
+	r, err := OpenJournald(JournaldConfig{JournalctlPath: testJournalctlPath, Units: []string{"nginx.service"}}, nil)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	var lines []string
+	br := bufio.NewReader(r)
+	require.Eventually(t, func() bool {
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				return len(lines) == 3
+			}
+			lines = append(lines, line)
+		}
+	}, time.Second*5, time.Millisecond*10)
+
+	assert.Equal(t, []string{
+		"10.0.0.1 - - \"GET / HTTP/1.1\" 200 612\n",
+		"10.0.0.2\n",
+		"10.0.0.3 - - \"GET / HTTP/1.1\" 404 0\n",
+	}, lines)
+
+	last, err := r.LastLine()
+	assert.NoError(t, err)
+	assert.Equal(t, `10.0.0.3 - - "GET / HTTP/1.1" 404 0`, string(last))
+}
+
+func TestJournaldReader_Read_RestartsJournalctl(t *testing.T) {
+	_ = os.Setenv("JOURNALCTL_EXIT", "1")
+	defer func() { _ = os.Unsetenv("JOURNALCTL_EXIT") }()
+
+	r, err := OpenJournald(JournaldConfig{JournalctlPath: testJournalctlPath, Units: []string{"nginx.service"}}, nil)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	exited := func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.exited
+	}
+	readLines := func() (n int) {
+		br := bufio.NewReader(r)
+		for {
+			if _, err := br.ReadString('\n'); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+
+	require.Eventually(t, exited, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, 3, readLines())
+
+	r.mu.Lock()
+	assert.Equal(t, journalctlMinRestartBackoff, r.backoff)
+	r.restartAt = time.Now()
+	r.mu.Unlock()
+
+	var lines int
+	require.Eventually(t, func() bool { lines += readLines(); return lines == 3 }, time.Second*5, time.Millisecond*10)
+
+	require.Eventually(t, exited, time.Second*5, time.Millisecond*10)
+	r.mu.Lock()
+	assert.Equal(t, journalctlMinRestartBackoff*2, r.backoff, "backoff grows if journalctl keeps exiting")
+	r.restartAt = time.Now()
+	r.mu.Unlock()
+
+	require.NoError(t, r.Close())
+	assert.Equal(t, 0, readLines())
+	r.mu.Lock()
+	assert.Nil(t, r.cmd, "closed reader is not restarted")
+	r.mu.Unlock()
+}
+
+func TestJournaldReader_LastLine(t *testing.T) {
+	r := &JournaldReader{
+		recordBuffer: newRecordBuffer(0),
+		path:         testJournalctlPath,
+	}
+
+	last, err := r.LastLine()
+	assert.NoError(t, err)
+	assert.Equal(t, `10.0.0.0 - - "GET / HTTP/1.1" 200 1`, string(last))
+}
+
+func Test_parseJournalEntryMessage(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		"string":     {input: `{"MESSAGE":"hello"}`, want: "hello"},
+		"bytes":      {input: `{"MESSAGE":[104,105]}`, want: "hi"},
+		"no message": {input: `{"PRIORITY":"6"}`, wantErr: true},
+		"number":     {input: `{"MESSAGE":1}`, wantErr: true},
+		"not json":   {input: `MESSAGE=hello`, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg, err := parseJournalEntryMessage([]byte(test.input))
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, string(msg))
+			}
+		})
+	}
+}
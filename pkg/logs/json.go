@@ -49,6 +49,9 @@ func (p *JSONParser) Parse(row []byte, line LogLine) error {
 		return err
 	}
 
+	conv := stringConv(line)
+	// values are appended to the buffer, values of zero copy lines stay valid until the next Parse call
+	p.buf = p.buf[:0]
 	obj.Visit(func(key []byte, v *fastjson.Value) {
 		if err != nil {
 			return
@@ -59,22 +62,23 @@ func (p *JSONParser) Parse(row []byte, line LogLine) error {
 			return
 		}
 
-		name := string(key)
-		if mapped, ok := p.mapping[name]; ok {
+		name := conv(key)
+		if mapped, ok := p.mapping[string(key)]; ok {
 			name = mapped
 		}
 
-		p.buf = p.buf[:0]
-		if p.buf = v.MarshalTo(p.buf); len(p.buf) == 0 {
+		start := len(p.buf)
+		if p.buf = v.MarshalTo(p.buf); len(p.buf) == start {
 			return
 		}
+		value := p.buf[start:]
 
 		switch v.Type() {
 		case fastjson.TypeString:
 			// trim "
-			err = line.Assign(name, string(p.buf[1:len(p.buf)-1]))
+			err = line.Assign(name, conv(value[1:len(value)-1]))
 		default:
-			err = line.Assign(name, string(p.buf))
+			err = line.Assign(name, conv(value))
 		}
 	})
 	if err != nil {
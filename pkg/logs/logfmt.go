@@ -0,0 +1,122 @@
+package logs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type (
+	LogfmtConfig struct {
+		Mapping map[string]string `yaml:"mapping"`
+	}
+
+	LogfmtParser struct {
+		r       *bufio.Reader
+		mapping map[string]string
+	}
+)
+
+var (
+	errLogfmtNoKey              = errors.New("pair has no key")
+	errLogfmtUnterminatedString = errors.New("unterminated quoted value")
+)
+
+func NewLogfmtParser(config LogfmtConfig, in io.Reader) (*LogfmtParser, error) {
+	p := &LogfmtParser{
+		r:       bufio.NewReader(in),
+		mapping: config.Mapping,
+	}
+	return p, nil
+}
+
+func (p *LogfmtParser) ReadLine(line LogLine) error {
+	row, err := p.r.ReadSlice('\n')
+	if err != nil && len(row) == 0 {
+		return err
+	}
+	if len(row) > 0 && row[len(row)-1] == '\n' {
+		row = row[:len(row)-1]
+	}
+	return p.Parse(row, line)
+}
+
+// Parse parses a row of space separated 'key=value' pairs.
+// Values containing spaces are double quoted, a key without '=' has an empty value.
+func (p *LogfmtParser) Parse(row []byte, line LogLine) error {
+	conv := stringConv(line)
+	for i := 0; i < len(row); {
+		if isLogfmtSpace(row[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(row) && row[i] != '=' && !isLogfmtSpace(row[i]) {
+			i++
+		}
+		if i == start {
+			return &ParseError{msg: fmt.Sprintf("logfmt parse: %v", errLogfmtNoKey), err: errLogfmtNoKey}
+		}
+		key := conv(row[start:i])
+
+		var value string
+		if i < len(row) && row[i] == '=' {
+			i++
+			var err error
+			if value, i, err = parseLogfmtValue(row, i, conv); err != nil {
+				return &ParseError{msg: fmt.Sprintf("logfmt parse: key '%s': %v", key, err), err: err}
+			}
+		}
+
+		if v, ok := p.mapping[key]; ok {
+			key = v
+		}
+		if err := line.Assign(key, value); err != nil {
+			return &ParseError{msg: fmt.Sprintf("logfmt parse: %v", err), err: err}
+		}
+	}
+	return nil
+}
+
+func (p *LogfmtParser) Info() string {
+	return fmt.Sprintf("logfmt: %q", p.mapping)
+}
+
+func parseLogfmtValue(row []byte, i int, conv func([]byte) string) (value string, next int, err error) {
+	if i >= len(row) || row[i] != '"' {
+		start := i
+		for i < len(row) && !isLogfmtSpace(row[i]) {
+			i++
+		}
+		return conv(row[start:i]), i, nil
+	}
+
+	start := i
+	var escaped bool
+	for i++; i < len(row); i++ {
+		if row[i] == '\\' {
+			escaped = true
+			i++
+			continue
+		}
+		if row[i] == '"' {
+			break
+		}
+	}
+	if i >= len(row) {
+		return "", i, errLogfmtUnterminatedString
+	}
+
+	if !escaped {
+		return conv(row[start+1 : i]), i + 1, nil
+	}
+	value, err = strconv.Unquote(string(row[start : i+1]))
+	return value, i + 1, err
+}
+
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
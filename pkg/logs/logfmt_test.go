@@ -0,0 +1,151 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogfmtParser(t *testing.T) {
+	tests := map[string]struct {
+		config  LogfmtConfig
+		wantErr bool
+	}{
+		"empty config": {
+			config: LogfmtConfig{},
+		},
+		"with mappings": {
+			config: LogfmtConfig{Mapping: map[string]string{"from_field_1": "to_field_1"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewLogfmtParser(test.config, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestLogfmtParser_ReadLine(t *testing.T) {
+	tests := map[string]struct {
+		config       LogfmtConfig
+		input        string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"simple values": {
+			input: "host=example.com status=200 request_time=0.001\n",
+			wantAssigned: map[string]string{
+				"host":         "example.com",
+				"status":       "200",
+				"request_time": "0.001",
+			},
+		},
+		"error on empty input": {
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewLogfmtParser(test.config, strings.NewReader(test.input))
+			require.NoError(t, err)
+
+			err = p.ReadLine(line)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestLogfmtParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		config       LogfmtConfig
+		input        string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"simple values": {
+			input: "host=example.com status=200",
+			wantAssigned: map[string]string{
+				"host":   "example.com",
+				"status": "200",
+			},
+		},
+		"quoted values": {
+			input: `request="GET / HTTP/1.1" agent="curl \"7.0\"" empty=""`,
+			wantAssigned: map[string]string{
+				"request": "GET / HTTP/1.1",
+				"agent":   `curl "7.0"`,
+				"empty":   "",
+			},
+		},
+		"extra spaces and keys without values": {
+			input: "  status=200   debug  empty= ",
+			wantAssigned: map[string]string{
+				"status": "200",
+				"debug":  "",
+				"empty":  "",
+			},
+		},
+		"with mappings": {
+			config: LogfmtConfig{Mapping: map[string]string{"code": "status"}},
+			input:  "code=200",
+			wantAssigned: map[string]string{
+				"status": "200",
+			},
+		},
+		"error on unterminated quoted value": {
+			input:   `request="GET / HTTP/1.1 status=200`,
+			wantErr: true,
+		},
+		"error on no key": {
+			input:   "=200",
+			wantErr: true,
+		},
+		"error on assigning": {
+			input:   "status=200 ERR=1",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewLogfmtParser(test.config, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.input), line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestLogfmtParser_Info(t *testing.T) {
+	p, err := NewLogfmtParser(LogfmtConfig{}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
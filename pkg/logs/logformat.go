@@ -0,0 +1,313 @@
+package logs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+LogFormat parser parses lines written using nginx 'log_format' or apache 'LogFormat' format strings.
+The format string is compiled into a regular expression, every variable becomes a subexpression.
+
+nginx variables:
+ - $name, ${name}
+
+apache format directives:
+ - %x, %>x, %<x, %{param}x, %400,501{param}x, %!200x, %^xx
+ - %% is a literal '%'
+*/
+
+type (
+	LogFormatConfig struct {
+		Format string `yaml:"format"`
+		// CheckField gets a format variable (e.g. "$remote_addr", "%>s", "%{Referer}i") and returns the field name.
+		// Not valid variables are matched but not assigned.
+		// If nil, the field name is the variable without '$' or '%' prefix.
+		CheckField func(string) (string, bool) `yaml:"-"`
+	}
+
+	// LogFormat is a compiled format string.
+	LogFormat struct {
+		Raw     string
+		Pattern *regexp.Regexp
+		// Fields holds a field name for every Pattern subexpression, empty name means not assigned.
+		Fields []string
+	}
+
+	LogFormatParser struct {
+		r      *bufio.Reader
+		format *LogFormat
+	}
+
+	logFormatToken struct {
+		literal  string
+		variable string
+	}
+)
+
+func NewLogFormatParser(config LogFormatConfig, in io.Reader) (*LogFormatParser, error) {
+	format, err := CompileLogFormat(config.Format, config.CheckField)
+	if err != nil {
+		return nil, fmt.Errorf("compile format: %w", err)
+	}
+
+	p := &LogFormatParser{
+		r:      bufio.NewReader(in),
+		format: format,
+	}
+	return p, nil
+}
+
+func (p *LogFormatParser) ReadLine(line LogLine) error {
+	row, err := p.r.ReadSlice('\n')
+	if err != nil && len(row) == 0 {
+		return err
+	}
+	if len(row) > 0 && row[len(row)-1] == '\n' {
+		row = row[:len(row)-1]
+	}
+	return p.Parse(row, line)
+}
+
+func (p *LogFormatParser) Parse(row []byte, line LogLine) error {
+	match := p.format.Pattern.FindSubmatch(row)
+	if len(match) == 0 {
+		return &ParseError{msg: "log_format parse: unmatched line"}
+	}
+
+	conv := stringConv(line)
+	for i, name := range p.format.Fields {
+		if name == "" || match[i] == nil {
+			continue
+		}
+		if err := line.Assign(name, conv(match[i])); err != nil {
+			return &ParseError{msg: fmt.Sprintf("log_format parse: %v", err), err: err}
+		}
+	}
+	return nil
+}
+
+func (p LogFormatParser) Info() string {
+	return fmt.Sprintf("log_format: %s", p.format.Raw)
+}
+
+// CompileLogFormat compiles nginx 'log_format' or apache 'LogFormat' format string.
+func CompileLogFormat(format string, checkField func(string) (string, bool)) (*LogFormat, error) {
+	format = joinNginxLogFormat(format)
+	if strings.TrimSpace(format) == "" {
+		return nil, errors.New("empty format")
+	}
+	if checkField == nil {
+		checkField = defaultLogFormatCheckField
+	}
+
+	tokens := tokenizeLogFormat(format)
+	var sb strings.Builder
+	fields := []string{""} // whole match
+	var numVars int
+
+	sb.WriteByte('^')
+	for i, tok := range tokens {
+		if tok.variable == "" {
+			sb.WriteString(regexp.QuoteMeta(tok.literal))
+			continue
+		}
+		numVars++
+
+		var next *logFormatToken
+		if i+1 < len(tokens) {
+			next = &tokens[i+1]
+		}
+		expr := logFormatVariableExpr(tok.variable, next)
+
+		name, ok := checkField(tok.variable)
+		if !ok || name == "" {
+			sb.WriteString("(?:" + expr + ")")
+			continue
+		}
+		sb.WriteString("(" + expr + ")")
+		fields = append(fields, name)
+	}
+	sb.WriteByte('$')
+
+	if numVars == 0 {
+		return nil, errors.New("format has no variables")
+	}
+	pattern, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, err
+	}
+	return &LogFormat{Raw: format, Pattern: pattern, Fields: fields}, nil
+}
+
+func logFormatVariableExpr(variable string, next *logFormatToken) string {
+	// apache '%t' is '[10/Oct/2000:13:55:36 -0700]'
+	if variable == "%t" {
+		return `\[[^\]]*\]`
+	}
+	if next == nil {
+		return `.*`
+	}
+	if next.variable != "" {
+		return `.*?`
+	}
+	c, _ := utf8.DecodeRuneInString(next.literal)
+	if c == '"' {
+		// apache escapes '"' as '\"'
+		return `(?:[^"\\]|\\.)*`
+	}
+	return fmt.Sprintf(`[^\x{%x}]*`, c)
+}
+
+func tokenizeLogFormat(format string) []logFormatToken {
+	var tokens []logFormatToken
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			tokens = append(tokens, logFormatToken{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); {
+		var n int
+		switch format[i] {
+		case '$':
+			n = nginxVariableLen(format[i:])
+		case '%':
+			if strings.HasPrefix(format[i:], "%%") {
+				literal.WriteByte('%')
+				i += 2
+				continue
+			}
+			n = apacheDirectiveLen(format[i:])
+		case '\\':
+			// escapes used in apache config files
+			if i+1 < len(format) {
+				switch format[i+1] {
+				case '"', '\\':
+					literal.WriteByte(format[i+1])
+					i += 2
+					continue
+				case 't':
+					literal.WriteByte('\t')
+					i += 2
+					continue
+				}
+			}
+		}
+
+		if n == 0 {
+			literal.WriteByte(format[i])
+			i++
+			continue
+		}
+		flush()
+		variable := format[i : i+n]
+		if strings.HasPrefix(variable, "${") {
+			variable = "$" + variable[2:len(variable)-1]
+		}
+		tokens = append(tokens, logFormatToken{variable: variable})
+		i += n
+	}
+	flush()
+	return tokens
+}
+
+func nginxVariableLen(s string) int {
+	if strings.HasPrefix(s, "${") {
+		if end := strings.IndexByte(s, '}'); end > 2 && isNginxVariableName(s[2:end]) {
+			return end + 1
+		}
+		return 0
+	}
+	n := 1
+	for n < len(s) && isNginxVariableChar(s[n]) {
+		n++
+	}
+	if n == 1 {
+		return 0
+	}
+	return n
+}
+
+func apacheDirectiveLen(s string) int {
+	n := 1
+	if n < len(s) && (s[n] == '<' || s[n] == '>') {
+		n++
+	}
+	if n < len(s) && s[n] == '!' {
+		n++
+	}
+	for n < len(s) && (s[n] == ',' || (s[n] >= '0' && s[n] <= '9')) {
+		n++
+	}
+	if n < len(s) && s[n] == '{' {
+		end := strings.IndexByte(s[n:], '}')
+		if end == -1 {
+			return 0
+		}
+		n += end + 1
+	}
+	if n < len(s) && s[n] == '^' {
+		if n+2 < len(s) && isLetter(s[n+1]) && isLetter(s[n+2]) {
+			return n + 3
+		}
+		return 0
+	}
+	if n < len(s) && isLetter(s[n]) {
+		return n + 1
+	}
+	return 0
+}
+
+// joinNginxLogFormat joins nginx multi-line format string parts:
+// '$remote_addr - $remote_user [$time_local] '
+// '"$request" $status $body_bytes_sent'
+func joinNginxLogFormat(format string) string {
+	s := strings.TrimSpace(format)
+	if !strings.HasPrefix(s, "'") {
+		return format
+	}
+
+	var sb strings.Builder
+	for len(s) > 0 {
+		if s[0] != '\'' {
+			return format
+		}
+		end := strings.IndexByte(s[1:], '\'')
+		if end == -1 {
+			return format
+		}
+		sb.WriteString(s[1 : end+1])
+		s = strings.TrimSpace(s[end+2:])
+	}
+	return sb.String()
+}
+
+func defaultLogFormatCheckField(variable string) (string, bool) {
+	return strings.TrimLeft(variable, "$%"), true
+}
+
+func isNginxVariableName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNginxVariableChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNginxVariableChar(c byte) bool {
+	return c == '_' || isLetter(c) || (c >= '0' && c <= '9')
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,190 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogFormatParser(t *testing.T) {
+	tests := map[string]struct {
+		config  LogFormatConfig
+		wantErr bool
+	}{
+		"nginx format": {
+			config: LogFormatConfig{Format: `$remote_addr - $remote_user [$time_local] "$request" $status`},
+		},
+		"apache format": {
+			config: LogFormatConfig{Format: `%h %l %u %t \"%r\" %>s %b`},
+		},
+		"empty format": {
+			config:  LogFormatConfig{},
+			wantErr: true,
+		},
+		"format without variables": {
+			config:  LogFormatConfig{Format: "- - -"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewLogFormatParser(test.config, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestCompileLogFormat(t *testing.T) {
+	tests := map[string]struct {
+		format     string
+		checkField func(string) (string, bool)
+		wantFields []string
+	}{
+		"nginx": {
+			format:     `$remote_addr - $remote_user [$time_local] "$request" $status ${body_bytes_sent}B`,
+			wantFields: []string{"", "remote_addr", "remote_user", "time_local", "request", "status", "body_bytes_sent"},
+		},
+		"nginx multi-line": {
+			format:     `'$remote_addr - [$time_local] '` + "\n  " + `'"$request" $status'`,
+			wantFields: []string{"", "remote_addr", "time_local", "request", "status"},
+		},
+		"apache": {
+			format:     `%v:%p %h %l %u %t \"%r\" %>s %O \"%{Referer}i\" %^FB %!200,304D 100%%`,
+			wantFields: []string{"", "v", "p", "h", "l", "u", "t", "r", ">s", "O", "{Referer}i", "^FB", "!200,304D"},
+		},
+		"check field": {
+			format: `$remote_addr [$time_local] $status`,
+			checkField: func(s string) (string, bool) {
+				if s == "$time_local" {
+					return "", false
+				}
+				return s[1:], true
+			},
+			wantFields: []string{"", "remote_addr", "status"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			format, err := CompileLogFormat(test.format, test.checkField)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantFields, format.Fields)
+			assert.Equal(t, len(test.wantFields), format.Pattern.NumSubexp()+1)
+		})
+	}
+}
+
+func TestLogFormatParser_ReadLine(t *testing.T) {
+	format := `'$remote_addr - $remote_user [$time_local] '
+               '"$request" $status $body_bytes_sent '
+               '"$http_referer" "$http_user_agent"'`
+	input := `10.0.0.1 - - [22/Mar/2009:09:30:31 +0100] "GET /index.html HTTP/1.1" 200 512 "-" "Mozilla/5.0 (X11; Linux x86_64)"` + "\n"
+
+	p, err := NewLogFormatParser(LogFormatConfig{Format: format}, strings.NewReader(input))
+	require.NoError(t, err)
+
+	line := newLogLine()
+	require.NoError(t, p.ReadLine(line))
+
+	expected := map[string]string{
+		"remote_addr":     "10.0.0.1",
+		"remote_user":     "-",
+		"time_local":      "22/Mar/2009:09:30:31 +0100",
+		"request":         "GET /index.html HTTP/1.1",
+		"status":          "200",
+		"body_bytes_sent": "512",
+		"http_referer":    "-",
+		"http_user_agent": "Mozilla/5.0 (X11; Linux x86_64)",
+	}
+	assert.Equal(t, expected, line.assigned)
+}
+
+func TestLogFormatParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		format       string
+		input        string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"apache combined": {
+			format: `%h %l %u %t \"%r\" %>s %b \"%{Referer}i\" \"%{User-Agent}i\"`,
+			input:  `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a\"b HTTP/1.0" 200 2326 "http://example.com/" "Mozilla/4.08"`,
+			wantAssigned: map[string]string{
+				"h":             "127.0.0.1",
+				"l":             "-",
+				"u":             "frank",
+				"t":             "[10/Oct/2000:13:55:36 -0700]",
+				"r":             `GET /a\"b HTTP/1.0`,
+				">s":            "200",
+				"b":             "2326",
+				"{Referer}i":    "http://example.com/",
+				"{User-Agent}i": "Mozilla/4.08",
+			},
+		},
+		"apache vhost with port": {
+			format: `%v:%p %h %>s %D`,
+			input:  `example.com:443 ::1 301 1500`,
+			wantAssigned: map[string]string{
+				"v":  "example.com",
+				"p":  "443",
+				"h":  "::1",
+				">s": "301",
+				"D":  "1500",
+			},
+		},
+		"nginx braced variable": {
+			format: `$scheme://$host ${request_time}s`,
+			input:  `https://example.com 0.123s`,
+			wantAssigned: map[string]string{
+				"scheme":       "https",
+				"host":         "example.com",
+				"request_time": "0.123",
+			},
+		},
+		"error on unmatched line": {
+			format:  `[$time_local] $status`,
+			input:   `22/Mar/2009:09:30:31 +0100 200`,
+			wantErr: true,
+		},
+		"error on assigning": {
+			format:  `$status $ERR`,
+			input:   `200 1`,
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewLogFormatParser(LogFormatConfig{Format: test.format}, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.input), line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestLogFormatParser_Info(t *testing.T) {
+	p, err := NewLogFormatParser(LogFormatConfig{Format: "$status"}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"strconv"
-	"unsafe"
 
 	"github.com/Wing924/ltsv"
 )
@@ -61,12 +60,13 @@ func (p *LTSVParser) ReadLine(line LogLine) error {
 }
 
 func (p *LTSVParser) Parse(row []byte, line LogLine) error {
+	conv := stringConv(line)
 	err := p.parser.ParseLine(row, func(label []byte, value []byte) error {
-		s := *(*string)(unsafe.Pointer(&label)) // no alloc, same as in fmt.Builder.String()
-		if v, ok := p.mapping[s]; ok {
+		s := conv(label)
+		if v, ok := p.mapping[string(label)]; ok {
 			s = v
 		}
-		return line.Assign(s, string(value))
+		return line.Assign(s, conv(value))
 	})
 	if err != nil {
 		return &ParseError{msg: fmt.Sprintf("ltsv parse: %v", err), err: err}
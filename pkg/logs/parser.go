@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"unsafe"
 )
 
 type ParseError struct {
@@ -23,6 +24,14 @@ type (
 		Assign(name string, value string) error
 	}
 
+	// ZeroCopyLogLine is a LogLine that doesn't retain assigned names and values after the line is processed.
+	// Parsers assign such lines strings that share memory with the parsed row instead of copies,
+	// they are valid as long as the row is.
+	ZeroCopyLogLine interface {
+		LogLine
+		ZeroCopy()
+	}
+
 	Parser interface {
 		ReadLine(LogLine) error
 		Parse(row []byte, line LogLine) error
@@ -31,18 +40,24 @@ type (
 )
 
 const (
-	TypeCSV    = "csv"
-	TypeLTSV   = "ltsv"
-	TypeRegExp = "regexp"
-	TypeJSON   = "json"
+	TypeCSV       = "csv"
+	TypeLTSV      = "ltsv"
+	TypeRegExp    = "regexp"
+	TypeJSON      = "json"
+	TypeLogfmt    = "logfmt"
+	TypeGrok      = "grok"
+	TypeLogFormat = "log_format"
 )
 
 type ParserConfig struct {
-	LogType string       `yaml:"log_type"`
-	CSV     CSVConfig    `yaml:"csv_config"`
-	LTSV    LTSVConfig   `yaml:"ltsv_config"`
-	RegExp  RegExpConfig `yaml:"regexp_config"`
-	JSON    JSONConfig   `yaml:"json_config"`
+	LogType   string          `yaml:"log_type"`
+	CSV       CSVConfig       `yaml:"csv_config"`
+	LTSV      LTSVConfig      `yaml:"ltsv_config"`
+	RegExp    RegExpConfig    `yaml:"regexp_config"`
+	JSON      JSONConfig      `yaml:"json_config"`
+	Logfmt    LogfmtConfig    `yaml:"logfmt_config"`
+	Grok      GrokConfig      `yaml:"grok_config"`
+	LogFormat LogFormatConfig `yaml:"log_format_config"`
 }
 
 func NewParser(config ParserConfig, in io.Reader) (Parser, error) {
@@ -55,9 +70,28 @@ func NewParser(config ParserConfig, in io.Reader) (Parser, error) {
 		return NewRegExpParser(config.RegExp, in)
 	case TypeJSON:
 		return NewJSONParser(config.JSON, in)
+	case TypeLogfmt:
+		return NewLogfmtParser(config.Logfmt, in)
+	case TypeGrok:
+		return NewGrokParser(config.Grok, in)
+	case TypeLogFormat:
+		return NewLogFormatParser(config.LogFormat, in)
 	default:
 		return nil, fmt.Errorf("invalid type: %q", config.LogType)
 	}
 }
 
+// stringConv returns a function that converts parsed row parts to strings suitable for the line.
+func stringConv(line LogLine) func([]byte) string {
+	if _, ok := line.(ZeroCopyLogLine); ok {
+		return bytesToString
+	}
+	return copyString
+}
+
+func copyString(b []byte) string { return string(b) }
+
+// bytesToString converts b to a string without copying, the string shares memory with b.
+func bytesToString(b []byte) string { return *(*string)(unsafe.Pointer(&b)) } // same as in strings.Builder.String()
+
 func isNumber(s string) bool { _, err := strconv.Atoi(s); return err == nil }
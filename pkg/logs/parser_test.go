@@ -1 +1,116 @@
 package logs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type zeroCopyLogLine struct{ *logLine }
+
+func (zeroCopyLogLine) ZeroCopy() {}
+
+func TestParser_Parse_ZeroCopyLogLine(t *testing.T) {
+	tests := map[string]struct {
+		config ParserConfig
+		row    string
+	}{
+		"csv": {
+			config: ParserConfig{LogType: TypeCSV, CSV: CSVConfig{
+				Format:     "$a $b",
+				Delimiter:  " ",
+				CheckField: func(name string) (string, int, bool) { return name[1:], 0, true },
+			}},
+			row: `1 "two"`,
+		},
+		"ltsv": {
+			config: ParserConfig{LogType: TypeLTSV},
+			row:    "a:1\tb:two",
+		},
+		"regexp": {
+			config: ParserConfig{LogType: TypeRegExp, RegExp: RegExpConfig{Pattern: `^(?P<a>\d+) (?P<b>\w+)$`}},
+			row:    "1 two",
+		},
+		"json": {
+			config: ParserConfig{LogType: TypeJSON},
+			row:    `{"a": 1, "b": "two"}`,
+		},
+		"logfmt": {
+			config: ParserConfig{LogType: TypeLogfmt},
+			row:    `a=1 b="two"`,
+		},
+		"grok": {
+			config: ParserConfig{LogType: TypeGrok, Grok: GrokConfig{Pattern: `%{INT:a} %{WORD:b}`}},
+			row:    "1 two",
+		},
+		"log_format": {
+			config: ParserConfig{LogType: TypeLogFormat, LogFormat: LogFormatConfig{Format: `$a $b`}},
+			row:    "1 two",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewParser(test.config, nil)
+			require.NoError(t, err)
+
+			want := map[string]string{"a": "1", "b": "two"}
+
+			row := []byte(test.row)
+			copied := newLogLine()
+			require.NoError(t, p.Parse(row, copied))
+			assert.Equal(t, want, copied.assigned)
+
+			borrowed := zeroCopyLogLine{newLogLine()}
+			require.NoError(t, p.Parse(row, borrowed))
+			assert.Equal(t, want, borrowed.assigned)
+
+			// copied values don't depend on the row
+			copy(row, bytes.Repeat([]byte("x"), len(row)))
+			assert.Equal(t, want, copied.assigned)
+		})
+	}
+}
+
+func BenchmarkParser_Parse(b *testing.B) {
+	row := []byte(`10.0.0.1 - - [22/Mar/2009:09:30:31 +0100] "GET /index.html HTTP/1.1" 200 2216`)
+	configs := map[string]ParserConfig{
+		"csv": {LogType: TypeCSV, CSV: CSVConfig{
+			Format:          `$remote_addr - - [$time_local $tz] "$request" $status $body_bytes_sent`,
+			Delimiter:       " ",
+			FieldsPerRecord: -1,
+		}},
+		"regexp": {LogType: TypeRegExp, RegExp: RegExpConfig{
+			Pattern: `^(?P<remote_addr>\S+) - - \[[^]]+\] "(?P<request>[^"]+)" (?P<status>\d+) (?P<body_bytes_sent>\d+)$`,
+		}},
+		"log_format": {LogType: TypeLogFormat, LogFormat: LogFormatConfig{
+			Format: `$remote_addr - - [$time_local] "$request" $status $body_bytes_sent`,
+		}},
+	}
+
+	for name, config := range configs {
+		p, err := NewParser(config, nil)
+		require.NoError(b, err)
+
+		for _, zeroCopy := range []bool{false, true} {
+			var line LogLine = &logLine{}
+			benchName := name + "/copy"
+			if zeroCopy {
+				line = zeroCopyLogLine{&logLine{}}
+				benchName = name + "/zero copy"
+			}
+
+			b.Run(benchName, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(row)))
+				for i := 0; i < b.N; i++ {
+					if err := p.Parse(row, line); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
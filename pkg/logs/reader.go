@@ -62,6 +62,15 @@ func (r *Reader) CurrentFilename() string {
 	return r.file.Name()
 }
 
+// LastLine returns the last line of the current opened file
+func (r *Reader) LastLine() ([]byte, error) {
+	return ReadLastLine(r.CurrentFilename(), 0)
+}
+
+func (r *Reader) Info() string {
+	return fmt.Sprintf("file: %q", r.CurrentFilename())
+}
+
 func (r *Reader) open() error {
 	path := r.findFile()
 	if path == "" {
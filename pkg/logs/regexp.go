@@ -57,11 +57,12 @@ func (p *RegExpParser) Parse(row []byte, line LogLine) error {
 		return &ParseError{msg: "regexp parse: unmatched line"}
 	}
 
+	conv := stringConv(line)
 	for i, name := range p.pattern.SubexpNames() {
 		if name == "" || match[i] == nil {
 			continue
 		}
-		err := line.Assign(name, string(match[i]))
+		err := line.Assign(name, conv(match[i]))
 		if err != nil {
 			return &ParseError{msg: fmt.Sprintf("regexp parse: %v", err), err: err}
 		}
@@ -0,0 +1,129 @@
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+const (
+	SourceFile     = "file"
+	SourceJournald = "journald"
+	SourceSyslog   = "syslog"
+)
+
+const defaultMaxBufferSize = 4 * 1024 * 1024
+
+var ErrNoRecords = errors.New("no records")
+
+type (
+	// Source is a log input. It yields newline-delimited records and can be used as an input for a Parser.
+	Source interface {
+		io.ReadCloser
+		// LastLine returns the most recent record, it is used for log format auto-detection.
+		LastLine() ([]byte, error)
+		Info() string
+	}
+
+	SourceConfig struct {
+		Type     string         `yaml:"log_source"`
+		Journald JournaldConfig `yaml:"journald_config"`
+		Syslog   SyslogConfig   `yaml:"syslog_config"`
+	}
+)
+
+// OpenSource creates a non-file Source. Files are opened with Open.
+func OpenSource(config SourceConfig, log *logger.Logger) (Source, error) {
+	switch config.Type {
+	case SourceJournald:
+		return OpenJournald(config.Journald, log)
+	case SourceSyslog:
+		return ListenSyslog(config.Syslog, log)
+	default:
+		return nil, fmt.Errorf("invalid source: %q", config.Type)
+	}
+}
+
+// recordBuffer is a bounded buffer of newline-delimited records.
+// Records are pushed by a background receiver and drained by Read, Read returns io.EOF when the buffer is empty.
+type recordBuffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	last    []byte
+	maxSize int
+	dropped int64
+	notify  chan struct{}
+}
+
+func newRecordBuffer(maxSize int) *recordBuffer {
+	if maxSize <= 0 {
+		maxSize = defaultMaxBufferSize
+	}
+	return &recordBuffer{maxSize: maxSize, notify: make(chan struct{})}
+}
+
+func (b *recordBuffer) push(record []byte) {
+	record = bytes.TrimRight(record, "\r\n")
+	if len(record) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf.Len()+len(record)+1 > b.maxSize {
+		b.dropped++
+		return
+	}
+	start := b.buf.Len()
+	b.buf.Write(record)
+	// every record must be a single line
+	line := b.buf.Bytes()[start:]
+	for i, c := range line {
+		if c == '\n' || c == '\r' {
+			line[i] = ' '
+		}
+	}
+	b.buf.WriteByte('\n')
+	b.last = append(b.last[:0], line...)
+
+	if b.notify != nil {
+		close(b.notify)
+		b.notify = nil
+	}
+}
+
+func (b *recordBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return b.buf.Read(p)
+}
+
+// lastRecord returns a copy of the most recently pushed record and a channel that is closed on the first push.
+func (b *recordBuffer) lastRecord() ([]byte, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.last) == 0 {
+		return nil, b.notify
+	}
+	return append([]byte(nil), b.last...), nil
+}
+
+// droppedRecords returns and resets the number of records dropped due to the buffer overflow.
+func (b *recordBuffer) droppedRecords() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.dropped
+	b.dropped = 0
+	return n
+}
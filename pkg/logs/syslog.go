@@ -0,0 +1,454 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/logger"
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+const (
+	defaultSyslogSampleTimeout = time.Second * 5
+	maxSyslogMessageSize       = 64 * 1024
+)
+
+type (
+	SyslogConfig struct {
+		// Listen is a "network://address" string, supported networks: udp, tcp, unix, unixgram.
+		Listen        string       `yaml:"listen"`
+		Tags          []string     `yaml:"tags"`
+		SampleTimeout web.Duration `yaml:"sample_timeout"`
+		MaxBufferSize int          `yaml:"max_buffer_size"`
+	}
+
+	// SyslogListener is a syslog server, it accepts RFC3164 and RFC5424 messages
+	// and yields their content (MSG part).
+	SyslogListener struct {
+		*recordBuffer
+		config  SyslogConfig
+		network string
+		address string
+		tags    map[string]bool
+		log     *logger.Logger
+
+		packetConn net.PacketConn
+		listener   net.Listener
+
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns map[net.Conn]bool
+	}
+)
+
+// ListenSyslog starts a syslog listener.
+func ListenSyslog(config SyslogConfig, log *logger.Logger) (*SyslogListener, error) {
+	network, address, err := parseListenAddress(config.Listen)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &SyslogListener{
+		recordBuffer: newRecordBuffer(config.MaxBufferSize),
+		config:       config,
+		network:      network,
+		address:      address,
+		log:          log,
+		conns:        make(map[net.Conn]bool),
+	}
+	if len(config.Tags) > 0 {
+		l.tags = make(map[string]bool)
+		for _, tag := range config.Tags {
+			l.tags[tag] = true
+		}
+	}
+
+	if err := l.listen(); err != nil {
+		return nil, fmt.Errorf("listen on %q: %v", config.Listen, err)
+	}
+	return l, nil
+}
+
+func (l *SyslogListener) Read(p []byte) (int, error) {
+	n, err := l.recordBuffer.Read(p)
+	if n == 0 && err == io.EOF {
+		if n := l.droppedRecords(); n > 0 {
+			l.log.Warningf("syslog: dropped %d messages due to buffer overflow", n)
+		}
+	}
+	return n, err
+}
+
+// LastLine returns the content of the last received message.
+// If no messages were received yet it waits for one for SampleTimeout.
+func (l *SyslogListener) LastLine() ([]byte, error) {
+	line, notify := l.lastRecord()
+	if line != nil {
+		return line, nil
+	}
+
+	timeout := l.config.SampleTimeout.Duration
+	if timeout <= 0 {
+		timeout = defaultSyslogSampleTimeout
+	}
+	l.log.Debugf("syslog: waiting %s for the first message", timeout)
+
+	select {
+	case <-notify:
+		line, _ = l.lastRecord()
+		return line, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("%w received in %s", ErrNoRecords, timeout)
+	}
+}
+
+func (l *SyslogListener) Info() string {
+	return fmt.Sprintf("syslog: listen %q, tags %q", l.config.Listen, l.config.Tags)
+}
+
+func (l *SyslogListener) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.log.Debug("syslog: stop listening on ", l.config.Listen)
+
+	if l.packetConn != nil {
+		_ = l.packetConn.Close()
+	}
+	if l.listener != nil {
+		_ = l.listener.Close()
+	}
+	l.mu.Lock()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+
+	if l.network == "unixgram" && l.packetConn != nil {
+		_ = os.Remove(l.address)
+	}
+	l.packetConn, l.listener = nil, nil
+	return nil
+}
+
+// Addr returns the listener network address.
+func (l *SyslogListener) Addr() net.Addr {
+	if l.packetConn != nil {
+		return l.packetConn.LocalAddr()
+	}
+	return l.listener.Addr()
+}
+
+func (l *SyslogListener) listen() (err error) {
+	if l.network == "unix" || l.network == "unixgram" {
+		removeStaleSocket(l.address)
+	}
+
+	switch l.network {
+	case "udp", "udp4", "udp6", "unixgram":
+		if l.packetConn, err = net.ListenPacket(l.network, l.address); err != nil {
+			return err
+		}
+		l.wg.Add(1)
+		go func() { defer l.wg.Done(); l.servePackets() }()
+	default:
+		if l.listener, err = net.Listen(l.network, l.address); err != nil {
+			return err
+		}
+		l.wg.Add(1)
+		go func() { defer l.wg.Done(); l.serveStreams() }()
+	}
+	l.log.Debugf("syslog: listening on %s", l.Addr())
+	return nil
+}
+
+func (l *SyslogListener) servePackets() {
+	buf := make([]byte, maxSyslogMessageSize)
+	for {
+		n, _, err := l.packetConn.ReadFrom(buf)
+		if err != nil {
+			if isClosedConnErr(err) {
+				return
+			}
+			l.log.Debugf("syslog: read: %v", err)
+			continue
+		}
+		l.handleMessage(buf[:n])
+	}
+}
+
+func (l *SyslogListener) serveStreams() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if isClosedConnErr(err) {
+				return
+			}
+			l.log.Debugf("syslog: accept: %v", err)
+			continue
+		}
+
+		l.mu.Lock()
+		l.conns[conn] = true
+		l.mu.Unlock()
+
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			defer func() {
+				_ = conn.Close()
+				l.mu.Lock()
+				delete(l.conns, conn)
+				l.mu.Unlock()
+			}()
+			err := readSyslogFrames(bufio.NewReaderSize(conn, maxSyslogMessageSize), l.handleMessage)
+			if err != nil && err != io.EOF && !isClosedConnErr(err) {
+				l.log.Debugf("syslog: read from %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (l *SyslogListener) handleMessage(data []byte) {
+	msg, err := parseSyslogMessage(data)
+	if err != nil {
+		l.log.Debugf("syslog: %v", err)
+		return
+	}
+	if l.tags != nil && !l.tags[msg.tag] {
+		return
+	}
+	l.push(msg.content)
+}
+
+// readSyslogFrames reads messages from a stream, it supports
+// both octet-counting and non-transparent (newline) framing (RFC6587).
+func readSyslogFrames(r *bufio.Reader, fn func([]byte)) error {
+	for {
+		c, err := r.Peek(1)
+		if err != nil {
+			return err
+		}
+
+		if c[0] >= '1' && c[0] <= '9' {
+			head, err := r.ReadSlice(' ')
+			if err != nil {
+				return fmt.Errorf("octet-counting frame: %v", err)
+			}
+			size, err := strconv.Atoi(string(head[:len(head)-1]))
+			if err != nil || size > maxSyslogMessageSize {
+				return fmt.Errorf("octet-counting frame: bad message length %q", head[:len(head)-1])
+			}
+			frame := make([]byte, size)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				return err
+			}
+			fn(frame)
+			continue
+		}
+
+		frame, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			return errors.New("non-transparent frame: too long message")
+		}
+		if len(frame) > 0 {
+			fn(frame)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type syslogMessage struct {
+	priority int
+	hostname string
+	// tag is RFC3164 TAG or RFC5424 APP-NAME
+	tag     string
+	content []byte
+}
+
+func parseSyslogMessage(data []byte) (syslogMessage, error) {
+	var msg syslogMessage
+	data = bytes.TrimRight(data, "\r\n\x00")
+
+	i := bytes.IndexByte(data, '>')
+	if len(data) < 3 || data[0] != '<' || i < 2 || i > 4 {
+		return msg, fmt.Errorf("bad syslog message: no PRI part (%s)", data)
+	}
+	pri, err := strconv.Atoi(string(data[1:i]))
+	if err != nil || pri > 191 {
+		return msg, fmt.Errorf("bad syslog message: invalid PRI %q", data[1:i])
+	}
+	msg.priority = pri
+	data = data[i+1:]
+
+	if isRFC5424Version(data) {
+		err = parseRFC5424(data, &msg)
+	} else {
+		parseRFC3164(data, &msg)
+	}
+	return msg, err
+}
+
+func isRFC5424Version(data []byte) bool {
+	// VERSION = NONZERO-DIGIT 0*2DIGIT
+	for i := 0; i < len(data) && i < 4; i++ {
+		switch c := data[i]; {
+		case c == ' ':
+			return i > 0
+		case c < '0' || c > '9', i == 0 && c == '0':
+			return false
+		}
+	}
+	return false
+}
+
+// <PRI>VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG]
+func parseRFC5424(data []byte, msg *syslogMessage) error {
+	var fields [6][]byte
+	for i := range fields {
+		if fields[i], data = nextToken(data); len(fields[i]) == 0 {
+			return errors.New("bad RFC5424 message: missing header fields")
+		}
+	}
+	if host := string(fields[2]); host != "-" {
+		msg.hostname = host
+	}
+	if app := string(fields[3]); app != "-" {
+		msg.tag = app
+	}
+
+	rest, err := skipStructuredData(data)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	msg.content = bytes.TrimPrefix(rest, []byte("\xEF\xBB\xBF"))
+	return nil
+}
+
+func skipStructuredData(data []byte) ([]byte, error) {
+	if len(data) > 0 && data[0] == '-' {
+		return data[1:], nil
+	}
+	for len(data) > 0 && data[0] == '[' {
+		var quoted, escaped bool
+		i := 1
+		for ; i < len(data); i++ {
+			c := data[i]
+			if escaped {
+				escaped = false
+				continue
+			}
+			if quoted && c == '\\' {
+				escaped = true
+				continue
+			}
+			if c == '"' {
+				quoted = !quoted
+				continue
+			}
+			if !quoted && c == ']' {
+				break
+			}
+		}
+		if i == len(data) {
+			return nil, errors.New("bad RFC5424 message: unterminated STRUCTURED-DATA")
+		}
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+// <PRI>TIMESTAMP SP [HOSTNAME SP] TAG[PID]: CONTENT
+func parseRFC3164(data []byte, msg *syslogMessage) {
+	data = skipRFC3164Timestamp(data)
+
+	tok, rest := nextToken(data)
+	if !isRFC3164Tag(tok) && len(rest) > 0 {
+		if next, after := nextToken(rest); isRFC3164Tag(next) {
+			msg.hostname = string(tok)
+			tok, rest = next, after
+		}
+	}
+	if !isRFC3164Tag(tok) {
+		msg.content = data
+		return
+	}
+
+	tag := tok[:len(tok)-1]
+	if i := bytes.IndexByte(tag, '['); i != -1 {
+		tag = tag[:i]
+	}
+	msg.tag = string(tag)
+	msg.content = rest
+}
+
+func isRFC3164Tag(tok []byte) bool {
+	return len(tok) > 1 && tok[len(tok)-1] == ':'
+}
+
+func skipRFC3164Timestamp(data []byte) []byte {
+	// Mmm dd hh:mm:ss
+	const stampLen = len(time.Stamp)
+	if len(data) > stampLen && data[stampLen] == ' ' {
+		if _, err := time.Parse(time.Stamp, string(data[:stampLen])); err == nil {
+			return data[stampLen+1:]
+		}
+	}
+	// some senders use RFC3339 timestamps
+	if tok, rest := nextToken(data); len(tok) > 0 {
+		if _, err := time.Parse(time.RFC3339Nano, string(tok)); err == nil {
+			return rest
+		}
+	}
+	return data
+}
+
+func nextToken(data []byte) (tok []byte, rest []byte) {
+	if i := bytes.IndexByte(data, ' '); i != -1 {
+		return data[:i], data[i+1:]
+	}
+	return data, nil
+}
+
+func parseListenAddress(listen string) (network, address string, err error) {
+	i := strings.Index(listen, "://")
+	if i == -1 {
+		return "", "", fmt.Errorf("bad listen syntax: %q (expected network://address)", listen)
+	}
+	network, address = listen[:i], listen[i+3:]
+
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+	default:
+		return "", "", fmt.Errorf("unsupported listen network: %q", network)
+	}
+	if address == "" {
+		return "", "", fmt.Errorf("bad listen syntax: %q (empty address)", listen)
+	}
+	return network, address, nil
+}
+
+func removeStaleSocket(path string) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+}
+
+func isClosedConnErr(err error) bool {
+	return errors.Is(err, net.ErrClosed)
+}
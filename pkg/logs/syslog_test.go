@@ -0,0 +1,242 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenSyslog(t *testing.T) {
+	tests := map[string]struct {
+		listen  string
+		wantErr bool
+	}{
+		"udp":                 {listen: "udp://127.0.0.1:0"},
+		"tcp":                 {listen: "tcp://127.0.0.1:0"},
+		"no network":          {listen: "127.0.0.1:0", wantErr: true},
+		"unsupported network": {listen: "ip://127.0.0.1", wantErr: true},
+		"empty address":       {listen: "udp://", wantErr: true},
+		"empty":               {listen: "", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := ListenSyslog(SyslogConfig{Listen: test.listen}, nil)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, l)
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, l.Close())
+			}
+		})
+	}
+}
+
+func TestSyslogListener_Read(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netdata-syslog-test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	messages := []string{
+		"<190>Oct 19 10:00:00 web01 nginx: 10.0.0.1 - - \"GET / HTTP/1.1\" 200 612",
+		"<190>Oct  9 10:00:00 nginx[1234]: 10.0.0.2 - - \"GET / HTTP/1.1\" 404 0",
+		"<165>1 2003-10-11T22:14:15.003Z web01 nginx - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"App\\]\"] 10.0.0.3 - - \"GET / HTTP/1.1\" 200 1",
+	}
+	wantLines := []string{
+		`10.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+		`10.0.0.2 - - "GET / HTTP/1.1" 404 0`,
+		`10.0.0.3 - - "GET / HTTP/1.1" 200 1`,
+	}
+
+	tests := map[string]struct {
+		listen string
+		write  func(conn net.Conn, msg string) error
+	}{
+		"udp": {
+			listen: "udp://127.0.0.1:0",
+			write:  func(conn net.Conn, msg string) error { _, err := conn.Write([]byte(msg)); return err },
+		},
+		"unixgram": {
+			listen: "unixgram://" + filepath.Join(dir, "dgram.sock"),
+			write:  func(conn net.Conn, msg string) error { _, err := conn.Write([]byte(msg)); return err },
+		},
+		"tcp octet-counting framing": {
+			listen: "tcp://127.0.0.1:0",
+			write:  func(conn net.Conn, msg string) error { _, err := fmt.Fprintf(conn, "%d %s", len(msg), msg); return err },
+		},
+		"unix non-transparent framing": {
+			listen: "unix://" + filepath.Join(dir, "stream.sock"),
+			write:  func(conn net.Conn, msg string) error { _, err := fmt.Fprintf(conn, "%s\n", msg); return err },
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := ListenSyslog(SyslogConfig{Listen: test.listen, Tags: []string{"nginx"}}, nil)
+			require.NoError(t, err)
+			defer func() { _ = l.Close() }()
+
+			conn, err := net.Dial(l.Addr().Network(), l.Addr().String())
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			for _, msg := range messages {
+				require.NoError(t, test.write(conn, msg))
+			}
+			require.NoError(t, test.write(conn, "<13>Oct 19 10:00:00 web01 sshd[1]: filtered out"))
+
+			lines := readSyslogLines(t, l, len(wantLines))
+			assert.Equal(t, wantLines, lines)
+
+			last, err := l.LastLine()
+			assert.NoError(t, err)
+			assert.Equal(t, wantLines[len(wantLines)-1], string(last))
+		})
+	}
+}
+
+func TestSyslogListener_LastLine_NoRecords(t *testing.T) {
+	cfg := SyslogConfig{Listen: "udp://127.0.0.1:0", SampleTimeout: web.Duration{Duration: time.Millisecond * 50}}
+	l, err := ListenSyslog(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	_, err = l.LastLine()
+	assert.ErrorIs(t, err, ErrNoRecords)
+}
+
+func TestSyslogListener_WithParser(t *testing.T) {
+	l, err := ListenSyslog(SyslogConfig{Listen: "udp://127.0.0.1:0"}, nil)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial(l.Addr().Network(), l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte(`<190>Oct 19 10:00:00 web01 app: {"status": 200, "host": "example.com"}`))
+	require.NoError(t, err)
+	_, err = l.LastLine()
+	require.NoError(t, err)
+
+	p, err := NewJSONParser(JSONConfig{}, l)
+	require.NoError(t, err)
+
+	line := newLogLine()
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{"status": "200", "host": "example.com"}, line.assigned)
+	assert.Equal(t, io.EOF, p.ReadLine(newLogLine()))
+}
+
+func Test_parseSyslogMessage(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    syslogMessage
+		wantErr bool
+	}{
+		"RFC3164 with hostname": {
+			input: "<34>Oct 11 22:14:15 mymachine su: 'su root' failed",
+			want:  syslogMessage{priority: 34, hostname: "mymachine", tag: "su", content: []byte("'su root' failed")},
+		},
+		"RFC3164 without hostname": {
+			input: "<30>Oct  1 02:14:15 nginx[123]: GET /",
+			want:  syslogMessage{priority: 30, tag: "nginx", content: []byte("GET /")},
+		},
+		"RFC3164 RFC3339 timestamp": {
+			input: "<30>2020-10-01T02:14:15+03:00 host app: message\n",
+			want:  syslogMessage{priority: 30, hostname: "host", tag: "app", content: []byte("message")},
+		},
+		"RFC3164 no tag": {
+			input: "<13>Oct 11 22:14:15 just a message",
+			want:  syslogMessage{priority: 13, content: []byte("just a message")},
+		},
+		"RFC5424 no structured data": {
+			input: "<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - \xEF\xBB\xBF'su root' failed",
+			want:  syslogMessage{priority: 34, hostname: "mymachine.example.com", tag: "su", content: []byte("'su root' failed")},
+		},
+		"RFC5424 structured data": {
+			input: `<165>1 2003-10-11T22:14:15.003Z host app 11 - [a@1 b="c\"]"][d@1 e="f"] message`,
+			want:  syslogMessage{priority: 165, hostname: "host", tag: "app", content: []byte("message")},
+		},
+		"RFC5424 no message": {
+			input: "<165>1 2003-10-11T22:14:15.003Z - - - - -",
+			want:  syslogMessage{priority: 165, content: []byte{}},
+		},
+		"RFC5424 unterminated structured data": {
+			input:   `<165>1 2003-10-11T22:14:15.003Z host app - - [a@1 b="c"`,
+			wantErr: true,
+		},
+		"RFC5424 missing header fields": {
+			input:   "<165>1 2003-10-11T22:14:15.003Z host",
+			wantErr: true,
+		},
+		"no PRI": {
+			input:   "Oct 11 22:14:15 mymachine su: message",
+			wantErr: true,
+		},
+		"invalid PRI": {
+			input:   "<999>Oct 11 22:14:15 mymachine su: message",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg, err := parseSyslogMessage([]byte(test.input))
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want.priority, msg.priority)
+				assert.Equal(t, test.want.hostname, msg.hostname)
+				assert.Equal(t, test.want.tag, msg.tag)
+				assert.Equal(t, string(test.want.content), string(msg.content))
+			}
+		})
+	}
+}
+
+func Test_readSyslogFrames(t *testing.T) {
+	input := "12 <13>message1<13>message2\n<13>message3"
+	var frames []string
+
+	err := readSyslogFrames(bufio.NewReader(strings.NewReader(input)), func(frame []byte) {
+		frames = append(frames, string(frame))
+	})
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"<13>message1", "<13>message2\n", "<13>message3"}, frames)
+}
+
+func readSyslogLines(t *testing.T, l *SyslogListener, num int) []string {
+	t.Helper()
+	var lines []string
+	r := bufio.NewReader(l)
+	deadline := time.Now().Add(time.Second * 5)
+
+	for len(lines) < num && time.Now().Before(deadline) {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			time.Sleep(time.Millisecond * 10)
+			continue
+		}
+		require.NoError(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	return lines
+}
@@ -0,0 +1,23 @@
+#!/bin/sh
+# fake journalctl, it mimics 'journalctl --output=json' behaviour.
+
+for arg in "$@"; do
+  case "$arg" in
+  --follow)
+    echo '{"__CURSOR":"s=1","_SYSTEMD_UNIT":"nginx.service","MESSAGE":"10.0.0.1 - - \"GET / HTTP/1.1\" 200 612"}'
+    echo '{"__CURSOR":"s=2","_SYSTEMD_UNIT":"nginx.service","MESSAGE":[49,48,46,48,46,48,46,50]}'
+    echo 'not a json'
+    echo '{"__CURSOR":"s=3","_SYSTEMD_UNIT":"nginx.service"}'
+    echo '{"__CURSOR":"s=4","_SYSTEMD_UNIT":"nginx.service","MESSAGE":"10.0.0.3 - - \"GET / HTTP/1.1\" 404 0"}'
+    [ -n "$JOURNALCTL_EXIT" ] && exit 1
+    exec sleep 60
+    ;;
+  --lines=1)
+    echo '{"__CURSOR":"s=0","_SYSTEMD_UNIT":"nginx.service","MESSAGE":"10.0.0.0 - - \"GET / HTTP/1.1\" 200 1"}'
+    exit 0
+    ;;
+  esac
+done
+
+echo "unexpected arguments: $*" >&2
+exit 1
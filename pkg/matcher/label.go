@@ -0,0 +1,327 @@
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Labels is a set of key/value pairs.
+	Labels interface {
+		// Get returns the label value and whether the label exists.
+		Get(name string) (string, bool)
+	}
+
+	// LabelsMap is a map based Labels implementation.
+	LabelsMap map[string]string
+
+	// LabelMatcher is an interface that wraps MatchLabels method.
+	LabelMatcher interface {
+		// Perform match against given label set
+		MatchLabels(Labels) bool
+	}
+
+	labelCondMatcher struct {
+		name string
+		m    Matcher
+	}
+	labelAndMatcher struct{ lhs, rhs LabelMatcher }
+	labelOrMatcher  struct{ lhs, rhs LabelMatcher }
+	labelNegMatcher struct{ LabelMatcher }
+)
+
+// Get returns the label value and whether the label exists.
+func (l LabelsMap) Get(name string) (string, bool) { v, ok := l[name]; return v, ok }
+
+// MatchLabels matches the label value, a missing label value is an empty string.
+func (m labelCondMatcher) MatchLabels(lbs Labels) bool {
+	v, _ := lbs.Get(m.name)
+	return m.m.MatchString(v)
+}
+
+func (m labelAndMatcher) MatchLabels(lbs Labels) bool {
+	return m.lhs.MatchLabels(lbs) && m.rhs.MatchLabels(lbs)
+}
+func (m labelOrMatcher) MatchLabels(lbs Labels) bool {
+	return m.lhs.MatchLabels(lbs) || m.rhs.MatchLabels(lbs)
+}
+func (m labelNegMatcher) MatchLabels(lbs Labels) bool { return !m.LabelMatcher.MatchLabels(lbs) }
+
+// MustLabels is a helper that wraps a call to a function returning (LabelMatcher, error) and panics if the error is non-nil.
+func MustLabels(m LabelMatcher, err error) LabelMatcher {
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// ParseLabels parses label expression and returns a label set matcher.
+//
+// Syntax
+//
+//	<expr>      ::= <and> { '||' <and> }
+//	<and>       ::= <unary> { '&&' <unary> }
+//	<unary>     ::= '!' <unary> | '(' <expr> ')' | <cond>
+//	<cond>      ::= <name> <op> <value>
+//	<name>      ::= [a-zA-Z_] { [a-zA-Z0-9_.-] }
+//	<op>        ::= '=' | '!=' | '=~' | '!~' | '=*' | '!*' | ':'
+//	                  '=', '!=' string match
+//	                  '=~', '!~' regexp match
+//	                  '=*', '!*' glob match
+//	                  ':' the value is a matcher line (see Parse), e.g. 'simple_patterns:db* !db1'
+//	<value>     ::= double quoted string
+//
+// A missing label value is an empty string.
+func ParseLabels(expr string) (LabelMatcher, error) {
+	p := labelParser{input: expr}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == labelTokEOF {
+		return nil, ErrEmptyExpr
+	}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != labelTokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return m, nil
+}
+
+type labelTokenKind int
+
+const (
+	labelTokEOF labelTokenKind = iota
+	labelTokName
+	labelTokValue
+	labelTokOp
+	labelTokAnd
+	labelTokOr
+	labelTokNot
+	labelTokLParen
+	labelTokRParen
+)
+
+type labelToken struct {
+	kind labelTokenKind
+	val  string
+	pos  int
+}
+
+func (t labelToken) String() string {
+	if t.kind == labelTokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("'%s' at %d", t.val, t.pos)
+}
+
+var labelOps = []string{"=~", "!~", "=*", "!*", "!=", "=", ":"}
+
+type labelParser struct {
+	input string
+	pos   int
+	tok   labelToken
+}
+
+func (p *labelParser) parseOr() (LabelMatcher, error) {
+	lhs, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == labelTokOr {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		lhs = labelOrMatcher{lhs, rhs}
+	}
+	return lhs, nil
+}
+
+func (p *labelParser) parseAnd() (LabelMatcher, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == labelTokAnd {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = labelAndMatcher{lhs, rhs}
+	}
+	return lhs, nil
+}
+
+func (p *labelParser) parseUnary() (LabelMatcher, error) {
+	switch p.tok.kind {
+	case labelTokNot:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return labelNegMatcher{m}, nil
+	case labelTokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != labelTokRParen {
+			return nil, p.errorf("expected ')', got %s", p.tok)
+		}
+		return m, p.next()
+	case labelTokName:
+		return p.parseCond()
+	default:
+		return nil, p.errorf("expected label name, '!' or '(', got %s", p.tok)
+	}
+}
+
+func (p *labelParser) parseCond() (LabelMatcher, error) {
+	name := p.tok.val
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != labelTokOp {
+		return nil, p.errorf("expected operator after label '%s', got %s", name, p.tok)
+	}
+	op := p.tok.val
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != labelTokValue {
+		return nil, p.errorf("expected quoted value after '%s%s', got %s", name, op, p.tok)
+	}
+	value := p.tok.val
+
+	m, err := newLabelValueMatcher(op, value)
+	if err != nil {
+		return nil, fmt.Errorf("label '%s': %v", name, err)
+	}
+	return labelCondMatcher{name: name, m: m}, p.next()
+}
+
+func newLabelValueMatcher(op, value string) (Matcher, error) {
+	var m Matcher
+	var err error
+	switch op {
+	case "=", "!=":
+		m, err = New(FmtString, value)
+	case "=~", "!~":
+		m, err = New(FmtRegExp, value)
+	case "=*", "!*":
+		m, err = New(FmtGlob, value)
+	case ":":
+		m, err = Parse(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(op, "!") {
+		m = Not(m)
+	}
+	return m, nil
+}
+
+func (p *labelParser) next() error {
+	for p.pos < len(p.input) && isLabelSpace(p.input[p.pos]) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = labelToken{kind: labelTokEOF, pos: start}
+		return nil
+	}
+
+	rest := p.input[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "&&"):
+		p.tok, p.pos = labelToken{kind: labelTokAnd, val: "&&", pos: start}, p.pos+2
+		return nil
+	case strings.HasPrefix(rest, "||"):
+		p.tok, p.pos = labelToken{kind: labelTokOr, val: "||", pos: start}, p.pos+2
+		return nil
+	case rest[0] == '(':
+		p.tok, p.pos = labelToken{kind: labelTokLParen, val: "(", pos: start}, p.pos+1
+		return nil
+	case rest[0] == ')':
+		p.tok, p.pos = labelToken{kind: labelTokRParen, val: ")", pos: start}, p.pos+1
+		return nil
+	case rest[0] == '"':
+		end := quotedStringLen(rest)
+		if end == -1 {
+			return p.errorf("unterminated quoted value at %d", start)
+		}
+		v, err := strconv.Unquote(rest[:end])
+		if err != nil {
+			return p.errorf("bad quoted value at %d: %v", start, err)
+		}
+		p.tok, p.pos = labelToken{kind: labelTokValue, val: v, pos: start}, p.pos+end
+		return nil
+	case isLabelNameStart(rest[0]):
+		end := 1
+		for end < len(rest) && isLabelNameChar(rest[end]) {
+			end++
+		}
+		p.tok, p.pos = labelToken{kind: labelTokName, val: rest[:end], pos: start}, p.pos+end
+		return nil
+	}
+
+	// operators are only valid after a label name, '!' elsewhere is a negation
+	if p.tok.kind == labelTokName {
+		for _, op := range labelOps {
+			if strings.HasPrefix(rest, op) {
+				p.tok, p.pos = labelToken{kind: labelTokOp, val: op, pos: start}, p.pos+len(op)
+				return nil
+			}
+		}
+	}
+	if rest[0] == '!' {
+		p.tok, p.pos = labelToken{kind: labelTokNot, val: "!", pos: start}, p.pos+1
+		return nil
+	}
+	return p.errorf("unexpected character '%c' at %d", rest[0], start)
+}
+
+func (p *labelParser) errorf(format string, a ...interface{}) error {
+	return errors.New("label expression: " + fmt.Sprintf(format, a...))
+}
+
+// quotedStringLen returns the length of the double quoted string at the beginning of s, or -1 if it is unterminated.
+func quotedStringLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func isLabelSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+func isLabelNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isLabelNameChar(c byte) bool {
+	return isLabelNameStart(c) || (c >= '0' && c <= '9') || c == '.' || c == '-'
+}
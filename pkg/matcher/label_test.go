@@ -0,0 +1,136 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := map[string]struct {
+		expr    string
+		wantErr bool
+	}{
+		"string":             {expr: `name="db1"`},
+		"not string":         {expr: `name!="db1"`},
+		"regexp":             {expr: `name=~"db.*"`},
+		"not regexp":         {expr: `name!~"db.*"`},
+		"glob":               {expr: `name=*"db*"`},
+		"not glob":           {expr: `name!*"db*"`},
+		"matcher line":       {expr: `name:"simple_patterns:db* !db1"`},
+		"and":                {expr: `name=~"db.*" && cluster="prod"`},
+		"or":                 {expr: `name="a" || name="b"`},
+		"parens and not":     {expr: `!(name="a" || name="b") && cluster="prod"`},
+		"escaped quote":      {expr: `name="a\"b"`},
+		"dotted name":        {expr: `host.name="a"`},
+		"empty":              {expr: "", wantErr: true},
+		"spaces only":        {expr: "  ", wantErr: true},
+		"no operator":        {expr: `name "a"`, wantErr: true},
+		"no value":           {expr: `name=`, wantErr: true},
+		"unquoted value":     {expr: `name=a`, wantErr: true},
+		"unterminated value": {expr: `name="a`, wantErr: true},
+		"bad regexp":         {expr: `name=~"(a"`, wantErr: true},
+		"bad matcher line":   {expr: `name:"unknown:a"`, wantErr: true},
+		"unbalanced parens":  {expr: `(name="a"`, wantErr: true},
+		"trailing operator":  {expr: `name="a" &&`, wantErr: true},
+		"trailing token":     {expr: `name="a" name="b"`, wantErr: true},
+		"single ampersand":   {expr: `name="a" & name="b"`, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := ParseLabels(test.expr)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, m)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, m)
+			}
+		})
+	}
+}
+
+func TestLabelMatcher_MatchLabels(t *testing.T) {
+	prodDB := LabelsMap{"name": "db1", "cluster": "prod"}
+	devDB := LabelsMap{"name": "db2", "cluster": "dev"}
+	prodWeb := LabelsMap{"name": "web1", "cluster": "prod"}
+	noCluster := LabelsMap{"name": "db3"}
+
+	tests := map[string]struct {
+		expr string
+		want map[*LabelsMap]bool
+	}{
+		"and": {
+			expr: `name=~"^db" && cluster="prod"`,
+			want: map[*LabelsMap]bool{&prodDB: true, &devDB: false, &prodWeb: false, &noCluster: false},
+		},
+		"or": {
+			expr: `name="web1" || cluster="dev"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: true, &prodWeb: true, &noCluster: false},
+		},
+		"and binds tighter than or": {
+			expr: `name="web1" || name=*"db*" && cluster="dev"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: true, &prodWeb: true, &noCluster: false},
+		},
+		"parens": {
+			expr: `(name="web1" || name=*"db*") && cluster="dev"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: true, &prodWeb: false, &noCluster: false},
+		},
+		"negation": {
+			expr: `!(cluster="prod")`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: true, &prodWeb: false, &noCluster: true},
+		},
+		"missing label is empty": {
+			expr: `cluster=""`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: false, &prodWeb: false, &noCluster: true},
+		},
+		"not equal matches missing label": {
+			expr: `cluster!="prod"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: true, &prodWeb: false, &noCluster: true},
+		},
+		"not regexp": {
+			expr: `name!~"^db"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: false, &prodWeb: true, &noCluster: false},
+		},
+		"not glob": {
+			expr: `name!*"db*"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: false, &prodWeb: true, &noCluster: false},
+		},
+		"simple patterns": {
+			expr: `name:"simple_patterns:!db1 db*"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: true, &prodWeb: false, &noCluster: true},
+		},
+		"short syntax matcher line": {
+			expr: `name:"!~ ^db"`,
+			want: map[*LabelsMap]bool{&prodDB: false, &devDB: false, &prodWeb: true, &noCluster: false},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := ParseLabels(test.expr)
+			require.NoError(t, err)
+
+			for lbs, want := range test.want {
+				assert.Equalf(t, want, m.MatchLabels(*lbs), "labels %v", *lbs)
+			}
+		})
+	}
+}
+
+func TestMustLabels(t *testing.T) {
+	assert.NotPanics(t, func() { MustLabels(ParseLabels(`name="a"`)) })
+	assert.Panics(t, func() { MustLabels(ParseLabels(`name=`)) })
+}
+
+func BenchmarkLabelMatcher_MatchLabels(b *testing.B) {
+	m := MustLabels(ParseLabels(`name=~"^db" && cluster="prod" || name=*"web*"`))
+	lbs := LabelsMap{"name": "web1", "cluster": "prod"}
+
+	for i := 0; i < b.N; i++ {
+		m.MatchLabels(lbs)
+	}
+}
@@ -61,6 +61,12 @@ func (c *Counter) Add(v float64) {
 	c.valFloat += v
 }
 
+// Merge adds the other counter value to the counter.
+func (c *Counter) Merge(other Counter) {
+	c.valInt += other.valInt
+	c.valFloat += other.valFloat
+}
+
 // NewCounterVec creates a new CounterVec
 func NewCounterVec() CounterVec {
 	return CounterVec{}
@@ -51,6 +51,17 @@ func TestCounter_Add(t *testing.T) {
 	})
 }
 
+func TestCounter_Merge(t *testing.T) {
+	var c, other Counter
+	c.Inc()
+	other.Inc()
+	other.Add(0.5)
+
+	c.Merge(other)
+	assert.Equal(t, 2.5, c.Value())
+	assert.Equal(t, 1.5, other.Value())
+}
+
 func BenchmarkCounter_Add(b *testing.B) {
 	benchmarks := []struct {
 		name  string
@@ -21,6 +21,9 @@ type (
 	// To create histogram instances, use NewHistogram.
 	Histogram interface {
 		Observer
+		Reset()
+		// Merge adds the other histogram observations to the histogram.
+		Merge(other Histogram)
 	}
 
 	histogram struct {
@@ -130,6 +133,37 @@ func (h *histogram) Observe(v float64) {
 	h.count++
 }
 
+// Reset resets all of its buckets, sum and count.
+func (h *histogram) Reset() {
+	for i := range h.buckets {
+		h.buckets[i] = 0
+	}
+	h.sum = 0
+	h.count = 0
+}
+
+// Merge adds the other histogram observations to the histogram.
+// It panics if the other is not created by NewHistogram or has different buckets.
+func (h *histogram) Merge(other Histogram) {
+	o, ok := other.(*histogram)
+	if !ok {
+		panic(fmt.Sprintf("histogram merge: unexpected histogram type %T", other))
+	}
+	if len(o.upperBounds) != len(h.upperBounds) {
+		panic("histogram merge: buckets mismatch")
+	}
+	for i, upper := range o.upperBounds {
+		if upper != h.upperBounds[i] {
+			panic("histogram merge: buckets mismatch")
+		}
+	}
+	for i, v := range o.buckets {
+		h.buckets[i] += v
+	}
+	h.sum += o.sum
+	h.count += o.count
+}
+
 func (h *histogram) searchBucketIndex(v float64) int {
 	if len(h.upperBounds) < 30 {
 		for i, upper := range h.upperBounds {
@@ -71,6 +71,33 @@ func TestHistogram_WriteTo(t *testing.T) {
 	assert.EqualValues(t, 2, m["pi_bucket_3"])
 }
 
+func TestHistogram_Merge(t *testing.T) {
+	h := NewHistogram([]float64{1, 2, 3})
+	h.Observe(1)
+	other := NewHistogram([]float64{1, 2, 3})
+	other.Observe(2)
+	other.Observe(4)
+
+	h.Merge(other)
+	m := map[string]int64{}
+	h.WriteTo(m, "h", 1, 1)
+	assert.Equal(t, map[string]int64{"h_sum": 7, "h_count": 3, "h_bucket_1": 1, "h_bucket_2": 2, "h_bucket_3": 2}, m)
+
+	assert.Panics(t, func() { h.Merge(NewHistogram([]float64{1, 2})) })
+	assert.Panics(t, func() { h.Merge(NewHistogram([]float64{1, 2, 4})) })
+}
+
+func TestHistogram_Reset(t *testing.T) {
+	h := NewHistogram([]float64{1, 2, 3})
+	h.Observe(1)
+	h.Observe(4)
+
+	h.Reset()
+	m := map[string]int64{}
+	h.WriteTo(m, "h", 1, 1)
+	assert.Equal(t, map[string]int64{"h_sum": 0, "h_count": 0, "h_bucket_1": 0, "h_bucket_2": 0, "h_bucket_3": 0}, m)
+}
+
 func TestHistogram_searchBucketIndex(t *testing.T) {
 	h := NewHistogram(LinearBuckets(1, 1, 5)).(*histogram) // [1, 2, ..., 5]
 	assert.Equal(t, 0, h.searchBucketIndex(0.1))
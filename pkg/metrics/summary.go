@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/netdata/go.d.plugin/pkg/stm"
@@ -17,6 +18,8 @@ type (
 	Summary interface {
 		Observer
 		Reset()
+		// Merge adds the other summary observations to the summary.
+		Merge(other Summary)
 	}
 
 	// SummaryVec is a Collector that bundles a set of Summary which have different values for their names.
@@ -91,6 +94,26 @@ func (s *summary) Observe(v float64) {
 	s.count++
 }
 
+// Merge adds the other summary observations to the summary.
+// It panics if the other is not created by NewSummary.
+func (s *summary) Merge(other Summary) {
+	o, ok := other.(*summary)
+	if !ok {
+		panic(fmt.Sprintf("summary merge: unexpected summary type %T", other))
+	}
+	if o.count == 0 {
+		return
+	}
+	if o.max > s.max {
+		s.max = o.max
+	}
+	if o.min < s.min {
+		s.min = o.min
+	}
+	s.sum += o.sum
+	s.count += o.count
+}
+
 // NewSummaryVec creates a new SummaryVec instance.
 func NewSummaryVec() SummaryVec {
 	return SummaryVec{}
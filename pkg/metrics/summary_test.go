@@ -57,6 +57,25 @@ func TestSummary_Reset(t *testing.T) {
 	assert.EqualValues(t, 0, s.count)
 }
 
+func TestSummary_Merge(t *testing.T) {
+	s := NewSummary()
+	s.Observe(5)
+	other := NewSummary()
+	other.Observe(1)
+	other.Observe(10)
+
+	s.Merge(other)
+	m := map[string]int64{}
+	s.WriteTo(m, "s", 1, 1)
+	assert.Equal(t, map[string]int64{"s_min": 1, "s_max": 10, "s_sum": 16, "s_count": 3, "s_avg": 5}, m)
+
+	s.Merge(NewSummary())
+	m = map[string]int64{}
+	s.WriteTo(m, "s", 1, 1)
+	assert.EqualValues(t, 1, m["s_min"])
+	assert.EqualValues(t, 3, m["s_count"])
+}
+
 func BenchmarkSummary_Observe(b *testing.B) {
 	s := NewSummary()
 	for i := 0; i < b.N; i++ {
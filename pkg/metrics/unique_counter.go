@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"fmt"
+
 	"github.com/axiomhq/hyperloglog"
 	"github.com/netdata/go.d.plugin/pkg/stm"
 )
@@ -11,6 +13,8 @@ type (
 		Insert(s string)
 		Value() int
 		Reset()
+		// Merge adds the other counter items to the counter.
+		Merge(other UniqueCounter)
 	}
 
 	mapUniqueCounter struct {
@@ -52,6 +56,18 @@ func (c mapUniqueCounter) Value() int {
 	return len(c.m)
 }
 
+// Merge adds the other counter items to the counter.
+// It panics if the other is not a map based counter.
+func (c mapUniqueCounter) Merge(other UniqueCounter) {
+	o, ok := other.(mapUniqueCounter)
+	if !ok {
+		panic(fmt.Sprintf("unique counter merge: unexpected counter type %T", other))
+	}
+	for key := range o.m {
+		c.m[key] = true
+	}
+}
+
 func (c mapUniqueCounter) Reset() {
 	for key := range c.m {
 		delete(c.m, key)
@@ -71,6 +87,18 @@ func (c *hyperLogLogUniqueCounter) Value() int {
 	return int(c.sketch.Estimate())
 }
 
+// Merge adds the other counter items to the counter.
+// It panics if the other is not a HyperLogLog based counter.
+func (c *hyperLogLogUniqueCounter) Merge(other UniqueCounter) {
+	o, ok := other.(*hyperLogLogUniqueCounter)
+	if !ok {
+		panic(fmt.Sprintf("unique counter merge: unexpected counter type %T", other))
+	}
+	if err := c.sketch.Merge(o.sketch); err != nil {
+		panic(fmt.Sprintf("unique counter merge: %v", err))
+	}
+}
+
 func (c *hyperLogLogUniqueCounter) Reset() {
 	c.sketch = hyperloglog.New()
 }
@@ -72,6 +72,22 @@ func TestUniqueCounterVec_Reset(t *testing.T) {
 	}
 }
 
+func TestUniqueCounter_Merge(t *testing.T) {
+	for _, useHLL := range []bool{false, true} {
+		c := NewUniqueCounter(useHLL)
+		c.Insert("a")
+		c.Insert("b")
+		other := NewUniqueCounter(useHLL)
+		other.Insert("b")
+		other.Insert("c")
+
+		c.Merge(other)
+		assert.Equal(t, 3, c.Value())
+		assert.Equal(t, 2, other.Value())
+		assert.Panics(t, func() { c.Merge(NewUniqueCounter(!useHLL)) })
+	}
+}
+
 func BenchmarkUniqueCounter_Insert(b *testing.B) {
 	benchmarks := []struct {
 		name        string
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+type (
+	// Bucket is a histogram bucket: the upper bound ('le' label value) and the cumulative count.
+	Bucket struct {
+		UpperBound float64
+		Count      float64
+	}
+	// Buckets is a list of histogram buckets sorted by the upper bound.
+	Buckets []Bucket
+)
+
+// Buckets returns the histogram buckets of the metrics, the metrics are expected to be the buckets
+// of a single histogram ('_bucket' time series with the same labels except 'le').
+// Time series without a valid 'le' label are skipped.
+func (m Metrics) Buckets() Buckets {
+	buckets := make(Buckets, 0, len(m))
+	for _, pm := range m {
+		le, err := strconv.ParseFloat(pm.Labels.Get("le"), 64)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, Bucket{UpperBound: le, Count: pm.Value})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].UpperBound < buckets[j].UpperBound })
+	return buckets
+}
+
+// Delta returns the observations since the previous buckets.
+// It returns false if the bucket layout has changed or the histogram has been reset.
+func (b Buckets) Delta(prev Buckets) (Buckets, bool) {
+	if len(b) != len(prev) {
+		return nil, false
+	}
+	delta := make(Buckets, len(b))
+	for i := range b {
+		if b[i].UpperBound != prev[i].UpperBound || b[i].Count < prev[i].Count {
+			return nil, false
+		}
+		delta[i] = Bucket{UpperBound: b[i].UpperBound, Count: b[i].Count - prev[i].Count}
+	}
+	return delta, true
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) using linear interpolation within a bucket,
+// the same way the Prometheus histogram_quantile function does.
+// It returns NaN if there are no observations or there is no '+Inf' bucket.
+func (b Buckets) Quantile(q float64) float64 {
+	switch {
+	case math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(+1)
+	}
+	if len(b) < 2 || !math.IsInf(b[len(b)-1].UpperBound, +1) {
+		return math.NaN()
+	}
+
+	// buckets are cumulative, but float rounding may break it
+	counts := make([]float64, len(b))
+	var max float64
+	for i, bucket := range b {
+		if bucket.Count > max {
+			max = bucket.Count
+		}
+		counts[i] = max
+	}
+
+	observations := counts[len(counts)-1]
+	if observations == 0 {
+		return math.NaN()
+	}
+	rank := q * observations
+	i := sort.Search(len(b)-1, func(i int) bool { return counts[i] >= rank })
+
+	switch {
+	case i == len(b)-1:
+		return b[len(b)-2].UpperBound
+	case i == 0 && b[0].UpperBound <= 0:
+		return b[0].UpperBound
+	}
+
+	var start float64
+	end, count := b[i].UpperBound, counts[i]
+	if i > 0 {
+		start = b[i-1].UpperBound
+		count -= counts[i-1]
+		rank -= counts[i-1]
+	}
+	return start + (end-start)*(rank/count)
+}
+
+// CounterDelta returns the counter increase since the previous value.
+// It returns false if the counter has been reset.
+func CounterDelta(value, prev float64) (float64, bool) {
+	if value < prev {
+		return 0, false
+	}
+	return value - prev, true
+}
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Buckets(t *testing.T) {
+	pms := Metrics{
+		{Labels: labels.FromStrings("__name__", "a_bucket", "le", "+Inf"), Value: 10},
+		{Labels: labels.FromStrings("__name__", "a_bucket", "le", "0.5"), Value: 5},
+		{Labels: labels.FromStrings("__name__", "a_bucket", "le", "0.1"), Value: 2},
+		{Labels: labels.FromStrings("__name__", "a_bucket", "le", "bad"), Value: 1},
+		{Labels: labels.FromStrings("__name__", "a_bucket"), Value: 1},
+	}
+
+	expected := Buckets{
+		{UpperBound: 0.1, Count: 2},
+		{UpperBound: 0.5, Count: 5},
+		{UpperBound: math.Inf(+1), Count: 10},
+	}
+	assert.Equal(t, expected, pms.Buckets())
+}
+
+func TestBuckets_Delta(t *testing.T) {
+	prev := Buckets{{1, 2}, {2, 5}, {math.Inf(+1), 10}}
+
+	tests := map[string]struct {
+		cur     Buckets
+		want    Buckets
+		wantErr bool
+	}{
+		"increase":       {cur: Buckets{{1, 3}, {2, 7}, {math.Inf(+1), 15}}, want: Buckets{{1, 1}, {2, 2}, {math.Inf(+1), 5}}},
+		"no change":      {cur: prev, want: Buckets{{1, 0}, {2, 0}, {math.Inf(+1), 0}}},
+		"reset":          {cur: Buckets{{1, 0}, {2, 1}, {math.Inf(+1), 1}}, wantErr: true},
+		"changed bounds": {cur: Buckets{{1, 3}, {3, 7}, {math.Inf(+1), 15}}, wantErr: true},
+		"changed length": {cur: Buckets{{1, 3}, {math.Inf(+1), 15}}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			delta, ok := test.cur.Delta(prev)
+
+			if test.wantErr {
+				assert.False(t, ok)
+			} else {
+				assert.True(t, ok)
+				assert.Equal(t, test.want, delta)
+			}
+		})
+	}
+}
+
+func TestBuckets_Quantile(t *testing.T) {
+	buckets := Buckets{{0.1, 50}, {0.5, 90}, {1, 99}, {math.Inf(+1), 100}}
+
+	tests := map[string]struct {
+		buckets Buckets
+		q       float64
+		want    float64
+	}{
+		"p50":                 {buckets: buckets, q: 0.5, want: 0.1},
+		"p25":                 {buckets: buckets, q: 0.25, want: 0.05},
+		"p70":                 {buckets: buckets, q: 0.7, want: 0.3},
+		"p90":                 {buckets: buckets, q: 0.9, want: 0.5},
+		"p99":                 {buckets: buckets, q: 0.99, want: 1},
+		"p100 in +Inf bucket": {buckets: buckets, q: 1, want: 1},
+		"q < 0":               {buckets: buckets, q: -1, want: math.Inf(-1)},
+		"q > 1":               {buckets: buckets, q: 2, want: math.Inf(+1)},
+		"no observations":     {buckets: Buckets{{0.1, 0}, {math.Inf(+1), 0}}, q: 0.5, want: math.NaN()},
+		"no +Inf bucket":      {buckets: Buckets{{0.1, 1}, {0.5, 2}}, q: 0.5, want: math.NaN()},
+		"single bucket":       {buckets: Buckets{{math.Inf(+1), 2}}, q: 0.5, want: math.NaN()},
+		"negative first bound": {
+			buckets: Buckets{{-1, 10}, {0, 20}, {math.Inf(+1), 20}},
+			q:       0.25,
+			want:    -1,
+		},
+		"non monotonic": {
+			buckets: Buckets{{0.1, 50}, {0.5, 49}, {1, 100}, {math.Inf(+1), 100}},
+			q:       0.5,
+			want:    0.1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := test.buckets.Quantile(test.q)
+
+			if math.IsNaN(test.want) {
+				assert.True(t, math.IsNaN(got), "want NaN, got %v", got)
+			} else {
+				assert.InDelta(t, test.want, got, 1e-9)
+			}
+		})
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	delta, ok := CounterDelta(15, 10)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, delta)
+
+	_, ok = CounterDelta(5, 10)
+	assert.False(t, ok)
+}
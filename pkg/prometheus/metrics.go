@@ -18,6 +18,7 @@ type (
 	MetaEntry struct {
 		Help string
 		Type textparse.MetricType
+		Unit string
 	}
 
 	Metadata map[string]*MetaEntry
@@ -130,25 +131,61 @@ func (m Metrics) Max() float64 {
 }
 
 func (m Metadata) Help(name string) string {
-	entry, ok := m[name]
-	if !ok {
-		if strings.HasSuffix(name, "_bucket") {
-			return m.Help(name[:len(name)-len("_bucket")])
-		}
-		return ""
+	if entry, ok := m.lookup(name); ok {
+		return entry.Help
 	}
-	return entry.Help
+	return ""
 }
 
 func (m Metadata) Type(name string) textparse.MetricType {
-	entry, ok := m[name]
+	if entry, ok := m.lookup(name); ok {
+		return entry.Type
+	}
+	return textparse.MetricTypeUnknown
+}
+
+// Unit returns the metric unit, it is set only by the OpenMetrics format.
+func (m Metadata) Unit(name string) string {
+	if entry, ok := m.lookup(name); ok {
+		return entry.Unit
+	}
+	return ""
+}
+
+// lookup finds the metric family entry of the series name.
+// OpenMetrics counters and info metrics are exposed with a suffix that is not a part of the family name.
+func (m Metadata) lookup(name string) (*MetaEntry, bool) {
+	if entry, ok := m[name]; ok {
+		return entry, true
+	}
+	switch {
+	case strings.HasSuffix(name, "_bucket"):
+		return m.lookup(name[:len(name)-len("_bucket")])
+	case strings.HasSuffix(name, "_total"):
+		entry, ok := m[name[:len(name)-len("_total")]]
+		return entry, ok && entry.Type == textparse.MetricTypeCounter
+	case strings.HasSuffix(name, "_info"):
+		entry, ok := m[name[:len(name)-len("_info")]]
+		return entry, ok && entry.Type == textparse.MetricTypeInfo
+	}
+	return nil, false
+}
+
+// isCreatedSeries reports whether the series is an OpenMetrics '_created' series of a counter, summary or histogram.
+// These are creation timestamps, not values.
+func (m Metadata) isCreatedSeries(name string) bool {
+	if !strings.HasSuffix(name, "_created") {
+		return false
+	}
+	entry, ok := m[name[:len(name)-len("_created")]]
 	if !ok {
-		if strings.HasSuffix(name, "_bucket") {
-			return m.Type(name[:len(name)-len("_bucket")])
-		}
-		return textparse.MetricTypeUnknown
+		return false
+	}
+	switch entry.Type {
+	case textparse.MetricTypeCounter, textparse.MetricTypeSummary, textparse.MetricTypeHistogram:
+		return true
 	}
-	return entry.Type
+	return false
 }
 
 func (m Metadata) setHelp(metric, help []byte) {
@@ -171,6 +208,17 @@ func (m Metadata) setType(metric []byte, mType textparse.MetricType) {
 	entry.Type = mType
 }
 
+func (m Metadata) setUnit(metric, unit []byte) {
+	entry, ok := m[unsafeString(metric)]
+	if !ok {
+		entry = &MetaEntry{Type: textparse.MetricTypeUnknown}
+		m[string(metric)] = entry
+	}
+	if entry.Unit != unsafeString(unit) {
+		entry.Unit = string(unit)
+	}
+}
+
 func (m Metadata) reset() {
 	for key := range m {
 		delete(m, key)
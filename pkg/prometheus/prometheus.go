@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strings"
 
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/prometheus/selector"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
@@ -31,6 +34,7 @@ type (
 		metrics  Metrics
 		metadata Metadata
 		sr       selector.Selector
+		rl       relabel.Processor
 
 		// internal use
 		buf     *bytes.Buffer
@@ -40,10 +44,20 @@ type (
 )
 
 const (
-	acceptHeader    = `text/plain;version=0.0.4;q=1,*/*;q=0.1`
+	acceptHeader = `application/openmetrics-text;version=0.0.1;q=0.9,` +
+		`application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,` +
+		`text/plain;version=0.0.4;q=0.5,*/*;q=0.1`
 	userAgentHeader = `netdata/go.d.plugin`
 )
 
+type format int
+
+const (
+	formatText format = iota
+	formatOpenMetrics
+	formatProtobuf
+)
+
 // New creates a Prometheus instance.
 func New(client *http.Client, request web.Request) Prometheus {
 	return &prometheus{
@@ -65,6 +79,19 @@ func NewWithSelector(client *http.Client, request web.Request, sr selector.Selec
 	}
 }
 
+// NewWithRelabeling creates a Prometheus instance with the selector and the relabeling processor, both are optional.
+// The selector is applied to the scraped time series, the relabeling is applied to the selected ones.
+func NewWithRelabeling(client *http.Client, request web.Request, sr selector.Selector, rl relabel.Processor) Prometheus {
+	return &prometheus{
+		client:   client,
+		request:  request,
+		metadata: make(Metadata),
+		sr:       sr,
+		rl:       rl,
+		buf:      bytes.NewBuffer(make([]byte, 0, 16000)),
+	}
+}
+
 // Scrape scrapes metrics, parses and sorts
 func (p *prometheus) Scrape() (Metrics, error) {
 	p.metrics.Reset()
@@ -82,14 +109,30 @@ func (p prometheus) Metadata() Metadata {
 
 func (p *prometheus) scrape(metrics *Metrics, meta Metadata) error {
 	p.buf.Reset()
-	if err := p.fetch(p.buf); err != nil {
+	contentType, err := p.fetch(p.buf)
+	if err != nil {
 		return err
 	}
-	return p.parse(p.buf.Bytes(), metrics, meta)
+
+	switch formatOf(contentType) {
+	case formatProtobuf:
+		return p.parseProtobuf(p.buf, metrics, meta)
+	case formatOpenMetrics:
+		return p.parseOpenMetrics(p.buf.Bytes(), metrics, meta)
+	default:
+		return p.parse(p.buf.Bytes(), metrics, meta)
+	}
 }
 
 func (p *prometheus) parse(prometheusText []byte, metrics *Metrics, meta Metadata) error {
-	parser := textparse.NewPromParser(prometheusText)
+	return p.parseText(textparse.NewPromParser(prometheusText), metrics, meta)
+}
+
+func (p *prometheus) parseOpenMetrics(openMetricsText []byte, metrics *Metrics, meta Metadata) error {
+	return p.parseText(textparse.NewOpenMetricsParser(openMetricsText), metrics, meta)
+}
+
+func (p *prometheus) parseText(parser textparse.Parser, metrics *Metrics, meta Metadata) error {
 	for {
 		entry, err := parser.Next()
 		if err != nil {
@@ -104,23 +147,40 @@ func (p *prometheus) parse(prometheusText []byte, metrics *Metrics, meta Metadat
 			var lbs labels.Labels
 			_, _, val := parser.Series()
 			parser.Metric(&lbs)
-			if p.sr != nil && !p.sr.Matches(lbs) {
+			if meta.isCreatedSeries(lbs[0].Value) {
 				continue
 			}
-			metrics.Add(Metric{lbs, val})
+			p.add(metrics, lbs, val)
 		case textparse.EntryType:
 			meta.setType(parser.Type())
 		case textparse.EntryHelp:
 			meta.setHelp(parser.Help())
+		case textparse.EntryUnit:
+			meta.setUnit(parser.Unit())
 		}
 	}
 	return nil
 }
 
-func (p *prometheus) fetch(w io.Writer) error {
+// add applies the selector and the relabeling and adds the time series.
+func (p *prometheus) add(metrics *Metrics, lbs labels.Labels, value float64) {
+	if p.sr != nil && !p.sr.Matches(lbs) {
+		return
+	}
+	if p.rl != nil {
+		var ok bool
+		if lbs, ok = p.rl.Process(lbs); !ok {
+			return
+		}
+	}
+	metrics.Add(Metric{lbs, value})
+}
+
+// fetch writes the decompressed response body to w and returns the response content type.
+func (p *prometheus) fetch(w io.Writer) (string, error) {
 	req, err := web.NewHTTPRequest(p.request)
 	if err != nil {
-		return err
+		return "", err
 	}
 	req.Header.Add("Accept", acceptHeader)
 	req.Header.Add("Accept-Encoding", "gzip")
@@ -128,7 +188,7 @@ func (p *prometheus) fetch(w io.Writer) error {
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	defer func() {
@@ -137,19 +197,21 @@ func (p *prometheus) fetch(w io.Writer) error {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server returned HTTP status %s", resp.Status)
+		return "", fmt.Errorf("server returned HTTP status %s", resp.Status)
 	}
 
+	contentType := resp.Header.Get("Content-Type")
+
 	if resp.Header.Get("Content-Encoding") != "gzip" {
 		_, err = io.Copy(w, resp.Body)
-		return err
+		return contentType, err
 	}
 
 	if p.gzipr == nil {
 		p.bodybuf = bufio.NewReader(resp.Body)
 		p.gzipr, err = gzip.NewReader(p.bodybuf)
 		if err != nil {
-			return err
+			return "", err
 		}
 	} else {
 		p.bodybuf.Reset(resp.Body)
@@ -157,5 +219,21 @@ func (p *prometheus) fetch(w io.Writer) error {
 	}
 	_, err = io.Copy(w, p.gzipr)
 	_ = p.gzipr.Close()
-	return err
+	return contentType, err
+}
+
+func formatOf(contentType string) format {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return formatText
+	}
+	switch mediaType {
+	case "application/openmetrics-text":
+		return formatOpenMetrics
+	case "application/vnd.google.protobuf":
+		if params["proto"] == "io.prometheus.client.MetricFamily" && strings.EqualFold(params["encoding"], "delimited") {
+			return formatProtobuf
+		}
+	}
+	return formatText
 }
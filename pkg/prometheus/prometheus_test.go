@@ -9,10 +9,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/netdata/go.d.plugin/pkg/prometheus/relabel"
 	"github.com/netdata/go.d.plugin/pkg/prometheus/selector"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -127,3 +131,222 @@ func verifyTestData(t *testing.T, ms Metrics) {
 	assert.Len(t, intervalQ90, 1)
 	assert.InDelta(t, 0.052614556, intervalQ90[0].Value, 0.000001)
 }
+
+func TestPrometheus_Scrape_ContentNegotiation(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		body        []byte
+		wantSeries  int
+	}{
+		"text": {
+			contentType: "text/plain; version=0.0.4; charset=utf-8",
+			body:        testdata,
+			wantSeries:  410,
+		},
+		"no content type": {
+			body:       testdata,
+			wantSeries: 410,
+		},
+		"openmetrics": {
+			contentType: "application/openmetrics-text; version=0.0.1; charset=utf-8",
+			body:        testdataOpenMetrics,
+			wantSeries:  11,
+		},
+		"protobuf": {
+			contentType: "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited",
+			body:        testdataProtobuf(t),
+			wantSeries:  12,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var accept string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept")
+				if test.contentType != "" {
+					w.Header().Set("Content-Type", test.contentType)
+				}
+				_, _ = w.Write(test.body)
+			}))
+			defer ts.Close()
+
+			prom := New(http.DefaultClient, web.Request{URL: ts.URL})
+			res, err := prom.Scrape()
+			require.NoError(t, err)
+
+			assert.Contains(t, accept, "application/openmetrics-text")
+			assert.Contains(t, accept, "encoding=delimited")
+			assert.Len(t, res, test.wantSeries)
+		})
+	}
+}
+
+func TestPrometheus_parseOpenMetrics(t *testing.T) {
+	res := Metrics{}
+	meta := Metadata{}
+	prom := prometheus{}
+	require.NoError(t, prom.parseOpenMetrics(testdataOpenMetrics, &res, meta))
+	res.Sort()
+
+	assert.Len(t, res.FindByName("http_requests_total"), 2)
+	assert.Len(t, res.FindByName("http_requests_created"), 0)
+	assert.Len(t, res.FindByName("request_duration_seconds_created"), 0)
+	assert.Len(t, res.FindByName("request_duration_seconds_bucket"), 3)
+	assert.Len(t, res.FindByName("build_info"), 1)
+	assert.Len(t, res.FindByName("service_state"), 2)
+
+	assert.Equal(t, textparse.MetricType(textparse.MetricTypeCounter), meta.Type("http_requests_total"))
+	assert.Equal(t, "Total number of HTTP requests.", meta.Help("http_requests_total"))
+	assert.Equal(t, textparse.MetricType(textparse.MetricTypeHistogram), meta.Type("request_duration_seconds_bucket"))
+	assert.Equal(t, "seconds", meta.Unit("request_duration_seconds_bucket"))
+	assert.Equal(t, textparse.MetricType(textparse.MetricTypeInfo), meta.Type("build_info"))
+	assert.Equal(t, textparse.MetricType(textparse.MetricTypeStateset), meta.Type("service_state"))
+	assert.Equal(t, "bytes", meta.Unit("memory_used_bytes"))
+}
+
+func TestPrometheus_parseOpenMetrics_NoEOF(t *testing.T) {
+	prom := prometheus{}
+	err := prom.parseOpenMetrics([]byte("# TYPE a gauge\na 1\n"), &Metrics{}, Metadata{})
+	assert.Error(t, err)
+}
+
+func TestPrometheus_parseProtobuf(t *testing.T) {
+	res := Metrics{}
+	meta := Metadata{}
+	prom := prometheus{}
+	require.NoError(t, prom.parseProtobuf(bytes.NewReader(testdataProtobuf(t)), &res, meta))
+	res.Sort()
+
+	reqs := res.FindByName("http_requests_total")
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "http_requests_total", reqs[0].Name())
+	assert.Equal(t, "200", reqs[0].Labels.Get("code"))
+	assert.Equal(t, "get", reqs[0].Labels.Get("method"))
+
+	gc := res.FindByName("go_gc_duration_seconds")
+	require.Len(t, gc, 2)
+	assert.ElementsMatch(t, []string{"0.5", "1"}, []string{gc[0].Labels.Get("quantile"), gc[1].Labels.Get("quantile")})
+	assert.Len(t, res.FindByName("go_gc_duration_seconds_sum"), 1)
+	assert.Len(t, res.FindByName("go_gc_duration_seconds_count"), 1)
+
+	buckets := res.FindByName("request_duration_seconds_bucket")
+	require.Len(t, buckets, 3)
+	m, _ := labels.NewMatcher(labels.MatchEqual, "le", "+Inf")
+	inf := buckets.Match(m)
+	require.Len(t, inf, 1)
+	assert.Equal(t, 17.0, inf[0].Value)
+
+	assert.Len(t, res.FindByName("go_goroutines"), 1)
+	assert.Equal(t, textparse.MetricType(textparse.MetricTypeCounter), meta.Type("http_requests_total"))
+	assert.Equal(t, textparse.MetricType(textparse.MetricTypeHistogram), meta.Type("request_duration_seconds_bucket"))
+	assert.Equal(t, "Number of goroutines.", meta.Help("go_goroutines"))
+}
+
+func TestPrometheus_parseProtobuf_WithSelector(t *testing.T) {
+	res := Metrics{}
+	sr, err := selector.Parse("go_*")
+	require.NoError(t, err)
+	prom := prometheus{sr: sr}
+	require.NoError(t, prom.parseProtobuf(bytes.NewReader(testdataProtobuf(t)), &res, Metadata{}))
+
+	require.NotEmpty(t, res)
+	for _, v := range res {
+		assert.Truef(t, strings.HasPrefix(v.Name(), "go_"), v.Name())
+	}
+}
+
+var testdataOpenMetrics, _ = ioutil.ReadFile("tests/testdata.openmetrics.txt")
+
+func testdataProtobuf(t *testing.T) []byte {
+	label := func(name, value string) *dto.LabelPair { return &dto.LabelPair{Name: &name, Value: &value} }
+	float := func(v float64) *float64 { return &v }
+	uint := func(v uint64) *uint64 { return &v }
+	str := func(v string) *string { return &v }
+	typ := func(v dto.MetricType) *dto.MetricType { return &v }
+
+	families := []*dto.MetricFamily{
+		{
+			Name: str("http_requests_total"),
+			Help: str("Total number of HTTP requests."),
+			Type: typ(dto.MetricType_COUNTER),
+			Metric: []*dto.Metric{
+				{Label: []*dto.LabelPair{label("method", "get"), label("code", "200")}, Counter: &dto.Counter{Value: float(1027)}},
+				{Label: []*dto.LabelPair{label("method", "post"), label("code", "400")}, Counter: &dto.Counter{Value: float(3)}},
+			},
+		},
+		{
+			Name: str("go_goroutines"),
+			Help: str("Number of goroutines."),
+			Type: typ(dto.MetricType_GAUGE),
+			Metric: []*dto.Metric{
+				{Gauge: &dto.Gauge{Value: float(33)}},
+			},
+		},
+		{
+			Name: str("go_gc_duration_seconds"),
+			Type: typ(dto.MetricType_SUMMARY),
+			Metric: []*dto.Metric{
+				{Summary: &dto.Summary{
+					SampleCount: uint(99),
+					SampleSum:   float(0.012),
+					Quantile: []*dto.Quantile{
+						{Quantile: float(0.5), Value: float(8.3e-05)},
+						{Quantile: float(1), Value: float(0.002)},
+					},
+				}},
+			},
+		},
+		{
+			Name: str("request_duration_seconds"),
+			Type: typ(dto.MetricType_HISTOGRAM),
+			Metric: []*dto.Metric{
+				{Histogram: &dto.Histogram{
+					SampleCount: uint(17),
+					SampleSum:   float(12.5),
+					Bucket: []*dto.Bucket{
+						{UpperBound: float(0.1), CumulativeCount: uint(10)},
+						{UpperBound: float(1), CumulativeCount: uint(15)},
+					},
+				}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	for _, mf := range families {
+		_, err := pbutil.WriteDelimited(&buf, mf)
+		require.NoError(t, err)
+	}
+	return buf.Bytes()
+}
+
+func TestPrometheus_Scrape_WithRelabeling(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testdata)
+	}))
+	defer ts.Close()
+
+	sr, err := selector.Parse("go_gc_duration_seconds")
+	require.NoError(t, err)
+	rl, err := relabel.Configs{
+		MetricRelabelConfigs: []relabel.Config{
+			{SourceLabels: []string{"quantile"}, TargetLabel: "q"},
+			{Action: relabel.ActionLabelDrop, Regex: strPtr("quantile")},
+		},
+	}.Parse()
+	require.NoError(t, err)
+
+	prom := NewWithRelabeling(http.DefaultClient, web.Request{URL: ts.URL}, sr, rl)
+	res, err := prom.Scrape()
+	require.NoError(t, err)
+
+	require.Len(t, res, 5)
+	for _, v := range res {
+		assert.Equal(t, "go_gc_duration_seconds", v.Name())
+		assert.False(t, v.Labels.Has("quantile"))
+		assert.True(t, v.Labels.Has("q"))
+	}
+}
+
+func strPtr(s string) *string { return &s }
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+)
+
+// parseProtobuf parses the protobuf delimited format.
+// Summaries and histograms are flattened to the same series the text format exposes.
+func (p *prometheus) parseProtobuf(r io.Reader, metrics *Metrics, meta Metadata) error {
+	for {
+		var mf dto.MetricFamily
+		if _, err := pbutil.ReadDelimited(r, &mf); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		name := mf.GetName()
+		meta.setType([]byte(name), protobufMetricType(mf.GetType()))
+		if mf.Help != nil {
+			meta.setHelp([]byte(name), []byte(mf.GetHelp()))
+		}
+
+		for _, m := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				p.addProtobufSeries(metrics, name, m, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				p.addProtobufSeries(metrics, name, m, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				p.addProtobufSeries(metrics, name, m, m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					p.addProtobufSeries(metrics, name, m, q.GetValue(), "quantile", formatFloat(q.GetQuantile()))
+				}
+				p.addProtobufSeries(metrics, name+"_sum", m, s.GetSampleSum())
+				p.addProtobufSeries(metrics, name+"_count", m, float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				var hasInf bool
+				for _, b := range h.GetBucket() {
+					hasInf = hasInf || math.IsInf(b.GetUpperBound(), +1)
+					p.addProtobufSeries(metrics, name+"_bucket", m, float64(b.GetCumulativeCount()),
+						"le", formatFloat(b.GetUpperBound()))
+				}
+				if !hasInf {
+					p.addProtobufSeries(metrics, name+"_bucket", m, float64(h.GetSampleCount()), "le", "+Inf")
+				}
+				p.addProtobufSeries(metrics, name+"_sum", m, h.GetSampleSum())
+				p.addProtobufSeries(metrics, name+"_count", m, float64(h.GetSampleCount()))
+			}
+		}
+	}
+}
+
+// addProtobufSeries adds the series with the metric labels and an optional extra label (name, value pair).
+func (p *prometheus) addProtobufSeries(metrics *Metrics, name string, m *dto.Metric, value float64, extra ...string) {
+	lbs := make(labels.Labels, 0, len(m.GetLabel())+1+len(extra)/2)
+	lbs = append(lbs, labels.Label{Name: labels.MetricName, Value: name})
+	for _, lp := range m.GetLabel() {
+		lbs = append(lbs, labels.Label{Name: lp.GetName(), Value: lp.GetValue()})
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		lbs = append(lbs, labels.Label{Name: extra[i], Value: extra[i+1]})
+	}
+	// the metric name stays first, the same way the text parser does it
+	sort.Sort(lbs[1:])
+
+	p.add(metrics, lbs, value)
+}
+
+func protobufMetricType(typ dto.MetricType) textparse.MetricType {
+	switch typ {
+	case dto.MetricType_COUNTER:
+		return textparse.MetricTypeCounter
+	case dto.MetricType_GAUGE:
+		return textparse.MetricTypeGauge
+	case dto.MetricType_SUMMARY:
+		return textparse.MetricTypeSummary
+	case dto.MetricType_HISTOGRAM:
+		return textparse.MetricTypeHistogram
+	default:
+		return textparse.MetricTypeUnknown
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,111 @@
+package relabel
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	ActionReplace   = "replace"
+	ActionKeep      = "keep"
+	ActionDrop      = "drop"
+	ActionLabelDrop = "labeldrop"
+	ActionLabelKeep = "labelkeep"
+	ActionLabelMap  = "labelmap"
+	ActionHashMod   = "hashmod"
+)
+
+const (
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+type (
+	// Config is a relabeling step, it has the same fields and defaults as the Prometheus relabel_config.
+	Config struct {
+		SourceLabels []string `yaml:"source_labels"`
+		Separator    *string  `yaml:"separator"`
+		Regex        *string  `yaml:"regex"`
+		Modulus      uint64   `yaml:"modulus"`
+		TargetLabel  string   `yaml:"target_label"`
+		Replacement  *string  `yaml:"replacement"`
+		Action       string   `yaml:"action"`
+	}
+
+	// Configs is the relabeling part of a job configuration.
+	// The module doesn't discover targets, so both lists are applied to the scraped time series,
+	// 'relabel_configs' first.
+	Configs struct {
+		RelabelConfigs       []Config `yaml:"relabel_configs"`
+		MetricRelabelConfigs []Config `yaml:"metric_relabel_configs"`
+	}
+)
+
+func (c Configs) Empty() bool {
+	return len(c.RelabelConfigs) == 0 && len(c.MetricRelabelConfigs) == 0
+}
+
+// Parse creates a Processor, it returns nil if there are no relabeling steps.
+func (c Configs) Parse() (Processor, error) {
+	if c.Empty() {
+		return nil, nil
+	}
+	var cfgs []Config
+	cfgs = append(cfgs, c.RelabelConfigs...)
+	cfgs = append(cfgs, c.MetricRelabelConfigs...)
+	return New(cfgs...)
+}
+
+func (c Config) compile() (*step, error) {
+	s := &step{
+		sourceLabels: c.SourceLabels,
+		separator:    defaultSeparator,
+		replacement:  defaultReplacement,
+		modulus:      c.Modulus,
+		targetLabel:  c.TargetLabel,
+		action:       c.Action,
+	}
+	if c.Separator != nil {
+		s.separator = *c.Separator
+	}
+	if c.Replacement != nil {
+		s.replacement = *c.Replacement
+	}
+	if s.action == "" {
+		s.action = ActionReplace
+	}
+
+	expr := defaultRegex
+	if c.Regex != nil {
+		expr = *c.Regex
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex '%s': %v", expr, err)
+	}
+	s.regex = re
+
+	switch s.action {
+	case ActionReplace:
+		if s.targetLabel == "" {
+			return nil, errors.New("'target_label' is required for 'replace' action")
+		}
+	case ActionHashMod:
+		if s.targetLabel == "" {
+			return nil, errors.New("'target_label' is required for 'hashmod' action")
+		}
+		if s.modulus == 0 {
+			return nil, errors.New("'modulus' is required for 'hashmod' action")
+		}
+	case ActionKeep, ActionDrop:
+	case ActionLabelDrop, ActionLabelKeep, ActionLabelMap:
+		if len(c.SourceLabels) > 0 || c.TargetLabel != "" {
+			return nil, fmt.Errorf("'%s' action doesn't use 'source_labels' and 'target_label'", s.action)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action '%s'", s.action)
+	}
+	return s, nil
+}
@@ -0,0 +1,180 @@
+package relabel
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+)
+
+// Processor relabels time series.
+type Processor interface {
+	// Process returns the relabeled label set, or false if the time series is dropped.
+	// The metric name label is the first one in the returned label set, the rest are sorted.
+	Process(lbs labels.Labels) (labels.Labels, bool)
+}
+
+type (
+	processor []*step
+	step      struct {
+		sourceLabels []string
+		separator    string
+		regex        *regexp.Regexp
+		modulus      uint64
+		targetLabel  string
+		replacement  string
+		action       string
+	}
+)
+
+// New creates a Processor that applies the steps in order.
+func New(cfgs ...Config) (Processor, error) {
+	p := make(processor, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		s, err := cfg.compile()
+		if err != nil {
+			return nil, fmt.Errorf("relabel config #%d: %v", i+1, err)
+		}
+		p = append(p, s)
+	}
+	return p, nil
+}
+
+func (p processor) Process(lbs labels.Labels) (labels.Labels, bool) {
+	if len(p) == 0 {
+		return lbs, true
+	}
+
+	// work on a copy, the input shares memory with the caller
+	lbs = append(make(labels.Labels, 0, len(lbs)+2), lbs...)
+	for _, s := range p {
+		var ok bool
+		if lbs, ok = s.apply(lbs); !ok {
+			return nil, false
+		}
+	}
+	return finalize(lbs)
+}
+
+func (s *step) apply(lbs labels.Labels) (labels.Labels, bool) {
+	switch s.action {
+	case ActionDrop:
+		return lbs, !s.regex.MatchString(s.sourceValue(lbs))
+	case ActionKeep:
+		return lbs, s.regex.MatchString(s.sourceValue(lbs))
+	case ActionReplace:
+		val := s.sourceValue(lbs)
+		indexes := s.regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			return lbs, true
+		}
+		target := string(s.regex.ExpandString(nil, s.targetLabel, val, indexes))
+		if !isValidLabelName(target) {
+			return lbs, true
+		}
+		res := string(s.regex.ExpandString(nil, s.replacement, val, indexes))
+		return setLabel(lbs, target, res), true
+	case ActionHashMod:
+		sum := md5.Sum([]byte(s.sourceValue(lbs)))
+		mod := binary.BigEndian.Uint64(sum[8:]) % s.modulus
+		return setLabel(lbs, s.targetLabel, strconv.FormatUint(mod, 10)), true
+	case ActionLabelDrop, ActionLabelKeep:
+		drop := s.action == ActionLabelDrop
+		res := lbs[:0]
+		for _, l := range lbs {
+			if l.Name == labels.MetricName || s.regex.MatchString(l.Name) != drop {
+				res = append(res, l)
+			}
+		}
+		return res, true
+	case ActionLabelMap:
+		for _, l := range lbs {
+			if !s.regex.MatchString(l.Name) {
+				continue
+			}
+			name := s.regex.ReplaceAllString(l.Name, s.replacement)
+			lbs = setLabel(lbs, name, l.Value)
+		}
+		return lbs, true
+	}
+	return lbs, true
+}
+
+func (s *step) sourceValue(lbs labels.Labels) string {
+	switch len(s.sourceLabels) {
+	case 0:
+		return ""
+	case 1:
+		return lbs.Get(s.sourceLabels[0])
+	}
+	values := make([]string, len(s.sourceLabels))
+	for i, name := range s.sourceLabels {
+		values[i] = lbs.Get(name)
+	}
+	return strings.Join(values, s.separator)
+}
+
+// setLabel sets the label value, an empty value removes the label.
+func setLabel(lbs labels.Labels, name, value string) labels.Labels {
+	for i, l := range lbs {
+		if l.Name != name {
+			continue
+		}
+		if value == "" {
+			return append(lbs[:i], lbs[i+1:]...)
+		}
+		lbs[i].Value = value
+		return lbs
+	}
+	if value == "" {
+		return lbs
+	}
+	return append(lbs, labels.Label{Name: name, Value: value})
+}
+
+// finalize removes temporary ('__' prefixed) labels, and sorts the label set.
+// A time series without the metric name is dropped.
+func finalize(lbs labels.Labels) (labels.Labels, bool) {
+	res := lbs[:0]
+	var hasName bool
+	for _, l := range lbs {
+		if l.Name == labels.MetricName {
+			hasName = true
+		} else if strings.HasPrefix(l.Name, "__") {
+			continue
+		}
+		res = append(res, l)
+	}
+	if !hasName {
+		return nil, false
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		switch {
+		case res[i].Name == labels.MetricName:
+			return true
+		case res[j].Name == labels.MetricName:
+			return false
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, true
+}
+
+func isValidLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
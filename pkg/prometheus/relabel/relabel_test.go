@@ -0,0 +1,261 @@
+package relabel
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"default action":                    {cfg: Config{SourceLabels: []string{"a"}, TargetLabel: "b"}},
+		"replace without target":            {cfg: Config{Action: ActionReplace, SourceLabels: []string{"a"}}, wantErr: true},
+		"keep":                              {cfg: Config{Action: ActionKeep, SourceLabels: []string{"a"}, Regex: strPtr("x")}},
+		"drop":                              {cfg: Config{Action: ActionDrop, SourceLabels: []string{"a"}, Regex: strPtr("x")}},
+		"labeldrop":                         {cfg: Config{Action: ActionLabelDrop, Regex: strPtr("pod_.*")}},
+		"labeldrop with source labels":      {cfg: Config{Action: ActionLabelDrop, SourceLabels: []string{"a"}}, wantErr: true},
+		"labelmap":                          {cfg: Config{Action: ActionLabelMap, Regex: strPtr("__meta_(.+)")}},
+		"hashmod":                           {cfg: Config{Action: ActionHashMod, SourceLabels: []string{"a"}, TargetLabel: "b", Modulus: 4}},
+		"hashmod without modulus":           {cfg: Config{Action: ActionHashMod, SourceLabels: []string{"a"}, TargetLabel: "b"}, wantErr: true},
+		"hashmod without target":            {cfg: Config{Action: ActionHashMod, SourceLabels: []string{"a"}, Modulus: 4}, wantErr: true},
+		"invalid regex":                     {cfg: Config{Action: ActionKeep, SourceLabels: []string{"a"}, Regex: strPtr("(x")}, wantErr: true},
+		"unknown action":                    {cfg: Config{Action: "rename"}, wantErr: true},
+		"replace with constant (no source)": {cfg: Config{TargetLabel: "env", Replacement: strPtr("prod")}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, p)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process(t *testing.T) {
+	input := labels.Labels{
+		{Name: labels.MetricName, Value: "http_requests_total"},
+		{Name: "code", Value: "200"},
+		{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+		{Name: "request_id", Value: "abc123"},
+	}
+
+	tests := map[string]struct {
+		cfgs     []Config
+		want     labels.Labels
+		wantDrop bool
+	}{
+		"no steps": {
+			want: input,
+		},
+		"replace": {
+			cfgs: []Config{
+				{SourceLabels: []string{"pod"}, Regex: strPtr("(.+)-[a-z0-9]+-[a-z0-9]+"), TargetLabel: "deployment"},
+			},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+				{Name: "deployment", Value: "web"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+				{Name: "request_id", Value: "abc123"},
+			},
+		},
+		"replace no match": {
+			cfgs: []Config{
+				{SourceLabels: []string{"code"}, Regex: strPtr("5.."), TargetLabel: "error", Replacement: strPtr("yes")},
+			},
+			want: input,
+		},
+		"replace empty value removes label": {
+			cfgs: []Config{
+				{TargetLabel: "request_id", Replacement: strPtr("")},
+			},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+			},
+		},
+		"replace metric name": {
+			cfgs: []Config{
+				{SourceLabels: []string{labels.MetricName}, Regex: strPtr("http_(.*)"), TargetLabel: labels.MetricName, Replacement: strPtr("web_$1")},
+			},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "web_requests_total"},
+				{Name: "code", Value: "200"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+				{Name: "request_id", Value: "abc123"},
+			},
+		},
+		"multiple source labels": {
+			cfgs: []Config{
+				{SourceLabels: []string{"code", "request_id"}, Separator: strPtr("/"), TargetLabel: "key"},
+			},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+				{Name: "key", Value: "200/abc123"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+				{Name: "request_id", Value: "abc123"},
+			},
+		},
+		"keep match": {
+			cfgs: []Config{{Action: ActionKeep, SourceLabels: []string{"code"}, Regex: strPtr("2..")}},
+			want: input,
+		},
+		"keep no match": {
+			cfgs:     []Config{{Action: ActionKeep, SourceLabels: []string{"code"}, Regex: strPtr("5..")}},
+			wantDrop: true,
+		},
+		"keep is anchored": {
+			cfgs:     []Config{{Action: ActionKeep, SourceLabels: []string{"code"}, Regex: strPtr("20")}},
+			wantDrop: true,
+		},
+		"drop match": {
+			cfgs:     []Config{{Action: ActionDrop, SourceLabels: []string{labels.MetricName}, Regex: strPtr("http_.*")}},
+			wantDrop: true,
+		},
+		"drop no match": {
+			cfgs: []Config{{Action: ActionDrop, SourceLabels: []string{labels.MetricName}, Regex: strPtr("go_.*")}},
+			want: input,
+		},
+		"labeldrop": {
+			cfgs: []Config{{Action: ActionLabelDrop, Regex: strPtr("pod|request_id")}},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+			},
+		},
+		"labeldrop doesn't drop metric name": {
+			cfgs: []Config{{Action: ActionLabelDrop, Regex: strPtr(".*")}},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+			},
+		},
+		"labelkeep": {
+			cfgs: []Config{{Action: ActionLabelKeep, Regex: strPtr("code")}},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+			},
+		},
+		"labelmap": {
+			cfgs: []Config{{Action: ActionLabelMap, Regex: strPtr("request_(.+)"), Replacement: strPtr("req_$1")}},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+				{Name: "req_id", Value: "abc123"},
+				{Name: "request_id", Value: "abc123"},
+			},
+		},
+		"hashmod": {
+			cfgs: []Config{
+				{Action: ActionHashMod, SourceLabels: []string{"pod"}, TargetLabel: "__tmp_hash", Modulus: 1},
+				{Action: ActionKeep, SourceLabels: []string{"__tmp_hash"}, Regex: strPtr("0")},
+			},
+			want: input,
+		},
+		"temporary labels are removed": {
+			cfgs: []Config{
+				{SourceLabels: []string{"code"}, TargetLabel: "__tmp_code"},
+				{SourceLabels: []string{"__tmp_code"}, TargetLabel: "status"},
+			},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "code", Value: "200"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+				{Name: "request_id", Value: "abc123"},
+				{Name: "status", Value: "200"},
+			},
+		},
+		"metric name stays first": {
+			cfgs: []Config{{TargetLabel: "Env", Replacement: strPtr("prod")}},
+			want: labels.Labels{
+				{Name: labels.MetricName, Value: "http_requests_total"},
+				{Name: "Env", Value: "prod"},
+				{Name: "code", Value: "200"},
+				{Name: "pod", Value: "web-5d8f9c7b4-x2x7z"},
+				{Name: "request_id", Value: "abc123"},
+			},
+		},
+		"removed metric name drops the series": {
+			cfgs:     []Config{{TargetLabel: labels.MetricName, Replacement: strPtr("")}},
+			wantDrop: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(test.cfgs...)
+			require.NoError(t, err)
+
+			orig := input.Copy()
+			lbs, ok := p.Process(input)
+
+			assert.Equal(t, orig, input, "input labels modified")
+			if test.wantDrop {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, test.want, lbs)
+		})
+	}
+}
+
+func TestProcessor_Process_HashMod(t *testing.T) {
+	p, err := New(Config{Action: ActionHashMod, SourceLabels: []string{"pod"}, TargetLabel: "shard", Modulus: 1000})
+	require.NoError(t, err)
+
+	// the same value as Prometheus produces for the same input
+	lbs, ok := p.Process(labels.FromStrings(labels.MetricName, "up", "pod", "foo"))
+	require.True(t, ok)
+	assert.Equal(t, "696", lbs.Get("shard"))
+}
+
+func TestConfigs_Parse(t *testing.T) {
+	p, err := Configs{}.Parse()
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	cfgs := Configs{
+		RelabelConfigs:       []Config{{TargetLabel: "env", Replacement: strPtr("prod")}},
+		MetricRelabelConfigs: []Config{{SourceLabels: []string{"env"}, TargetLabel: "stage"}},
+	}
+	p, err = cfgs.Parse()
+	require.NoError(t, err)
+
+	lbs, ok := p.Process(labels.FromStrings(labels.MetricName, "up"))
+	require.True(t, ok)
+	assert.Equal(t, "prod", lbs.Get("stage"), "relabel_configs must be applied before metric_relabel_configs")
+
+	_, err = Configs{MetricRelabelConfigs: []Config{{Action: "unknown"}}}.Parse()
+	assert.Error(t, err)
+}
+
+func BenchmarkProcessor_Process(b *testing.B) {
+	p, _ := New(
+		Config{SourceLabels: []string{"pod"}, Regex: strPtr("(.+)-[a-z0-9]+-[a-z0-9]+"), TargetLabel: "deployment"},
+		Config{Action: ActionLabelDrop, Regex: strPtr("pod|request_id")},
+	)
+	lbs := labels.FromStrings(labels.MetricName, "http_requests_total", "code", "200", "pod", "web-5d8f9c7b4-x2x7z", "request_id", "abc123")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Process(lbs)
+	}
+}
+
+func strPtr(s string) *string { return &s }